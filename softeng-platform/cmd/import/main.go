@@ -0,0 +1,135 @@
+// import 从 CSV、JSON 或 YAML 课程目录批量导入课程，包括任课教师、分类、网页资源和上传资源。
+// 课程名称和学期为自然键，已存在的课程只补充缺少的内容，同一文件可以重复导入。
+// 写入与提交课程和资源使用相同的方法：封面本地化，上传资源下载到 RESOURCE_STORAGE_DIR，
+// 新建的课程和资源按 -status 待审核或直接审核通过。
+//
+// 用法：
+//
+//	go run ./cmd/import -dry-run database/course_catalog.example.yaml
+//	go run ./cmd/import -file courses.csv -submitter admin
+//
+// 任一记录无效时不写入数据库，可用 -skip-invalid 跳过无效记录；文件格式见 README。
+// 数据库连接和资源存储与服务端相同，读取 .env 中的配置。
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/importer"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	file := flag.String("file", "", "course catalog file (.csv, .json, .yaml)")
+	format := flag.String("format", "", "file format: csv, json or yaml (default: by extension)")
+	dryRun := flag.Bool("dry-run", false, "show the changes without writing to the database")
+	skipInvalid := flag.Bool("skip-invalid", false, "import the valid records even if some records are invalid")
+	status := flag.String("status", model.CourseStatusApproved, "status of new courses and resources: approved or pending")
+	submitter := flag.String("submitter", "", "username recorded as submitter and contributor (required unless -dry-run)")
+	flag.Parse()
+
+	path := *file
+	if path == "" {
+		path = flag.Arg(0)
+	}
+	if path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *status != model.CourseStatusApproved && *status != model.CourseStatusPending {
+		log.Fatalf("invalid -status %q, must be approved or pending", *status)
+	}
+	if *submitter == "" && !*dryRun {
+		log.Fatal("-submitter is required to write courses")
+	}
+	if *format == "" {
+		detected, err := importer.FormatFromPath(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		*format = detected
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	records, err := importer.Decode(f, *format)
+	f.Close()
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", path, err)
+	}
+
+	cfg := config.LoadConfig()
+	db, err := repository.NewDatabase(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	opts := importer.Options{
+		DryRun:      *dryRun,
+		SkipInvalid: *skipInvalid,
+		Status:      *status,
+		StorageDir:  cfg.ResourceStorageDir,
+		MaxFileSize: int64(cfg.MaxResourceSizeMB) * 1024 * 1024,
+	}
+	if *submitter != "" {
+		user, err := repository.NewUserRepository(db).GetByUsername(ctx, *submitter)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if user == nil {
+			log.Fatalf("User %q not found", *submitter)
+		}
+		opts.SubmitterID = user.ID
+	}
+
+	report, err := importer.New(repository.NewCourseImportRepository(db), repository.NewCourseRepository(db), opts).Run(ctx, records)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printReport(report, *dryRun)
+	if report.Invalid > 0 || report.Failed > 0 {
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+func printReport(report *importer.Report, dryRun bool) {
+	for _, result := range report.Results {
+		title := result.Name
+		if result.Semester != "" {
+			title += " / " + result.Semester
+		}
+		if result.CourseID > 0 {
+			title += fmt.Sprintf(" [course %d]", result.CourseID)
+		}
+		fmt.Printf("%-10s %s %s\n", result.Action, result.Position(), title)
+		for _, change := range result.Changes {
+			fmt.Printf("           %s\n", change)
+		}
+		for _, msg := range result.Errors {
+			fmt.Printf("           ! %s\n", msg)
+		}
+	}
+
+	fmt.Printf("\n%d records: %d create, %d update, %d unchanged, %d invalid, %d failed\n",
+		len(report.Results), report.Created, report.Updated, report.Unchanged, report.Invalid, report.Failed)
+	switch {
+	case report.Applied:
+		fmt.Println("Changes written to the database.")
+	case dryRun:
+		fmt.Println("Dry run: nothing written.")
+	default:
+		fmt.Println("Nothing written: fix the invalid records or use -skip-invalid.")
+	}
+}
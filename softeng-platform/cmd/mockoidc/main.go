@@ -0,0 +1,196 @@
+// mockoidc 本地开发/联调用的最小 OIDC 提供方，授权页自动同意，支持 PKCE（S256）校验。
+//
+// 用法：
+//
+//	go run ./cmd/mockoidc -addr :9000
+//
+// 平台侧配置：
+//
+//	OAUTH_PROVIDERS=mock
+//	OAUTH_MOCK_CLIENT_ID=softeng
+//	OAUTH_MOCK_CLIENT_SECRET=secret
+//	OAUTH_MOCK_ISSUER=http://localhost:9000
+//
+// 授权地址可附加 login_hint=<用户名> 模拟不同用户，邮箱为 <用户名>@example.edu。
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"softeng-platform/internal/oauth"
+	"strings"
+	"sync"
+)
+
+type authCode struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	login         string
+}
+
+type server struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+
+	mu     sync.Mutex
+	codes  map[string]authCode
+	tokens map[string]string // access token -> login
+}
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer url")
+	clientID := flag.String("client-id", "softeng", "expected client id")
+	clientSecret := flag.String("client-secret", "secret", "expected client secret")
+	flag.Parse()
+
+	s := newServer(*issuer, *clientID, *clientSecret)
+
+	log.Printf("Mock OIDC provider listening on %s (issuer %s)", *addr, s.issuer)
+	log.Fatal(http.ListenAndServe(*addr, s.routes()))
+}
+
+func newServer(issuer, clientID, clientSecret string) *server {
+	return &server{
+		issuer:       strings.TrimRight(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		codes:        make(map[string]authCode),
+		tokens:       make(map[string]string),
+	}
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/userinfo", s.userinfo)
+	return mux
+}
+
+func (s *server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                           s.issuer,
+		"authorization_endpoint":           s.issuer + "/authorize",
+		"token_endpoint":                   s.issuer + "/token",
+		"userinfo_endpoint":                s.issuer + "/userinfo",
+		"response_types_supported":         []string{"code"},
+		"code_challenge_methods_supported": []string{"S256"},
+	})
+}
+
+func (s *server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != s.clientID {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "pkce (S256) is required", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	login := q.Get("login_hint")
+	if login == "" {
+		login = "student"
+	}
+
+	code := randomHex()
+	s.mu.Lock()
+	s.codes[code] = authCode{
+		clientID:      s.clientID,
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		login:         login,
+	}
+	s.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("client_id") != s.clientID || r.PostForm.Get("client_secret") != s.clientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	code, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || code.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if oauth.CodeChallengeS256(r.PostForm.Get("code_verifier")) != code.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "pkce verification failed"})
+		return
+	}
+
+	accessToken := randomHex()
+	s.mu.Lock()
+	s.tokens[accessToken] = code.login
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *server) userinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	login, ok := s.tokens[accessToken]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":                "mock-" + login,
+		"email":              login + "@example.edu",
+		"email_verified":     true,
+		"name":               login,
+		"preferred_username": login,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomHex() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/handler"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/oauth"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/service"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const platformURL = "http://platform.test"
+
+// fakeIdentities 内存中的授权请求状态和第三方账号
+type fakeIdentities struct {
+	repository.IdentityRepository
+	mu         sync.Mutex
+	states     map[string]model.OAuthState
+	identities []model.Identity
+}
+
+func (r *fakeIdentities) CreateState(ctx context.Context, state *model.OAuthState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[state.StateHash] = *state
+	return nil
+}
+
+func (r *fakeIdentities) ConsumeState(ctx context.Context, stateHash string) (*model.OAuthState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[stateHash]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.states, stateHash)
+	return &state, nil
+}
+
+func (r *fakeIdentities) GetByProviderSubject(ctx context.Context, provider, subject string) (*model.Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeIdentities) ListByUser(ctx context.Context, userID int) ([]model.Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var identities []model.Identity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *fakeIdentities) Create(ctx context.Context, identity *model.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identity.ID = len(r.identities) + 1
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *fakeIdentities) TouchLogin(ctx context.Context, id int) error {
+	return nil
+}
+
+type fakeUsers struct {
+	repository.UserRepository
+	users map[int]*model.User
+}
+
+func (r *fakeUsers) GetByID(ctx context.Context, id int) (*model.User, error) {
+	return r.users[id], nil
+}
+
+type fakeTokens struct {
+	service.TokenService
+}
+
+func (fakeTokens) IssueTokens(ctx context.Context, user *model.User, client model.ClientInfo, mfa bool) (*model.TokenPair, error) {
+	return &model.TokenPair{AccessToken: "access-" + user.Username, RefreshToken: "refresh", ExpiresIn: 900}, nil
+}
+
+type fakeMFA struct {
+	service.MFAService
+}
+
+func (fakeMFA) IsEnabled(ctx context.Context, userID int) (bool, error) {
+	return false, nil
+}
+
+func (fakeMFA) RequiredFor(role string) bool {
+	return false
+}
+
+// fakeAudit 记录审计事件类型
+type fakeAudit struct {
+	service.AuditService
+	mu     sync.Mutex
+	events []string
+}
+
+func (a *fakeAudit) Record(ctx context.Context, event model.AuthEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event.Type)
+}
+
+type testPlatform struct {
+	router     *gin.Engine
+	provider   *httptest.Server
+	identities *fakeIdentities
+	guard      *loginguard.Guard
+	audit      *fakeAudit
+}
+
+// newTestPlatform 启动 mock 提供方，并用它配置平台的第三方登录路由；
+// 用户 1 未绑定，用户 2（student）和用户 3（locked）已绑定 mock 账号
+func newTestPlatform(t *testing.T) *testPlatform {
+	gin.SetMode(gin.ReleaseMode)
+
+	s := newServer("", "softeng", "secret")
+	provider := httptest.NewServer(s.routes())
+	t.Cleanup(provider.Close)
+	s.issuer = provider.URL
+
+	cfg := &config.Config{
+		OAuthProviders: []config.OAuthProvider{{
+			Name: "mock", Type: "oidc", ClientID: "softeng", ClientSecret: "secret",
+			Issuer: provider.URL, Scopes: []string{"openid", "email"},
+		}},
+		OAuthRedirectBaseURL: platformURL,
+		OAuthStateTTL:        10 * time.Minute,
+		LoginFreeAttempts:    3,
+		LoginBackoffBase:     time.Second,
+		LoginBackoffMax:      time.Minute,
+		LoginLockThreshold:   5,
+		LoginLockDuration:    time.Minute,
+	}
+
+	identities := &fakeIdentities{
+		states: make(map[string]model.OAuthState),
+		identities: []model.Identity{
+			{ID: 1, UserID: 2, Provider: "mock", Subject: "mock-student"},
+			{ID: 2, UserID: 3, Provider: "mock", Subject: "mock-locked"},
+		},
+	}
+	users := &fakeUsers{users: map[int]*model.User{
+		1: {ID: 1, Username: "teacher", Role: model.RoleUser},
+		2: {ID: 2, Username: "student", Role: model.RoleUser},
+		3: {ID: 3, Username: "locked", Role: model.RoleUser},
+	}}
+	guard := loginguard.NewGuard(loginguard.NewMemoryTracker(), cfg)
+	audit := &fakeAudit{}
+	oauthService := service.NewOAuthService(oauth.NewRegistry(cfg, provider.Client()), identities, users, nil, fakeTokens{}, fakeMFA{}, guard, audit, cfg)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+
+	r := gin.New()
+	r.GET("/auth/oauth/:provider", oauthHandler.Start)
+	r.GET("/auth/oauth/:provider/callback", oauthHandler.Callback)
+	r.POST("/users/oauth/:provider", func(c *gin.Context) { c.Set("userID", 1) }, oauthHandler.Link)
+
+	return &testPlatform{router: r, provider: provider, identities: identities, guard: guard, audit: audit}
+}
+
+func (p *testPlatform) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	p.router.ServeHTTP(w, req)
+	return w
+}
+
+// start 在平台发起授权，返回授权地址和写入浏览器的 state Cookie
+func (p *testPlatform) start(t *testing.T, link bool) (string, *http.Cookie) {
+	var w *httptest.ResponseRecorder
+	var authURL string
+	if link {
+		w = p.serve(httptest.NewRequest(http.MethodPost, "/users/oauth/mock", nil))
+		var body struct {
+			Data struct {
+				AuthorizationURL string `json:"authorization_url"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("link response %s: %v", w.Body, err)
+		}
+		authURL = body.Data.AuthorizationURL
+	} else {
+		w = p.serve(httptest.NewRequest(http.MethodGet, "/auth/oauth/mock", nil))
+		if w.Code != http.StatusFound {
+			t.Fatalf("start = %d %s", w.Code, w.Body)
+		}
+		authURL = w.Header().Get("Location")
+	}
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "oauth_state" {
+			if !cookie.HttpOnly || cookie.Path != "/auth/oauth" || cookie.SameSite != http.SameSiteLaxMode {
+				t.Errorf("state cookie = %+v", cookie)
+			}
+			return authURL, cookie
+		}
+	}
+	t.Fatalf("no state cookie in %v", w.Header())
+	return "", nil
+}
+
+// authorize 以 login 的身份在 mock 提供方授权，返回平台回调的路径和参数
+func (p *testPlatform) authorize(t *testing.T, authURL, login string) string {
+	client := p.provider.Client()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Get(authURL + "&login_hint=" + url.QueryEscape(login))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+
+	callback := resp.Header.Get("Location")
+	if !strings.HasPrefix(callback, platformURL+"/auth/oauth/mock/callback?") {
+		t.Fatalf("authorize redirected to %q", callback)
+	}
+	return strings.TrimPrefix(callback, platformURL)
+}
+
+func TestOAuthCallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		link       bool
+		login      string
+		cookie     string // same：发起授权的浏览器；other：另一次授权的 state；none：没有 Cookie
+		locked     bool
+		wantCode   int
+		wantBody   string
+		wantEvents []string
+	}{
+		{name: "login", login: "student", cookie: "same", wantCode: http.StatusOK, wantBody: "access-student", wantEvents: []string{model.AuthEventLogin}},
+		{name: "login without cookie", login: "student", cookie: "none", wantCode: http.StatusUnauthorized, wantEvents: []string{model.AuthEventLoginFailed}},
+		{name: "login with another state", login: "student", cookie: "other", wantCode: http.StatusUnauthorized, wantEvents: []string{model.AuthEventLoginFailed}},
+		{name: "locked account", login: "locked", cookie: "same", locked: true, wantCode: http.StatusTooManyRequests, wantBody: "account_locked", wantEvents: []string{model.AuthEventLoginBlocked}},
+		{name: "link", link: true, login: "teacher-gh", cookie: "same", wantCode: http.StatusOK, wantBody: "Account linked successfully", wantEvents: []string{model.AuthEventIdentityLinked}},
+		{name: "link without cookie", link: true, login: "teacher-gh", cookie: "none", wantCode: http.StatusUnauthorized, wantEvents: []string{model.AuthEventLoginFailed}},
+		{name: "link taken identity", link: true, login: "student", cookie: "same", wantCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlatform(t)
+			if tt.locked {
+				for i := 0; i < 5; i++ {
+					_ = p.guard.Fail(context.Background(), loginguard.UserKey(3))
+				}
+			}
+
+			authURL, cookie := p.start(t, tt.link)
+			callback := p.authorize(t, authURL, tt.login)
+
+			req := httptest.NewRequest(http.MethodGet, callback, nil)
+			switch tt.cookie {
+			case "same":
+				req.AddCookie(cookie)
+			case "other":
+				_, other := p.start(t, tt.link)
+				req.AddCookie(other)
+			}
+			w := p.serve(req)
+			if w.Code != tt.wantCode || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Fatalf("callback = %d %s, want %d containing %q", w.Code, w.Body, tt.wantCode, tt.wantBody)
+			}
+
+			// 回调总是清除 state Cookie
+			cleared := false
+			for _, c := range w.Result().Cookies() {
+				cleared = cleared || (c.Name == "oauth_state" && c.MaxAge < 0)
+			}
+			if !cleared {
+				t.Errorf("state cookie not cleared: %v", w.Header())
+			}
+			if strings.Join(p.audit.events, ",") != strings.Join(tt.wantEvents, ",") {
+				t.Errorf("audit events = %v, want %v", p.audit.events, tt.wantEvents)
+			}
+
+			if tt.link && tt.wantCode == http.StatusOK {
+				identities, _ := p.identities.ListByUser(context.Background(), 1)
+				if len(identities) != 1 || identities[0].Subject != "mock-"+tt.login {
+					t.Errorf("user 1 identities = %+v", identities)
+				}
+			}
+		})
+	}
+}
+
+// 同一浏览器的 state 不匹配时，授权请求保持有效，正确的回调仍可完成登录
+func TestOAuthCallbackKeepsStateOnMismatch(t *testing.T) {
+	p := newTestPlatform(t)
+	authURL, cookie := p.start(t, false)
+	callback := p.authorize(t, authURL, "student")
+
+	if w := p.serve(httptest.NewRequest(http.MethodGet, callback, nil)); w.Code != http.StatusUnauthorized {
+		t.Fatalf("callback without cookie = %d %s", w.Code, w.Body)
+	}
+	req := httptest.NewRequest(http.MethodGet, callback, nil)
+	req.AddCookie(cookie)
+	if w := p.serve(req); w.Code != http.StatusOK {
+		t.Fatalf("callback with cookie = %d %s", w.Code, w.Body)
+	}
+}
@@ -7,9 +7,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"softeng-platform/internal/analyzer"
 	"softeng-platform/internal/config"
 	"softeng-platform/internal/handler"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/mailer"
 	"softeng-platform/internal/middleware"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/oauth"
 	"softeng-platform/internal/repository"
 	"softeng-platform/internal/service"
 	"softeng-platform/pkg/response"
@@ -67,36 +72,114 @@ func main() {
 	toolRepo := repository.NewToolRepository(db)
 	courseRepo := repository.NewCourseRepository(db)
 	projectRepo := repository.NewProjectRepository(db)
+	verificationRepo := repository.NewVerificationRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db, verificationRepo)
+	tokenRepo := repository.NewTokenRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	personalTokenRepo := repository.NewPersonalTokenRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	accountRepo := repository.NewAccountRepository(db)
+	authEventRepo := repository.NewAuthEventRepository(db)
+	learningPlanRepo := repository.NewLearningPlanRepository(db)
+	teacherRepo := repository.NewTeacherRepository(db)
+	semesterRepo := repository.NewSemesterRepository(db)
+	calendarRepo := repository.NewCalendarRepository(db)
+
+	// 初始化邮件发送器
+	mail, err := mailer.NewMailer(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize mailer:", err)
+	}
+
+	// 初始化登录防暴力破解
+	loginGuard := loginguard.NewGuard(loginguard.NewTracker(cfg, db), cfg)
+
+	// 初始化第三方登录提供方
+	oauthRegistry := oauth.NewRegistry(cfg, nil)
 
 	// 初始化服务
-	authService := service.NewAuthService(userRepo)
-	userService := service.NewUserService(userRepo, toolRepo, projectRepo)
+	verificationService := service.NewVerificationService(verificationRepo, mail)
+	auditService := service.NewAuditService(authEventRepo, cfg)
+	tokenService := service.NewTokenService(tokenRepo, personalTokenRepo, userRepo, auditService, cfg)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, loginGuard, auditService, cfg)
+	authService := service.NewAuthService(userRepo, invitationRepo, verificationService, tokenService, mfaService, loginGuard, auditService)
+	userService := service.NewUserService(userRepo, toolRepo, projectRepo, courseRepo, verificationService, tokenService, loginGuard, auditService)
 	toolService := service.NewToolService(toolRepo)
-	courseService := service.NewCourseService(courseRepo)
+	courseAnalyzer := analyzer.New(analyzer.NewHTTPFetcher(
+		analyzer.NewDefaultClient(cfg.CourseAnalyzerTimeout, cfg.CourseAnalyzerAllowPrivate),
+		int64(cfg.CourseAnalyzerMaxPageKB)*1024,
+	))
+	courseService := service.NewCourseService(courseRepo, cfg, courseAnalyzer)
+	learningPlanService := service.NewLearningPlanService(learningPlanRepo, cfg)
+	teacherService := service.NewTeacherService(teacherRepo)
+	semesterService := service.NewSemesterService(semesterRepo, courseRepo)
+	calendarService := service.NewCalendarService(calendarRepo, courseRepo, semesterRepo, cfg)
 	projectService := service.NewProjectService(projectRepo)
-	adminService := service.NewAdminService(toolRepo, courseRepo, projectRepo)
+	adminService := service.NewAdminService(toolRepo, courseRepo, projectRepo, userRepo, commentRepo, tokenService, auditService)
+	invitationService := service.NewInvitationService(invitationRepo)
+	personalTokenService := service.NewPersonalTokenService(personalTokenRepo, auditService)
+	oauthService := service.NewOAuthService(oauthRegistry, identityRepo, userRepo, invitationRepo, tokenService, mfaService, loginGuard, auditService, cfg)
+	accountService := service.NewAccountService(accountRepo, userRepo, identityRepo, mfaService, tokenService, loginGuard, auditService, cfg)
 
 	// 初始化处理器
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(userService)
 	toolHandler := handler.NewToolHandler(toolService)
-	courseHandler := handler.NewCourseHandler(courseService)
+	courseHandler := handler.NewCourseHandler(courseService, int64(cfg.MaxResourceSizeMB)*1024*1024)
+	learningPlanHandler := handler.NewLearningPlanHandler(learningPlanService)
+	teacherHandler := handler.NewTeacherHandler(teacherService)
+	semesterHandler := handler.NewSemesterHandler(semesterService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
 	projectHandler := handler.NewProjectHandler(projectService)
 	adminHandler := handler.NewAdminHandler(adminService)
+	invitationHandler := handler.NewInvitationHandler(invitationService)
+	sessionHandler := handler.NewSessionHandler(tokenService)
+	personalTokenHandler := handler.NewPersonalTokenHandler(personalTokenService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	auditHandler := handler.NewAuditHandler(auditService)
 	uploadHandler := handler.NewUploadHandler()
 
+	// 定期清理过期的刷新令牌、吊销记录、第三方授权请求和审计日志，并匿名化冷静期已结束的注销账号
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := tokenService.Cleanup(context.Background()); err != nil {
+				log.Printf("Token cleanup error: %v", err)
+			}
+			if err := oauthService.Cleanup(context.Background()); err != nil {
+				log.Printf("OAuth state cleanup error: %v", err)
+			}
+			if err := auditService.Cleanup(context.Background()); err != nil {
+				log.Printf("Auth event cleanup error: %v", err)
+			}
+			if err := accountService.PurgeDue(context.Background()); err != nil {
+				log.Printf("Account purge error: %v", err)
+			}
+		}
+	}()
+
 	// 设置路由
-	r := gin.Default()
+	// 请求日志隐藏个人日历订阅令牌
+	r := gin.New()
+	r.Use(middleware.Logger(), gin.Recovery())
+	if err := middleware.TrustProxies(r, cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 	
 	// 强制输出调试信息，确认代码已重新编译
 	log.Println("=== Server starting with updated routes ===")
 
 	// 中间件
 	r.Use(middleware.CORS())
+	r.Use(middleware.ClientInfo()) // 客户端信息写入请求 context，用于审计日志
 	
 	// 添加请求日志中间件（用于调试）
 	r.Use(func(c *gin.Context) {
-		log.Printf("[DEBUG] Request: %s %s", c.Request.Method, c.Request.URL.Path)
+		log.Printf("[DEBUG] Request: %s %s", c.Request.Method, middleware.RedactPath(c.Request.URL.Path))
 		c.Next()
 	})
 
@@ -111,14 +194,43 @@ func main() {
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/login/mfa", authHandler.VerifyMFA) // 登录第二步：两步验证
 		auth.POST("/forgot-password", authHandler.ForgotPassword)
+		auth.POST("/send-code", authHandler.SendCode)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.GET("/oauth/providers", oauthHandler.ListProviders)          // 可用的第三方登录方式
+		auth.GET("/oauth/:provider", oauthHandler.Start)                  // 跳转到第三方授权页
+		auth.GET("/oauth/:provider/callback", oauthHandler.Callback)      // 第三方授权回调
 	}
 
 	// 用户路由
 	users := r.Group("/users")
-	users.Use(middleware.AuthMiddleware())
+	users.Use(middleware.AuthMiddleware(tokenService))
 	{
-		users.POST("/logout", userHandler.Logout)
+		users.POST("/logout", authHandler.Logout)
+		users.POST("/logout-all", authHandler.LogoutAll) // 登出全部设备
+		users.GET("/sessions", sessionHandler.ListSessions)                // 已登录设备列表
+		users.DELETE("/sessions/:sessionId", sessionHandler.RevokeSession) // 注销指定设备
+		users.GET("/tokens", personalTokenHandler.ListTokens)              // 个人访问令牌列表
+		users.POST("/tokens", personalTokenHandler.CreateToken)            // 创建个人访问令牌
+		users.DELETE("/tokens/:tokenId", personalTokenHandler.RevokeToken) // 吊销个人访问令牌
+		users.GET("/mfa", mfaHandler.GetStatus)                            // 两步验证状态
+		users.POST("/mfa/setup", mfaHandler.Setup)                         // 生成密钥和二维码链接
+		users.POST("/mfa/enable", mfaHandler.Enable)                       // 确认启用，返回恢复码
+		users.POST("/mfa/disable", mfaHandler.Disable)                     // 关闭两步验证
+		users.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes) // 重新生成恢复码
+		users.GET("/oauth", oauthHandler.ListIdentities)                   // 已绑定的第三方账号
+		users.POST("/oauth/:provider", oauthHandler.Link)                  // 绑定第三方账号
+		users.DELETE("/oauth/:provider", oauthHandler.Unlink)              // 解绑第三方账号
+		users.GET("/export", accountHandler.Export)                       // 导出个人数据（format=json/zip）
+		users.DELETE("/account", accountHandler.DeleteAccount)            // 申请注销账号
+		users.GET("/account/deletion", accountHandler.GetDeletion)         // 注销申请状态
+		users.POST("/account/restore", accountHandler.CancelDeletion)      // 冷静期内撤销注销
+		users.GET("/security-log", auditHandler.GetSecurityLog)          // 安全日志
+		users.GET("/learning-plan", learningPlanHandler.GetPlan)         // 学习计划及各学期学分
+		users.PUT("/learning-plan/order", learningPlanHandler.Reorder)   // 调整学期内课程顺序
+		users.POST("/calendar-feed", calendarHandler.CreateFeed)         // 生成个人日历订阅地址（旧地址失效）
+		users.DELETE("/calendar-feed", calendarHandler.RevokeFeed)       // 停用个人日历订阅地址
 		users.GET("/profile", userHandler.GetProfile)
 		users.GET("/status", userHandler.GetStatus)
 		users.GET("/collection", userHandler.GetCollection)
@@ -136,29 +248,30 @@ func main() {
 		// 具体路由放在前面
 		tools.GET("/profile", toolHandler.GetTools)                                                    // 获取工具列表
 		tools.GET("/search", toolHandler.SearchTools)                                                  // 搜索工具
-		tools.POST("/submit", middleware.AuthMiddleware(), toolHandler.SubmitTool)                    // 提交工具
+		tools.POST("/submit", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), toolHandler.SubmitTool)                    // 提交工具
 		
 		// 更具体的参数路由放在前面
 		tools.GET("/:resourceId/comments", toolHandler.GetComments)                                   // 获取工具评论（新增）
-		tools.POST("/:resourceId/comments", middleware.AuthMiddleware(), toolHandler.AddComment)      // 发表评论
-		tools.DELETE("/:resourceId/comments/:commentId", middleware.AuthMiddleware(), toolHandler.DeleteComment) // 删除评论（修正路径）
-		tools.POST("/:resourceId/comments/:commentId/like", middleware.AuthMiddleware(), toolHandler.LikeComment) // 点赞评论（新增）
-		tools.POST("/:resourceId/comments/:commentId/reply", middleware.AuthMiddleware(), toolHandler.ReplyComment) // 回复评论
-		tools.DELETE("/:resourceId/comments/:commentId/reply", middleware.AuthMiddleware(), toolHandler.DeleteReply) // 删除回复
+		tools.POST("/:resourceId/comments", middleware.AuthMiddleware(tokenService, model.ScopeComment), toolHandler.AddComment)      // 发表评论
+		tools.DELETE("/:resourceId/comments/:commentId", middleware.AuthMiddleware(tokenService, model.ScopeComment), toolHandler.DeleteComment) // 删除评论（修正路径）
+		tools.POST("/:resourceId/comments/:commentId/like", middleware.AuthMiddleware(tokenService, model.ScopeComment), toolHandler.LikeComment) // 点赞评论（新增）
+		tools.POST("/:resourceId/comments/:commentId/reply", middleware.AuthMiddleware(tokenService, model.ScopeComment), toolHandler.ReplyComment) // 回复评论
+		tools.DELETE("/:resourceId/comments/:commentId/reply", middleware.AuthMiddleware(tokenService, model.ScopeComment), toolHandler.DeleteReply) // 删除回复
 		tools.POST("/:resourceId/views", toolHandler.AddView)                                         // 增加浏览量
-		tools.POST("/:resourceId/collections", middleware.AuthMiddleware(), toolHandler.CollectTool)  // 收藏工具
-		tools.DELETE("/:resourceId/collections", middleware.AuthMiddleware(), toolHandler.UncollectTool) // 取消收藏
-		tools.POST("/:resourceId/like", middleware.AuthMiddleware(), toolHandler.LikeTool)            // 点赞工具
-		tools.DELETE("/:resourceId/like", middleware.AuthMiddleware(), toolHandler.UnlikeTool)        // 取消点赞
+		tools.POST("/:resourceId/collections", middleware.AuthMiddleware(tokenService), toolHandler.CollectTool)  // 收藏工具
+		tools.DELETE("/:resourceId/collections", middleware.AuthMiddleware(tokenService), toolHandler.UncollectTool) // 取消收藏
+		tools.POST("/:resourceId/like", middleware.AuthMiddleware(tokenService), toolHandler.LikeTool)            // 点赞工具
+		tools.DELETE("/:resourceId/like", middleware.AuthMiddleware(tokenService), toolHandler.UnlikeTool)        // 取消点赞
+		tools.GET("/:resourceId/revisions", toolHandler.GetToolRevisions)                              // 工具修改记录（含字段差异）
 		
 		// 最通用的参数路由放在最后
 		tools.GET("/:resourceId", toolHandler.GetTool)                                                 // 获取工具详情
-		tools.PUT("/:resourceId", middleware.AuthMiddleware(), toolHandler.UpdateTool)                // 更新工具
+		tools.PUT("/:resourceId", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), toolHandler.UpdateTool)                // 提议修改工具（审核通过后生效）
 	}
 	
 	// 添加 NoRoute handler 用于调试
 	r.NoRoute(func(c *gin.Context) {
-		log.Printf("[DEBUG] NoRoute: Method=%s, Path=%s", c.Request.Method, c.Request.URL.Path)
+		log.Printf("[DEBUG] NoRoute: Method=%s, Path=%s", c.Request.Method, middleware.RedactPath(c.Request.URL.Path))
 		response.Error(c, http.StatusNotFound, fmt.Sprintf("Route not found: %s %s", c.Request.Method, c.Request.URL.Path))
 	})
 
@@ -168,64 +281,113 @@ func main() {
 		course.GET("", courseHandler.GetCourses)                         // 获取课程列表
 		course.GET("/search", courseHandler.SearchCourses)               // 搜索课程
 		course.GET("/:courseId", courseHandler.GetCourse)                // 获取课程详情
-		course.POST("/submit", middleware.AuthMiddleware(), courseHandler.SubmitCourse) // 提交课程（新增）
+		course.POST("/submit", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), courseHandler.SubmitCourse) // 提交课程（新增）
+		course.POST("/analyze", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), courseHandler.AnalyzeCourse) // 分析课程链接，返回提交预填信息
 		course.POST("/:courseId/view", courseHandler.AddView)            // 增加浏览量
-		course.POST("/:courseId/collections", middleware.AuthMiddleware(), courseHandler.CollectCourse) // 收藏课程
-		course.DELETE("/:courseId/collections", middleware.AuthMiddleware(), courseHandler.UncollectCourse) // 取消收藏
-		course.POST("/:courseId/like", middleware.AuthMiddleware(), courseHandler.LikeCourse) // 点赞课程
-		course.DELETE("/:courseId/like", middleware.AuthMiddleware(), courseHandler.UnlikeCourse) // 取消点赞
+		course.POST("/:courseId/collections", middleware.AuthMiddleware(tokenService), courseHandler.CollectCourse) // 收藏课程
+		course.DELETE("/:courseId/collections", middleware.AuthMiddleware(tokenService), courseHandler.UncollectCourse) // 取消收藏
+		course.POST("/:courseId/like", middleware.AuthMiddleware(tokenService), courseHandler.LikeCourse) // 点赞课程
+		course.DELETE("/:courseId/like", middleware.AuthMiddleware(tokenService), courseHandler.UnlikeCourse) // 取消点赞
 		course.GET("/:courseId/comments", courseHandler.GetComments)     // 获取课程评论（新增）
-		course.POST("/:courseId/comments", middleware.AuthMiddleware(), courseHandler.AddComment) // 发表评论
-		course.DELETE("/:courseId/comments/:commentId", middleware.AuthMiddleware(), courseHandler.DeleteComment) // 删除评论
-		course.POST("/:courseId/comments/:commentId/like", middleware.AuthMiddleware(), courseHandler.LikeComment) // 点赞评论（新增）
-		course.POST("/:courseId/comments/:commentId/reply", middleware.AuthMiddleware(), courseHandler.ReplyComment) // 回复评论
-		course.DELETE("/:courseId/comments/:commentId/reply", middleware.AuthMiddleware(), courseHandler.DeleteReply) // 删除回复
+		course.POST("/:courseId/comments", middleware.AuthMiddleware(tokenService, model.ScopeComment), courseHandler.AddComment) // 发表评论
+		course.DELETE("/:courseId/comments/:commentId", middleware.AuthMiddleware(tokenService, model.ScopeComment), courseHandler.DeleteComment) // 删除评论
+		course.POST("/:courseId/comments/:commentId/like", middleware.AuthMiddleware(tokenService, model.ScopeComment), courseHandler.LikeComment) // 点赞评论（新增）
+		course.POST("/:courseId/comments/:commentId/reply", middleware.AuthMiddleware(tokenService, model.ScopeComment), courseHandler.ReplyComment) // 回复评论
+		course.DELETE("/:courseId/comments/:commentId/reply", middleware.AuthMiddleware(tokenService, model.ScopeComment), courseHandler.DeleteReply) // 删除回复
 		course.GET("/:courseId/resources", courseHandler.GetResources)   // 获取课程资源（新增）
-		course.POST("/:courseId/resources", middleware.AuthMiddleware(), courseHandler.UploadResource) // 上传资源（改为resources）
-		course.GET("/:courseId/textbooks/:textbookId/download", middleware.AuthMiddleware(), courseHandler.DownloadTextbook) // 下载课本
-		// 以下为前端定义但可能暂时不实现的功能
-		// course.POST("/:courseId/learning-plan", ...) // 加入学习计划
-		// course.DELETE("/:courseId/learning-plan", ...) // 从学习计划移除
-		// course.GET("/:courseId/progress", ...) // 获取学习进度
-		// course.PUT("/:courseId/progress/:chapterId", ...) // 更新学习进度
-		// course.POST("/:courseId/rating", ...) // 添加课程评分
-		// course.POST("/analyze", ...) // 分析课程链接
+		course.POST("/:courseId/resources", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), courseHandler.UploadResource) // 上传资源（改为resources）
+		course.GET("/:courseId/textbooks/:textbookId/download", middleware.AuthMiddleware(tokenService), courseHandler.DownloadTextbook) // 下载课本
+		course.POST("/:courseId/learning-plan", middleware.AuthMiddleware(tokenService), learningPlanHandler.AddCourse) // 加入学习计划（已加入时更新学期和状态）
+		course.DELETE("/:courseId/learning-plan", middleware.AuthMiddleware(tokenService), learningPlanHandler.RemoveCourse) // 从学习计划移除
+		course.GET("/:courseId/chapters", courseHandler.GetChapters)     // 获取章节大纲
+		course.POST("/:courseId/chapters", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), courseHandler.SubmitChapter) // 提交章节（待审核）
+		course.GET("/:courseId/progress", middleware.AuthMiddleware(tokenService), courseHandler.GetProgress) // 获取学习进度
+		course.PUT("/:courseId/progress/:chapterId", middleware.AuthMiddleware(tokenService), courseHandler.UpdateProgress) // 更新学习进度
+		course.GET("/:courseId/rating", courseHandler.GetRatings)        // 获取课程评分和评价
+		course.POST("/:courseId/rating", middleware.AuthMiddleware(tokenService, model.ScopeComment), courseHandler.RateCourse) // 添加课程评分
+		course.GET("/:courseId/prerequisites", courseHandler.GetPrerequisites) // 获取先修 / 同修课程（含间接先修和先修环）
+		course.POST("/:courseId/prerequisites", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), courseHandler.SubmitPrerequisite) // 提交修读关系（待审核）
+		course.GET("/prerequisites/graph", courseHandler.GetCurriculumGraph) // 导出课程关系图（format=json|dot）
+		course.POST("/prerequisites/check", courseHandler.CheckPrerequisites) // 检查按学期排列的修读安排
+		course.GET("/:courseId/events", calendarHandler.GetCourseEvents) // 获取课程的截止时间、考试和活动
+		course.POST("/:courseId/events", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), calendarHandler.AddCourseEvent) // 添加课程事件（课程贡献者）
+		course.DELETE("/:courseId/events/:eventId", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), calendarHandler.DeleteCourseEvent) // 删除课程事件
+		course.GET("/calendar.ics", calendarHandler.CourseCalendar)      // 课程日历订阅（iCalendar，支持 course_id 和学期筛选）
+		course.GET("/calendar/:token", calendarHandler.UserCalendar)     // 个人日历订阅（收藏和学习计划中的课程，令牌即凭证）
 	}
 
+	// 教师路由
+	teachers := r.Group("/teachers")
+	{
+		teachers.GET("", teacherHandler.ListTeachers)             // 获取教师列表
+		teachers.GET("/:teacherId", teacherHandler.GetTeacher)    // 获取教师详情及讲授的课程
+	}
+
+	// 学期目录
+	r.GET("/semesters", semesterHandler.ListSemesters) // 获取学期列表（按时间先后排列）
+
 	// 项目路由
 	projects := r.Group("/projects")
 	{
 		projects.GET("/profile", projectHandler.GetProjects)
 		projects.GET("/search", projectHandler.SearchProjects)
 		projects.GET("/:projectId", projectHandler.GetProject)
-		projects.PUT("/:projectId", middleware.AuthMiddleware(), projectHandler.UpdateProject)
-		projects.POST("/upload", middleware.AuthMiddleware(), projectHandler.UploadProject)
-		projects.POST("/:projectId/like", middleware.AuthMiddleware(), projectHandler.LikeProject)
-		projects.DELETE("/:projectId/like", middleware.AuthMiddleware(), projectHandler.UnlikeProject)
+		projects.PUT("/:projectId", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), projectHandler.UpdateProject)
+		projects.POST("/upload", middleware.AuthMiddleware(tokenService, model.ScopeSubmit), projectHandler.UploadProject)
+		projects.POST("/:projectId/like", middleware.AuthMiddleware(tokenService), projectHandler.LikeProject)
+		projects.DELETE("/:projectId/like", middleware.AuthMiddleware(tokenService), projectHandler.UnlikeProject)
 		projects.GET("/:projectId/comments", projectHandler.GetComments)                                    // 获取项目评论列表
-		projects.POST("/:projectId/comments", middleware.AuthMiddleware(), projectHandler.AddComment)      // 发表评论
-		projects.DELETE("/:projectId/comments/:commentId", middleware.AuthMiddleware(), projectHandler.DeleteComment) // 删除评论
-		projects.POST("/:projectId/comments/:commentId/like", middleware.AuthMiddleware(), projectHandler.LikeComment) // 点赞评论
-		projects.POST("/:projectId/comments/:commentId/reply", middleware.AuthMiddleware(), projectHandler.ReplyComment) // 回复评论
-		projects.DELETE("/:projectId/comments/:commentId/reply", middleware.AuthMiddleware(), projectHandler.DeleteReply) // 删除回复
+		projects.POST("/:projectId/comments", middleware.AuthMiddleware(tokenService, model.ScopeComment), projectHandler.AddComment)      // 发表评论
+		projects.DELETE("/:projectId/comments/:commentId", middleware.AuthMiddleware(tokenService, model.ScopeComment), projectHandler.DeleteComment) // 删除评论
+		projects.POST("/:projectId/comments/:commentId/like", middleware.AuthMiddleware(tokenService, model.ScopeComment), projectHandler.LikeComment) // 点赞评论
+		projects.POST("/:projectId/comments/:commentId/reply", middleware.AuthMiddleware(tokenService, model.ScopeComment), projectHandler.ReplyComment) // 回复评论
+		projects.DELETE("/:projectId/comments/:commentId/reply", middleware.AuthMiddleware(tokenService, model.ScopeComment), projectHandler.DeleteReply) // 删除回复
 		projects.POST("/:projectId/view", projectHandler.AddView)
-		projects.POST("/:projectId/collected", middleware.AuthMiddleware(), projectHandler.CollectProject)
-		projects.DELETE("/:projectId/collected", middleware.AuthMiddleware(), projectHandler.UncollectProject)
+		projects.POST("/:projectId/collected", middleware.AuthMiddleware(tokenService), projectHandler.CollectProject)
+		projects.DELETE("/:projectId/collected", middleware.AuthMiddleware(tokenService), projectHandler.UncollectProject)
 	}
 
 	// 管理员路由
 	admin := r.Group("/admin")
-	admin.Use(middleware.AuthMiddleware()) // 先验证身份
-	admin.Use(middleware.AdminMiddleware()) // 再验证管理员权限
+	admin.Use(middleware.AuthMiddleware(tokenService)) // 先验证身份，再按路由校验权限
+	admin.Use(middleware.RejectPersonalToken())        // 管理接口只允许登录会话，审核的 GET 接口也会修改数据
+	if cfg.RequireAdminMFA {
+		admin.Use(middleware.RequireMFA(model.RoleAdmin)) // 管理员必须通过两步验证登录
+	}
 	{
-		admin.GET("/pending", adminHandler.GetPending)              // 获取待审核内容
-		admin.POST("/review/:itemId", adminHandler.ReviewItem)      // 审核项目（支持POST和GET，前端使用GET但需要requestBody，所以用POST）
-		admin.GET("/review/:itemId", adminHandler.ReviewItem)       // 也支持GET方法（前端调用的是GET）
+		// 审核：具体资源类型的权限在 AdminService 中校验
+		review := middleware.RequireAnyPermission(model.PermReviewTool, model.PermReviewCourse, model.PermReviewProject)
+		admin.GET("/pending", review, adminHandler.GetPending)              // 获取待审核内容
+		admin.POST("/review/:itemId", review, adminHandler.ReviewItem)      // 审核项目（支持POST和GET，前端使用GET但需要requestBody，所以用POST）
+		admin.GET("/review/:itemId", review, adminHandler.ReviewItem)       // 也支持GET方法（前端调用的是GET）
+
+		// 教师资料、学期目录和开课维护：与课程审核使用同一权限
+		courseManage := middleware.RequirePermission(model.PermReviewCourse)
+		admin.PUT("/teachers/:teacherId", courseManage, teacherHandler.UpdateTeacher)          // 修改教师资料和别名
+		admin.POST("/teachers/:teacherId/merge", courseManage, teacherHandler.MergeTeacher)    // 合并同一教师的不同写法
+		admin.POST("/semesters", courseManage, semesterHandler.CreateSemester)                 // 新增学期
+		admin.PUT("/semesters/:semesterId", courseManage, semesterHandler.UpdateSemester)      // 修改学期名称和起止日期
+		admin.POST("/courses/:courseId/offerings", courseManage, semesterHandler.AddOffering)  // 为课程添加开课（学期 + 任课教师）
+
+		// 评论管理
+		admin.DELETE("/comments/:commentId", middleware.RequirePermission(model.PermCommentModerate), adminHandler.DeleteComment) // 删除任意评论
+
+		// 用户管理
+		manage := middleware.RequirePermission(model.PermUserManage)
+		admin.GET("/roles", manage, adminHandler.GetRoles)                                        // 角色及权限列表
+		admin.PUT("/users/:userId/role", manage, adminHandler.AssignRole)                         // 分配角色
+		admin.GET("/invitations", manage, invitationHandler.ListInvitations)                      // 邀请码列表
+		admin.POST("/invitations", manage, invitationHandler.CreateInvitation)                    // 生成邀请码
+		admin.GET("/invitations/:invitationId", manage, invitationHandler.GetInvitation)          // 邀请码详情及兑换记录
+		admin.DELETE("/invitations/:invitationId", manage, invitationHandler.RevokeInvitation)    // 撤销邀请码
+		admin.GET("/login-locks", manage, authHandler.ListLoginLocks)                             // 被封禁的账号和IP
+		admin.DELETE("/login-locks/:key", manage, authHandler.ClearLoginLock)                     // 解除封禁
+		admin.GET("/auth-events", manage, auditHandler.SearchAuthEvents)                         // 认证审计日志检索
 	}
 
 	// 上传路由
 	upload := r.Group("/api/upload")
-	upload.Use(middleware.AuthMiddleware()) // 需要登录才能上传
+	upload.Use(middleware.AuthMiddleware(tokenService, model.ScopeSubmit)) // 需要登录才能上传
 	{
 		upload.POST("/image", uploadHandler.UploadImage)           // 上传图片文件
 		upload.POST("/process", uploadHandler.ProcessImageURL)    // 处理图片URL（自动本地化）
@@ -32,6 +32,9 @@ INSERT IGNORE INTO course_teachers (course_id, teacher_name) VALUES
 (701, '林老师'),
 (702, '何老师');
 
+-- 为教师姓名创建教师记录并关联（已有教师按姓名或别名关联），需在 database 目录下用 mysql 客户端执行
+SOURCE link_course_teachers.sql;
+
 -- 插入课程分类
 INSERT IGNORE INTO course_categories (course_id, category) VALUES
 (201, '公必'),
@@ -0,0 +1,13 @@
+-- 为 course_teachers 中只有姓名的任课教师创建教师记录并关联（已有教师按姓名或别名关联）
+-- 课程数据脚本插入 course_teachers 后通过 SOURCE 执行本脚本，可重复执行
+UPDATE IGNORE course_teachers ct
+JOIN teacher_aliases a ON a.alias = TRIM(ct.teacher_name)
+JOIN teachers t ON t.teacher_id = a.teacher_id
+SET ct.teacher_id = t.teacher_id, ct.teacher_name = t.name
+WHERE ct.teacher_id IS NULL;
+INSERT IGNORE INTO teachers (name)
+SELECT DISTINCT TRIM(teacher_name) FROM course_teachers WHERE teacher_id IS NULL AND TRIM(teacher_name) <> '';
+UPDATE IGNORE course_teachers ct
+JOIN teachers t ON t.name = TRIM(ct.teacher_name)
+SET ct.teacher_id = t.teacher_id, ct.teacher_name = t.name
+WHERE ct.teacher_id IS NULL;
@@ -0,0 +1,13 @@
+-- 新增账号注销（冷静期 + 匿名化）
+-- 执行此SQL前请先备份数据库
+-- 注销不再删除 users 行：个人数据被清除，用户行改为匿名占位，评论和提交的内容保留
+
+-- 账号注销申请表（冷静期结束后匿名化，用户行保留为占位以保留评论串）
+CREATE TABLE IF NOT EXISTS account_deletions (
+    user_id INT PRIMARY KEY COMMENT '用户ID',
+    requested_at TIMESTAMP NOT NULL COMMENT '申请时间',
+    purge_after TIMESTAMP NOT NULL COMMENT '冷静期结束时间',
+    completed_at TIMESTAMP NULL COMMENT '匿名化完成时间',
+    INDEX idx_purge_after (purge_after),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='账号注销申请表';
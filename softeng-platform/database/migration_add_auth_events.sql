@@ -0,0 +1,20 @@
+-- 新增认证审计日志
+-- 执行此SQL前请先备份数据库
+-- 不设外键：失败登录可能没有对应用户，注销匿名化时按 user_id 删除
+
+-- 认证审计日志表（登录、改密、改邮箱、角色变更、令牌吊销等）
+CREATE TABLE IF NOT EXISTS auth_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NULL COMMENT '用户ID，账号不存在的失败登录为空',
+    identifier VARCHAR(100) NULL COMMENT '用户名，或失败登录时输入的用户名/邮箱',
+    event_type VARCHAR(32) NOT NULL COMMENT '事件类型',
+    ip VARCHAR(45) NULL COMMENT '客户端IP',
+    user_agent VARCHAR(255) NULL COMMENT '客户端User-Agent',
+    detail VARCHAR(255) NULL COMMENT '补充说明',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_created (user_id, created_at),
+    INDEX idx_identifier (identifier),
+    INDEX idx_type_created (event_type, created_at),
+    INDEX idx_ip_created (ip, created_at),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='认证审计日志表';
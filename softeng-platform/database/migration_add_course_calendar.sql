@@ -0,0 +1,30 @@
+-- 新增课程日历事件表和日历订阅令牌表
+-- 学期的起止日期为空时，日历按 CALENDAR_*_TERM 配置的默认日期生成
+-- 执行此SQL前请先备份数据库
+
+-- 课程日历事件表（课程贡献者添加的截止时间、考试和活动，出现在日历订阅中）
+CREATE TABLE IF NOT EXISTS course_events (
+    event_id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    title VARCHAR(255) NOT NULL COMMENT '标题',
+    description TEXT NULL COMMENT '说明',
+    kind VARCHAR(20) NOT NULL DEFAULT 'event' COMMENT '类型：deadline/exam/event',
+    start_at DATETIME NOT NULL COMMENT '开始时间，截止时间类事件为截止时刻',
+    end_at DATETIME NULL COMMENT '结束时间，为空表示没有持续时间',
+    all_day TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否全天事件',
+    location VARCHAR(255) NULL COMMENT '地点',
+    creator_id INT NULL COMMENT '添加者ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_course_start (course_id, start_at),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程日历事件表';
+
+-- 日历订阅令牌表（每个用户一个，订阅地址中的令牌只保存哈希）
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+    user_id INT PRIMARY KEY COMMENT '用户ID',
+    token_hash CHAR(64) NOT NULL UNIQUE COMMENT '令牌哈希（SHA-256）',
+    last_used_at TIMESTAMP NULL COMMENT '最后访问时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='日历订阅令牌表';
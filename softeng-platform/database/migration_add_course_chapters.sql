@@ -0,0 +1,44 @@
+-- 创建课程章节表和章节学习进度表，课程资源可关联到章节
+-- 执行此SQL前请先备份数据库
+
+-- 课程章节表（章、节两级大纲）
+CREATE TABLE IF NOT EXISTS course_chapters (
+    chapter_id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    parent_id INT NULL COMMENT '所属章ID，为空表示章',
+    title VARCHAR(255) NOT NULL COMMENT '章节标题',
+    sort_order INT DEFAULT 0 COMMENT '同级排序',
+    submitter_id INT NULL COMMENT '提交者ID',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected/offline',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT NULL COMMENT '驳回原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_course_id (course_id),
+    INDEX idx_parent_id (parent_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES course_chapters(chapter_id) ON DELETE CASCADE,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程章节表';
+
+-- 章节学习进度表（有记录即表示已完成）
+CREATE TABLE IF NOT EXISTS chapter_progress (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    chapter_id INT NOT NULL COMMENT '章节ID',
+    completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '完成时间',
+    UNIQUE KEY uk_user_chapter (user_id, chapter_id),
+    INDEX idx_chapter_id (chapter_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (chapter_id) REFERENCES course_chapters(chapter_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='章节学习进度表';
+
+ALTER TABLE course_resources_web
+ADD COLUMN chapter_id INT NULL COMMENT '关联的章节ID' AFTER course_id,
+ADD INDEX idx_chapter_id (chapter_id),
+ADD FOREIGN KEY (chapter_id) REFERENCES course_chapters(chapter_id) ON DELETE SET NULL;
+
+ALTER TABLE course_resources_upload
+ADD COLUMN chapter_id INT NULL COMMENT '关联的章节ID' AFTER course_id,
+ADD INDEX idx_chapter_id (chapter_id),
+ADD FOREIGN KEY (chapter_id) REFERENCES course_chapters(chapter_id) ON DELETE SET NULL;
@@ -0,0 +1,22 @@
+-- 创建课程修读关系表（先修 / 同修），由用户提交、管理员审核
+-- 执行此SQL前请先备份数据库
+
+-- 课程修读关系表（先修 / 同修，审核通过后生效）
+CREATE TABLE IF NOT EXISTS course_prerequisites (
+    relation_id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    required_course_id INT NOT NULL COMMENT '需要先修或同修的课程ID',
+    relation_type VARCHAR(20) NOT NULL DEFAULT 'prerequisite' COMMENT '关系类型：prerequisite/corequisite',
+    note VARCHAR(500) NULL COMMENT '说明',
+    submitter_id INT NULL COMMENT '提交者ID',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected/offline',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT NULL COMMENT '驳回原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_course_required (course_id, required_course_id),
+    INDEX idx_required_course_id (required_course_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (required_course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程修读关系表';
@@ -0,0 +1,22 @@
+-- 创建课程评分表，按课程、教师、学期记录多维度评分
+-- 执行此SQL前请先备份数据库
+
+-- 课程评分表（每个用户对每次开课评分一次）
+CREATE TABLE IF NOT EXISTS course_ratings (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    user_id INT NOT NULL COMMENT '用户ID',
+    teacher_name VARCHAR(100) NOT NULL DEFAULT '' COMMENT '任课教师',
+    semester VARCHAR(50) NOT NULL COMMENT '修读学期',
+    difficulty TINYINT NOT NULL COMMENT '难度 1-5',
+    workload TINYINT NOT NULL COMMENT '课业负担 1-5',
+    teaching TINYINT NOT NULL COMMENT '教学质量 1-5',
+    usefulness TINYINT NOT NULL COMMENT '实用性 1-5',
+    review TEXT NULL COMMENT '文字评价',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_offering (course_id, user_id, teacher_name, semester),
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程评分表';
@@ -0,0 +1,26 @@
+-- 为课程资源表添加提交者和审核状态字段，上传文件记录原始文件名、大小、类型和存储路径
+-- 执行此SQL前请先备份数据库
+
+ALTER TABLE course_resources_web
+ADD COLUMN submitter_id INT NULL COMMENT '提交者ID，历史导入数据为空' AFTER sort_order,
+ADD COLUMN status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected' AFTER submitter_id,
+ADD COLUMN audit_time TIMESTAMP NULL COMMENT '审核时间' AFTER status,
+ADD COLUMN reject_reason TEXT NULL COMMENT '拒绝原因' AFTER audit_time,
+ADD INDEX idx_status (status),
+ADD FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE course_resources_upload
+ADD COLUMN file_name VARCHAR(255) NULL COMMENT '原始文件名' AFTER resource_upload,
+ADD COLUMN file_size BIGINT NULL COMMENT '文件大小（字节）' AFTER file_name,
+ADD COLUMN content_type VARCHAR(100) NULL COMMENT '文件类型' AFTER file_size,
+ADD COLUMN storage_path VARCHAR(500) NULL COMMENT '本地存储路径（相对 RESOURCE_STORAGE_DIR），外部链接为空' AFTER content_type,
+ADD COLUMN submitter_id INT NULL COMMENT '提交者ID，历史导入数据为空' AFTER sort_order,
+ADD COLUMN status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected' AFTER submitter_id,
+ADD COLUMN audit_time TIMESTAMP NULL COMMENT '审核时间' AFTER status,
+ADD COLUMN reject_reason TEXT NULL COMMENT '拒绝原因' AFTER audit_time,
+ADD INDEX idx_status (status),
+ADD FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- 已有的资源均为管理员导入，视为已审核通过
+UPDATE course_resources_web SET status = 'approved' WHERE submitter_id IS NULL;
+UPDATE course_resources_upload SET status = 'approved' WHERE submitter_id IS NULL;
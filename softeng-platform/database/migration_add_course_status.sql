@@ -0,0 +1,14 @@
+-- 为课程表添加审核状态、审核时间、驳回原因和提交者字段
+-- 执行此SQL前请先备份数据库
+
+ALTER TABLE courses
+ADD COLUMN status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected/offline' AFTER collections,
+ADD COLUMN audit_time TIMESTAMP NULL COMMENT '审核时间' AFTER status,
+ADD COLUMN reject_reason TEXT NULL COMMENT '驳回原因' AFTER audit_time,
+ADD COLUMN submitter_id INT NULL COMMENT '提交用户ID，历史导入数据为空' AFTER reject_reason,
+ADD INDEX idx_status (status),
+ADD INDEX idx_submitter (submitter_id),
+ADD FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- 已有课程均为管理员导入，视为已审核通过
+UPDATE courses SET status = 'approved' WHERE submitter_id IS NULL;
@@ -0,0 +1,31 @@
+-- 新增邀请码管理（受邀注册）
+-- 执行此SQL前请先备份数据库
+
+ALTER TABLE users
+ADD COLUMN cohort VARCHAR(100) NULL COMMENT '届别/班级（由邀请码分配）' AFTER role;
+
+CREATE TABLE IF NOT EXISTS invitation_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(64) NOT NULL UNIQUE COMMENT '邀请码',
+    max_uses INT NOT NULL DEFAULT 1 COMMENT '最大使用次数',
+    used_count INT NOT NULL DEFAULT 0 COMMENT '已使用次数',
+    expires_at TIMESTAMP NULL COMMENT '过期时间（NULL 表示不过期）',
+    role VARCHAR(50) DEFAULT 'user' COMMENT '注册后分配的角色',
+    cohort VARCHAR(100) NULL COMMENT '注册后分配的届别/班级',
+    note VARCHAR(255) NULL COMMENT '备注',
+    created_by INT COMMENT '创建管理员ID',
+    revoked_at TIMESTAMP NULL COMMENT '撤销时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='邀请码表';
+
+CREATE TABLE IF NOT EXISTS invitation_redemptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    invitation_id INT NOT NULL COMMENT '邀请码ID',
+    user_id INT NOT NULL COMMENT '注册用户ID',
+    redeemed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '兑换时间',
+    INDEX idx_invitation_id (invitation_id),
+    FOREIGN KEY (invitation_id) REFERENCES invitation_codes(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_invitation_user (invitation_id, user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='邀请码兑换记录表';
@@ -0,0 +1,18 @@
+-- 创建学习计划表
+-- 执行此SQL前请先备份数据库
+
+-- 学习计划表
+CREATE TABLE IF NOT EXISTS learning_plans (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    course_id INT NOT NULL COMMENT '课程ID',
+    semester VARCHAR(50) NOT NULL COMMENT '计划修读学期',
+    sort_order INT NOT NULL DEFAULT 1 COMMENT '学期内顺序',
+    status VARCHAR(20) NOT NULL DEFAULT 'planned' COMMENT '状态：planned/in_progress/completed',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_course (user_id, course_id),
+    INDEX idx_user_semester (user_id, semester, sort_order),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='学习计划表';
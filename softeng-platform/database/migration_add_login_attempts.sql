@@ -0,0 +1,12 @@
+-- 新增登录失败记录表（防暴力破解，LOGIN_GUARD_DRIVER=database 时使用）
+-- 执行此SQL前请先备份数据库
+
+-- 登录失败记录表（LOGIN_GUARD_DRIVER=database 时使用）
+CREATE TABLE IF NOT EXISTS login_attempts (
+    attempt_key VARCHAR(191) PRIMARY KEY COMMENT '计数键：user:<id> / account:<用户名或邮箱> / ip:<IP>',
+    failures INT NOT NULL DEFAULT 0 COMMENT '窗口内连续失败次数',
+    last_failure_at TIMESTAMP NOT NULL COMMENT '最后一次失败时间',
+    blocked_until TIMESTAMP NULL COMMENT '退避/锁定截止时间',
+    locked TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为账号锁定',
+    INDEX idx_blocked_until (blocked_until)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录失败记录表';
@@ -0,0 +1,27 @@
+-- 新增 TOTP 两步验证及恢复码
+-- 执行此SQL前请先备份数据库
+
+-- 两步验证表（TOTP）
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id INT PRIMARY KEY COMMENT '用户ID',
+    secret VARCHAR(64) NOT NULL COMMENT 'TOTP 密钥（Base32）',
+    last_counter BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次使用的时间步，防止验证码重放',
+    enabled_at TIMESTAMP NULL COMMENT '启用时间，为空表示尚未确认',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '密钥生成时间',
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证表';
+
+-- 两步验证恢复码表（每个恢复码只能使用一次）
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    code_hash CHAR(64) NOT NULL COMMENT '恢复码哈希（SHA-256）',
+    used_at TIMESTAMP NULL COMMENT '使用时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_code (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证恢复码表';
+
+-- 会话记录登录时是否通过两步验证（REQUIRE_ADMIN_MFA 据此放行管理接口）
+ALTER TABLE user_sessions
+ADD COLUMN mfa_verified TINYINT(1) NOT NULL DEFAULT 0 COMMENT '登录时是否通过两步验证' AFTER ip;
@@ -0,0 +1,18 @@
+-- 新增个人访问令牌表
+-- 执行此SQL前请先备份数据库
+
+-- 个人访问令牌表（用于脚本调用 API）
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    name VARCHAR(100) NOT NULL COMMENT '令牌名称',
+    token_hash CHAR(64) NOT NULL UNIQUE COMMENT '令牌哈希（SHA-256）',
+    token_prefix VARCHAR(16) NOT NULL COMMENT '令牌前缀（展示用）',
+    scopes VARCHAR(100) NOT NULL COMMENT '授权范围，逗号分隔：read/submit/comment',
+    expires_at TIMESTAMP NULL COMMENT '过期时间',
+    last_used_at TIMESTAMP NULL COMMENT '最后使用时间',
+    revoked_at TIMESTAMP NULL COMMENT '吊销时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='个人访问令牌表';
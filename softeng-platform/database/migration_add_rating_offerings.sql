@@ -0,0 +1,99 @@
+-- 课程评分关联到开课：每个用户对每次开课只有一条评分，学期的不同写法（"2024秋"、"Fall 2024"）和教师姓名的不同写法不再产生重复评分
+-- 需要先执行 migration_add_semesters.sql，并为旧评分涉及的课程添加开课；学期识别规则与 model.ParseSemester 和该脚本一致
+-- 能识别且课程在该学期有开课的评分关联到开课，学期统一为学期目录中的名称；同一用户对同一次开课的多条评分只保留最后更新的一条
+-- 无法对应开课的评分和被合并的重复评分移到 course_ratings_removed（reason 为 unmatched_offering / duplicate），末尾按原因输出移除的评分
+-- 需要 MySQL 8.0（使用 REGEXP_LIKE / REGEXP_SUBSTR）
+-- 执行此SQL前请先备份数据库
+
+ALTER TABLE course_ratings
+    ADD COLUMN offering_id INT NULL COMMENT '开课ID' AFTER user_id,
+    ADD INDEX idx_course_id (course_id),
+    ADD INDEX idx_offering_id (offering_id),
+    MODIFY COLUMN semester VARCHAR(50) NOT NULL COMMENT '修读学期（开课学期的名称）';
+
+-- 原唯一键包含学期原文，统一学期名称前先删除
+ALTER TABLE course_ratings DROP INDEX uk_user_offering;
+
+DROP TABLE IF EXISTS tmp_rating_semesters;
+CREATE TABLE tmp_rating_semesters AS
+SELECT raw, y1, y2, term,
+    CASE WHEN y1 IS NULL OR term IS NULL THEN NULL
+         WHEN y2 IS NOT NULL OR term = 1 THEN y1
+         ELSE y1 - 1 END AS academic_year
+FROM (
+    SELECT raw,
+        CAST(REGEXP_SUBSTR(raw, '(19|20)[0-9]{2}') AS SIGNED) AS y1,
+        REGEXP_SUBSTR(raw, '(19|20)[0-9]{2}', 1, 2) AS y2,
+        CASE
+            WHEN REGEXP_LIKE(raw, '秋|fall|autumn', 'i') THEN 1
+            WHEN REGEXP_LIKE(raw, '春|spring', 'i') THEN 2
+            WHEN REGEXP_LIKE(raw, '夏|summer|小学期', 'i') THEN 3
+            WHEN REGEXP_SUBSTR(raw, '(19|20)[0-9]{2}', 1, 2) IS NULL THEN NULL
+            WHEN REGEXP_LIKE(raw, '第\\s*[一1]\\s*学期|[-_ ]1$') THEN 1
+            WHEN REGEXP_LIKE(raw, '第\\s*[二2]\\s*学期|[-_ ]2$') THEN 2
+            WHEN REGEXP_LIKE(raw, '第\\s*[三3]\\s*学期|[-_ ]3$') THEN 3
+        END AS term
+    FROM (
+        SELECT DISTINCT TRIM(semester) AS raw FROM course_ratings
+    ) s
+) t;
+
+UPDATE course_ratings cr
+JOIN tmp_rating_semesters m ON m.raw = TRIM(cr.semester)
+JOIN semesters s ON s.academic_year = m.academic_year AND s.term = m.term
+JOIN course_offerings o ON o.course_id = cr.course_id AND o.semester_id = s.semester_id
+SET cr.offering_id = o.offering_id, cr.semester = s.name;
+
+-- 移除的评分原样保存，确认无误后可删除该表；为课程补充开课后可按 offering_id 手动恢复
+CREATE TABLE IF NOT EXISTS course_ratings_removed (
+    id INT PRIMARY KEY COMMENT '原评分ID',
+    course_id INT NOT NULL,
+    user_id INT NOT NULL,
+    offering_id INT NULL,
+    teacher_name VARCHAR(100) NOT NULL,
+    semester VARCHAR(50) NOT NULL,
+    difficulty TINYINT NOT NULL,
+    workload TINYINT NOT NULL,
+    teaching TINYINT NOT NULL,
+    usefulness TINYINT NOT NULL,
+    review TEXT NULL,
+    created_at TIMESTAMP NULL,
+    updated_at TIMESTAMP NULL,
+    reason VARCHAR(30) NOT NULL COMMENT 'unmatched_offering：无法对应开课；duplicate：同一用户对同一次开课有更新的评分',
+    removed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='迁移时移除的课程评分';
+
+INSERT INTO course_ratings_removed (id, course_id, user_id, offering_id, teacher_name, semester, difficulty, workload, teaching, usefulness, review, created_at, updated_at, reason)
+SELECT id, course_id, user_id, offering_id, teacher_name, semester, difficulty, workload, teaching, usefulness, review, created_at, updated_at, 'unmatched_offering'
+FROM course_ratings
+WHERE offering_id IS NULL;
+
+DELETE FROM course_ratings WHERE offering_id IS NULL;
+
+-- 同一用户对同一次开课的重复评分（包括不同教师写法）只保留最后更新的一条
+INSERT INTO course_ratings_removed (id, course_id, user_id, offering_id, teacher_name, semester, difficulty, workload, teaching, usefulness, review, created_at, updated_at, reason)
+SELECT cr1.id, cr1.course_id, cr1.user_id, cr1.offering_id, cr1.teacher_name, cr1.semester, cr1.difficulty, cr1.workload, cr1.teaching, cr1.usefulness,
+    cr1.review, cr1.created_at, cr1.updated_at, 'duplicate'
+FROM course_ratings cr1
+WHERE EXISTS (
+    SELECT 1 FROM course_ratings cr2
+    WHERE cr2.user_id = cr1.user_id AND cr2.offering_id = cr1.offering_id
+        AND (cr2.updated_at > cr1.updated_at OR (cr2.updated_at = cr1.updated_at AND cr2.id > cr1.id))
+);
+
+DELETE cr1 FROM course_ratings cr1
+JOIN course_ratings_removed r ON r.id = cr1.id AND r.reason = 'duplicate';
+
+ALTER TABLE course_ratings
+    MODIFY COLUMN offering_id INT NOT NULL COMMENT '开课ID',
+    ADD UNIQUE KEY uk_user_offering (user_id, offering_id),
+    DROP INDEX idx_user_id,
+    ADD FOREIGN KEY (offering_id) REFERENCES course_offerings(offering_id) ON DELETE CASCADE;
+
+DROP TABLE tmp_rating_semesters;
+
+-- 移除的评分：按原因、课程和学期写法汇总
+SELECT r.reason, r.course_id, r.semester, COUNT(*) AS ratings
+FROM course_ratings_removed r
+GROUP BY r.reason, r.course_id, r.semester
+ORDER BY r.reason, r.course_id, r.semester;
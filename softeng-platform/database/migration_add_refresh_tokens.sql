@@ -0,0 +1,28 @@
+-- 新增刷新令牌与访问令牌吊销列表（登出/全部登出/刷新令牌重用检测）
+-- 执行此SQL前请先备份数据库
+
+-- 刷新令牌表（同一次登录轮换出的令牌属于同一家族）
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    family_id VARCHAR(64) NOT NULL COMMENT '令牌家族ID（会话ID）',
+    token_hash CHAR(64) NOT NULL UNIQUE COMMENT '刷新令牌哈希（SHA-256）',
+    access_jti VARCHAR(64) NOT NULL COMMENT '同时签发的访问令牌ID',
+    access_expires_at TIMESTAMP NOT NULL COMMENT '访问令牌过期时间',
+    expires_at TIMESTAMP NOT NULL COMMENT '刷新令牌过期时间',
+    used_at TIMESTAMP NULL COMMENT '轮换时间（再次使用视为重用）',
+    revoked_at TIMESTAMP NULL COMMENT '吊销时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_family_id (family_id),
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='刷新令牌表';
+
+-- 访问令牌吊销列表
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY COMMENT '访问令牌ID',
+    user_id INT NOT NULL COMMENT '用户ID',
+    expires_at TIMESTAMP NOT NULL COMMENT '令牌原过期时间，过期后可清理',
+    revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '吊销时间',
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='访问令牌吊销列表';
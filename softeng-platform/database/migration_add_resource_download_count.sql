@@ -0,0 +1,5 @@
+-- 为课程上传资源添加下载次数
+-- 执行此SQL前请先备份数据库
+
+ALTER TABLE course_resources_upload
+ADD COLUMN download_count INT DEFAULT 0 COMMENT '下载次数' AFTER storage_path;
@@ -0,0 +1,5 @@
+-- 为课程上传资源添加原始地址，cmd/import 导入的文件下载到本地存储后按原始地址去重
+-- 执行此SQL前请先备份数据库
+
+ALTER TABLE course_resources_upload
+ADD COLUMN source_url VARCHAR(500) NULL COMMENT '批量导入时文件的原始地址，用于重复导入时去重' AFTER storage_path;
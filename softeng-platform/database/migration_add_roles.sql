@@ -0,0 +1,10 @@
+-- 细粒度角色：user / moderator / course_editor / admin
+-- 角色与权限的对应关系定义在 internal/model/role.go
+-- 执行此SQL前请先备份数据库
+
+ALTER TABLE users
+MODIFY COLUMN role VARCHAR(50) DEFAULT 'user' COMMENT '角色：user/moderator/course_editor/admin';
+
+-- 将无法识别的历史角色归为普通用户
+UPDATE users SET role = 'user'
+WHERE role IS NULL OR role NOT IN ('user', 'moderator', 'course_editor', 'admin');
@@ -0,0 +1,111 @@
+-- 创建学期目录和开课表，将 courses.semester 中能识别的学期写法映射到学期并创建开课记录
+-- 识别规则与 model.ParseSemester 一致："2024秋"、"Fall 2024"、"2024-Fall"、"2025春季"、"2024-2025学年第二学期"、"2024-2025-2" 等；
+-- "大三上" 等相对学期无法识别，保留原文，可在迁移后通过 POST /admin/courses/:courseId/offerings 手动添加开课
+-- 可重复执行：导入课程数据后再次执行即可为新课程创建开课记录；末尾输出每种写法的映射结果
+-- 需要 MySQL 8.0（使用 REGEXP_LIKE / REGEXP_SUBSTR）
+-- 执行此SQL前请先备份数据库
+
+-- 学期表（学年从秋季学期开始，academic_year * 10 + term 即学期先后顺序）
+CREATE TABLE IF NOT EXISTS semesters (
+    semester_id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL COMMENT '学期名称，如 2024秋季',
+    academic_year INT NOT NULL COMMENT '学年起始年份，2024-2025 学年为 2024',
+    term TINYINT NOT NULL COMMENT '学期：1 秋季 / 2 春季 / 3 夏季',
+    start_date DATE NULL COMMENT '开始日期',
+    end_date DATE NULL COMMENT '结束日期',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_name (name),
+    UNIQUE KEY uk_year_term (academic_year, term)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='学期表';
+
+-- 开课表（课程在某个学期的一次开课）
+CREATE TABLE IF NOT EXISTS course_offerings (
+    offering_id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    semester_id INT NOT NULL COMMENT '学期ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_course_semester (course_id, semester_id),
+    INDEX idx_semester_id (semester_id),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (semester_id) REFERENCES semesters(semester_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='开课表';
+
+-- 开课教师表
+CREATE TABLE IF NOT EXISTS course_offering_teachers (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    offering_id INT NOT NULL COMMENT '开课ID',
+    teacher_id INT NOT NULL COMMENT '教师ID',
+    UNIQUE KEY uk_offering_teacher (offering_id, teacher_id),
+    INDEX idx_teacher_id (teacher_id),
+    FOREIGN KEY (offering_id) REFERENCES course_offerings(offering_id) ON DELETE CASCADE,
+    FOREIGN KEY (teacher_id) REFERENCES teachers(teacher_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='开课教师表';
+
+-- 识别每种学期写法：y1 / y2 为出现的第一、二个年份，只写一个年份时春季和夏季学期属于上一学年
+DROP TABLE IF EXISTS tmp_semester_mapping;
+CREATE TABLE tmp_semester_mapping AS
+SELECT raw, courses, y1, y2, term,
+    CASE WHEN y1 IS NULL OR term IS NULL THEN NULL
+         WHEN y2 IS NOT NULL OR term = 1 THEN y1
+         ELSE y1 - 1 END AS academic_year
+FROM (
+    SELECT raw, courses,
+        CAST(REGEXP_SUBSTR(raw, '(19|20)[0-9]{2}') AS SIGNED) AS y1,
+        REGEXP_SUBSTR(raw, '(19|20)[0-9]{2}', 1, 2) AS y2,
+        CASE
+            WHEN REGEXP_LIKE(raw, '秋|fall|autumn', 'i') THEN 1
+            WHEN REGEXP_LIKE(raw, '春|spring', 'i') THEN 2
+            WHEN REGEXP_LIKE(raw, '夏|summer|小学期', 'i') THEN 3
+            WHEN REGEXP_SUBSTR(raw, '(19|20)[0-9]{2}', 1, 2) IS NULL THEN NULL
+            WHEN REGEXP_LIKE(raw, '第\\s*[一1]\\s*学期|[-_ ]1$') THEN 1
+            WHEN REGEXP_LIKE(raw, '第\\s*[二2]\\s*学期|[-_ ]2$') THEN 2
+            WHEN REGEXP_LIKE(raw, '第\\s*[三3]\\s*学期|[-_ ]3$') THEN 3
+        END AS term
+    FROM (
+        SELECT TRIM(semester) AS raw, COUNT(*) AS courses
+        FROM courses
+        WHERE semester IS NOT NULL AND TRIM(semester) <> ''
+        GROUP BY raw
+    ) s
+) t;
+
+-- 规范名称与 model.SemesterName 一致：秋季学期使用学年起始年份，春季和夏季学期使用下一年
+INSERT IGNORE INTO semesters (name, academic_year, term)
+SELECT DISTINCT
+    CASE term WHEN 1 THEN CONCAT(academic_year, '秋季')
+              WHEN 2 THEN CONCAT(academic_year + 1, '春季')
+              ELSE CONCAT(academic_year + 1, '夏季') END,
+    academic_year, term
+FROM tmp_semester_mapping
+WHERE academic_year IS NOT NULL;
+
+INSERT IGNORE INTO course_offerings (course_id, semester_id)
+SELECT c.course_id, s.semester_id
+FROM courses c
+JOIN tmp_semester_mapping m ON m.raw = TRIM(c.semester)
+JOIN semesters s ON s.academic_year = m.academic_year AND s.term = m.term;
+
+-- 迁移的开课沿用课程的任课教师
+INSERT IGNORE INTO course_offering_teachers (offering_id, teacher_id)
+SELECT o.offering_id, ct.teacher_id
+FROM courses c
+JOIN tmp_semester_mapping m ON m.raw = TRIM(c.semester)
+JOIN semesters s ON s.academic_year = m.academic_year AND s.term = m.term
+JOIN course_offerings o ON o.course_id = c.course_id AND o.semester_id = s.semester_id
+JOIN course_teachers ct ON ct.course_id = c.course_id
+WHERE ct.teacher_id IS NOT NULL;
+
+-- 课程中的学期统一为学期目录中的名称
+UPDATE courses c
+JOIN tmp_semester_mapping m ON m.raw = TRIM(c.semester)
+JOIN semesters s ON s.academic_year = m.academic_year AND s.term = m.term
+SET c.semester = s.name;
+
+-- 映射结果：每种原始写法的课程数和对应的学期，未识别的排在前面
+SELECT m.raw AS original_semester, m.courses, s.semester_id, COALESCE(s.name, '(未识别，保留原文)') AS mapped_semester
+FROM tmp_semester_mapping m
+LEFT JOIN semesters s ON s.academic_year = m.academic_year AND s.term = m.term
+ORDER BY s.semester_id IS NOT NULL, s.academic_year, s.term, m.raw;
+
+DROP TABLE tmp_semester_mapping;
@@ -0,0 +1,106 @@
+-- 创建教师表和教师别名表，将 course_teachers 中的教师姓名迁移为教师记录并按教师ID关联课程和评分
+-- 只有空白和大小写不同的写法会自动合并为同一教师，其他写法（如"张老师"和"张三"）迁移后通过 POST /admin/teachers/:teacherId/merge 合并
+-- 执行此SQL前请先备份数据库
+
+-- 教师表
+CREATE TABLE IF NOT EXISTS teachers (
+    teacher_id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL COMMENT '教师姓名（规范写法）',
+    title VARCHAR(100) NULL COMMENT '职称',
+    department VARCHAR(255) NULL COMMENT '院系',
+    email VARCHAR(255) NULL COMMENT '邮箱',
+    homepage VARCHAR(500) NULL COMMENT '个人主页',
+    avatar VARCHAR(500) NULL COMMENT '头像',
+    bio TEXT NULL COMMENT '简介',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='教师表';
+
+-- 教师别名表（同一教师的其他写法，提交课程时按别名关联到教师）
+CREATE TABLE IF NOT EXISTS teacher_aliases (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    teacher_id INT NOT NULL COMMENT '教师ID',
+    alias VARCHAR(100) NOT NULL COMMENT '别名',
+    UNIQUE KEY uk_alias (alias),
+    INDEX idx_teacher_id (teacher_id),
+    FOREIGN KEY (teacher_id) REFERENCES teachers(teacher_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='教师别名表';
+
+ALTER TABLE course_teachers
+    ADD COLUMN teacher_id INT NULL COMMENT '教师ID，导入脚本只写姓名时由脚本末尾的语句关联' AFTER course_id,
+    MODIFY COLUMN teacher_name VARCHAR(100) NOT NULL COMMENT '教师姓名（与 teachers.name 保持一致）';
+
+-- 去掉空白后相同的写法视为同一教师，取最常用的写法作为规范写法
+DELETE FROM course_teachers WHERE TRIM(teacher_name) = '';
+
+-- 临时表在同一语句中需要引用两次，使用普通表，迁移结束后删除
+DROP TABLE IF EXISTS tmp_teacher_names;
+CREATE TABLE tmp_teacher_names AS
+SELECT
+    REPLACE(REPLACE(REPLACE(teacher_name, ' ', ''), '　', ''), '\t', '') AS name_key,
+    TRIM(teacher_name) AS spelling,
+    COUNT(*) AS uses
+FROM course_teachers
+GROUP BY name_key, spelling;
+
+INSERT IGNORE INTO teachers (name)
+SELECT n.spelling
+FROM tmp_teacher_names n
+WHERE n.spelling = (
+    SELECT n2.spelling FROM tmp_teacher_names n2
+    WHERE n2.name_key = n.name_key
+    ORDER BY n2.uses DESC, n2.spelling ASC
+    LIMIT 1
+);
+
+-- 其他写法作为别名保留
+INSERT IGNORE INTO teacher_aliases (teacher_id, alias)
+SELECT t.teacher_id, n.spelling
+FROM tmp_teacher_names n
+JOIN teachers t ON REPLACE(REPLACE(REPLACE(t.name, ' ', ''), '　', ''), '\t', '') = n.name_key
+WHERE n.spelling <> t.name;
+
+-- 关联课程，同一课程重复的教师只保留一条
+UPDATE course_teachers ct
+JOIN teachers t ON REPLACE(REPLACE(REPLACE(t.name, ' ', ''), '　', ''), '\t', '') = REPLACE(REPLACE(REPLACE(ct.teacher_name, ' ', ''), '　', ''), '\t', '')
+SET ct.teacher_id = t.teacher_id, ct.teacher_name = t.name;
+
+DELETE ct1 FROM course_teachers ct1
+JOIN course_teachers ct2 ON ct2.course_id = ct1.course_id AND ct2.teacher_id = ct1.teacher_id AND ct2.id < ct1.id;
+
+ALTER TABLE course_teachers
+    ADD UNIQUE KEY uk_course_teacher (course_id, teacher_id),
+    ADD INDEX idx_teacher_id (teacher_id),
+    ADD FOREIGN KEY (teacher_id) REFERENCES teachers(teacher_id) ON DELETE CASCADE;
+
+-- 评分中的教师姓名统一为规范写法
+UPDATE course_ratings cr
+JOIN course_teachers ct ON ct.course_id = cr.course_id
+JOIN teachers t ON t.teacher_id = ct.teacher_id
+SET cr.teacher_name = t.name
+WHERE cr.teacher_name <> t.name
+    AND REPLACE(REPLACE(REPLACE(cr.teacher_name, ' ', ''), '　', ''), '\t', '') = REPLACE(REPLACE(REPLACE(t.name, ' ', ''), '　', ''), '\t', '');
+
+-- 评分按教师ID关联，教师改名或合并后仍然归属同一教师；对应不到课程任课教师的评分保留为 NULL
+ALTER TABLE course_ratings
+    ADD COLUMN teacher_id INT NULL COMMENT '教师ID，未填写任课教师的评分为 NULL' AFTER user_id,
+    MODIFY COLUMN teacher_name VARCHAR(100) NOT NULL DEFAULT '' COMMENT '任课教师（与 teachers.name 保持一致）';
+
+UPDATE course_ratings cr
+JOIN course_teachers ct ON ct.course_id = cr.course_id
+SET cr.teacher_id = ct.teacher_id
+WHERE cr.teacher_name = ct.teacher_name;
+
+ALTER TABLE course_ratings
+    ADD INDEX idx_teacher_id (teacher_id),
+    ADD FOREIGN KEY (teacher_id) REFERENCES teachers(teacher_id) ON DELETE SET NULL;
+
+DROP TABLE tmp_teacher_names;
+
+-- 迁移结果：每位教师的规范写法、合并的写法和课程数
+SELECT t.teacher_id, t.name,
+    (SELECT GROUP_CONCAT(a.alias ORDER BY a.alias SEPARATOR ', ') FROM teacher_aliases a WHERE a.teacher_id = t.teacher_id) AS merged_spellings,
+    (SELECT COUNT(*) FROM course_teachers ct WHERE ct.teacher_id = t.teacher_id) AS courses
+FROM teachers t
+ORDER BY t.name;
@@ -0,0 +1,19 @@
+-- 创建工具修改提议表，用户提议修改工具信息、管理员审核通过后应用
+-- 执行此SQL前请先备份数据库
+
+-- 工具修改提议表（字段级差异，审核通过后应用到工具）
+CREATE TABLE IF NOT EXISTS tool_revisions (
+    revision_id INT AUTO_INCREMENT PRIMARY KEY,
+    tool_id INT NOT NULL COMMENT '工具ID',
+    editor_id INT NULL COMMENT '提议用户ID',
+    summary VARCHAR(255) NULL COMMENT '修改说明',
+    changes TEXT NOT NULL COMMENT '字段级差异（JSON）：[{field, before, after}]',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT NULL COMMENT '驳回原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_tool_id (tool_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (tool_id) REFERENCES tools(resource_id) ON DELETE CASCADE,
+    FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='工具修改提议表';
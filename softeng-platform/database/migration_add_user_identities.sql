@@ -0,0 +1,30 @@
+-- 新增第三方账号（OAuth2/OIDC）登录
+-- 执行此SQL前请先备份数据库
+-- 通过第三方账号自动创建的用户 password 为空字符串，只能通过第三方登录，可用忘记密码设置密码
+
+-- 第三方账号绑定表（OAuth2/OIDC 登录）
+CREATE TABLE IF NOT EXISTS user_identities (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    provider VARCHAR(50) NOT NULL COMMENT '提供方名称（OAUTH_PROVIDERS 中的名称）',
+    subject VARCHAR(255) NOT NULL COMMENT '提供方内的用户ID',
+    email VARCHAR(255) NULL COMMENT '提供方返回的邮箱',
+    display_name VARCHAR(100) NULL COMMENT '提供方返回的显示名称',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '绑定时间',
+    last_login_at TIMESTAMP NULL COMMENT '最后一次通过该账号登录的时间',
+    UNIQUE KEY uk_provider_subject (provider, subject),
+    UNIQUE KEY uk_user_provider (user_id, provider),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='第三方账号绑定表';
+
+-- 第三方授权请求表（state 与 PKCE code_verifier，回调时一次性使用）
+CREATE TABLE IF NOT EXISTS oauth_states (
+    state_hash CHAR(64) PRIMARY KEY COMMENT 'state 哈希（SHA-256）',
+    provider VARCHAR(50) NOT NULL COMMENT '提供方名称',
+    code_verifier VARCHAR(128) NOT NULL COMMENT 'PKCE code_verifier',
+    user_id INT NULL COMMENT '绑定账号时的当前用户，登录时为空',
+    invitation_code VARCHAR(50) NULL COMMENT '首次登录自动创建账号使用的邀请码',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='第三方授权请求表';
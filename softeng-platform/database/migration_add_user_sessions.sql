@@ -0,0 +1,18 @@
+-- 新增登录会话表（设备管理）
+-- 执行此SQL前请先备份数据库
+-- 注意：升级后旧的访问令牌不再有效，用户需要重新登录
+
+-- 登录会话表（每次登录一条，对应一个刷新令牌家族）
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    family_id VARCHAR(64) NOT NULL UNIQUE COMMENT '令牌家族ID（写入访问令牌的 sid）',
+    user_agent VARCHAR(255) NULL COMMENT '客户端 User-Agent',
+    ip VARCHAR(45) NULL COMMENT '客户端IP',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '登录时间',
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '最后活跃时间',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间（随刷新顺延）',
+    revoked_at TIMESTAMP NULL COMMENT '吊销时间',
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录会话表';
@@ -0,0 +1,15 @@
+-- 新增邮箱验证码表（注册/重置密码/更换邮箱）
+-- 执行此SQL前请先备份数据库
+
+CREATE TABLE IF NOT EXISTS verification_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL COMMENT '接收邮箱',
+    purpose VARCHAR(50) NOT NULL COMMENT '用途：register/reset/change-email',
+    code_hash CHAR(64) NOT NULL COMMENT '验证码哈希（SHA-256）',
+    attempts INT DEFAULT 0 COMMENT '已尝试次数',
+    max_attempts INT DEFAULT 5 COMMENT '最大尝试次数',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    consumed_at TIMESTAMP NULL COMMENT '使用/作废时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_email_purpose (email, purpose, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='邮箱验证码表';
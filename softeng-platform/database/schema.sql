@@ -16,13 +16,208 @@ CREATE TABLE IF NOT EXISTS users (
     avatar VARCHAR(500) COMMENT '头像地址',
     description TEXT COMMENT '个人动态描述',
     face_photo VARCHAR(500) COMMENT '封面地址',
-    role VARCHAR(50) DEFAULT 'user' COMMENT '角色：user/admin',
+    role VARCHAR(50) DEFAULT 'user' COMMENT '角色：user/moderator/course_editor/admin',
+    cohort VARCHAR(100) NULL COMMENT '届别/班级（由邀请码分配）',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX idx_username (username),
     INDEX idx_email (email)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表';
 
+-- 邮箱验证码表
+CREATE TABLE IF NOT EXISTS verification_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL COMMENT '接收邮箱',
+    purpose VARCHAR(50) NOT NULL COMMENT '用途：register/reset/change-email',
+    code_hash CHAR(64) NOT NULL COMMENT '验证码哈希（SHA-256）',
+    attempts INT DEFAULT 0 COMMENT '已尝试次数',
+    max_attempts INT DEFAULT 5 COMMENT '最大尝试次数',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    consumed_at TIMESTAMP NULL COMMENT '使用/作废时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_email_purpose (email, purpose, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='邮箱验证码表';
+
+-- 邀请码表
+CREATE TABLE IF NOT EXISTS invitation_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(64) NOT NULL UNIQUE COMMENT '邀请码',
+    max_uses INT NOT NULL DEFAULT 1 COMMENT '最大使用次数',
+    used_count INT NOT NULL DEFAULT 0 COMMENT '已使用次数',
+    expires_at TIMESTAMP NULL COMMENT '过期时间（NULL 表示不过期）',
+    role VARCHAR(50) DEFAULT 'user' COMMENT '注册后分配的角色',
+    cohort VARCHAR(100) NULL COMMENT '注册后分配的届别/班级',
+    note VARCHAR(255) NULL COMMENT '备注',
+    created_by INT COMMENT '创建管理员ID',
+    revoked_at TIMESTAMP NULL COMMENT '撤销时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='邀请码表';
+
+-- 邀请码兑换记录表
+CREATE TABLE IF NOT EXISTS invitation_redemptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    invitation_id INT NOT NULL COMMENT '邀请码ID',
+    user_id INT NOT NULL COMMENT '注册用户ID',
+    redeemed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '兑换时间',
+    INDEX idx_invitation_id (invitation_id),
+    FOREIGN KEY (invitation_id) REFERENCES invitation_codes(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_invitation_user (invitation_id, user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='邀请码兑换记录表';
+
+-- 刷新令牌表（同一次登录轮换出的令牌属于同一家族）
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    family_id VARCHAR(64) NOT NULL COMMENT '令牌家族ID（会话ID）',
+    token_hash CHAR(64) NOT NULL UNIQUE COMMENT '刷新令牌哈希（SHA-256）',
+    access_jti VARCHAR(64) NOT NULL COMMENT '同时签发的访问令牌ID',
+    access_expires_at TIMESTAMP NOT NULL COMMENT '访问令牌过期时间',
+    expires_at TIMESTAMP NOT NULL COMMENT '刷新令牌过期时间',
+    used_at TIMESTAMP NULL COMMENT '轮换时间（再次使用视为重用）',
+    revoked_at TIMESTAMP NULL COMMENT '吊销时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_family_id (family_id),
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='刷新令牌表';
+
+-- 登录会话表（每次登录一条，对应一个刷新令牌家族）
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    family_id VARCHAR(64) NOT NULL UNIQUE COMMENT '令牌家族ID（写入访问令牌的 sid）',
+    user_agent VARCHAR(255) NULL COMMENT '客户端 User-Agent',
+    ip VARCHAR(45) NULL COMMENT '客户端IP',
+    mfa_verified TINYINT(1) NOT NULL DEFAULT 0 COMMENT '登录时是否通过两步验证',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '登录时间',
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '最后活跃时间',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间（随刷新顺延）',
+    revoked_at TIMESTAMP NULL COMMENT '吊销时间',
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录会话表';
+
+-- 访问令牌吊销列表
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY COMMENT '访问令牌ID',
+    user_id INT NOT NULL COMMENT '用户ID',
+    expires_at TIMESTAMP NOT NULL COMMENT '令牌原过期时间，过期后可清理',
+    revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '吊销时间',
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='访问令牌吊销列表';
+
+-- 个人访问令牌表（用于脚本调用 API）
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    name VARCHAR(100) NOT NULL COMMENT '令牌名称',
+    token_hash CHAR(64) NOT NULL UNIQUE COMMENT '令牌哈希（SHA-256）',
+    token_prefix VARCHAR(16) NOT NULL COMMENT '令牌前缀（展示用）',
+    scopes VARCHAR(100) NOT NULL COMMENT '授权范围，逗号分隔：read/submit/comment',
+    expires_at TIMESTAMP NULL COMMENT '过期时间',
+    last_used_at TIMESTAMP NULL COMMENT '最后使用时间',
+    revoked_at TIMESTAMP NULL COMMENT '吊销时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='个人访问令牌表';
+
+-- 日历订阅令牌表（每个用户一个，订阅地址中的令牌只保存哈希）
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+    user_id INT PRIMARY KEY COMMENT '用户ID',
+    token_hash CHAR(64) NOT NULL UNIQUE COMMENT '令牌哈希（SHA-256）',
+    last_used_at TIMESTAMP NULL COMMENT '最后访问时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='日历订阅令牌表';
+
+-- 登录失败记录表（LOGIN_GUARD_DRIVER=database 时使用）
+CREATE TABLE IF NOT EXISTS login_attempts (
+    attempt_key VARCHAR(191) PRIMARY KEY COMMENT '计数键：user:<id> / account:<用户名或邮箱> / ip:<IP>',
+    failures INT NOT NULL DEFAULT 0 COMMENT '窗口内连续失败次数',
+    last_failure_at TIMESTAMP NOT NULL COMMENT '最后一次失败时间',
+    blocked_until TIMESTAMP NULL COMMENT '退避/锁定截止时间',
+    locked TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为账号锁定',
+    INDEX idx_blocked_until (blocked_until)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录失败记录表';
+
+-- 两步验证表（TOTP）
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id INT PRIMARY KEY COMMENT '用户ID',
+    secret VARCHAR(64) NOT NULL COMMENT 'TOTP 密钥（Base32）',
+    last_counter BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次使用的时间步，防止验证码重放',
+    enabled_at TIMESTAMP NULL COMMENT '启用时间，为空表示尚未确认',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '密钥生成时间',
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证表';
+
+-- 两步验证恢复码表（每个恢复码只能使用一次）
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    code_hash CHAR(64) NOT NULL COMMENT '恢复码哈希（SHA-256）',
+    used_at TIMESTAMP NULL COMMENT '使用时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_code (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证恢复码表';
+
+-- 第三方账号绑定表（OAuth2/OIDC 登录）
+CREATE TABLE IF NOT EXISTS user_identities (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    provider VARCHAR(50) NOT NULL COMMENT '提供方名称（OAUTH_PROVIDERS 中的名称）',
+    subject VARCHAR(255) NOT NULL COMMENT '提供方内的用户ID',
+    email VARCHAR(255) NULL COMMENT '提供方返回的邮箱',
+    display_name VARCHAR(100) NULL COMMENT '提供方返回的显示名称',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '绑定时间',
+    last_login_at TIMESTAMP NULL COMMENT '最后一次通过该账号登录的时间',
+    UNIQUE KEY uk_provider_subject (provider, subject),
+    UNIQUE KEY uk_user_provider (user_id, provider),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='第三方账号绑定表';
+
+-- 第三方授权请求表（state 与 PKCE code_verifier，回调时一次性使用）
+CREATE TABLE IF NOT EXISTS oauth_states (
+    state_hash CHAR(64) PRIMARY KEY COMMENT 'state 哈希（SHA-256）',
+    provider VARCHAR(50) NOT NULL COMMENT '提供方名称',
+    code_verifier VARCHAR(128) NOT NULL COMMENT 'PKCE code_verifier',
+    user_id INT NULL COMMENT '绑定账号时的当前用户，登录时为空',
+    invitation_code VARCHAR(50) NULL COMMENT '首次登录自动创建账号使用的邀请码',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='第三方授权请求表';
+
+-- 账号注销申请表（冷静期结束后匿名化，用户行保留为占位以保留评论串）
+CREATE TABLE IF NOT EXISTS account_deletions (
+    user_id INT PRIMARY KEY COMMENT '用户ID',
+    requested_at TIMESTAMP NOT NULL COMMENT '申请时间',
+    purge_after TIMESTAMP NOT NULL COMMENT '冷静期结束时间',
+    completed_at TIMESTAMP NULL COMMENT '匿名化完成时间',
+    INDEX idx_purge_after (purge_after),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='账号注销申请表';
+
+-- 认证审计日志表（登录、改密、改邮箱、角色变更、令牌吊销等）
+CREATE TABLE IF NOT EXISTS auth_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NULL COMMENT '用户ID，账号不存在的失败登录为空',
+    identifier VARCHAR(100) NULL COMMENT '用户名，或失败登录时输入的用户名/邮箱',
+    event_type VARCHAR(32) NOT NULL COMMENT '事件类型',
+    ip VARCHAR(45) NULL COMMENT '客户端IP',
+    user_agent VARCHAR(255) NULL COMMENT '客户端User-Agent',
+    detail VARCHAR(255) NULL COMMENT '补充说明',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_created (user_id, created_at),
+    INDEX idx_identifier (identifier),
+    INDEX idx_type_created (event_type, created_at),
+    INDEX idx_ip_created (ip, created_at),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='认证审计日志表';
+
 -- ==================== 工具相关表 ====================
 
 -- 工具表
@@ -83,6 +278,23 @@ CREATE TABLE IF NOT EXISTS tool_contributors (
     UNIQUE KEY uk_tool_user (tool_id, user_id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='工具贡献者表';
 
+-- 工具修改提议表（字段级差异，审核通过后应用到工具）
+CREATE TABLE IF NOT EXISTS tool_revisions (
+    revision_id INT AUTO_INCREMENT PRIMARY KEY,
+    tool_id INT NOT NULL COMMENT '工具ID',
+    editor_id INT NULL COMMENT '提议用户ID',
+    summary VARCHAR(255) NULL COMMENT '修改说明',
+    changes TEXT NOT NULL COMMENT '字段级差异（JSON）：[{field, before, after}]',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT NULL COMMENT '驳回原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_tool_id (tool_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (tool_id) REFERENCES tools(resource_id) ON DELETE CASCADE,
+    FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='工具修改提议表';
+
 -- ==================== 课程相关表 ====================
 
 -- 课程表
@@ -96,21 +308,94 @@ CREATE TABLE IF NOT EXISTS courses (
     views INT DEFAULT 0 COMMENT '浏览量',
     loves INT DEFAULT 0 COMMENT '点赞数',
     collections INT DEFAULT 0 COMMENT '收藏量',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected/offline',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT COMMENT '驳回原因',
+    submitter_id INT COMMENT '提交用户ID',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX idx_semester (semester),
-    INDEX idx_name (name)
+    INDEX idx_name (name),
+    INDEX idx_status (status),
+    INDEX idx_submitter (submitter_id),
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程表';
 
+-- 教师表
+CREATE TABLE IF NOT EXISTS teachers (
+    teacher_id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL COMMENT '教师姓名（规范写法）',
+    title VARCHAR(100) NULL COMMENT '职称',
+    department VARCHAR(255) NULL COMMENT '院系',
+    email VARCHAR(255) NULL COMMENT '邮箱',
+    homepage VARCHAR(500) NULL COMMENT '个人主页',
+    avatar VARCHAR(500) NULL COMMENT '头像',
+    bio TEXT NULL COMMENT '简介',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='教师表';
+
+-- 教师别名表（同一教师的其他写法，提交课程时按别名关联到教师）
+CREATE TABLE IF NOT EXISTS teacher_aliases (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    teacher_id INT NOT NULL COMMENT '教师ID',
+    alias VARCHAR(100) NOT NULL COMMENT '别名',
+    UNIQUE KEY uk_alias (alias),
+    INDEX idx_teacher_id (teacher_id),
+    FOREIGN KEY (teacher_id) REFERENCES teachers(teacher_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='教师别名表';
+
 -- 课程教师表
 CREATE TABLE IF NOT EXISTS course_teachers (
     id INT AUTO_INCREMENT PRIMARY KEY,
     course_id INT NOT NULL COMMENT '课程ID',
-    teacher_name VARCHAR(100) NOT NULL COMMENT '教师姓名',
+    teacher_id INT NULL COMMENT '教师ID，导入脚本只写姓名时由脚本末尾的语句关联',
+    teacher_name VARCHAR(100) NOT NULL COMMENT '教师姓名（与 teachers.name 保持一致）',
     INDEX idx_course_id (course_id),
-    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+    UNIQUE KEY uk_course_teacher (course_id, teacher_id),
+    INDEX idx_teacher_id (teacher_id),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (teacher_id) REFERENCES teachers(teacher_id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程教师表';
 
+-- 学期表（学年从秋季学期开始，academic_year * 10 + term 即学期先后顺序）
+CREATE TABLE IF NOT EXISTS semesters (
+    semester_id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL COMMENT '学期名称，如 2024秋季',
+    academic_year INT NOT NULL COMMENT '学年起始年份，2024-2025 学年为 2024',
+    term TINYINT NOT NULL COMMENT '学期：1 秋季 / 2 春季 / 3 夏季',
+    start_date DATE NULL COMMENT '开始日期',
+    end_date DATE NULL COMMENT '结束日期',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_name (name),
+    UNIQUE KEY uk_year_term (academic_year, term)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='学期表';
+
+-- 开课表（课程在某个学期的一次开课）
+CREATE TABLE IF NOT EXISTS course_offerings (
+    offering_id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    semester_id INT NOT NULL COMMENT '学期ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_course_semester (course_id, semester_id),
+    INDEX idx_semester_id (semester_id),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (semester_id) REFERENCES semesters(semester_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='开课表';
+
+-- 开课教师表
+CREATE TABLE IF NOT EXISTS course_offering_teachers (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    offering_id INT NOT NULL COMMENT '开课ID',
+    teacher_id INT NOT NULL COMMENT '教师ID',
+    UNIQUE KEY uk_offering_teacher (offering_id, teacher_id),
+    INDEX idx_teacher_id (teacher_id),
+    FOREIGN KEY (offering_id) REFERENCES course_offerings(offering_id) ON DELETE CASCADE,
+    FOREIGN KEY (teacher_id) REFERENCES teachers(teacher_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='开课教师表';
+
 -- 课程分类表
 CREATE TABLE IF NOT EXISTS course_categories (
     id INT AUTO_INCREMENT PRIMARY KEY,
@@ -120,28 +405,72 @@ CREATE TABLE IF NOT EXISTS course_categories (
     FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程分类表';
 
+-- 课程章节表（章、节两级大纲）
+CREATE TABLE IF NOT EXISTS course_chapters (
+    chapter_id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    parent_id INT NULL COMMENT '所属章ID，为空表示章',
+    title VARCHAR(255) NOT NULL COMMENT '章节标题',
+    sort_order INT DEFAULT 0 COMMENT '同级排序',
+    submitter_id INT NULL COMMENT '提交者ID',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected/offline',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT NULL COMMENT '驳回原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_course_id (course_id),
+    INDEX idx_parent_id (parent_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES course_chapters(chapter_id) ON DELETE CASCADE,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程章节表';
+
 -- 课程资源表（URL资源）
 CREATE TABLE IF NOT EXISTS course_resources_web (
     resource_id INT AUTO_INCREMENT PRIMARY KEY,
     course_id INT NOT NULL COMMENT '课程ID',
+    chapter_id INT NULL COMMENT '关联的章节ID',
     resource_intro VARCHAR(255) NOT NULL COMMENT '资源说明',
     resource_url VARCHAR(500) NOT NULL COMMENT '资源网址',
     sort_order INT DEFAULT 0 COMMENT '排序',
+    submitter_id INT NULL COMMENT '提交者ID，历史导入数据为空',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT NULL COMMENT '拒绝原因',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     INDEX idx_course_id (course_id),
-    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+    INDEX idx_chapter_id (chapter_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (chapter_id) REFERENCES course_chapters(chapter_id) ON DELETE SET NULL,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程URL资源表';
 
 -- 课程资源表（上传资源/课本）
 CREATE TABLE IF NOT EXISTS course_resources_upload (
     resource_id INT AUTO_INCREMENT PRIMARY KEY,
     course_id INT NOT NULL COMMENT '课程ID',
+    chapter_id INT NULL COMMENT '关联的章节ID',
     resource_intro VARCHAR(255) NOT NULL COMMENT '资源说明',
     resource_upload VARCHAR(500) NOT NULL COMMENT '上传文件URL',
+    file_name VARCHAR(255) NULL COMMENT '原始文件名',
+    file_size BIGINT NULL COMMENT '文件大小（字节）',
+    content_type VARCHAR(100) NULL COMMENT '文件类型',
+    storage_path VARCHAR(500) NULL COMMENT '本地存储路径（相对 RESOURCE_STORAGE_DIR），外部链接为空',
+    source_url VARCHAR(500) NULL COMMENT '批量导入时文件的原始地址，用于重复导入时去重',
+    download_count INT DEFAULT 0 COMMENT '下载次数',
     sort_order INT DEFAULT 0 COMMENT '排序',
+    submitter_id INT NULL COMMENT '提交者ID，历史导入数据为空',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT NULL COMMENT '拒绝原因',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     INDEX idx_course_id (course_id),
-    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+    INDEX idx_chapter_id (chapter_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (chapter_id) REFERENCES course_chapters(chapter_id) ON DELETE SET NULL,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程上传资源表';
 
 -- 课程贡献者表
@@ -156,6 +485,98 @@ CREATE TABLE IF NOT EXISTS course_contributors (
     UNIQUE KEY uk_course_user (course_id, user_id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程贡献者表';
 
+-- 学习计划表
+CREATE TABLE IF NOT EXISTS learning_plans (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    course_id INT NOT NULL COMMENT '课程ID',
+    semester VARCHAR(50) NOT NULL COMMENT '计划修读学期',
+    sort_order INT NOT NULL DEFAULT 1 COMMENT '学期内顺序',
+    status VARCHAR(20) NOT NULL DEFAULT 'planned' COMMENT '状态：planned/in_progress/completed',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_course (user_id, course_id),
+    INDEX idx_user_semester (user_id, semester, sort_order),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='学习计划表';
+
+-- 章节学习进度表（有记录即表示已完成）
+CREATE TABLE IF NOT EXISTS chapter_progress (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    chapter_id INT NOT NULL COMMENT '章节ID',
+    completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '完成时间',
+    UNIQUE KEY uk_user_chapter (user_id, chapter_id),
+    INDEX idx_chapter_id (chapter_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (chapter_id) REFERENCES course_chapters(chapter_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='章节学习进度表';
+
+-- 课程评分表（每个用户对每次开课评分一次）
+CREATE TABLE IF NOT EXISTS course_ratings (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    user_id INT NOT NULL COMMENT '用户ID',
+    offering_id INT NOT NULL COMMENT '开课ID',
+    teacher_id INT NULL COMMENT '教师ID，未填写任课教师的评分为 NULL',
+    teacher_name VARCHAR(100) NOT NULL DEFAULT '' COMMENT '任课教师（与 teachers.name 保持一致）',
+    semester VARCHAR(50) NOT NULL COMMENT '修读学期（开课学期的名称）',
+    difficulty TINYINT NOT NULL COMMENT '难度 1-5',
+    workload TINYINT NOT NULL COMMENT '课业负担 1-5',
+    teaching TINYINT NOT NULL COMMENT '教学质量 1-5',
+    usefulness TINYINT NOT NULL COMMENT '实用性 1-5',
+    review TEXT NULL COMMENT '文字评价',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_offering (user_id, offering_id),
+    INDEX idx_course_id (course_id),
+    INDEX idx_offering_id (offering_id),
+    INDEX idx_teacher_id (teacher_id),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (offering_id) REFERENCES course_offerings(offering_id) ON DELETE CASCADE,
+    FOREIGN KEY (teacher_id) REFERENCES teachers(teacher_id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程评分表';
+
+-- 课程修读关系表（先修 / 同修，审核通过后生效）
+CREATE TABLE IF NOT EXISTS course_prerequisites (
+    relation_id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    required_course_id INT NOT NULL COMMENT '需要先修或同修的课程ID',
+    relation_type VARCHAR(20) NOT NULL DEFAULT 'prerequisite' COMMENT '关系类型：prerequisite/corequisite',
+    note VARCHAR(500) NULL COMMENT '说明',
+    submitter_id INT NULL COMMENT '提交者ID',
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected/offline',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT NULL COMMENT '驳回原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_course_required (course_id, required_course_id),
+    INDEX idx_required_course_id (required_course_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (required_course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程修读关系表';
+
+-- 课程日历事件表（课程贡献者添加的截止时间、考试和活动，出现在日历订阅中）
+CREATE TABLE IF NOT EXISTS course_events (
+    event_id INT AUTO_INCREMENT PRIMARY KEY,
+    course_id INT NOT NULL COMMENT '课程ID',
+    title VARCHAR(255) NOT NULL COMMENT '标题',
+    description TEXT NULL COMMENT '说明',
+    kind VARCHAR(20) NOT NULL DEFAULT 'event' COMMENT '类型：deadline/exam/event',
+    start_at DATETIME NOT NULL COMMENT '开始时间，截止时间类事件为截止时刻',
+    end_at DATETIME NULL COMMENT '结束时间，为空表示没有持续时间',
+    all_day TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否全天事件',
+    location VARCHAR(255) NULL COMMENT '地点',
+    creator_id INT NULL COMMENT '添加者ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_course_start (course_id, start_at),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程日历事件表';
+
 -- ==================== 项目相关表 ====================
 
 -- 项目表
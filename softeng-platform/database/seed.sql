@@ -14,7 +14,12 @@ TRUNCATE TABLE course_contributors;
 TRUNCATE TABLE course_resources_upload;
 TRUNCATE TABLE course_resources_web;
 TRUNCATE TABLE course_categories;
+TRUNCATE TABLE course_offering_teachers;
+TRUNCATE TABLE course_offerings;
+TRUNCATE TABLE semesters;
 TRUNCATE TABLE course_teachers;
+TRUNCATE TABLE teacher_aliases;
+TRUNCATE TABLE teachers;
 TRUNCATE TABLE courses;
 
 TRUNCATE TABLE project_authors;
@@ -69,15 +74,37 @@ INSERT INTO tool_contributors (tool_id, user_id) VALUES
 
 -- ===================== courses =====================
 INSERT INTO courses (
-  course_id, resource_type, name, semester, credit, cover, views, loves, collections
+  course_id, resource_type, name, semester, credit, cover, views, loves, collections, status
 ) VALUES
-  (1, 'course', '软件工程导论', '大二上', 3, 'https://example.com/course1.jpg', 1000, 200, 150),
-  (2, 'course', '高级软件工程', '大三上', 2, 'https://example.com/course2.jpg', 800, 150, 100);
+  (1, 'course', '软件工程导论', '2024秋季', 3, 'https://example.com/course1.jpg', 1000, 200, 150, 'approved'),
+  (2, 'course', '高级软件工程', '2025秋季', 2, 'https://example.com/course2.jpg', 800, 150, 100, 'approved');
 
-INSERT INTO course_teachers (course_id, teacher_name) VALUES
-  (1, '张教授'),
-  (1, '李教授'),
-  (2, '王教授');
+INSERT INTO semesters (semester_id, name, academic_year, term, start_date, end_date) VALUES
+  (1, '2024秋季', 2024, 1, '2024-09-02', '2025-01-12'),
+  (2, '2025春季', 2024, 2, '2025-02-24', '2025-06-29'),
+  (3, '2025秋季', 2025, 1, '2025-09-01', '2026-01-11');
+
+INSERT INTO teachers (teacher_id, name, title, department) VALUES
+  (1, '张教授', '教授', '软件工程学院'),
+  (2, '李教授', '教授', '软件工程学院'),
+  (3, '王教授', '副教授', '计算机学院');
+
+INSERT INTO teacher_aliases (teacher_id, alias) VALUES
+  (1, '张老师');
+
+INSERT INTO course_teachers (course_id, teacher_id, teacher_name) VALUES
+  (1, 1, '张教授'),
+  (1, 2, '李教授'),
+  (2, 3, '王教授');
+
+INSERT INTO course_offerings (offering_id, course_id, semester_id) VALUES
+  (1, 1, 1),
+  (2, 2, 3);
+
+INSERT INTO course_offering_teachers (offering_id, teacher_id) VALUES
+  (1, 1),
+  (1, 2),
+  (2, 3);
 
 INSERT INTO course_categories (course_id, category) VALUES
   (1, '专必'),
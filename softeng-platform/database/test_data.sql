@@ -67,20 +67,20 @@ INSERT IGNORE INTO tool_contributors (tool_id, user_id) VALUES
 
 -- ==================== 课程数据 ====================
 
-INSERT INTO courses (resource_type, name, semester, credit, cover, views, loves, collections, created_at) VALUES
-('course', '软件工程导论', '2024春季', 3, 'https://via.placeholder.com/400x300?text=软件工程导论', 520, 45, 38, '2024-02-01 08:00:00'),
-('course', '数据库系统原理', '2024春季', 3, 'https://via.placeholder.com/400x300?text=数据库系统原理', 480, 42, 35, '2024-02-02 09:00:00'),
-('course', 'Web开发技术', '2024春季', 2, 'https://via.placeholder.com/400x300?text=Web开发技术', 680, 58, 52, '2024-02-03 10:00:00'),
-('course', '软件项目管理', '2024春季', 2, 'https://via.placeholder.com/400x300?text=软件项目管理', 390, 32, 28, '2024-02-04 11:00:00'),
-('course', '软件测试与质量保证', '2024春季', 2, 'https://via.placeholder.com/400x300?text=软件测试', 350, 28, 25, '2024-02-05 12:00:00'),
-('course', '数据结构与算法', '2023秋季', 4, 'https://via.placeholder.com/400x300?text=数据结构', 950, 78, 65, '2023-09-01 08:00:00'),
-('course', '操作系统', '2023秋季', 3, 'https://via.placeholder.com/400x300?text=操作系统', 720, 56, 48, '2023-09-02 09:00:00'),
-('course', '计算机网络', '2023秋季', 3, 'https://via.placeholder.com/400x300?text=计算机网络', 650, 52, 45, '2023-09-03 10:00:00');
-
--- 待审核的课程（courses表没有status字段，这些课程也会正常显示）
-INSERT INTO courses (resource_type, name, semester, credit, cover, views, loves, collections, created_at) VALUES
-('course', '移动应用开发', '2024秋季', 2, 'https://via.placeholder.com/400x300?text=移动开发', 0, 0, 0, NOW()),
-('course', '人工智能基础', '2024秋季', 3, 'https://via.placeholder.com/400x300?text=人工智能', 0, 0, 0, NOW());
+INSERT INTO courses (resource_type, name, semester, credit, cover, views, loves, collections, status, audit_time, created_at) VALUES
+('course', '软件工程导论', '2024春季', 3, 'https://via.placeholder.com/400x300?text=软件工程导论', 520, 45, 38, 'approved', '2024-02-01 08:00:00', '2024-02-01 08:00:00'),
+('course', '数据库系统原理', '2024春季', 3, 'https://via.placeholder.com/400x300?text=数据库系统原理', 480, 42, 35, 'approved', '2024-02-02 09:00:00', '2024-02-02 09:00:00'),
+('course', 'Web开发技术', '2024春季', 2, 'https://via.placeholder.com/400x300?text=Web开发技术', 680, 58, 52, 'approved', '2024-02-03 10:00:00', '2024-02-03 10:00:00'),
+('course', '软件项目管理', '2024春季', 2, 'https://via.placeholder.com/400x300?text=软件项目管理', 390, 32, 28, 'approved', '2024-02-04 11:00:00', '2024-02-04 11:00:00'),
+('course', '软件测试与质量保证', '2024春季', 2, 'https://via.placeholder.com/400x300?text=软件测试', 350, 28, 25, 'approved', '2024-02-05 12:00:00', '2024-02-05 12:00:00'),
+('course', '数据结构与算法', '2023秋季', 4, 'https://via.placeholder.com/400x300?text=数据结构', 950, 78, 65, 'approved', '2023-09-01 08:00:00', '2023-09-01 08:00:00'),
+('course', '操作系统', '2023秋季', 3, 'https://via.placeholder.com/400x300?text=操作系统', 720, 56, 48, 'approved', '2023-09-02 09:00:00', '2023-09-02 09:00:00'),
+('course', '计算机网络', '2023秋季', 3, 'https://via.placeholder.com/400x300?text=计算机网络', 650, 52, 45, 'approved', '2023-09-03 10:00:00', '2023-09-03 10:00:00');
+
+-- 待审核的课程（不会出现在课程列表中）
+INSERT INTO courses (resource_type, name, semester, credit, cover, views, loves, collections, status, submitter_id, created_at) VALUES
+('course', '移动应用开发', '2024秋季', 2, 'https://via.placeholder.com/400x300?text=移动开发', 0, 0, 0, 'pending', 17, NOW()),
+('course', '人工智能基础', '2024秋季', 3, 'https://via.placeholder.com/400x300?text=人工智能', 0, 0, 0, 'pending', 17, NOW());
 
 -- 课程教师
 INSERT INTO course_teachers (course_id, teacher_name) VALUES
@@ -95,6 +95,9 @@ INSERT INTO course_teachers (course_id, teacher_name) VALUES
 (9, '王老师'),
 (10, '李老师');
 
+-- 为教师姓名创建教师记录并关联（已有教师按姓名或别名关联），需在 database 目录下用 mysql 客户端执行
+SOURCE link_course_teachers.sql;
+
 -- 课程分类
 INSERT INTO course_categories (course_id, category) VALUES
 (1, '专业必修'), (1, '软件工程'),
@@ -0,0 +1,259 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"softeng-platform/internal/model"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// ErrUnsupportedContent 链接既不是 HTML 页面也不是可识别的课件
+var ErrUnsupportedContent = errors.New("unsupported content type")
+
+const (
+	// maxResources 单个页面最多建议的资源数
+	maxResources = 50
+	// maxIntroLength resource_intro 列的长度上限
+	maxIntroLength = 255
+)
+
+// slideExtensions 识别为课件的文件扩展名
+var slideExtensions = map[string]bool{
+	".ppt": true, ".pptx": true, ".pps": true, ".ppsx": true, ".key": true, ".odp": true,
+}
+
+// Analyzer 抓取课程页面，提取标题、简介、封面和页面中的 PDF / 课件链接
+type Analyzer struct {
+	fetcher Fetcher
+}
+
+func New(fetcher Fetcher) *Analyzer {
+	return &Analyzer{fetcher: fetcher}
+}
+
+// Analyze 分析课程链接，封面为页面中的原始地址，由调用方决定是否本地化
+func (a *Analyzer) Analyze(ctx context.Context, rawURL string) (*model.CourseAnalysis, error) {
+	page, err := a.fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(page.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page url: %v", err)
+	}
+
+	result := &model.CourseAnalysis{SourceURL: page.URL, Resources: make([]model.ProposedResource, 0)}
+
+	// 链接本身就是 PDF 或课件时，建议将其作为唯一的资源
+	if page.ContentType != "" && !isHTML(page.ContentType) {
+		kind := resourceType(base)
+		if kind == "" && page.ContentType == "application/pdf" {
+			kind = "pdf"
+		}
+		if kind == "" {
+			return nil, ErrUnsupportedContent
+		}
+		result.Name = fileTitle(base)
+		result.Resources = append(result.Resources, model.ProposedResource{
+			ResourceIntro: truncate(result.Name, maxIntroLength),
+			ResourceURL:   page.URL,
+			Type:          kind,
+		})
+		return result, nil
+	}
+
+	doc, err := html.Parse(bytes.NewReader(page.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page: %v", err)
+	}
+
+	p := &pageParser{base: base, meta: make(map[string]string), seen: make(map[string]bool)}
+	p.walk(doc)
+
+	result.Name = firstNonEmpty(p.meta["og:title"], p.meta["twitter:title"], p.title)
+	result.Description = firstNonEmpty(p.meta["og:description"], p.meta["description"], p.meta["twitter:description"])
+	image := firstNonEmpty(p.meta["og:image:secure_url"], p.meta["og:image"], p.meta["og:image:url"], p.meta["twitter:image"], p.imageSrc)
+	if image != "" {
+		if u := p.resolve(image); u != nil {
+			result.Cover = u.String()
+		}
+	}
+	result.Resources = append(result.Resources, p.resources...)
+	return result, nil
+}
+
+// pageParser 遍历 HTML 节点树收集元信息和资源链接
+type pageParser struct {
+	base      *url.URL
+	baseSet   bool
+	title     string
+	imageSrc  string            // <link rel="image_src">
+	meta      map[string]string // 小写的 property / name -> content，同名时保留第一个
+	resources []model.ProposedResource
+	seen      map[string]bool
+}
+
+func (p *pageParser) walk(n *html.Node) {
+	if n.Type == html.ElementNode {
+		switch n.Data {
+		case "svg", "script", "style", "noscript", "template":
+			return
+		case "base":
+			// 只有第一个 <base> 生效
+			if href := attr(n, "href"); href != "" && !p.baseSet {
+				p.baseSet = true
+				if u := p.resolve(href); u != nil {
+					p.base = u
+				}
+			}
+		case "title":
+			if p.title == "" {
+				p.title = collapseSpace(textContent(n))
+			}
+		case "meta":
+			key := strings.ToLower(firstNonEmpty(attr(n, "property"), attr(n, "name")))
+			content := collapseSpace(attr(n, "content"))
+			if key != "" && content != "" && p.meta[key] == "" {
+				p.meta[key] = content
+			}
+		case "link":
+			if p.imageSrc == "" && strings.EqualFold(attr(n, "rel"), "image_src") {
+				p.imageSrc = attr(n, "href")
+			}
+		case "a":
+			p.addResource(attr(n, "href"), firstNonEmpty(collapseSpace(textContent(n)), attr(n, "title")))
+		case "iframe", "embed":
+			p.addResource(attr(n, "src"), attr(n, "title"))
+		case "object":
+			p.addResource(attr(n, "data"), attr(n, "title"))
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.walk(c)
+	}
+}
+
+// addResource 链接指向 PDF 或课件时加入建议列表，同一地址只保留第一次出现
+func (p *pageParser) addResource(ref, intro string) {
+	if ref == "" || len(p.resources) >= maxResources {
+		return
+	}
+	u := p.resolve(ref)
+	if u == nil {
+		return
+	}
+	kind := resourceType(u)
+	if kind == "" {
+		return
+	}
+	u.Fragment = ""
+	link := u.String()
+	if p.seen[link] {
+		return
+	}
+	p.seen[link] = true
+
+	if intro == "" {
+		intro = fileTitle(u)
+	}
+	p.resources = append(p.resources, model.ProposedResource{
+		ResourceIntro: truncate(intro, maxIntroLength),
+		ResourceURL:   link,
+		Type:          kind,
+	})
+}
+
+// resolve 将页面中的链接解析为绝对地址，只接受 http / https
+func (p *pageParser) resolve(ref string) *url.URL {
+	u, err := p.base.Parse(strings.TrimSpace(ref))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+	return u
+}
+
+// resourceType 根据扩展名和常见的幻灯片托管站点判断资源类型，无法识别时返回空
+func resourceType(u *url.URL) string {
+	ext := strings.ToLower(path.Ext(u.Path))
+	if ext == ".pdf" {
+		return "pdf"
+	}
+	if slideExtensions[ext] {
+		return "slides"
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch {
+	case host == "docs.google.com" && strings.HasPrefix(u.Path, "/presentation/"):
+		return "slides"
+	case host == "slideshare.net" || strings.HasSuffix(host, ".slideshare.net"),
+		host == "speakerdeck.com", host == "slides.com":
+		return "slides"
+	}
+	return ""
+}
+
+// fileTitle 使用文件名作为资源说明，地址中没有文件名（如托管站点的嵌入地址）时使用完整地址
+func fileTitle(u *url.URL) string {
+	name := path.Base(u.Path)
+	if path.Ext(name) == "" {
+		return u.String()
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return sb.String()
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncate 按字符截断，避免切断多字节字符
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max])
+}
@@ -0,0 +1,147 @@
+package analyzer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"softeng-platform/internal/analyzer"
+	"softeng-platform/internal/utils"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const coursePage = `<!DOCTYPE html>
+<html><head>
+<title>  软件工程  </title>
+<meta property="og:title" content="软件工程导论">
+<meta name="description" content="课程简介">
+<meta property="og:image" content="/cover.png">
+</head><body>
+<a href="/slides/ch1.pdf">第一章</a>
+<a href="/slides/ch1.pdf#page=2">重复链接</a>
+<a href="ch2.pptx"></a>
+<a href="/about">关于</a>
+<script><a href="/hidden.pdf">x</a></script>
+</body></html>`
+
+// fixtureServer 本地课程页面，记录封面图片被请求的次数
+func fixtureServer(t *testing.T, coverHits *int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/course/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(coursePage))
+	})
+	mux.HandleFunc("/notes.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.4"))
+	})
+	mux.HandleFunc("/cover.png", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(coverHits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newAnalyzer(allowPrivate bool) *analyzer.Analyzer {
+	return analyzer.New(analyzer.NewHTTPFetcher(analyzer.NewDefaultClient(5*time.Second, allowPrivate), 1<<20))
+}
+
+func TestAnalyzeFixturePages(t *testing.T) {
+	var coverHits int32
+	server := fixtureServer(t, &coverHits)
+	a := newAnalyzer(true)
+
+	tests := []struct {
+		name      string
+		path      string
+		wantName  string
+		wantCover string
+		wantURLs  []string
+	}{
+		{
+			name:      "html page",
+			path:      "/course/se",
+			wantName:  "软件工程导论",
+			wantCover: server.URL + "/cover.png",
+			wantURLs:  []string{server.URL + "/slides/ch1.pdf", server.URL + "/course/ch2.pptx"},
+		},
+		{
+			name:     "pdf link",
+			path:     "/notes.pdf",
+			wantName: "notes.pdf",
+			wantURLs: []string{server.URL + "/notes.pdf"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := a.Analyze(context.Background(), server.URL+tt.path)
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			if result.Name != tt.wantName || result.Cover != tt.wantCover {
+				t.Errorf("name, cover = %q, %q; want %q, %q", result.Name, result.Cover, tt.wantName, tt.wantCover)
+			}
+			if len(result.Resources) != len(tt.wantURLs) {
+				t.Fatalf("resources = %+v, want %v", result.Resources, tt.wantURLs)
+			}
+			for i, res := range result.Resources {
+				if res.ResourceURL != tt.wantURLs[i] {
+					t.Errorf("resource %d = %s, want %s", i, res.ResourceURL, tt.wantURLs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeRejectsPrivatePage(t *testing.T) {
+	var coverHits int32
+	server := fixtureServer(t, &coverHits)
+
+	_, err := newAnalyzer(false).Analyze(context.Background(), server.URL+"/course/se")
+	if err == nil || !strings.Contains(err.Error(), analyzer.ErrPrivateAddress.Error()) {
+		t.Fatalf("Analyze(%s) error = %v, want private address error", server.URL, err)
+	}
+}
+
+func TestCoverDownloadRejectsPrivateAddress(t *testing.T) {
+	var coverHits int32
+	server := fixtureServer(t, &coverHits)
+
+	// 页面本身允许抓取（模拟公网页面），封面指向本机地址
+	result, err := newAnalyzer(true).Analyze(context.Background(), server.URL+"/course/se")
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if result.Cover == "" {
+		t.Fatal("expected a cover URL from og:image")
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(dir) }()
+
+	if _, err := utils.DownloadAndSaveImage(result.Cover); err == nil || !strings.Contains(err.Error(), utils.ErrPrivateAddress.Error()) {
+		t.Fatalf("DownloadAndSaveImage(%s) error = %v, want private address error", result.Cover, err)
+	}
+	if local, _ := utils.ProcessImageURL(result.Cover); local != result.Cover {
+		t.Errorf("ProcessImageURL = %q, want original URL kept", local)
+	}
+	if hits := atomic.LoadInt32(&coverHits); hits != 0 {
+		t.Errorf("cover was requested %d times, want 0", hits)
+	}
+	if _, err := os.Stat(filepath.FromSlash(utils.UploadDir)); !os.IsNotExist(err) {
+		t.Errorf("upload dir should not be created, stat error = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"softeng-platform/internal/utils"
+	"strings"
+	"time"
+)
+
+// ErrPrivateAddress 目标地址为内网或本机地址
+var ErrPrivateAddress = utils.ErrPrivateAddress
+
+// Page 抓取到的页面
+type Page struct {
+	URL         string // 跟随重定向后的最终地址，用于解析页面中的相对链接
+	ContentType string // 不含参数的 MIME 类型，如 text/html
+	Body        []byte // 只有 HTML 页面才读取内容
+}
+
+// Fetcher 抓取课程链接，测试时可替换为指向本地 fixture 服务器的实现
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*Page, error)
+}
+
+type httpFetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// NewHTTPFetcher 创建基于 HTTP 的抓取器，maxBytes 为读取页面内容的上限，超出部分丢弃
+func NewHTTPFetcher(client *http.Client, maxBytes int64) Fetcher {
+	return &httpFetcher{client: client, maxBytes: maxBytes}
+}
+
+// NewDefaultClient 创建抓取课程链接的 HTTP 客户端，allowPrivate 为 false 时拒绝连接内网和本机地址，见 utils.NewHTTPClient
+func NewDefaultClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	return utils.NewHTTPClient(timeout, allowPrivate)
+}
+
+func (f *httpFetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	req.Header.Set("User-Agent", "SoftEngPlatform-CourseAnalyzer/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch page: status %d", resp.StatusCode)
+	}
+
+	page := &Page{URL: resp.Request.URL.String()}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		page.ContentType = strings.ToLower(mediaType)
+	}
+	if page.ContentType == "" || isHTML(page.ContentType) {
+		page.Body, err = io.ReadAll(io.LimitReader(resp.Body, f.maxBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page: %v", err)
+		}
+	}
+	return page, nil
+}
+
+func isHTML(contentType string) bool {
+	return contentType == "text/html" || contentType == "application/xhtml+xml"
+}
@@ -0,0 +1,181 @@
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// prodID 日历生成方标识
+	prodID = "-//softeng-platform//course calendar//ZH"
+	// maxLineOctets RFC 5545 规定每行（不含 CRLF）不超过 75 个字节
+	maxLineOctets = 75
+
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// Calendar 一个 iCalendar 日历（VCALENDAR）
+type Calendar struct {
+	Name        string        // 日历名称（X-WR-CALNAME），订阅后显示在日历 App 中
+	Description string        // 日历说明
+	Refresh     time.Duration // 建议的刷新间隔，0 表示不声明
+	Events      []Event
+}
+
+// Event 日历中的一个事件（VEVENT）。
+// AllDay 为 true 时只使用 Start / End 的日期部分，End 为最后一天（含）；
+// 否则按 UTC 输出，End 为零值时表示没有持续时间的时间点（如截止时间）
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Categories  []string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Updated     time.Time // 最后修改时间，零值时不输出
+}
+
+// Encode 按 RFC 5545 输出日历，stamp 为各事件的 DTSTAMP
+func (c *Calendar) Encode(w io.Writer, stamp time.Time) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+	e.line("BEGIN:VCALENDAR")
+	e.line("VERSION:2.0")
+	e.line("PRODID:" + prodID)
+	e.line("CALSCALE:GREGORIAN")
+	e.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+	if c.Description != "" {
+		e.line("X-WR-CALDESC:" + escapeText(c.Description))
+	}
+	if c.Refresh > 0 {
+		e.line("REFRESH-INTERVAL;VALUE=DURATION:" + formatDuration(c.Refresh))
+		e.line("X-PUBLISHED-TTL:" + formatDuration(c.Refresh))
+	}
+
+	stampValue := stamp.UTC().Format(dateTimeLayout)
+	for _, ev := range c.Events {
+		e.line("BEGIN:VEVENT")
+		e.line("UID:" + ev.UID)
+		e.line("DTSTAMP:" + stampValue)
+		if ev.AllDay {
+			end := ev.End
+			if end.IsZero() || end.Before(ev.Start) {
+				end = ev.Start
+			}
+			// 全天事件的 DTEND 为结束日期的后一天（不含）
+			e.line("DTSTART;VALUE=DATE:" + ev.Start.Format(dateLayout))
+			e.line("DTEND;VALUE=DATE:" + dateOnly(end).AddDate(0, 0, 1).Format(dateLayout))
+		} else {
+			e.line("DTSTART:" + ev.Start.UTC().Format(dateTimeLayout))
+			if !ev.End.IsZero() && ev.End.After(ev.Start) {
+				e.line("DTEND:" + ev.End.UTC().Format(dateTimeLayout))
+			}
+		}
+		e.line("SUMMARY:" + escapeText(ev.Summary))
+		if ev.Description != "" {
+			e.line("DESCRIPTION:" + escapeText(ev.Description))
+		}
+		if ev.Location != "" {
+			e.line("LOCATION:" + escapeText(ev.Location))
+		}
+		if ev.URL != "" {
+			e.line("URL:" + ev.URL)
+		}
+		if len(ev.Categories) > 0 {
+			values := make([]string, len(ev.Categories))
+			for i, category := range ev.Categories {
+				values[i] = escapeText(category)
+			}
+			e.line("CATEGORIES:" + strings.Join(values, ","))
+		}
+		if !ev.Updated.IsZero() {
+			e.line("LAST-MODIFIED:" + ev.Updated.UTC().Format(dateTimeLayout))
+		}
+		e.line("END:VEVENT")
+	}
+	e.line("END:VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// Bytes 输出日历内容
+func (c *Calendar) Bytes(stamp time.Time) []byte {
+	var buf bytes.Buffer
+	c.Encode(&buf, stamp) // 写入内存不会出错
+	return buf.Bytes()
+}
+
+// encoder 按行写入并折行，记录第一个写入错误
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line 写入一行内容，超过 75 字节时折行（续行以空格开头），不拆分 UTF-8 字符
+func (e *encoder) line(s string) {
+	if e.err != nil {
+		return
+	}
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		e.write(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1 // 续行开头的空格占一个字节
+	}
+	e.write(s + "\r\n")
+}
+
+func (e *encoder) write(s string) {
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+// escapeText 转义 TEXT 类型的值：反斜杠、分号、逗号和换行
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// formatDuration 输出 DURATION 值，如 PT6H、PT30M
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		d = time.Minute
+	}
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	var b strings.Builder
+	b.WriteString("PT")
+	if hours > 0 {
+		b.WriteString(strconv.Itoa(hours) + "H")
+	}
+	if minutes > 0 {
+		b.WriteString(strconv.Itoa(minutes) + "M")
+	}
+	return b.String()
+}
+
+// dateOnly 去掉时间部分，保留所在时区的日期
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,162 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"数据结构", "数据结构"},
+		{`a\b`, `a\\b`},
+		{"教室; 机房, 线上", `教室\; 机房\, 线上`},
+		{"第一行\r\n第二行\n第三行\r第四行", `第一行\n第二行\n第三行\n第四行`},
+		{`\;`, `\\\;`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := escapeText(tt.in); got != tt.want {
+				t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// unfold 按 RFC 5545 还原折行，并检查每行不超过 75 字节且不拆分 UTF-8 字符
+func unfold(t *testing.T, data string) []string {
+	if !strings.HasSuffix(data, "\r\n") {
+		t.Fatalf("output does not end with CRLF: %q", data)
+	}
+	var lines []string
+	for _, physical := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		if len(physical) > maxLineOctets {
+			t.Errorf("line has %d octets: %q", len(physical), physical)
+		}
+		if !utf8.ValidString(physical) {
+			t.Errorf("line splits a UTF-8 character: %q", physical)
+		}
+		if strings.HasPrefix(physical, " ") {
+			lines[len(lines)-1] += physical[1:]
+			continue
+		}
+		lines = append(lines, physical)
+	}
+	return lines
+}
+
+func TestEncode(t *testing.T) {
+	stamp := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+	long := strings.Repeat("软件工程课程讲义，", 12)
+
+	tests := []struct {
+		name  string
+		event Event
+		want  []string // 事件中应出现的属性行（折行还原后）
+	}{
+		{
+			name:  "all day",
+			event: Event{UID: "offering-1", Summary: "数据结构", Start: start, End: start.AddDate(0, 0, 6), AllDay: true},
+			want:  []string{"DTSTART;VALUE=DATE:20240902", "DTEND;VALUE=DATE:20240909", "SUMMARY:数据结构"},
+		},
+		{
+			name:  "all day without end",
+			event: Event{UID: "offering-2", Summary: "操作系统", Start: start, AllDay: true},
+			want:  []string{"DTSTART;VALUE=DATE:20240902", "DTEND;VALUE=DATE:20240903"},
+		},
+		{
+			name: "timed in another zone",
+			event: Event{
+				UID:     "event-1",
+				Summary: "期末考试",
+				Start:   time.Date(2025, 1, 6, 9, 0, 0, 0, time.FixedZone("CST", 8*3600)),
+				End:     time.Date(2025, 1, 6, 11, 0, 0, 0, time.FixedZone("CST", 8*3600)),
+			},
+			want: []string{"DTSTART:20250106T010000Z", "DTEND:20250106T030000Z"},
+		},
+		{
+			name:  "deadline",
+			event: Event{UID: "event-2", Summary: "作业截止", Start: start.Add(15 * time.Hour)},
+			want:  []string{"DTSTART:20240902T150000Z"},
+		},
+		{
+			name: "folded and escaped",
+			event: Event{
+				UID:         "event-3",
+				Summary:     "实验; 第一次, 分组",
+				Description: long + "\n" + long,
+				Location:    "A101",
+				Categories:  []string{"课程", "考试,测验"},
+				Start:       start,
+				AllDay:      true,
+				Updated:     stamp,
+			},
+			want: []string{
+				`SUMMARY:实验\; 第一次\, 分组`,
+				"DESCRIPTION:" + long + `\n` + long,
+				"LOCATION:A101",
+				`CATEGORIES:课程,考试\,测验`,
+				"LAST-MODIFIED:20240901T080000Z",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := &Calendar{Name: "我的课程", Refresh: 6 * time.Hour, Events: []Event{tt.event}}
+			lines := unfold(t, string(cal.Bytes(stamp)))
+
+			head := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:" + prodID}
+			for i, want := range head {
+				if lines[i] != want {
+					t.Fatalf("line %d = %q, want %q", i, lines[i], want)
+				}
+			}
+			if lines[len(lines)-1] != "END:VCALENDAR" {
+				t.Errorf("last line = %q", lines[len(lines)-1])
+			}
+
+			present := make(map[string]bool, len(lines))
+			for _, line := range lines {
+				present[line] = true
+			}
+			for _, want := range append([]string{
+				"X-WR-CALNAME:我的课程",
+				"REFRESH-INTERVAL;VALUE=DURATION:PT6H",
+				"UID:" + tt.event.UID,
+				"DTSTAMP:20240901T080000Z",
+			}, tt.want...) {
+				if !present[want] {
+					t.Errorf("missing line %q in\n%s", want, strings.Join(lines, "\n"))
+				}
+			}
+			if tt.event.End.IsZero() && !tt.event.AllDay {
+				for _, line := range lines {
+					if strings.HasPrefix(line, "DTEND") {
+						t.Errorf("unexpected %q for an event without end", line)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{6 * time.Hour, "PT6H"},
+		{90 * time.Minute, "PT1H30M"},
+		{30 * time.Minute, "PT30M"},
+		{10 * time.Second, "PT1M"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.in); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -2,12 +2,80 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Port        string
 	DatabaseURL string
 	JWTSecret   string
+
+	// 可信的反向代理（IP 或 CIDR），只采信它们转发的 X-Forwarded-For / X-Real-IP；为空时使用连接的对端地址
+	TrustedProxies []string
+
+	// 令牌配置
+	AccessTokenTTL  time.Duration // 访问令牌有效期
+	RefreshTokenTTL time.Duration // 刷新令牌有效期
+
+	// 登录防暴力破解配置
+	LoginGuardDriver   string        // memory / database
+	LoginFreeAttempts  int           // 不触发退避的失败次数
+	LoginBackoffBase   time.Duration // 首次退避时长，之后指数增长
+	LoginBackoffMax    time.Duration // 退避时长上限
+	LoginLockThreshold int           // 账号失败达到该次数后锁定
+	LoginLockDuration  time.Duration // 锁定时长
+
+	// 密码策略配置
+	PasswordMinLength     int
+	PasswordMaxLength     int
+	PasswordCharClasses   string // 必须包含的字符类别，逗号分隔：lower/upper/letter/digit/symbol
+	PasswordBlocklistFile string // 额外的本地弱密码列表，每行一个
+	BcryptCost            int
+
+	// 两步验证配置
+	MFAIssuer       string        // 验证器 App 中显示的发行方名称
+	MFAPendingTTL   time.Duration // 登录第二步的有效期
+	RequireAdminMFA bool          // 管理员必须启用两步验证才能访问管理接口
+
+	// 第三方登录配置
+	OAuthProviders       []OAuthProvider
+	OAuthRedirectBaseURL string        // 回调地址前缀，回调为 <base>/auth/oauth/<provider>/callback
+	OAuthStateTTL        time.Duration // 授权请求（state）的有效期
+
+	// 账号注销冷静期，期间可撤销，结束后匿名化
+	AccountDeletionGrace time.Duration
+
+	// 认证审计日志保留期，超过后由定时任务清理
+	AuthEventRetention time.Duration
+
+	// 课程资源上传配置
+	ResourceStorageDir string // 上传文件保存目录，不通过静态路由公开，审核通过后经下载接口访问
+	MaxResourceSizeMB  int    // 单个文件大小上限（MB）
+
+	// 学习计划每学期学分上限，超过时给出提醒，0 表示不限制
+	LearningPlanCreditLimit int
+
+	// 课程链接分析配置
+	CourseAnalyzerTimeout      time.Duration // 抓取页面的超时时间
+	CourseAnalyzerMaxPageKB    int           // 读取页面内容的上限（KB），超出部分不解析
+	CourseAnalyzerAllowPrivate bool          // 允许抓取内网和本机地址，仅用于本地调试
+
+	// 日历订阅配置
+	CalendarFeedBaseURL string // 订阅地址前缀，个人订阅地址为 <base>/course/calendar/<token>.ics
+	CalendarFallTerm    string // 学期未设置起止日期时的默认日期，格式 MM-DD/MM-DD，结束早于开始时跨年
+	CalendarSpringTerm  string
+	CalendarSummerTerm  string
+
+	// 邮件配置
+	MailDriver   string // smtp / log
+	MailFrom     string
+	MailLogFile  string // log 驱动下写入的文件，为空则输出到标准日志
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
 }
 
 func LoadConfig() *Config {
@@ -18,9 +86,107 @@ func LoadConfig() *Config {
 		Port:        getEnv("PORT", "8080"),
 		DatabaseURL: databaseURL,
 		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		LoginGuardDriver:   getEnv("LOGIN_GUARD_DRIVER", "memory"),
+		LoginFreeAttempts:  getEnvInt("LOGIN_FREE_ATTEMPTS", 3),
+		LoginBackoffBase:   getEnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+		LoginBackoffMax:    getEnvDuration("LOGIN_BACKOFF_MAX", 15*time.Minute),
+		LoginLockThreshold: getEnvInt("LOGIN_LOCK_THRESHOLD", 10),
+		LoginLockDuration:  getEnvDuration("LOGIN_LOCK_DURATION", 30*time.Minute),
+
+		PasswordMinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:     getEnvInt("PASSWORD_MAX_LENGTH", 72),
+		PasswordCharClasses:   getEnv("PASSWORD_CHAR_CLASSES", "letter,digit"),
+		PasswordBlocklistFile: getEnv("PASSWORD_BLOCKLIST_FILE", ""),
+		BcryptCost:            getEnvInt("BCRYPT_COST", 12),
+
+		MFAIssuer:       getEnv("MFA_ISSUER", "SoftEng Platform"),
+		MFAPendingTTL:   getEnvDuration("MFA_PENDING_TTL", 5*time.Minute),
+		RequireAdminMFA: getEnvBool("REQUIRE_ADMIN_MFA", false),
+
+		OAuthProviders:       loadOAuthProviders(),
+		OAuthRedirectBaseURL: getEnv("OAUTH_REDIRECT_BASE_URL", "http://localhost:8080"),
+		OAuthStateTTL:        getEnvDuration("OAUTH_STATE_TTL", 10*time.Minute),
+
+		AccountDeletionGrace: getEnvDuration("ACCOUNT_DELETION_GRACE", 14*24*time.Hour),
+
+		AuthEventRetention: getEnvDuration("AUTH_EVENT_RETENTION", 180*24*time.Hour),
+
+		ResourceStorageDir: getEnv("RESOURCE_STORAGE_DIR", "storage/resources"),
+		MaxResourceSizeMB:  getEnvInt("MAX_RESOURCE_SIZE_MB", 50),
+
+		LearningPlanCreditLimit: getEnvInt("LEARNING_PLAN_CREDIT_LIMIT", 30),
+
+		CourseAnalyzerTimeout:      getEnvDuration("COURSE_ANALYZER_TIMEOUT", 10*time.Second),
+		CourseAnalyzerMaxPageKB:    getEnvInt("COURSE_ANALYZER_MAX_PAGE_KB", 2048),
+		CourseAnalyzerAllowPrivate: getEnvBool("COURSE_ANALYZER_ALLOW_PRIVATE", false),
+
+		CalendarFeedBaseURL: getEnv("CALENDAR_FEED_BASE_URL", "http://localhost:8080"),
+		CalendarFallTerm:    getEnv("CALENDAR_FALL_TERM", "09-01/01-15"),
+		CalendarSpringTerm:  getEnv("CALENDAR_SPRING_TERM", "02-24/07-05"),
+		CalendarSummerTerm:  getEnv("CALENDAR_SUMMER_TERM", "07-06/08-31"),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		MailFrom:     getEnv("MAIL_FROM", "noreply@softeng.local"),
+		MailLogFile:  getEnv("MAIL_LOG_FILE", ""),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 	}
 }
 
+// OAuthProvider 第三方登录提供方配置。
+// Type 为 oidc 时只需配置 Issuer，端点通过 /.well-known/openid-configuration 自动发现；
+// 显式配置的端点优先，可用于 GitHub 等非 OIDC 的 OAuth2 提供方
+type OAuthProvider struct {
+	Name         string
+	Type         string // oidc / github
+	ClientID     string
+	ClientSecret string
+	Issuer       string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       []string
+}
+
+// loadOAuthProviders 读取 OAUTH_PROVIDERS（逗号分隔的名称）及每个提供方的 OAUTH_<NAME>_* 配置
+func loadOAuthProviders() []OAuthProvider {
+	var providers []OAuthProvider
+	for _, name := range strings.Split(getEnv("OAUTH_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		providerType := getEnv(prefix+"TYPE", "oidc")
+		defaultScopes := "openid,profile,email"
+		if providerType == "github" {
+			defaultScopes = "read:user,user:email"
+		}
+
+		providers = append(providers, OAuthProvider{
+			Name:         name,
+			Type:         providerType,
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			AuthURL:      getEnv(prefix+"AUTH_URL", ""),
+			TokenURL:     getEnv(prefix+"TOKEN_URL", ""),
+			UserInfoURL:  getEnv(prefix+"USERINFO_URL", ""),
+			Scopes:       strings.Split(getEnv(prefix+"SCOPES", defaultScopes), ","),
+		})
+	}
+	return providers
+}
+
 func buildDatabaseURL() string {
 	// 从环境变量获取配置，如果没有则使用默认值
 	user := getEnv("DB_USER", "softeng_app")    // 默认用户
@@ -42,3 +208,44 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration 读取时长配置（如 15m、720h），格式错误时使用默认值
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// getEnvInt 读取整数配置，格式错误时使用默认值
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList 读取逗号分隔的列表配置，去掉空白和空项
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvBool 读取布尔配置（true/false/1/0），格式错误时使用默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// Export 导出个人数据，format=zip 时打包 data.json 和上传的图片，默认返回 JSON
+func (h *AccountHandler) Export(c *gin.Context) {
+	userID := c.GetInt("userID")
+	filename := fmt.Sprintf("softeng-export-%d-%s", userID, time.Now().Format("20060102"))
+
+	if c.DefaultQuery("format", "json") == "zip" {
+		// 先写入内存，打包失败时仍能返回错误信息
+		var buf bytes.Buffer
+		if err := h.accountService.WriteExportZip(c.Request.Context(), userID, &buf); err != nil {
+			response.Error(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+		c.Data(http.StatusOK, "application/zip", buf.Bytes())
+		return
+	}
+
+	export, err := h.accountService.Export(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    export,
+	})
+}
+
+// DeleteAccount 申请注销账号，冷静期结束后匿名化
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	var req model.DeleteAccountRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	deletion, err := h.accountService.RequestDeletion(c.Request.Context(), c.GetInt("userID"), c.GetString("sessionID"), req)
+	if err != nil {
+		if writeLoginBlockedError(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidMFACode) {
+			response.Error(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Account deletion scheduled, you can cancel it before purge_after",
+		"data":    deletion,
+	})
+}
+
+// GetDeletion 查询注销申请状态
+func (h *AccountHandler) GetDeletion(c *gin.Context) {
+	deletion, err := h.accountService.GetDeletion(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    deletion,
+	})
+}
+
+// CancelDeletion 冷静期内撤销注销
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	if err := h.accountService.CancelDeletion(c.Request.Context(), c.GetInt("userID")); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Account deletion cancelled",
+	})
+}
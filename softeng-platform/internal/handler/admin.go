@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/service"
 	"softeng-platform/pkg/response"
 	"strconv"
@@ -37,8 +39,12 @@ func (h *AdminHandler) GetPending(c *gin.Context) {
 	
 	sort := c.Query("sort")
 
-	result, err := h.adminService.GetPending(c.Request.Context(), itemType, cursor, limit, sort)
+	result, err := h.adminService.GetPending(c.Request.Context(), c.GetString("role"), itemType, cursor, limit, sort)
 	if err != nil {
+		if errors.Is(err, service.ErrPermissionDenied) {
+			response.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -81,8 +87,16 @@ func (h *AdminHandler) ReviewItem(c *gin.Context) {
 		status = "rejected"
 	}
 
-	err := h.adminService.ReviewItem(c.Request.Context(), itemID, status, req.ResourceType, req.RejectReason)
+	err := h.adminService.ReviewItem(c.Request.Context(), c.GetString("role"), itemID, status, req.ResourceType, req.RejectReason)
 	if err != nil {
+		if errors.Is(err, service.ErrPermissionDenied) {
+			response.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrToolRevisionConflict) {
+			response.Error(c, http.StatusConflict, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -91,3 +105,57 @@ func (h *AdminHandler) ReviewItem(c *gin.Context) {
 		"message": "Review completed successfully",
 	})
 }
+
+// GetRoles 获取角色及权限列表
+func (h *AdminHandler) GetRoles(c *gin.Context) {
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    h.adminService.GetRoles(c.Request.Context()),
+	})
+}
+
+// AssignRole 为用户分配角色
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	operatorID := c.GetInt("userID")
+
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req model.AssignRoleRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	user, err := h.adminService.AssignRole(c.Request.Context(), operatorID, userID, req.Role)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Role assigned successfully",
+		"user":    user,
+	})
+}
+
+// DeleteComment 删除任意评论（版主）
+func (h *AdminHandler) DeleteComment(c *gin.Context) {
+	commentID, err := strconv.Atoi(c.Param("commentId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	if err := h.adminService.DeleteComment(c.Request.Context(), commentID); err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Comment deleted successfully",
+	})
+}
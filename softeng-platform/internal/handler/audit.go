@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetSecurityLog 获取当前用户的安全日志（登录、改密、吊销令牌等）
+func (h *AuditHandler) GetSecurityLog(c *gin.Context) {
+	cursor, _ := strconv.Atoi(c.Query("cursor"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := h.auditService.ListForUser(c.Request.Context(), c.GetInt("userID"), c.Query("type"), cursor, limit)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// SearchAuthEvents 检索全站认证审计日志（管理员）
+// 参数：user（用户ID或用户名/邮箱）、type、ip、from、to（YYYY-MM-DD 或 RFC3339）
+func (h *AuditHandler) SearchAuthEvents(c *gin.Context) {
+	cursor, _ := strconv.Atoi(c.Query("cursor"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	filter := model.AuthEventFilter{
+		Type:   c.Query("type"),
+		IP:     strings.TrimSpace(c.Query("ip")),
+		Cursor: cursor,
+		Limit:  limit,
+	}
+	if user := strings.TrimSpace(c.Query("user")); user != "" {
+		if id, err := strconv.Atoi(user); err == nil {
+			filter.UserID = id
+		} else {
+			filter.Identifier = user
+		}
+	}
+
+	var err error
+	if filter.From, err = parseQueryTime(c.Query("from"), false); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid from: "+err.Error())
+		return
+	}
+	if filter.To, err = parseQueryTime(c.Query("to"), true); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid to: "+err.Error())
+		return
+	}
+
+	result, err := h.auditService.Search(c.Request.Context(), filter)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// parseQueryTime 解析查询参数中的时间，只给日期时 endOfDay 表示取当天结束（次日零点）
+func parseQueryTime(value string, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("expected YYYY-MM-DD or RFC3339")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
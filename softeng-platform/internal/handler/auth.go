@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"log"
+	"math"
 	"net/http"
+	"softeng-platform/internal/loginguard"
 	"softeng-platform/internal/model"
 	"softeng-platform/internal/service"
 	"softeng-platform/internal/utils"
 	"softeng-platform/pkg/response"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,22 +32,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.Register(c.Request.Context(), req)
+	tokens, err := h.authService.Register(c.Request.Context(), req, clientInfo(c))
 	if err != nil {
+		if writePasswordPolicyError(c, err) {
+			return
+		}
 		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// 生成JWT token
-	token, err := utils.GenerateToken(user.ID, user.Username, user.Role)
-	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to generate token")
-		return
-	}
-
 	response.Success(c, gin.H{
-		"message":   "Registration successful",
-		"JWT token": token,
+		"message":       "Registration successful",
+		"JWT token":     tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
@@ -59,24 +61,124 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// 添加日志确认使用了新代码
 	log.Printf("Login attempt: username_or_email=%s", req.UsernameOrEmail)
 
-	user, err := h.authService.Login(c.Request.Context(), req)
+	result, err := h.authService.Login(c.Request.Context(), req, clientInfo(c))
 	if err != nil {
 		// 添加日志确认错误信息
 		log.Printf("Login error: %v", err)
+		if writeLoginBlockedError(c, err) {
+			return
+		}
+		response.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	writeLoginResult(c, result)
+}
+
+// writeLoginResult 输出登录结果（密码登录和第三方登录共用）
+func writeLoginResult(c *gin.Context, result *model.LoginResult) {
+	// 已启用两步验证：返回待验证令牌，前端再调用 /auth/login/mfa
+	if result.MFARequired {
+		response.Success(c, gin.H{
+			"message":      "MFA required",
+			"mfa_required": true,
+			"mfa_token":    result.MFAToken,
+			"expires_in":   result.MFAExpiresIn,
+		})
+		return
+	}
+
+	data := gin.H{
+		"message":       "1",
+		"JWT token":     result.Tokens.AccessToken,
+		"refresh_token": result.Tokens.RefreshToken,
+		"expires_in":    result.Tokens.ExpiresIn,
+	}
+	if result.MFASetupRequired {
+		// 角色要求两步验证但尚未启用，提示前端引导绑定
+		data["mfa_setup_required"] = true
+	}
+	response.Success(c, data)
+}
+
+// VerifyMFA 登录第二步：提交验证器验证码或恢复码
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req model.MFALoginRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	tokens, err := h.authService.VerifyMFA(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		if writeLoginBlockedError(c, err) {
+			return
+		}
 		response.Error(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	// 生成JWT token
-	token, err := utils.GenerateToken(user.ID, user.Username, user.Role)
+	response.Success(c, gin.H{
+		"message":       "1",
+		"JWT token":     tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌（刷新令牌同时轮换）
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	tokens, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to generate token")
+		response.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":       "Token refreshed",
+		"JWT token":     tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// Logout 登出当前会话
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), claims.(*utils.Claims)); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response.Success(c, gin.H{
-		"message":   "1",
-		"JWT token": token,
+		"message": "Logout successful",
+	})
+}
+
+// LogoutAll 登出全部设备
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	if err := h.authService.LogoutAll(c.Request.Context(), userID); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Logged out from all devices",
 	})
 }
 
@@ -85,7 +187,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	var req struct {
 		Email           string `form:"email" json:"email" binding:"required"`
 		NewPassword     string `form:"new_password" json:"new_password" binding:"required"`
-		CertifyPassword string `form:"certify_password" json:"certify_password" binding:"required"`
+		CertifyPassword string `form:"certify_password" json:"certify_password" binding:"required"` // 发送到邮箱的重置密码验证码
 	}
 
 	// 支持 multipart/form-data 和 application/json
@@ -96,6 +198,9 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 
 	err := h.authService.ForgotPassword(c.Request.Context(), req.Email, req.NewPassword, req.CertifyPassword)
 	if err != nil {
+		if writePasswordPolicyError(c, err) {
+			return
+		}
 		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -104,3 +209,89 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 		"message": "Password reset successful",
 	})
 }
+
+// SendCode 发送邮箱验证码
+func (h *AuthHandler) SendCode(c *gin.Context) {
+	var req model.SendCodeRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	err := h.authService.SendCode(c.Request.Context(), req.Email, req.Purpose)
+	if err != nil {
+		if errors.Is(err, service.ErrCodeTooFrequent) {
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Verification code sent",
+	})
+}
+
+// writeLoginBlockedError 登录处于退避或锁定时返回 429 及 Retry-After
+func writeLoginBlockedError(c *gin.Context, err error) bool {
+	var blocked *loginguard.BlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+	// data 字段区分退避（too_many_attempts）和锁定（account_locked）
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	response.ErrorWithData(c, http.StatusTooManyRequests, err.Error(), blocked.Code())
+	return true
+}
+
+// writePasswordPolicyError 密码不符合策略时返回 400 及逐条违规原因
+func writePasswordPolicyError(c *gin.Context, err error) bool {
+	var policyErr *utils.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	response.ErrorWithDetails(c, http.StatusBadRequest, err.Error(), policyErr.Violations)
+	return true
+}
+
+// clientInfo 提取请求方的客户端信息，用于记录会话
+func clientInfo(c *gin.Context) model.ClientInfo {
+	return model.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+// ListLoginLocks 获取被封禁的账号和IP（管理员）
+func (h *AuthHandler) ListLoginLocks(c *gin.Context) {
+	locks, err := h.authService.ListLoginLocks(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    locks,
+	})
+}
+
+// ClearLoginLock 解除封禁（管理员），key 形如 user:12、account:alice、ip:1.2.3.4
+func (h *AuthHandler) ClearLoginLock(c *gin.Context) {
+	key := c.Param("key")
+	if key == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid lock key")
+		return
+	}
+
+	if err := h.authService.ClearLoginLock(c.Request.Context(), key); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Lock cleared successfully",
+	})
+}
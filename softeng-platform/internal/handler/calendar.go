@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// calendarContentType iCalendar 的 MIME 类型
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type CalendarHandler struct {
+	calendarService service.CalendarService
+}
+
+func NewCalendarHandler(calendarService service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// CourseCalendar 公共课程日历（iCalendar），支持 course_id 和学期筛选参数，未指定学期时只包含尚未结束的学期
+func (h *CalendarHandler) CourseCalendar(c *gin.Context) {
+	filter, err := parseSemesterFilter(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	courseIDs, err := queryIDs(c, "course_id", "course")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := h.calendarService.CourseCalendar(c.Request.Context(), filter, courseIDs)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="courses.ics"`)
+	c.Data(http.StatusOK, calendarContentType, data)
+}
+
+// UserCalendar 个人课程日历（iCalendar），通过订阅地址中的令牌识别用户，无需登录
+func (h *CalendarHandler) UserCalendar(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	data, err := h.calendarService.UserCalendar(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, service.ErrCalendarFeedNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="my-courses.ics"`)
+	c.Data(http.StatusOK, calendarContentType, data)
+}
+
+// CreateFeed 生成个人日历订阅地址，之前的地址随即失效
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	feed, err := h.calendarService.CreateFeed(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Calendar feed created successfully, the URL will only be shown once",
+		"data":    feed,
+	})
+}
+
+// RevokeFeed 停用个人日历订阅地址
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	if err := h.calendarService.RevokeFeed(c.Request.Context(), userID); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Calendar feed revoked successfully",
+	})
+}
+
+// GetCourseEvents 获取课程的截止时间、考试和活动
+func (h *CalendarHandler) GetCourseEvents(c *gin.Context) {
+	events, err := h.calendarService.ListCourseEvents(c.Request.Context(), c.Param("courseId"))
+	if err != nil {
+		calendarErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    events,
+	})
+}
+
+// AddCourseEvent 添加课程事件（课程贡献者或课程审核员）
+func (h *CalendarHandler) AddCourseEvent(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	var req model.CreateCourseEventRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	event, err := h.calendarService.AddCourseEvent(c.Request.Context(), userID, c.GetString("role"), c.Param("courseId"), req)
+	if err != nil {
+		calendarErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Course event added successfully",
+		"data":    event,
+	})
+}
+
+// DeleteCourseEvent 删除课程事件（添加者、课程贡献者或课程审核员）
+func (h *CalendarHandler) DeleteCourseEvent(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	eventID, err := strconv.Atoi(c.Param("eventId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	if err := h.calendarService.DeleteCourseEvent(c.Request.Context(), userID, c.GetString("role"), c.Param("courseId"), eventID); err != nil {
+		calendarErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Course event deleted successfully",
+	})
+}
+
+// calendarErrorResponse 将课程事件的错误映射为 HTTP 状态码
+func calendarErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCourseNotFound), errors.Is(err, service.ErrCourseEventNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrPermissionDenied):
+		response.Error(c, http.StatusForbidden, err.Error())
+	default:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	}
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/service"
 	"softeng-platform/internal/utils"
 	"softeng-platform/pkg/response"
@@ -11,17 +14,27 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// uploadFormOverhead 上传请求中除文件内容外的表单字段和 multipart 头部的大小上限
+const uploadFormOverhead = 1 << 20
+
 type CourseHandler struct {
 	courseService service.CourseService
+	maxUploadSize int64
 }
 
-func NewCourseHandler(courseService service.CourseService) *CourseHandler {
-	return &CourseHandler{courseService: courseService}
+// NewCourseHandler maxUploadSize 为单个上传文件的大小上限（字节）
+func NewCourseHandler(courseService service.CourseService, maxUploadSize int64) *CourseHandler {
+	return &CourseHandler{courseService: courseService, maxUploadSize: maxUploadSize}
 }
 
-// GetCourses 获取课程列表
+// GetCourses 获取课程列表，学期可按 semester_id（可重复或逗号分隔）、semester_from / semester_to（学期ID，含两端）
+// 或 semester（学期名称）筛选
 func (h *CourseHandler) GetCourses(c *gin.Context) {
-	semester := c.Query("semester")
+	semester, err := parseSemesterFilter(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	category := c.QueryArray("category")
 	sort := c.Query("sort")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
@@ -37,6 +50,45 @@ func (h *CourseHandler) GetCourses(c *gin.Context) {
 	response.Success(c, courses)
 }
 
+// parseSemesterFilter 解析课程列表的学期筛选参数
+func parseSemesterFilter(c *gin.Context) (model.SemesterFilter, error) {
+	filter := model.SemesterFilter{Name: c.Query("semester")}
+	var err error
+	if filter.IDs, err = queryIDs(c, "semester_id", "semester"); err != nil {
+		return filter, err
+	}
+
+	if value := c.Query("semester_from"); value != "" {
+		if filter.FromID, err = strconv.Atoi(value); err != nil || filter.FromID <= 0 {
+			return filter, fmt.Errorf("invalid semester ID: %s", value)
+		}
+	}
+	if value := c.Query("semester_to"); value != "" {
+		if filter.ToID, err = strconv.Atoi(value); err != nil || filter.ToID <= 0 {
+			return filter, fmt.Errorf("invalid semester ID: %s", value)
+		}
+	}
+	return filter, nil
+}
+
+// queryIDs 解析可重复或逗号分隔的 ID 查询参数
+func queryIDs(c *gin.Context, key, name string) ([]int, error) {
+	var ids []int
+	for _, value := range c.QueryArray(key) {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := strconv.Atoi(part)
+			if err != nil || id <= 0 {
+				return nil, fmt.Errorf("invalid %s ID: %s", name, part)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 // SearchCourses 搜索课程
 func (h *CourseHandler) SearchCourses(c *gin.Context) {
 	keyword := c.Query("keyword")
@@ -81,6 +133,47 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 	response.Success(c, course)
 }
 
+// SubmitCourse 提交课程，提交后进入待审核状态
+func (h *CourseHandler) SubmitCourse(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	var req service.CourseSubmitRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	result, err := h.courseService.SubmitCourse(c.Request.Context(), userID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// AnalyzeCourse 分析课程链接，返回课程提交的预填信息
+func (h *CourseHandler) AnalyzeCourse(c *gin.Context) {
+	var req model.AnalyzeCourseRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	analysis, err := h.courseService.AnalyzeCourse(c.Request.Context(), req.URL)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    analysis,
+	})
+}
+
 // UploadResource 上传课程资源
 func (h *CourseHandler) UploadResource(c *gin.Context) {
 	userID := c.GetInt("userID")
@@ -93,34 +186,315 @@ func (h *CourseHandler) UploadResource(c *gin.Context) {
 		return
 	}
 
+	// 解析表单前限制请求体大小，超大的请求不会写入临时文件
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+uploadFormOverhead)
+
 	var req service.CourseUploadRequest
 	// 支持 multipart/form-data 和 application/json
 	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("file size exceeds %d MB", h.maxUploadSize/1024/1024))
+			return
+		}
 		response.Error(c, http.StatusBadRequest, "Invalid request data")
 		return
 	}
+	// 文件只能通过 multipart 上传，JSON 请求只提交链接
+	if file, err := c.FormFile("file"); err == nil {
+		req.Upload = file
+	}
 
 	result, err := h.courseService.UploadResource(c.Request.Context(), userID, courseID, resourceType, req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetResources 获取课程已审核通过的资源
+func (h *CourseHandler) GetResources(c *gin.Context) {
+	courseID := c.Param("courseId")
+
+	result, err := h.courseService.GetResources(c.Request.Context(), courseID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetChapters 获取课程章节大纲
+func (h *CourseHandler) GetChapters(c *gin.Context) {
+	courseID := c.Param("courseId")
+
+	result, err := h.courseService.GetChapters(c.Request.Context(), courseID)
+	if err != nil {
+		courseErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// SubmitChapter 提交课程章节，审核通过后出现在大纲中
+func (h *CourseHandler) SubmitChapter(c *gin.Context) {
+	userID := c.GetInt("userID")
+	courseID := c.Param("courseId")
+
+	var req model.SubmitChapterRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	chapter, err := h.courseService.SubmitChapter(c.Request.Context(), userID, courseID, req)
+	if err != nil {
+		courseErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Chapter submitted successfully, pending review",
+		"data":    chapter,
+	})
+}
+
+// GetProgress 获取学习进度
+func (h *CourseHandler) GetProgress(c *gin.Context) {
+	userID := c.GetInt("userID")
+	courseID := c.Param("courseId")
+
+	progress, err := h.courseService.GetProgress(c.Request.Context(), userID, courseID)
+	if err != nil {
+		courseErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    progress,
+	})
+}
+
+// UpdateProgress 更新学习进度，completed 为 false 时取消完成
+func (h *CourseHandler) UpdateProgress(c *gin.Context) {
+	userID := c.GetInt("userID")
+	courseID := c.Param("courseId")
+	chapterID := c.Param("chapterId")
+
+	var req model.UpdateProgressRequest
+	// 支持 multipart/form-data 和 application/json，请求体为空时视为完成
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBind(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request data")
+			return
+		}
+	}
+	completed := req.Completed == nil || *req.Completed
+
+	progress, err := h.courseService.UpdateProgress(c.Request.Context(), userID, courseID, chapterID, completed)
+	if err != nil {
+		courseErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    progress,
+	})
+}
+
+// RateCourse 添加课程评分，同一用户对同一次开课重复评分时覆盖
+func (h *CourseHandler) RateCourse(c *gin.Context) {
+	userID := c.GetInt("userID")
+	courseID := c.Param("courseId")
+
+	var req model.RateCourseRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	rating, err := h.courseService.RateCourse(c.Request.Context(), userID, courseID, req)
+	if err != nil {
+		courseErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Rating saved successfully",
+		"data":    rating,
+	})
+}
+
+// GetRatings 获取课程评分汇总和评价
+func (h *CourseHandler) GetRatings(c *gin.Context) {
+	courseID := c.Param("courseId")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	cursor, _ := strconv.Atoi(c.Query("cursor"))
+
+	result, err := h.courseService.GetRatings(c.Request.Context(), courseID, cursor, limit)
+	if err != nil {
+		courseErrorResponse(c, err)
 		return
 	}
 
 	response.Success(c, result)
 }
 
-// DownloadTextbook 下载课本
+// GetPrerequisites 获取课程的先修、同修要求和全部间接先修课程
+func (h *CourseHandler) GetPrerequisites(c *gin.Context) {
+	courseID := c.Param("courseId")
+
+	prerequisites, err := h.courseService.GetPrerequisites(c.Request.Context(), courseID)
+	if err != nil {
+		courseErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    prerequisites,
+	})
+}
+
+// SubmitPrerequisite 提交修读关系，审核通过后生效
+func (h *CourseHandler) SubmitPrerequisite(c *gin.Context) {
+	userID := c.GetInt("userID")
+	courseID := c.Param("courseId")
+
+	var req model.SubmitPrerequisiteRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	prerequisite, err := h.courseService.SubmitPrerequisite(c.Request.Context(), userID, courseID, req)
+	if err != nil {
+		courseErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Prerequisite submitted successfully, pending review",
+		"data":    prerequisite,
+	})
+}
+
+// GetCurriculumGraph 导出全部课程的修读关系图，format=dot 时返回 Graphviz DOT 文本
+func (h *CourseHandler) GetCurriculumGraph(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "dot" {
+		response.Error(c, http.StatusBadRequest, "format must be json or dot")
+		return
+	}
+
+	graph, err := h.courseService.GetCurriculumGraph(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if format == "dot" {
+		c.Header("Content-Disposition", `inline; filename="curriculum.dot"`)
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(graph.DOT()))
+		return
+	}
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    graph,
+	})
+}
+
+// CheckPrerequisites 检查按学期排列的修读安排，标出先修课程安排在之后的课程
+func (h *CourseHandler) CheckPrerequisites(c *gin.Context) {
+	var req model.PrerequisiteCheckRequest
+	// 学期列表为嵌套结构，只支持 application/json
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	result, err := h.courseService.CheckPrerequisites(c.Request.Context(), req)
+	if err != nil {
+		courseErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    result,
+	})
+}
+
+// courseErrorResponse 课程或章节不存在返回 404，修读关系重复或成环返回 409，其他错误返回 400
+func courseErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrCourseNotFound) || errors.Is(err, service.ErrChapterNotFound) {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrPrerequisiteExists) || errors.Is(err, service.ErrPrerequisiteCycle) {
+		response.Error(c, http.StatusConflict, err.Error())
+		return
+	}
+	response.Error(c, http.StatusBadRequest, err.Error())
+}
+
+// DownloadTextbook 下载课本（课程上传资源），支持 Range 断点续传和 If-None-Match 缓存校验
 func (h *CourseHandler) DownloadTextbook(c *gin.Context) {
 	courseID := c.Param("courseId")
 	textbookID := c.Param("textbookId")
 
-	result, err := h.courseService.DownloadTextbook(c.Request.Context(), courseID, textbookID)
+	download, err := h.courseService.DownloadTextbook(c.Request.Context(), courseID, textbookID)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		switch {
+		case errors.Is(err, service.ErrResourceNotFound):
+			response.Error(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrResourceGone):
+			response.Error(c, http.StatusGone, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
-	response.Success(c, result)
+	// 历史导入的资源只有外部链接，直接跳转
+	if download.RedirectURL != "" {
+		h.courseService.RecordDownload(c.Request.Context(), download.Resource.ResourceID)
+		c.Redirect(http.StatusFound, download.RedirectURL)
+		return
+	}
+	defer download.Content.Close()
+
+	c.Header("Content-Type", download.Resource.ContentType)
+	c.Header("Content-Disposition", utils.AttachmentDisposition(download.Resource.FileName))
+	c.Header("ETag", download.ETag)
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	// 断点续传的后续分段和 304 不重复计数
+	if isNewDownload(c.Request, download.ETag) {
+		h.courseService.RecordDownload(c.Request.Context(), download.Resource.ResourceID)
+	}
+
+	// ServeContent 负责 Range、If-None-Match、If-Modified-Since 的处理
+	http.ServeContent(c.Writer, c.Request, download.Resource.FileName, download.ModTime, download.Content)
+}
+
+// isNewDownload 判断请求是否为一次新的下载：完整请求或从头开始的分段请求，且缓存未命中
+func isNewDownload(r *http.Request, etag string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" && (inm == "*" || strings.Contains(inm, etag)) {
+		return false
+	}
+	rng := r.Header.Get("Range")
+	return rng == "" || strings.HasPrefix(strings.TrimSpace(rng), "bytes=0-")
 }
 
 // AddComment 发表评论
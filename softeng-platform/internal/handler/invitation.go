@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	invitationService service.InvitationService
+}
+
+func NewInvitationHandler(invitationService service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService}
+}
+
+// CreateInvitation 生成邀请码
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	adminID := c.GetInt("userID")
+
+	var req model.CreateInvitationRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(c.Request.Context(), adminID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Invitation created successfully",
+		"data":    invitation,
+	})
+}
+
+// ListInvitations 获取邀请码列表
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	cursor, _ := strconv.Atoi(c.Query("cursor"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := h.invitationService.ListInvitations(c.Request.Context(), cursor, limit)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetInvitation 获取邀请码详情及兑换记录
+func (h *InvitationHandler) GetInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("invitationId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	invitation, err := h.invitationService.GetInvitation(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    invitation,
+	})
+}
+
+// RevokeInvitation 撤销邀请码
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("invitationId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	if err := h.invitationService.RevokeInvitation(c.Request.Context(), id); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Invitation revoked successfully",
+	})
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LearningPlanHandler struct {
+	learningPlanService service.LearningPlanService
+}
+
+func NewLearningPlanHandler(learningPlanService service.LearningPlanService) *LearningPlanHandler {
+	return &LearningPlanHandler{learningPlanService: learningPlanService}
+}
+
+// GetPlan 获取学习计划及各学期学分合计
+func (h *LearningPlanHandler) GetPlan(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	plan, err := h.learningPlanService.GetPlan(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    plan,
+	})
+}
+
+// AddCourse 加入学习计划，课程已在计划中时更新学期和状态
+func (h *LearningPlanHandler) AddCourse(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	courseID, err := strconv.Atoi(c.Param("courseId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid course ID")
+		return
+	}
+
+	var req model.LearningPlanRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	plan, err := h.learningPlanService.AddCourse(c.Request.Context(), userID, courseID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrPlanCourseNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Course added to learning plan",
+		"data":    plan,
+	})
+}
+
+// RemoveCourse 从学习计划移除
+func (h *LearningPlanHandler) RemoveCourse(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	courseID, err := strconv.Atoi(c.Param("courseId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid course ID")
+		return
+	}
+
+	plan, err := h.learningPlanService.RemoveCourse(c.Request.Context(), userID, courseID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlanItemNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Course removed from learning plan",
+		"data":    plan,
+	})
+}
+
+// Reorder 调整学期内课程的顺序
+func (h *LearningPlanHandler) Reorder(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	var req model.ReorderLearningPlanRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	plan, err := h.learningPlanService.Reorder(c.Request.Context(), userID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    plan,
+	})
+}
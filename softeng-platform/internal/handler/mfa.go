@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaService service.MFAService
+}
+
+func NewMFAHandler(mfaService service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// GetStatus 获取两步验证状态
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	status, err := h.mfaService.GetStatus(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    status,
+	})
+}
+
+// Setup 生成 TOTP 密钥和二维码链接
+func (h *MFAHandler) Setup(c *gin.Context) {
+	setup, err := h.mfaService.Setup(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Scan the QR code with your authenticator app, then confirm with a code",
+		"data":    setup,
+	})
+}
+
+// Enable 提交验证码确认启用，返回恢复码（只展示一次）
+func (h *MFAHandler) Enable(c *gin.Context) {
+	var req model.MFACodeRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	codes, err := h.mfaService.Enable(c.Request.Context(), c.GetInt("userID"), req.Code)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Two-factor authentication enabled, please store the recovery codes safely",
+		"data": gin.H{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// Disable 关闭两步验证，需要密码和验证码（或恢复码）
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req model.MFADisableRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), c.GetInt("userID"), req); err != nil {
+		if writeLoginBlockedError(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrMFARequired) {
+			response.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部失效
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req model.MFACodeRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), c.GetInt("userID"), req.Code)
+	if err != nil {
+		if writeLoginBlockedError(c, err) {
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Recovery codes regenerated",
+		"data": gin.H{
+			"recovery_codes": codes,
+		},
+	})
+}
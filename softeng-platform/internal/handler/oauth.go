@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthStateCookie 保存发起授权时的 state，回调时与参数中的 state 比对，防止登录 CSRF
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/auth/oauth"
+)
+
+type OAuthHandler struct {
+	oauthService service.OAuthService
+}
+
+func NewOAuthHandler(oauthService service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// ListProviders 获取可用的第三方登录方式
+func (h *OAuthHandler) ListProviders(c *gin.Context) {
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    h.oauthService.Providers(),
+	})
+}
+
+// Start 跳转到第三方授权页，首次登录（自动创建账号）需要通过 invitation 参数提供邀请码
+func (h *OAuthHandler) Start(c *gin.Context) {
+	start, err := h.oauthService.StartLogin(c.Request.Context(), c.Param("provider"), c.Query("invitation"))
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	setOAuthStateCookie(c, start)
+	c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// Callback 第三方授权回调，登录时返回令牌，绑定时返回绑定结果
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	if errMsg := c.Query("error"); errMsg != "" {
+		response.Error(c, http.StatusBadRequest, "Authorization failed: "+errMsg)
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid callback parameters")
+		return
+	}
+
+	// state 只能使用一次，无论成功与否都清除 Cookie
+	browserState, _ := c.Cookie(oauthStateCookie)
+	clearOAuthStateCookie(c)
+
+	result, err := h.oauthService.HandleCallback(c.Request.Context(), c.Param("provider"), code, state, browserState, clientInfo(c))
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	if result.Identity != nil {
+		response.Success(c, gin.H{
+			"message": "Account linked successfully",
+			"data":    result.Identity,
+		})
+		return
+	}
+	writeLoginResult(c, result.Login)
+}
+
+// ListIdentities 获取当前用户绑定的第三方账号
+func (h *OAuthHandler) ListIdentities(c *gin.Context) {
+	identities, err := h.oauthService.ListIdentities(c.Request.Context(), c.GetInt("userID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":   "success",
+		"data":      identities,
+		"providers": h.oauthService.Providers(),
+	})
+}
+
+// Link 绑定第三方账号，返回授权地址，由前端在同一浏览器中跳转
+func (h *OAuthHandler) Link(c *gin.Context) {
+	start, err := h.oauthService.StartLink(c.Request.Context(), c.GetInt("userID"), c.Param("provider"))
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	setOAuthStateCookie(c, start)
+	response.Success(c, gin.H{
+		"message": "success",
+		"data": gin.H{
+			"authorization_url": start.AuthURL,
+		},
+	})
+}
+
+// Unlink 解绑第三方账号
+func (h *OAuthHandler) Unlink(c *gin.Context) {
+	if err := h.oauthService.Unlink(c.Request.Context(), c.GetInt("userID"), c.Param("provider")); err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Account unlinked successfully",
+	})
+}
+
+// setOAuthStateCookie 提供方回调是跨站的顶层跳转，SameSite=Lax 时 Cookie 仍会随回调发送
+func setOAuthStateCookie(c *gin.Context, start *model.OAuthStart) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, start.State, start.ExpiresIn, oauthStateCookiePath, "", isHTTPS(c), true)
+}
+
+func clearOAuthStateCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, "", -1, oauthStateCookiePath, "", isHTTPS(c), true)
+}
+
+// isHTTPS 直接的 TLS 连接或反向代理声明的 https 请求
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
+
+func writeOAuthError(c *gin.Context, err error) {
+	if writeLoginBlockedError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrOAuthProviderNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrOAuthEmailExists), errors.Is(err, service.ErrOAuthIdentityTaken):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrOAuthInvalidState):
+		response.Error(c, http.StatusUnauthorized, err.Error())
+	default:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PersonalTokenHandler struct {
+	personalTokenService service.PersonalTokenService
+}
+
+func NewPersonalTokenHandler(personalTokenService service.PersonalTokenService) *PersonalTokenHandler {
+	return &PersonalTokenHandler{personalTokenService: personalTokenService}
+}
+
+// ListTokens 获取个人访问令牌列表
+func (h *PersonalTokenHandler) ListTokens(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	tokens, err := h.personalTokenService.ListTokens(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    tokens,
+	})
+}
+
+// CreateToken 创建个人访问令牌
+func (h *PersonalTokenHandler) CreateToken(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	var req model.CreatePersonalTokenRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	token, plain, err := h.personalTokenService.CreateToken(c.Request.Context(), userID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Token created successfully, it will only be shown once",
+		"token":   plain,
+		"data":    token,
+	})
+}
+
+// RevokeToken 吊销个人访问令牌
+func (h *PersonalTokenHandler) RevokeToken(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	tokenID, err := strconv.Atoi(c.Param("tokenId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	if err := h.personalTokenService.RevokeToken(c.Request.Context(), userID, tokenID); err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Token revoked successfully",
+	})
+}
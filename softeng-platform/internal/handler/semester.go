@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SemesterHandler struct {
+	semesterService service.SemesterService
+}
+
+func NewSemesterHandler(semesterService service.SemesterService) *SemesterHandler {
+	return &SemesterHandler{semesterService: semesterService}
+}
+
+// ListSemesters 获取学期目录，按时间先后排列
+func (h *SemesterHandler) ListSemesters(c *gin.Context) {
+	semesters, err := h.semesterService.ListSemesters(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    semesters,
+	})
+}
+
+// CreateSemester 新增学期（管理员）
+func (h *SemesterHandler) CreateSemester(c *gin.Context) {
+	var req model.CreateSemesterRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	semester, err := h.semesterService.CreateSemester(c.Request.Context(), req)
+	if err != nil {
+		semesterErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Semester created successfully",
+		"data":    semester,
+	})
+}
+
+// UpdateSemester 修改学期名称和起止日期（管理员）
+func (h *SemesterHandler) UpdateSemester(c *gin.Context) {
+	semesterID, err := strconv.Atoi(c.Param("semesterId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid semester ID")
+		return
+	}
+
+	var req model.UpdateSemesterRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	semester, err := h.semesterService.UpdateSemester(c.Request.Context(), semesterID, req)
+	if err != nil {
+		semesterErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Semester updated successfully",
+		"data":    semester,
+	})
+}
+
+// AddOffering 为课程添加一次开课（管理员）
+func (h *SemesterHandler) AddOffering(c *gin.Context) {
+	courseID := c.Param("courseId")
+
+	var req model.AddOfferingRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	offerings, err := h.semesterService.AddOffering(c.Request.Context(), courseID, req)
+	if err != nil {
+		semesterErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Offering added successfully",
+		"data":    offerings,
+	})
+}
+
+// semesterErrorResponse 学期或课程不存在返回 404，学期重复返回 409，其余返回 400
+func semesterErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSemesterNotFound), errors.Is(err, service.ErrCourseNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrSemesterExists):
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	}
+}
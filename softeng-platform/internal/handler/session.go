@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	tokenService service.TokenService
+}
+
+func NewSessionHandler(tokenService service.TokenService) *SessionHandler {
+	return &SessionHandler{tokenService: tokenService}
+}
+
+// ListSessions 获取当前用户已登录的设备
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	sessions, err := h.tokenService.ListSessions(c.Request.Context(), userID, c.GetString("sessionID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    sessions,
+	})
+}
+
+// RevokeSession 注销指定设备上的会话
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	sessionID, err := strconv.Atoi(c.Param("sessionId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	if err := h.tokenService.RevokeSessionByID(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
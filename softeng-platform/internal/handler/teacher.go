@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeacherHandler struct {
+	teacherService service.TeacherService
+}
+
+func NewTeacherHandler(teacherService service.TeacherService) *TeacherHandler {
+	return &TeacherHandler{teacherService: teacherService}
+}
+
+// ListTeachers 获取教师列表，keyword 匹配姓名和别名
+func (h *TeacherHandler) ListTeachers(c *gin.Context) {
+	cursor, _ := strconv.Atoi(c.Query("cursor"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := h.teacherService.ListTeachers(c.Request.Context(), c.Query("keyword"), cursor, limit)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetTeacher 获取教师详情及按学期分组的课程
+func (h *TeacherHandler) GetTeacher(c *gin.Context) {
+	teacherID, err := strconv.Atoi(c.Param("teacherId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid teacher ID")
+		return
+	}
+
+	teacher, err := h.teacherService.GetTeacher(c.Request.Context(), teacherID)
+	if err != nil {
+		if errors.Is(err, service.ErrTeacherNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    teacher,
+	})
+}
+
+// UpdateTeacher 修改教师资料和别名（管理员）
+func (h *TeacherHandler) UpdateTeacher(c *gin.Context) {
+	teacherID, err := strconv.Atoi(c.Param("teacherId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid teacher ID")
+		return
+	}
+
+	var req model.UpdateTeacherRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	teacher, err := h.teacherService.UpdateTeacher(c.Request.Context(), teacherID, req)
+	if err != nil {
+		teacherErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Teacher updated successfully",
+		"data":    teacher,
+	})
+}
+
+// MergeTeacher 将同一教师的另一条记录合并到当前教师（管理员）
+func (h *TeacherHandler) MergeTeacher(c *gin.Context) {
+	teacherID, err := strconv.Atoi(c.Param("teacherId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid teacher ID")
+		return
+	}
+
+	var req model.MergeTeacherRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	teacher, err := h.teacherService.MergeTeacher(c.Request.Context(), teacherID, req.SourceID)
+	if err != nil {
+		teacherErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Teachers merged successfully",
+		"data":    teacher,
+	})
+}
+
+// teacherErrorResponse 教师不存在返回 404，姓名冲突返回 409，其余返回 400
+func teacherErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTeacherNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTeacherNameTaken):
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		response.Error(c, http.StatusBadRequest, err.Error())
+	}
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/service"
 	"softeng-platform/internal/utils"
 	"softeng-platform/pkg/response"
@@ -277,3 +279,50 @@ func (h *ToolHandler) AddView(c *gin.Context) {
 
 	response.Success(c, result)
 }
+
+// UpdateTool 提议修改工具信息（名称、链接、简介、详细介绍、类别、标签、图片），审核通过后生效
+func (h *ToolHandler) UpdateTool(c *gin.Context) {
+	userID := c.GetInt("userID")
+	resourceID := c.Param("resourceId")
+
+	var req model.ToolEditRequest
+	// 支持 multipart/form-data 和 application/json
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	revision, err := h.toolService.UpdateTool(c.Request.Context(), userID, resourceID, req)
+	if err != nil {
+		toolErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Tool revision submitted successfully, pending review",
+		"data":    revision,
+	})
+}
+
+// GetToolRevisions 获取工具的修改记录及每次修改的字段差异
+func (h *ToolHandler) GetToolRevisions(c *gin.Context) {
+	revisions, err := h.toolService.GetToolRevisions(c.Request.Context(), c.Param("resourceId"))
+	if err != nil {
+		toolErrorResponse(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"data":    revisions,
+	})
+}
+
+// toolErrorResponse 将工具修改提议的错误映射为 HTTP 状态码
+func toolErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrToolNotFound) {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Error(c, http.StatusBadRequest, err.Error())
+}
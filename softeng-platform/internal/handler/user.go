@@ -32,15 +32,6 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	response.Success(c, profile)
 }
 
-// Logout 用户登出
-func (h *UserHandler) Logout(c *gin.Context) {
-	// 在实际应用中，你可能需要将token加入黑名单
-	// 这里简单返回成功消息
-	response.Success(c, gin.H{
-		"message": "Logout successful",
-	})
-}
-
 // UpdateProfile 更新个人资料
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	userID := c.GetInt("userID")
@@ -192,6 +183,9 @@ func (h *UserHandler) UpdateEmail(c *gin.Context) {
 
 	user, err := h.userService.UpdateEmail(c.Request.Context(), userID, req.Name, req.Password, req.NewEmail, req.Code)
 	if err != nil {
+		if writeLoginBlockedError(c, err) {
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -219,8 +213,11 @@ func (h *UserHandler) UpdatePassword(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.UpdatePassword(c.Request.Context(), userID, req.Name, req.Email, req.NewPassword, req.Code)
+	user, err := h.userService.UpdatePassword(c.Request.Context(), userID, c.GetString("sessionID"), req.Name, req.Email, req.NewPassword, req.Code)
 	if err != nil {
+		if writePasswordPolicyError(c, err) {
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,282 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"softeng-platform/internal/model"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的文件格式
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Record 文件中的一条课程记录，Index 从 1 开始，Line 为记录开始的行号（JSON 无行号，为 0）；
+// 记录本身无法解析时 Err 不为空
+type Record struct {
+	Index int
+	Line  int
+	Row   model.CourseImportRow
+	Err   error
+}
+
+// Position 记录在文件中的位置，用于报告
+func (r Record) Position() string {
+	if r.Line > 0 {
+		return fmt.Sprintf("#%d (line %d)", r.Index, r.Line)
+	}
+	return fmt.Sprintf("#%d", r.Index)
+}
+
+// FormatFromPath 按扩展名识别文件格式
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("cannot detect format of %s, use -format csv|json|yaml", path)
+}
+
+// Decode 解析课程目录文件。文件整体格式错误时返回错误；单条记录的错误记录在 Record.Err 中
+func Decode(r io.Reader, format string) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return decodeCSV(r)
+	case FormatJSON:
+		return decodeJSON(r)
+	case FormatYAML:
+		return decodeYAML(r)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// courseFields 课程支持的字段（CSV 列名、YAML 键），CSV 中 name 列必须存在
+var courseFields = map[string]bool{
+	"name": true, "semester": true, "credit": true, "cover": true, "teachers": true,
+	"categories": true, "web_resources": true, "upload_resources": true,
+}
+
+// decodeCSV 第一行为表头；teachers、categories 用分号分隔，
+// web_resources 为分号分隔的"说明|URL"，upload_resources 为"说明|URL|文件名"（文件名可省略）
+func decodeCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv file is empty")
+		}
+		return nil, fmt.Errorf("failed to read csv header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !courseFields[name] {
+			return nil, fmt.Errorf("unknown csv column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicate csv column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("csv header must contain a name column")
+	}
+
+	var records []Record
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		record := Record{Index: len(records) + 1}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("failed to read csv: %v", err)
+			}
+			record.Line = parseErr.StartLine
+			record.Err = parseErr.Err
+			records = append(records, record)
+			continue
+		}
+		record.Line, _ = reader.FieldPos(0)
+		if len(fields) == 1 && strings.TrimSpace(fields[0]) == "" {
+			continue // 空行
+		}
+		if len(fields) > len(header) {
+			record.Err = fmt.Errorf("row has %d fields, header has %d", len(fields), len(header))
+			records = append(records, record)
+			continue
+		}
+		record.Row, record.Err = csvRow(columns, fields)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func csvRow(columns map[string]int, fields []string) (model.CourseImportRow, error) {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	row := model.CourseImportRow{
+		Name:       cell("name"),
+		Semester:   cell("semester"),
+		Cover:      cell("cover"),
+		Teachers:   splitList(cell("teachers")),
+		Categories: splitList(cell("categories")),
+	}
+	if value := cell("credit"); value != "" {
+		credit, err := strconv.Atoi(value)
+		if err != nil {
+			return row, fmt.Errorf("credit %q is not an integer", value)
+		}
+		row.Credit = &credit
+	}
+	for _, item := range splitList(cell("web_resources")) {
+		parts := strings.Split(item, "|")
+		if len(parts) != 2 {
+			return row, fmt.Errorf("web resource %q must be intro|url", item)
+		}
+		row.WebResources = append(row.WebResources, model.CourseImportResource{Intro: parts[0], URL: parts[1]})
+	}
+	for _, item := range splitList(cell("upload_resources")) {
+		parts := strings.Split(item, "|")
+		if len(parts) != 2 && len(parts) != 3 {
+			return row, fmt.Errorf("upload resource %q must be intro|url or intro|url|file_name", item)
+		}
+		res := model.CourseImportResource{Intro: parts[0], URL: parts[1]}
+		if len(parts) == 3 {
+			res.FileName = parts[2]
+		}
+		row.UploadResources = append(row.UploadResources, res)
+	}
+	return row, nil
+}
+
+// splitList 拆分分号（含全角分号）分隔的列表，忽略空项
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '；' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// decodeJSON 顶层为课程数组，或 {"courses": [...]}；未知字段作为该条记录的错误
+func decodeJSON(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read json: %v", err)
+	}
+	var items []json.RawMessage
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapper struct {
+			Courses []json.RawMessage `json:"courses"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapper); err != nil {
+			return nil, fmt.Errorf("invalid json: %v", err)
+		}
+		items = wrapper.Courses
+	} else if err := json.Unmarshal(trimmed, &items); err != nil {
+		return nil, fmt.Errorf("invalid json: %v", err)
+	}
+
+	records := make([]Record, 0, len(items))
+	for i, item := range items {
+		record := Record{Index: i + 1}
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		decoder.DisallowUnknownFields()
+		record.Err = decoder.Decode(&record.Row)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodeYAML 顶层为课程列表，或带 courses 键的映射；未知字段作为该条记录的错误
+func decodeYAML(r io.Reader) ([]Record, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid yaml: %v", err)
+	}
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind == yaml.MappingNode {
+		var list *yaml.Node
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "courses" {
+				list = root.Content[i+1]
+			}
+		}
+		if list == nil {
+			return nil, errors.New("yaml must be a list of courses or contain a courses key")
+		}
+		root = list
+	}
+	if root.Kind != yaml.SequenceNode {
+		return nil, errors.New("yaml courses must be a list")
+	}
+
+	records := make([]Record, 0, len(root.Content))
+	for i, item := range root.Content {
+		record := Record{Index: i + 1, Line: item.Line}
+		record.Err = item.Decode(&record.Row)
+		if err := checkYAMLFields(item, courseFields); err != nil {
+			record.Err = err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// yamlResourceFields 资源支持的字段
+var yamlResourceFields = map[string]bool{"intro": true, "url": true, "file_name": true}
+
+// checkYAMLFields 检查课程和资源中拼错的字段名，错误中的行号为文件中的行号
+func checkYAMLFields(node *yaml.Node, fields map[string]bool) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !fields[key.Value] {
+			return fmt.Errorf("line %d: unknown field %q", key.Line, key.Value)
+		}
+		if (key.Value == "web_resources" || key.Value == "upload_resources") && value.Kind == yaml.SequenceNode {
+			for _, res := range value.Content {
+				if err := checkYAMLFields(res, yamlResourceFields); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
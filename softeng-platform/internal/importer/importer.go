@@ -0,0 +1,507 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 每条记录的处理结果
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionInvalid   = "invalid"
+	ActionFailed    = "failed"
+)
+
+// Options 导入选项
+type Options struct {
+	DryRun      bool   // 只比较不写入
+	SkipInvalid bool   // 存在无效记录时仍导入其余记录，默认任一记录无效则不写入
+	Status      string // 新建课程和资源的审核状态
+	SubmitterID int    // 新建课程、资源的提交者，写入时必须设置
+	StorageDir  string // 上传资源的保存目录，与服务端的 RESOURCE_STORAGE_DIR 相同
+	MaxFileSize int64  // 单个上传资源的大小上限（字节）
+}
+
+// Result 一条记录的校验、比较和写入结果，Changes 为与数据库的差异
+type Result struct {
+	Index    int
+	Line     int
+	Name     string
+	Semester string
+	Action   string
+	CourseID int
+	Changes  []string
+	Errors   []string
+}
+
+// Position 记录在文件中的位置，用于报告
+func (r Result) Position() string {
+	return Record{Index: r.Index, Line: r.Line}.Position()
+}
+
+// Report 整个文件的导入结果，Applied 表示已写入数据库
+type Report struct {
+	Results   []Result
+	Created   int
+	Updated   int
+	Unchanged int
+	Invalid   int
+	Failed    int
+	Applied   bool
+}
+
+// Importer 按自然键查找已有课程，写入与提交课程、提交资源和审核使用同一套 CourseRepository 方法
+type Importer struct {
+	repo    repository.CourseImportRepository
+	courses repository.CourseRepository
+	opts    Options
+}
+
+func New(repo repository.CourseImportRepository, courses repository.CourseRepository, opts Options) *Importer {
+	if opts.Status == "" {
+		opts.Status = model.CourseStatusApproved
+	}
+	return &Importer{repo: repo, courses: courses, opts: opts}
+}
+
+// Run 校验全部记录并与数据库比较，再按选项写入；课程名称和规范学期相同的记录视为同一门课程，
+// 已存在的教师、分类和资源（按 URL）不会重复写入，因此同一文件可以重复导入，写入失败的记录重新导入即可补全
+func (im *Importer) Run(ctx context.Context, records []Record) (*Report, error) {
+	report := &Report{Results: make([]Result, 0, len(records))}
+	changes := make([]*model.CourseImportChange, len(records))
+	seen := make(map[string]int) // 自然键 -> 第一次出现的记录序号
+
+	for i, record := range records {
+		result := Result{Index: record.Index, Line: record.Line}
+		row, errs := validateRecord(record)
+		result.Name, result.Semester = row.Name, row.Semester
+		if len(errs) == 0 {
+			key := row.Name + "\x00" + row.Semester
+			if first, ok := seen[key]; ok {
+				errs = append(errs, fmt.Sprintf("duplicate of record #%d", first))
+			} else {
+				seen[key] = record.Index
+			}
+		}
+		if len(errs) > 0 {
+			result.Action = ActionInvalid
+			result.Errors = errs
+			report.Invalid++
+			report.Results = append(report.Results, result)
+			continue
+		}
+
+		change, err := im.plan(ctx, row, &result)
+		if err != nil {
+			return nil, err
+		}
+		changes[i] = change
+		report.Results = append(report.Results, result)
+	}
+
+	if im.opts.DryRun || (report.Invalid > 0 && !im.opts.SkipInvalid) {
+		im.count(report)
+		return report, nil
+	}
+
+	if im.opts.SubmitterID <= 0 {
+		return nil, errors.New("submitter is required to write courses")
+	}
+	report.Applied = true
+	for i := range report.Results {
+		result := &report.Results[i]
+		if result.Action != ActionCreate && result.Action != ActionUpdate {
+			continue
+		}
+		courseID, err := im.apply(ctx, changes[i])
+		if courseID > 0 {
+			result.CourseID = courseID
+		}
+		if err != nil {
+			// 失败不影响其他记录
+			result.Action = ActionFailed
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	im.count(report)
+	return report, nil
+}
+
+// apply 写入一门课程：封面和提交课程时一样本地化，上传资源先下载到本地存储，
+// 新建的课程和资源按 Status 保持待审核或经审核流程通过；返回课程ID（课程未创建时为 0）
+func (im *Importer) apply(ctx context.Context, change *model.CourseImportChange) (int, error) {
+	// 先下载全部上传资源，任一下载失败时不写入这门课程
+	files := make([]string, len(change.UploadResources))
+	sizes := make([]int64, len(change.UploadResources))
+	saved := 0
+	defer func() {
+		// 未写入数据库的文件删除
+		for _, key := range files[saved:] {
+			if key == "" {
+				continue
+			}
+			if err := utils.DeleteResourceFile(im.opts.StorageDir, key); err != nil {
+				log.Printf("Failed to delete orphan resource file %s: %v", key, err)
+			}
+		}
+	}()
+	for i, res := range change.UploadResources {
+		key, size, err := utils.DownloadResourceFile(im.opts.StorageDir, res.URL, res.FileName, im.opts.MaxFileSize)
+		if err != nil {
+			return 0, fmt.Errorf("upload resource %s: %v", res.URL, err)
+		}
+		files[i], sizes[i] = key, size
+	}
+
+	data := map[string]interface{}{
+		"name":       change.Name,
+		"semester":   change.Semester,
+		"teachers":   change.Teachers,
+		"categories": change.Categories,
+	}
+	if change.SemesterYear > 0 {
+		data["semester_year"] = change.SemesterYear
+		data["semester_term"] = change.SemesterTerm
+	}
+	if change.Credit != nil {
+		data["credit"] = *change.Credit
+	}
+	if change.Cover != nil {
+		// 下载失败时保留原地址
+		cover, err := utils.ProcessImageURL(*change.Cover)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cover: %v", err)
+		}
+		data["cover"] = cover
+	}
+
+	courseID := change.CourseID
+	if courseID == 0 {
+		course, err := im.courses.Create(ctx, change.SubmitterID, data)
+		if err != nil {
+			return 0, err
+		}
+		courseID, _ = course["resourceId"].(int)
+		if err := im.review(ctx, "course", courseID); err != nil {
+			return courseID, err
+		}
+	} else if err := im.courses.UpdateCourse(ctx, change.SubmitterID, courseID, data); err != nil {
+		return courseID, err
+	}
+
+	cid := strconv.Itoa(courseID)
+	for _, res := range change.WebResources {
+		resource, err := im.courses.UploadResource(ctx, change.SubmitterID, cid, map[string]interface{}{
+			"description": res.Intro,
+			"resource":    res.URL,
+		})
+		if err != nil {
+			return courseID, err
+		}
+		if err := im.review(ctx, "web", resourceID(resource, "resource1")); err != nil {
+			return courseID, err
+		}
+	}
+	for i, res := range change.UploadResources {
+		resource, err := im.courses.UploadResource(ctx, change.SubmitterID, cid, map[string]interface{}{
+			"description":  res.Intro,
+			"file_path":    files[i],
+			"file_name":    res.FileName,
+			"file_size":    sizes[i],
+			"content_type": utils.ResourceContentType(res.FileName),
+			"source_url":   res.URL,
+		})
+		if err != nil {
+			return courseID, err
+		}
+		saved = i + 1
+		if err := im.review(ctx, "upload", resourceID(resource, "resource2")); err != nil {
+			return courseID, err
+		}
+	}
+	return courseID, nil
+}
+
+// review 按导入状态审核新建的课程或资源，kind 为 course、web 或 upload；待审核时不处理
+func (im *Importer) review(ctx context.Context, kind string, id int) error {
+	if im.opts.Status != model.CourseStatusApproved {
+		return nil
+	}
+	if id <= 0 {
+		return fmt.Errorf("failed to get created %s id", kind)
+	}
+	if kind == "course" {
+		return im.courses.UpdateCourseStatus(ctx, strconv.Itoa(id), model.CourseStatusApproved, "")
+	}
+	return im.courses.UpdateResourceStatus(ctx, kind, strconv.Itoa(id), model.CourseStatusApproved, "")
+}
+
+// resourceID 从 UploadResource 的返回结果中取出新建资源的ID
+func resourceID(resource map[string]interface{}, key string) int {
+	item, _ := resource[key].(map[string]interface{})
+	id, _ := item["resource_id"].(int)
+	return id
+}
+
+func (im *Importer) count(report *Report) {
+	report.Created, report.Updated, report.Unchanged, report.Failed = 0, 0, 0, 0
+	for _, result := range report.Results {
+		switch result.Action {
+		case ActionCreate:
+			report.Created++
+		case ActionUpdate:
+			report.Updated++
+		case ActionUnchanged:
+			report.Unchanged++
+		case ActionFailed:
+			report.Failed++
+		}
+	}
+}
+
+// plan 与数据库中的课程比较，生成需要写入的内容和差异说明
+func (im *Importer) plan(ctx context.Context, row model.CourseImportRow, result *Result) (*model.CourseImportChange, error) {
+	year, term, parsed := model.ParseSemester(row.Semester)
+	change := &model.CourseImportChange{
+		Name:        row.Name,
+		Semester:    row.Semester,
+		Status:      im.opts.Status,
+		SubmitterID: im.opts.SubmitterID,
+	}
+	if parsed {
+		change.SemesterYear, change.SemesterTerm = year, term
+	}
+
+	entry, err := im.repo.FindCourse(ctx, row.Name, row.Semester, year, term)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		entry = &model.CourseCatalogEntry{}
+		result.Action = ActionCreate
+		result.Changes = append(result.Changes, fmt.Sprintf("+ course %s (%s)", row.Name, im.opts.Status))
+		if row.Credit != nil {
+			result.Changes = append(result.Changes, fmt.Sprintf("+ credit %d", *row.Credit))
+		}
+		if row.Cover != "" {
+			result.Changes = append(result.Changes, "+ cover "+row.Cover)
+		}
+	} else {
+		change.CourseID = entry.CourseID
+		result.CourseID = entry.CourseID
+		if row.Credit != nil && *row.Credit != entry.Credit {
+			result.Changes = append(result.Changes, fmt.Sprintf("~ credit %d -> %d", entry.Credit, *row.Credit))
+		}
+		// 封面写入时会本地化，与文件中的地址不再相同，已有封面的课程不修改封面
+		if row.Cover != "" && entry.Cover == "" {
+			result.Changes = append(result.Changes, "+ cover "+row.Cover)
+		}
+	}
+	if row.Credit != nil && (entry.CourseID == 0 || *row.Credit != entry.Credit) {
+		change.Credit = row.Credit
+	}
+	if row.Cover != "" && entry.Cover == "" {
+		cover := row.Cover
+		change.Cover = &cover
+	}
+	if parsed && !entry.Offered {
+		result.Changes = append(result.Changes, "+ offering "+model.SemesterName(year, term))
+	}
+
+	existing := make(map[string]bool)
+	for _, teacher := range entry.Teachers {
+		existing[model.TeacherNameKey(teacher)] = true
+	}
+	for _, teacher := range row.Teachers {
+		canonical, err := im.repo.CanonicalTeacher(ctx, teacher)
+		if err != nil {
+			return nil, err
+		}
+		change.Teachers = append(change.Teachers, canonical)
+		if !existing[model.TeacherNameKey(canonical)] {
+			existing[model.TeacherNameKey(canonical)] = true
+			result.Changes = append(result.Changes, "+ teacher "+canonical)
+		}
+	}
+
+	existing = toSet(entry.Categories)
+	for _, category := range row.Categories {
+		if !existing[category] {
+			change.Categories = append(change.Categories, category)
+			result.Changes = append(result.Changes, "+ category "+category)
+		}
+	}
+	existing = toSet(entry.WebURLs)
+	for _, res := range row.WebResources {
+		if !existing[res.URL] {
+			change.WebResources = append(change.WebResources, res)
+			result.Changes = append(result.Changes, "+ web resource "+res.URL)
+		}
+	}
+	existing = toSet(entry.UploadURLs)
+	for _, res := range row.UploadResources {
+		if !existing[res.URL] {
+			change.UploadResources = append(change.UploadResources, res)
+			result.Changes = append(result.Changes, "+ upload resource "+res.URL)
+		}
+	}
+
+	switch {
+	case result.Action == ActionCreate:
+	case len(result.Changes) > 0:
+		result.Action = ActionUpdate
+	default:
+		result.Action = ActionUnchanged
+	}
+	return change, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// validateRecord 校验并整理一条记录：去除空白、规范学期和教师姓名、去除重复项
+func validateRecord(record Record) (model.CourseImportRow, []string) {
+	row := record.Row
+	if record.Err != nil {
+		return row, []string{record.Err.Error()}
+	}
+
+	var errs []string
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	row.Name = strings.TrimSpace(row.Name)
+	if row.Name == "" {
+		check(errors.New("name is required"))
+	}
+	check(maxLength("name", row.Name, 255))
+	row.Semester = model.NormalizeSemester(row.Semester)
+	check(maxLength("semester", row.Semester, 50))
+	if row.Credit != nil && (*row.Credit < 0 || *row.Credit > 30) {
+		check(errors.New("credit must be between 0 and 30"))
+	}
+	row.Cover = strings.TrimSpace(row.Cover)
+	if row.Cover != "" && !isHTTPURL(row.Cover) && !strings.HasPrefix(row.Cover, "/uploads/") {
+		check(errors.New("cover must be an http(s) URL or an /uploads/ path"))
+	}
+	check(maxLength("cover", row.Cover, 500))
+
+	teachers := make([]string, 0, len(row.Teachers))
+	seen := make(map[string]bool)
+	for _, teacher := range row.Teachers {
+		teacher = model.NormalizeTeacherName(teacher)
+		key := model.TeacherNameKey(teacher)
+		if teacher == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		check(maxLength("teacher", teacher, 100))
+		teachers = append(teachers, teacher)
+	}
+	row.Teachers = teachers
+
+	categories := make([]string, 0, len(row.Categories))
+	seen = make(map[string]bool)
+	for _, category := range row.Categories {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		check(maxLength("category", category, 100))
+		categories = append(categories, category)
+	}
+	row.Categories = categories
+
+	var resErrs []string
+	row.WebResources, resErrs = validateResources("web resource", row.WebResources)
+	errs = append(errs, resErrs...)
+	row.UploadResources, resErrs = validateResources("upload resource", row.UploadResources)
+	errs = append(errs, resErrs...)
+	// 上传资源会下载到本地存储，文件类型按文件名（未填写时为 URL 中的文件名）判断
+	for i := range row.UploadResources {
+		res := &row.UploadResources[i]
+		if res.FileName == "" {
+			res.FileName = urlFileName(res.URL)
+		}
+		if utils.ResourceContentType(res.FileName) == "" {
+			errs = append(errs, fmt.Sprintf("upload resource %s: %v", res.URL, utils.ErrResourceTypeNotAllowed))
+		}
+	}
+	return row, errs
+}
+
+// urlFileName 返回 URL 路径中的文件名，没有时返回空字符串
+func urlFileName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		return ""
+	}
+	return name
+}
+
+func validateResources(kind string, resources []model.CourseImportResource) ([]model.CourseImportResource, []string) {
+	var errs []string
+	result := make([]model.CourseImportResource, 0, len(resources))
+	seen := make(map[string]bool)
+	for i, res := range resources {
+		res.Intro = strings.TrimSpace(res.Intro)
+		res.URL = strings.TrimSpace(res.URL)
+		res.FileName = strings.TrimSpace(res.FileName)
+		label := fmt.Sprintf("%s %d", kind, i+1)
+		if res.Intro == "" {
+			errs = append(errs, label+": intro is required")
+		} else if err := maxLength(label+" intro", res.Intro, 255); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if !isHTTPURL(res.URL) {
+			errs = append(errs, label+": url must be an http(s) URL")
+		} else if err := maxLength(label+" url", res.URL, 500); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if err := maxLength(label+" file_name", res.FileName, 255); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if seen[res.URL] {
+			continue
+		}
+		seen[res.URL] = true
+		result = append(result, res)
+	}
+	return result, errs
+}
+
+func maxLength(field, value string, max int) error {
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("%s must be at most %d characters", field, max)
+	}
+	return nil
+}
+
+func isHTTPURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
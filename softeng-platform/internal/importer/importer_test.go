@@ -0,0 +1,291 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		wantErr string
+		want    []Record // 只比较 Index、Line、名称、学期和记录错误
+		check   func(t *testing.T, records []Record)
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			input: string(rune(0xFEFF)) + "Name,semester,credit,teachers,web_resources,upload_resources\n" +
+				"数据结构,2024秋,4,张三；李四,主页|https://a.edu/ds,讲义|https://a.edu/ch1.pdf|ch1.pdf\n" +
+				"\n" +
+				"操作系统,2025春,x,,,\n",
+			want: []Record{{Index: 1, Line: 2}, {Index: 2, Line: 4, Err: errors.New(`credit "x" is not an integer`)}},
+			check: func(t *testing.T, records []Record) {
+				row := records[0].Row
+				if row.Credit == nil || *row.Credit != 4 || strings.Join(row.Teachers, ",") != "张三,李四" {
+					t.Errorf("row = %+v", row)
+				}
+				if len(row.WebResources) != 1 || row.WebResources[0].URL != "https://a.edu/ds" {
+					t.Errorf("web resources = %+v", row.WebResources)
+				}
+				if len(row.UploadResources) != 1 || row.UploadResources[0].FileName != "ch1.pdf" {
+					t.Errorf("upload resources = %+v", row.UploadResources)
+				}
+			},
+		},
+		{name: "csv unknown column", format: FormatCSV, input: "name,teacher\n", wantErr: `unknown csv column "teacher"`},
+		{name: "csv without name", format: FormatCSV, input: "semester\n2024秋\n", wantErr: "name column"},
+		{name: "csv empty", format: FormatCSV, input: "", wantErr: "empty"},
+		{
+			name:   "csv bad resource",
+			format: FormatCSV,
+			input:  "name,web_resources\n编译原理,https://a.edu\n",
+			want:   []Record{{Index: 1, Line: 2, Err: errors.New(`web resource "https://a.edu" must be intro|url`)}},
+		},
+		{
+			name:   "json array",
+			format: FormatJSON,
+			input:  `[{"name": "数据结构", "semester": "Fall 2024"}, {"name": "操作系统", "teacher": "王五"}]`,
+			want: []Record{
+				{Index: 1},
+				{Index: 2, Err: errors.New(`json: unknown field "teacher"`)},
+			},
+		},
+		{
+			name:   "json courses key",
+			format: FormatJSON,
+			input:  `{"courses": [{"name": "数据结构", "credit": 3}]}`,
+			want:   []Record{{Index: 1}},
+		},
+		{name: "json invalid", format: FormatJSON, input: `[{"name": }]`, wantErr: "invalid json"},
+		{
+			name:   "yaml courses key",
+			format: FormatYAML,
+			input: "courses:\n" +
+				"  - name: 数据结构\n" +
+				"    semester: 2024秋\n" +
+				"  - name: 操作系统\n" +
+				"    web_resources:\n" +
+				"      - intro: 主页\n" +
+				"        link: https://a.edu/os\n",
+			want: []Record{
+				{Index: 1, Line: 2},
+				{Index: 2, Line: 4, Err: errors.New(`line 7: unknown field "link"`)},
+			},
+		},
+		{name: "yaml empty", format: FormatYAML, input: ""},
+		{name: "yaml not a list", format: FormatYAML, input: "name: 数据结构\n", wantErr: "courses key"},
+		{name: "unknown format", format: "xml", input: "", wantErr: "unsupported format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := Decode(strings.NewReader(tt.input), tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Decode error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("got %d records, want %d: %+v", len(records), len(tt.want), records)
+			}
+			for i, want := range tt.want {
+				got := records[i]
+				if got.Index != want.Index || got.Line != want.Line {
+					t.Errorf("record %d position = %s, want %s", i, got.Position(), want.Position())
+				}
+				if (got.Err == nil) != (want.Err == nil) || (got.Err != nil && got.Err.Error() != want.Err.Error()) {
+					t.Errorf("record %d error = %v, want %v", i, got.Err, want.Err)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, records)
+			}
+		})
+	}
+}
+
+func TestValidateRecord(t *testing.T) {
+	credit := 40
+	tests := []struct {
+		name     string
+		row      model.CourseImportRow
+		wantErrs []string
+		check    func(t *testing.T, row model.CourseImportRow)
+	}{
+		{
+			name: "normalized",
+			row: model.CourseImportRow{
+				Name:       " 数据结构 ",
+				Semester:   "Fall 2024",
+				Teachers:   []string{"张 三", "张三", ""},
+				Categories: []string{"必修", " 必修 "},
+				UploadResources: []model.CourseImportResource{
+					{Intro: "讲义", URL: "https://a.edu/files/%E7%AC%AC1%E7%AB%A0.pdf"},
+				},
+			},
+			check: func(t *testing.T, row model.CourseImportRow) {
+				if row.Name != "数据结构" || row.Semester != "2024秋季" {
+					t.Errorf("name, semester = %q, %q", row.Name, row.Semester)
+				}
+				if len(row.Teachers) != 1 || len(row.Categories) != 1 {
+					t.Errorf("teachers = %v, categories = %v", row.Teachers, row.Categories)
+				}
+				if row.UploadResources[0].FileName != "第1章.pdf" {
+					t.Errorf("file name = %q, want name from url", row.UploadResources[0].FileName)
+				}
+			},
+		},
+		{
+			name:     "missing name and bad credit",
+			row:      model.CourseImportRow{Credit: &credit},
+			wantErrs: []string{"name is required", "credit must be between 0 and 30"},
+		},
+		{
+			name: "bad resources",
+			row: model.CourseImportRow{
+				Name:            "操作系统",
+				Cover:           "cover.png",
+				WebResources:    []model.CourseImportResource{{URL: "ftp://a.edu"}},
+				UploadResources: []model.CourseImportResource{{Intro: "程序", URL: "https://a.edu/setup.exe"}},
+			},
+			wantErrs: []string{
+				"cover must be an http(s) URL or an /uploads/ path",
+				"web resource 1: intro is required",
+				"web resource 1: url must be an http(s) URL",
+				"upload resource https://a.edu/setup.exe: unsupported file type",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, errs := validateRecord(Record{Index: 1, Row: tt.row})
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("errors = %q, want %q", errs, tt.wantErrs)
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.HasPrefix(errs[i], want) {
+					t.Errorf("error %d = %q, want prefix %q", i, errs[i], want)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, row)
+			}
+		})
+	}
+}
+
+// fakeLookup 没有已存在的课程和教师
+type fakeLookup struct{}
+
+func (fakeLookup) FindCourse(ctx context.Context, name, semester string, year, term int) (*model.CourseCatalogEntry, error) {
+	return nil, nil
+}
+
+func (fakeLookup) CanonicalTeacher(ctx context.Context, name string) (string, error) {
+	return name, nil
+}
+
+// fakeCourses 记录导入调用的 CourseRepository 写入方法，其余方法不会被调用
+type fakeCourses struct {
+	repository.CourseRepository
+	created   []map[string]interface{}
+	resources []map[string]interface{}
+	reviewed  []string
+}
+
+func (f *fakeCourses) Create(ctx context.Context, userID int, data map[string]interface{}) (map[string]interface{}, error) {
+	f.created = append(f.created, data)
+	return map[string]interface{}{"resourceId": len(f.created)}, nil
+}
+
+func (f *fakeCourses) UploadResource(ctx context.Context, userID int, courseID string, data map[string]interface{}) (map[string]interface{}, error) {
+	f.resources = append(f.resources, data)
+	return map[string]interface{}{"resource1": map[string]interface{}{"resource_id": len(f.resources)}}, nil
+}
+
+func (f *fakeCourses) UpdateCourseStatus(ctx context.Context, courseID, status, rejectReason string) error {
+	f.reviewed = append(f.reviewed, "course "+courseID+" "+status)
+	return nil
+}
+
+func (f *fakeCourses) UpdateResourceStatus(ctx context.Context, kind, resourceID, status, rejectReason string) error {
+	f.reviewed = append(f.reviewed, kind+" "+resourceID+" "+status)
+	return nil
+}
+
+func TestRunStatus(t *testing.T) {
+	records := []Record{{Index: 1, Row: model.CourseImportRow{
+		Name:         "数据结构",
+		Semester:     "2024秋",
+		WebResources: []model.CourseImportResource{{Intro: "主页", URL: "https://a.edu/ds"}},
+	}}}
+	tests := []struct {
+		status       string
+		wantReviewed []string
+	}{
+		{model.CourseStatusApproved, []string{"course 1 approved", "web 1 approved"}},
+		{model.CourseStatusPending, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			courses := &fakeCourses{}
+			report, err := New(fakeLookup{}, courses, Options{Status: tt.status, SubmitterID: 7}).Run(context.Background(), records)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if !report.Applied || report.Created != 1 || report.Results[0].CourseID != 1 {
+				t.Fatalf("report = %+v", report)
+			}
+			if len(courses.created) != 1 || courses.created[0]["semester_year"] != 2024 {
+				t.Errorf("created = %+v", courses.created)
+			}
+			if strings.Join(courses.reviewed, ",") != strings.Join(tt.wantReviewed, ",") {
+				t.Errorf("reviewed = %v, want %v", courses.reviewed, tt.wantReviewed)
+			}
+		})
+	}
+}
+
+func TestRunRequiresSubmitter(t *testing.T) {
+	records := []Record{{Index: 1, Row: model.CourseImportRow{Name: "数据结构"}}}
+	if _, err := New(fakeLookup{}, &fakeCourses{}, Options{}).Run(context.Background(), records); err == nil {
+		t.Fatal("expected an error without submitter")
+	}
+	report, err := New(fakeLookup{}, &fakeCourses{}, Options{DryRun: true}).Run(context.Background(), records)
+	if err != nil || report.Applied || report.Created != 1 {
+		t.Fatalf("dry run = %+v, %v", report, err)
+	}
+}
+
+// 上传资源下载失败时不写入这门课程（本机地址被下载客户端拒绝）
+func TestRunDownloadsBeforeWriting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("%PDF-1.4"))
+	}))
+	defer server.Close()
+
+	records := []Record{{Index: 1, Row: model.CourseImportRow{
+		Name:            "数据结构",
+		UploadResources: []model.CourseImportResource{{Intro: "讲义", URL: server.URL + "/ch1.pdf"}},
+	}}}
+	courses := &fakeCourses{}
+	report, err := New(fakeLookup{}, courses, Options{SubmitterID: 7, StorageDir: t.TempDir(), MaxFileSize: 1 << 20}).Run(context.Background(), records)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Failed != 1 || len(courses.created) != 0 || len(courses.resources) != 0 {
+		t.Fatalf("report = %+v, created = %v, resources = %v", report, courses.created, courses.resources)
+	}
+}
@@ -0,0 +1,114 @@
+package loginguard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/repository"
+	"time"
+)
+
+type databaseTracker struct {
+	db *repository.Database
+}
+
+// NewDatabaseTracker 创建基于 login_attempts 表的失败记录存储，多实例部署时共享
+func NewDatabaseTracker(db *repository.Database) Tracker {
+	return &databaseTracker{db: db}
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func getRecord(ctx context.Context, q queryRower, key string, forUpdate bool) (*Record, error) {
+	query := `SELECT attempt_key, failures, last_failure_at, blocked_until, locked FROM login_attempts WHERE attempt_key = ?`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	rec := &Record{}
+	var blockedUntil sql.NullTime
+	err := q.QueryRowContext(ctx, query, key).Scan(&rec.Key, &rec.Failures, &rec.LastFailure, &blockedUntil, &rec.Locked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get login attempts: %v", err)
+	}
+	if blockedUntil.Valid {
+		rec.BlockedUntil = &blockedUntil.Time
+	}
+	return rec, nil
+}
+
+func (t *databaseTracker) Get(ctx context.Context, key string) (*Record, error) {
+	return getRecord(ctx, t.db, key, false)
+}
+
+func (t *databaseTracker) AddFailure(ctx context.Context, key string, policy Policy, now time.Time) (*Record, error) {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	prev, err := getRecord(ctx, tx, key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := policy.next(key, prev, now)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO login_attempts (attempt_key, failures, last_failure_at, blocked_until, locked)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			failures = VALUES(failures),
+			last_failure_at = VALUES(last_failure_at),
+			blocked_until = VALUES(blocked_until),
+			locked = VALUES(locked)
+	`, rec.Key, rec.Failures, rec.LastFailure, rec.BlockedUntil, rec.Locked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save login attempts: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return rec, nil
+}
+
+func (t *databaseTracker) Reset(ctx context.Context, key string) error {
+	if _, err := t.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE attempt_key = ?`, key); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %v", err)
+	}
+	return nil
+}
+
+func (t *databaseTracker) ListBlocked(ctx context.Context, now time.Time) ([]Record, error) {
+	rows, err := t.db.QueryContext(ctx, `
+		SELECT attempt_key, failures, last_failure_at, blocked_until, locked
+		FROM login_attempts
+		WHERE blocked_until > ?
+		ORDER BY last_failure_at DESC
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query login attempts: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]Record, 0)
+	for rows.Next() {
+		var rec Record
+		var blockedUntil sql.NullTime
+		if err := rows.Scan(&rec.Key, &rec.Failures, &rec.LastFailure, &blockedUntil, &rec.Locked); err != nil {
+			return nil, fmt.Errorf("failed to scan login attempts: %v", err)
+		}
+		if blockedUntil.Valid {
+			rec.BlockedUntil = &blockedUntil.Time
+		}
+		result = append(result, rec)
+	}
+	return result, rows.Err()
+}
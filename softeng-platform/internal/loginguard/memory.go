@@ -0,0 +1,80 @@
+package loginguard
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryPruneSize 记录数超过该值时清理已过期的记录
+const memoryPruneSize = 10000
+
+type memoryTracker struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+// NewMemoryTracker 创建进程内的失败记录存储，仅适用于单实例部署
+func NewMemoryTracker() Tracker {
+	return &memoryTracker{records: make(map[string]*Record)}
+}
+
+func (t *memoryTracker) Get(ctx context.Context, key string) (*Record, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rec, ok := t.records[key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *rec
+	return &copied, nil
+}
+
+func (t *memoryTracker) AddFailure(ctx context.Context, key string, policy Policy, now time.Time) (*Record, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.records) > memoryPruneSize {
+		t.prune(now, policy.Window)
+	}
+
+	rec := policy.next(key, t.records[key], now)
+	t.records[key] = rec
+	copied := *rec
+	return &copied, nil
+}
+
+func (t *memoryTracker) Reset(ctx context.Context, key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.records, key)
+	return nil
+}
+
+func (t *memoryTracker) ListBlocked(ctx context.Context, now time.Time) ([]Record, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]Record, 0)
+	for _, rec := range t.records {
+		if _, blocked := rec.blockedAt(now); blocked {
+			result = append(result, *rec)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastFailure.After(result[j].LastFailure)
+	})
+	return result, nil
+}
+
+// prune 删除不再封禁且已超出计数窗口的记录
+func (t *memoryTracker) prune(now time.Time, window time.Duration) {
+	for key, rec := range t.records {
+		if _, blocked := rec.blockedAt(now); !blocked && now.Sub(rec.LastFailure) >= window {
+			delete(t.records, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package loginguard
+
+import (
+	"time"
+)
+
+// Policy 失败计数与退避策略
+type Policy struct {
+	FreeAttempts int           // 不触发退避的失败次数
+	BaseDelay    time.Duration // 第一次退避的时长，之后每次翻倍
+	MaxDelay     time.Duration // 退避时长上限
+	LockAfter    int           // 失败达到该次数后锁定，0 表示不锁定
+	LockDuration time.Duration // 锁定时长
+	Window       time.Duration // 超过该时长没有新的失败则重新计数
+}
+
+// next 在 prev 的基础上记录一次失败，返回新的记录
+func (p Policy) next(key string, prev *Record, now time.Time) *Record {
+	rec := &Record{Key: key}
+	if prev != nil && now.Sub(prev.LastFailure) < p.Window {
+		rec.Failures = prev.Failures
+	}
+	rec.Failures++
+	rec.LastFailure = now
+
+	if p.LockAfter > 0 && rec.Failures >= p.LockAfter {
+		until := now.Add(p.LockDuration)
+		rec.BlockedUntil = &until
+		rec.Locked = true
+		return rec
+	}
+
+	if extra := rec.Failures - p.FreeAttempts; extra > 0 {
+		delay := p.BaseDelay
+		for i := 1; i < extra && delay < p.MaxDelay; i++ {
+			delay *= 2
+		}
+		if delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+		until := now.Add(delay)
+		rec.BlockedUntil = &until
+	}
+	return rec
+}
@@ -0,0 +1,51 @@
+package loginguard
+
+import (
+	"context"
+	"log"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/repository"
+	"time"
+)
+
+// Record 某个键（账号或IP）的登录失败记录
+type Record struct {
+	Key          string     `json:"key"`
+	Failures     int        `json:"failures"`
+	LastFailure  time.Time  `json:"last_failure"`
+	BlockedUntil *time.Time `json:"blocked_until"`
+	Locked       bool       `json:"locked"` // 达到锁定阈值（区别于普通退避）
+}
+
+// blockedAt 判断记录在 now 时刻是否处于封禁中，返回剩余时间
+func (r *Record) blockedAt(now time.Time) (time.Duration, bool) {
+	if r == nil || r.BlockedUntil == nil || !now.Before(*r.BlockedUntil) {
+		return 0, false
+	}
+	return r.BlockedUntil.Sub(now), true
+}
+
+// Tracker 登录失败记录的存储接口，可以是进程内存或数据库
+type Tracker interface {
+	// Get 获取键的记录，不存在时返回 nil
+	Get(ctx context.Context, key string) (*Record, error)
+	// AddFailure 原子地累加一次失败并按策略计算封禁时间，返回更新后的记录
+	AddFailure(ctx context.Context, key string, policy Policy, now time.Time) (*Record, error)
+	// Reset 清除键的记录
+	Reset(ctx context.Context, key string) error
+	// ListBlocked 列出 now 时刻仍处于封禁中的记录
+	ListBlocked(ctx context.Context, now time.Time) ([]Record, error)
+}
+
+// NewTracker 根据配置创建失败记录存储
+func NewTracker(cfg *config.Config, db *repository.Database) Tracker {
+	switch cfg.LoginGuardDriver {
+	case "database":
+		return NewDatabaseTracker(db)
+	case "memory", "":
+		return NewMemoryTracker()
+	default:
+		log.Printf("Unknown LOGIN_GUARD_DRIVER %q, falling back to memory tracker", cfg.LoginGuardDriver)
+		return NewMemoryTracker()
+	}
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+type logMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLogMailer 创建本地开发用的邮件发送器：邮件写入文件（path 非空）或标准日志，不会真正发出
+func NewLogMailer(path string) Mailer {
+	return &logMailer{path: path}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	entry := fmt.Sprintf("[%s] To: %s\nSubject: %s\n\n%s\n----\n",
+		time.Now().Format("2006-01-02 15:04:05"), msg.To, msg.Subject, msg.Body)
+
+	if m.path == "" {
+		log.Printf("[Mailer] %s", entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(entry); err != nil {
+		return fmt.Errorf("failed to write mail log file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/config"
+)
+
+// Message 待发送的邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口，便于在 SMTP 与本地开发用的日志实现之间切换
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer 根据配置创建邮件发送器；配置错误时返回错误，避免验证码邮件被静默写入日志
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER is smtp")
+		}
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "log", "":
+		return NewLogMailer(cfg.MailLogFile), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q, must be smtp or log", cfg.MailDriver)
+	}
+}
@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"softeng-platform/internal/config"
+	"strings"
+	"testing"
+)
+
+func TestNewMailer(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{name: "default", cfg: config.Config{}},
+		{name: "log", cfg: config.Config{MailDriver: "log"}},
+		{name: "smtp", cfg: config.Config{MailDriver: "smtp", SMTPHost: "smtp.example.edu", SMTPPort: "587"}},
+		{name: "smtp without host", cfg: config.Config{MailDriver: "smtp"}, wantErr: "SMTP_HOST"},
+		{name: "unknown driver", cfg: config.Config{MailDriver: "smpt"}, wantErr: `unknown MAIL_DRIVER "smpt"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMailer(&tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || m != nil {
+					t.Fatalf("NewMailer = %v, %v; want error containing %q", m, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || m == nil {
+				t.Fatalf("NewMailer = %v, %v", m, err)
+			}
+		})
+	}
+}
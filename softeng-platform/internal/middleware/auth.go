@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/utils"
 	"softeng-platform/pkg/response"
 	"strings"
@@ -9,7 +11,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// TokenVerifier 校验访问令牌是否仍然有效（如是否已被吊销），以及校验个人访问令牌
+type TokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, claims *utils.Claims) error
+	AuthenticatePersonalToken(ctx context.Context, token string) (*model.PersonalToken, *model.User, error)
+}
+
+// AuthMiddleware 校验登录 JWT 或个人访问令牌。
+// scopes 为使用个人访问令牌时该路由要求的授权范围；未指定时令牌只能访问 GET 接口且需要 read 范围，
+// 登录 JWT 不受 scopes 限制。
+func AuthMiddleware(verifier TokenVerifier, scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -19,6 +30,12 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+
+		if strings.HasPrefix(tokenString, model.PersonalTokenPrefix) {
+			authenticatePersonalToken(c, verifier, tokenString, scopes)
+			return
+		}
+
 		claims, err := utils.ValidateToken(tokenString)
 		if err != nil {
 			response.Error(c, http.StatusUnauthorized, "Invalid token")
@@ -26,6 +43,16 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 检查吊销列表
+		if err := verifier.VerifyAccessToken(c.Request.Context(), claims); err != nil {
+			response.Error(c, http.StatusUnauthorized, "Token has been revoked")
+			c.Abort()
+			return
+		}
+
+		c.Set("claims", claims)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("mfa", claims.MFA)
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
@@ -33,14 +60,109 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-func AdminMiddleware() gin.HandlerFunc {
+func authenticatePersonalToken(c *gin.Context, verifier TokenVerifier, tokenString string, scopes []string) {
+	token, user, err := verifier.AuthenticatePersonalToken(c.Request.Context(), tokenString)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Invalid token")
+		c.Abort()
+		return
+	}
+
+	required, ok := personalTokenScopes(c.Request.Method, scopes)
+	if !ok {
+		response.Error(c, http.StatusForbidden, "Personal access tokens cannot be used for this endpoint")
+		c.Abort()
+		return
+	}
+	if missing := missingScope(token, required); missing != "" {
+		response.Error(c, http.StatusForbidden, "Token scope required: "+missing)
+		c.Abort()
+		return
+	}
+
+	c.Set("personalTokenID", token.ID)
+	c.Set("userID", user.ID)
+	c.Set("username", user.Username)
+	c.Set("role", user.Role)
+	c.Next()
+}
+
+// personalTokenScopes 返回个人访问令牌访问该路由需要的授权范围；
+// 未声明授权范围的写接口（修改密码、管理令牌等）不允许使用个人访问令牌，返回 false
+func personalTokenScopes(method string, scopes []string) ([]string, bool) {
+	if len(scopes) > 0 {
+		return scopes, true
+	}
+	if method != http.MethodGet && method != http.MethodHead {
+		return nil, false
+	}
+	return []string{model.ScopeRead}, true
+}
+
+// missingScope 返回令牌缺少的第一个授权范围，全部具备时返回空字符串
+func missingScope(token *model.PersonalToken, required []string) string {
+	for _, scope := range required {
+		if !token.HasScope(scope) {
+			return scope
+		}
+	}
+	return ""
+}
+
+// RejectPersonalToken 拒绝个人访问令牌，只允许登录会话访问，需在 AuthMiddleware 之后使用。
+// 用于管理接口：其中部分 GET 接口（如审核）会修改数据，不能按读接口处理
+func RejectPersonalToken() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role := c.GetString("role")
-		if role != "admin" {
-			response.Error(c, http.StatusForbidden, "Admin access required")
+		if _, ok := c.Get("personalTokenID"); ok {
+			response.Error(c, http.StatusForbidden, "Personal access tokens cannot be used for admin endpoints")
 			c.Abort()
 			return
 		}
 		c.Next()
 	}
 }
+
+// RequirePermission 要求当前用户的角色拥有全部指定权限，需在 AuthMiddleware 之后使用
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, permission := range permissions {
+			if !model.HasPermission(role, permission) {
+				response.Error(c, http.StatusForbidden, "Permission denied: "+permission)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireMFA 要求指定角色的会话通过两步验证登录，个人访问令牌视为未通过，需在 AuthMiddleware 之后使用
+func RequireMFA(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if r == role && !c.GetBool("mfa") {
+				response.ErrorWithData(c, http.StatusForbidden, "Two-factor authentication required", "mfa_required")
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireAnyPermission 要求当前用户的角色拥有任一指定权限，具体资源的权限由业务层再次校验
+func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, permission := range permissions {
+			if model.HasPermission(role, permission) {
+				c.Next()
+				return
+			}
+		}
+		response.Error(c, http.StatusForbidden, "Permission denied")
+		c.Abort()
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/utils"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeVerifier struct {
+	token *model.PersonalToken
+}
+
+func (v fakeVerifier) VerifyAccessToken(ctx context.Context, claims *utils.Claims) error {
+	return nil
+}
+
+func (v fakeVerifier) AuthenticatePersonalToken(ctx context.Context, token string) (*model.PersonalToken, *model.User, error) {
+	if v.token == nil {
+		return nil, nil, errors.New("invalid token")
+	}
+	return v.token, &model.User{ID: 7, Username: "mod", Role: model.RoleAdmin}, nil
+}
+
+func TestPersonalTokenScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		scopes []string
+		want   []string
+		ok     bool
+	}{
+		{"declared scope", http.MethodPost, []string{model.ScopeSubmit}, []string{model.ScopeSubmit}, true},
+		{"get defaults to read", http.MethodGet, nil, []string{model.ScopeRead}, true},
+		{"head defaults to read", http.MethodHead, nil, []string{model.ScopeRead}, true},
+		{"undeclared write", http.MethodPost, nil, nil, false},
+		{"undeclared delete", http.MethodDelete, nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := personalTokenScopes(tt.method, tt.scopes)
+			if ok != tt.ok || len(got) != len(tt.want) {
+				t.Fatalf("personalTokenScopes(%s, %v) = %v, %v; want %v, %v", tt.method, tt.scopes, got, ok, tt.want, tt.ok)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("scope %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMissingScope(t *testing.T) {
+	token := &model.PersonalToken{Scopes: []string{model.ScopeRead, model.ScopeComment}}
+	tests := []struct {
+		required []string
+		want     string
+	}{
+		{[]string{model.ScopeRead}, ""},
+		{[]string{model.ScopeRead, model.ScopeComment}, ""},
+		{[]string{model.ScopeSubmit}, model.ScopeSubmit},
+		{[]string{model.ScopeRead, model.ScopeSubmit}, model.ScopeSubmit},
+	}
+	for _, tt := range tests {
+		if got := missingScope(token, tt.required); got != tt.want {
+			t.Errorf("missingScope(%v) = %q, want %q", tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestPersonalTokenRoutes(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	verifier := fakeVerifier{token: &model.PersonalToken{ID: 1, Scopes: []string{model.ScopeRead}}}
+
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/profile", AuthMiddleware(verifier), ok)
+	r.POST("/password", AuthMiddleware(verifier), ok)
+	r.POST("/submit", AuthMiddleware(verifier, model.ScopeSubmit), ok)
+	admin := r.Group("/admin", AuthMiddleware(verifier), RejectPersonalToken())
+	admin.GET("/review/:itemId", ok)
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/profile", http.StatusOK},
+		{http.MethodPost, "/password", http.StatusForbidden},
+		{http.MethodPost, "/submit", http.StatusForbidden},
+		// 审核的 GET 接口会修改数据，只读令牌也不能访问
+		{http.MethodGet, "/admin/review/1?action=approve", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer "+model.PersonalTokenPrefix+"abc")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+	}
+}
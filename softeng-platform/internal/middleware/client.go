@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"softeng-platform/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustProxies 只信任 proxies 中的反向代理转发的客户端地址，proxies 为空时 ClientIP 总是连接的对端地址，
+// 客户端自带的 X-Forwarded-For 不能改变登录限流的 IP 和审计日志中的 IP
+func TrustProxies(r *gin.Engine, proxies []string) error {
+	return r.SetTrustedProxies(proxies)
+}
+
+// ClientInfo 将客户端 IP 和 User-Agent 放入请求 context，供服务层写审计日志
+func ClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := model.ContextWithClientInfo(c.Request.Context(), model.ClientInfo{
+			UserAgent: c.Request.UserAgent(),
+			IP:        c.ClientIP(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
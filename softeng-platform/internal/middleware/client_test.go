@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientInfoIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		forwarded  []string // 每次请求的 X-Forwarded-For
+		want       string   // 登录限流使用的 IP 键
+	}{
+		{
+			name:       "no trusted proxy",
+			remoteAddr: "203.0.113.7:51000",
+			forwarded:  []string{"", "198.51.100.1", "198.51.100.2, 10.0.0.1"},
+			want:       loginguard.IPKey("203.0.113.7"),
+		},
+		{
+			name:       "untrusted peer",
+			proxies:    []string{"10.0.0.1"},
+			remoteAddr: "203.0.113.7:51000",
+			forwarded:  []string{"198.51.100.1", "198.51.100.2"},
+			want:       loginguard.IPKey("203.0.113.7"),
+		},
+		{
+			name:       "trusted reverse proxy",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.1:443",
+			forwarded:  []string{"203.0.113.7", "198.51.100.1, 203.0.113.7"},
+			want:       loginguard.IPKey("203.0.113.7"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			if err := TrustProxies(r, tt.proxies); err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			r.Use(ClientInfo())
+			r.POST("/auth/login", func(c *gin.Context) {
+				got = loginguard.IPKey(model.ClientInfoFromContext(c.Request.Context()).IP)
+			})
+
+			for _, forwarded := range tt.forwarded {
+				req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+				req.RemoteAddr = tt.remoteAddr
+				if forwarded != "" {
+					req.Header.Set("X-Forwarded-For", forwarded)
+				}
+				r.ServeHTTP(httptest.NewRecorder(), req)
+				if got != tt.want {
+					t.Errorf("X-Forwarded-For %q: guard key = %q, want %q", forwarded, got, tt.want)
+				}
+			}
+		})
+	}
+}
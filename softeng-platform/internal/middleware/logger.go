@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// calendarFeedPrefix 个人日历订阅地址的前缀，之后的令牌即凭证，不能写入日志
+const calendarFeedPrefix = "/course/calendar/"
+
+// RedactPath 隐藏请求路径中的个人日历订阅令牌，用于写日志
+func RedactPath(path string) string {
+	if strings.HasPrefix(path, calendarFeedPrefix) {
+		return calendarFeedPrefix + "[REDACTED]"
+	}
+	return path
+}
+
+// Logger 请求日志，格式与 gin 默认的日志相同，路径经过 RedactPath 处理
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(p gin.LogFormatterParams) string {
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+			p.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			p.StatusCode,
+			p.Latency.Truncate(time.Microsecond),
+			p.ClientIP,
+			p.Method,
+			RedactPath(p.Path),
+			p.ErrorMessage,
+		)
+	})
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import "testing"
+
+func TestRedactPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"/course/calendar/3f9a0c7e.ics", "/course/calendar/[REDACTED]"},
+		{"/course/calendar/3f9a0c7e?download=1", "/course/calendar/[REDACTED]"},
+		{"/course/calendar.ics", "/course/calendar.ics"},
+		{"/course/12/events", "/course/12/events"},
+		{"/users/calendar-feed", "/users/calendar-feed"},
+	}
+	for _, tt := range tests {
+		if got := RedactPath(tt.path); got != tt.want {
+			t.Errorf("RedactPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
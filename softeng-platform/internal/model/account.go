@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+)
+
+// AccountDeletion 注销申请，冷静期结束（PurgeAfter）后执行匿名化
+type AccountDeletion struct {
+	UserID      int        `json:"user_id" db:"user_id"`
+	RequestedAt time.Time  `json:"requested_at" db:"requested_at"`
+	PurgeAfter  time.Time  `json:"purge_after" db:"purge_after"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `form:"password" json:"password" binding:"required"`
+	Code     string `form:"code" json:"code"` // 已启用两步验证时必填
+}
+
+// AccountExport 个人数据导出
+type AccountExport struct {
+	ExportedAt   time.Time          `json:"exported_at"`
+	Profile      *User              `json:"profile"`
+	Identities   []Identity         `json:"identities"`
+	Comments     []ExportComment    `json:"comments"`
+	Collections  []ExportReaction   `json:"collections"`
+	Likes        []ExportReaction   `json:"likes"`
+	CommentLikes []ExportReaction   `json:"comment_likes"`
+	Tools        []ExportSubmission `json:"tools"`
+	Courses      []ExportSubmission `json:"courses"`
+	Projects     []ExportSubmission `json:"projects"`
+	LearningPlan []LearningPlanItem `json:"learning_plan"`
+	Ratings      []CourseRating     `json:"ratings"`
+	Images       []string           `json:"images"` // 以上数据引用的全部图片地址
+}
+
+type ExportComment struct {
+	ID           int        `json:"comment_id"`
+	ResourceType string     `json:"resource_type"`
+	ResourceID   int        `json:"resource_id"`
+	ParentID     *int       `json:"parent_id,omitempty"`
+	Content      string     `json:"content"`
+	LoveCount    int        `json:"love_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ExportReaction 收藏/点赞记录
+type ExportReaction struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   int       `json:"resource_id"`
+	ResourceName string    `json:"resource_name,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ExportSubmission 提交或参与的工具/课程/项目
+type ExportSubmission struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Link        string    `json:"link,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Cover       string    `json:"cover,omitempty"`
+	Images      []string  `json:"images,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// 认证审计事件类型
+const (
+	AuthEventLogin                = "login"                  // 登录成功（密码或第三方账号）
+	AuthEventLoginFailed          = "login_failed"           // 密码错误、账号不存在或第三方授权 state 不匹配
+	AuthEventLoginBlocked         = "login_blocked"          // 处于退避期或锁定中被拒绝
+	AuthEventMFAFailed            = "mfa_failed"             // 两步验证码错误
+	AuthEventLogout               = "logout"                 // 登出当前会话
+	AuthEventLogoutAll            = "logout_all"             // 登出全部设备
+	AuthEventPasswordChanged      = "password_changed"       // 登录状态下修改密码
+	AuthEventPasswordReset        = "password_reset"         // 通过邮箱验证码重置密码
+	AuthEventEmailChanged         = "email_changed"          // 修改邮箱
+	AuthEventRoleChanged          = "role_changed"           // 管理员修改角色
+	AuthEventSessionRevoked       = "session_revoked"        // 注销指定设备
+	AuthEventTokenReused          = "refresh_token_reused"   // 刷新令牌重用，整个家族被吊销
+	AuthEventPersonalTokenCreated = "personal_token_created" // 创建个人访问令牌
+	AuthEventPersonalTokenRevoked = "personal_token_revoked" // 吊销个人访问令牌
+	AuthEventMFAEnabled           = "mfa_enabled"            // 启用两步验证
+	AuthEventMFADisabled          = "mfa_disabled"           // 关闭两步验证
+	AuthEventIdentityLinked       = "identity_linked"        // 绑定第三方账号
+	AuthEventIdentityUnlinked     = "identity_unlinked"      // 解绑第三方账号
+	AuthEventDeletionRequested    = "deletion_requested"     // 申请注销账号，进入冷静期
+)
+
+// AuthEventTypes 全部事件类型，用于校验查询条件
+var AuthEventTypes = []string{
+	AuthEventLogin,
+	AuthEventLoginFailed,
+	AuthEventLoginBlocked,
+	AuthEventMFAFailed,
+	AuthEventLogout,
+	AuthEventLogoutAll,
+	AuthEventPasswordChanged,
+	AuthEventPasswordReset,
+	AuthEventEmailChanged,
+	AuthEventRoleChanged,
+	AuthEventSessionRevoked,
+	AuthEventTokenReused,
+	AuthEventPersonalTokenCreated,
+	AuthEventPersonalTokenRevoked,
+	AuthEventMFAEnabled,
+	AuthEventMFADisabled,
+	AuthEventIdentityLinked,
+	AuthEventIdentityUnlinked,
+	AuthEventDeletionRequested,
+}
+
+// ValidAuthEventType 判断事件类型是否存在
+func ValidAuthEventType(eventType string) bool {
+	for _, t := range AuthEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthEvent 认证相关的审计记录
+type AuthEvent struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"user_id,omitempty" db:"user_id"`       // 账号不存在的失败登录为 0
+	Identifier string    `json:"identifier,omitempty" db:"identifier"` // 用户名，或失败登录时输入的用户名/邮箱
+	Type       string    `json:"type" db:"event_type"`
+	IP         string    `json:"ip" db:"ip"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	Detail     string    `json:"detail,omitempty" db:"detail"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// AuthEventFilter 审计日志查询条件，零值字段不参与过滤
+type AuthEventFilter struct {
+	UserID     int
+	Identifier string
+	Type       string
+	IP         string
+	From       time.Time
+	To         time.Time
+	Cursor     int
+	Limit      int
+}
+
+type clientInfoKey struct{}
+
+// ContextWithClientInfo 将请求方的客户端信息放入 context，供不直接接收 ClientInfo 的服务记录审计日志
+func ContextWithClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, client)
+}
+
+// ClientInfoFromContext 取出 ContextWithClientInfo 放入的客户端信息
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return client
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+)
+
+// 课程日历事件类型
+const (
+	CourseEventDeadline = "deadline" // 截止时间，如作业、报名截止
+	CourseEventExam     = "exam"     // 考试
+	CourseEventOther    = "event"    // 其他活动，如讲座、答疑
+)
+
+// ValidCourseEventKind 判断课程日历事件类型是否有效
+func ValidCourseEventKind(kind string) bool {
+	return kind == CourseEventDeadline || kind == CourseEventExam || kind == CourseEventOther
+}
+
+// CourseEvent 贡献者为课程添加的截止时间或活动，AllDay 为 true 时只使用日期部分
+type CourseEvent struct {
+	EventID     int        `json:"event_id" db:"event_id"`
+	CourseID    int        `json:"course_id" db:"course_id"`
+	CourseName  string     `json:"course_name"`
+	Title       string     `json:"title" db:"title"`
+	Description string     `json:"description" db:"description"`
+	Kind        string     `json:"kind" db:"kind"`
+	StartAt     time.Time  `json:"start_at" db:"start_at"`
+	EndAt       *time.Time `json:"end_at" db:"end_at"` // 截止时间等没有持续时间的事件为空
+	AllDay      bool       `json:"all_day" db:"all_day"`
+	Location    string     `json:"location" db:"location"`
+	CreatorID   int        `json:"creator_id" db:"creator_id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateCourseEventRequest 添加课程日历事件。
+// 时间支持 2006-01-02（全天）、2006-01-02 15:04 和 RFC 3339 格式，不带时区时按服务器时区解析
+type CreateCourseEventRequest struct {
+	Title       string `form:"title" json:"title" binding:"required,max=255"`
+	Description string `form:"description" json:"description" binding:"max=2000"`
+	Kind        string `form:"kind" json:"kind"` // 为空时为 event
+	StartAt     string `form:"start_at" json:"start_at" binding:"required"`
+	EndAt       string `form:"end_at" json:"end_at"`
+	Location    string `form:"location" json:"location" binding:"max=255"`
+}
+
+// CalendarOffering 日历中的一次开课及其学期
+type CalendarOffering struct {
+	OfferingID int
+	CourseID   int
+	CourseName string
+	Credit     int
+	Semester   Semester
+	Teachers   []string
+}
+
+// CalendarPlannedCourse 用户收藏或加入学习计划的课程，PlanSemester 为学习计划中的学期（仅收藏时为空）
+type CalendarPlannedCourse struct {
+	CourseID     int
+	CourseName   string
+	PlanSemester string
+	Collected    bool
+}
+
+// CalendarFeed 个人日历订阅地址，Token 只在生成时返回一次
+type CalendarFeed struct {
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
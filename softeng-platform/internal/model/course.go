@@ -1,5 +1,10 @@
 package model
 
+import (
+	"math"
+	"time"
+)
+
 type Course struct {
 	CourseID     int      `json:"courseId"`
 	ResourceType string   `json:"resourceType"`
@@ -65,3 +70,166 @@ type TeachPersonal struct {
 	Introduce    string `json:"introduce"`
 	Contributer  []User `json:"contributer"`
 }
+
+// CourseResourceFile 课程上传资源（课本、讲义等）的下载信息
+type CourseResourceFile struct {
+	ResourceID  int       `json:"resource_id"`
+	CourseID    int       `json:"course_id"`
+	Intro       string    `json:"resource_intro"`
+	URL         string    `json:"resource_upload"` // 历史导入的外部链接，或本地文件的下载地址
+	FileName    string    `json:"file_name"`
+	FileSize    int64     `json:"file_size"`
+	ContentType string    `json:"content_type"`
+	StoragePath string    `json:"-"` // 本地存储路径，外部链接为空
+	Status      string    `json:"status"`
+	Downloads   int       `json:"downloads"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// 课程及课程资源的审核状态
+const (
+	CourseStatusPending  = "pending"  // 待审核（新提交或重新提交）
+	CourseStatusApproved = "approved" // 审核通过，公开展示
+	CourseStatusRejected = "rejected" // 审核驳回
+	CourseStatusOffline  = "offline"  // 已下架（提交者撤回或管理员下架）
+)
+
+// courseStatusTransitions 目标状态 -> 允许的原状态
+var courseStatusTransitions = map[string][]string{
+	CourseStatusPending:  {CourseStatusRejected, CourseStatusOffline},
+	CourseStatusApproved: {CourseStatusPending, CourseStatusOffline},
+	CourseStatusRejected: {CourseStatusPending, CourseStatusApproved},
+	CourseStatusOffline:  {CourseStatusPending, CourseStatusApproved},
+}
+
+// CourseStatusSources 返回可以变更为 status 的原状态，status 无效时返回 nil
+func CourseStatusSources(status string) []string {
+	return courseStatusTransitions[status]
+}
+
+// CourseChapter 课程大纲中的章或节，ParentID 为 0 表示章，否则为所属章下的节
+type CourseChapter struct {
+	ChapterID   int             `json:"chapter_id"`
+	CourseID    int             `json:"course_id"`
+	ParentID    int             `json:"parent_id"`
+	Title       string          `json:"title"`
+	SortOrder   int             `json:"order"`
+	Status      string          `json:"status"`
+	SubmitterID int             `json:"-"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Sections    []CourseChapter `json:"sections,omitempty"`
+}
+
+// BuildChapterOutline 将按顺序排列的章节列表组装为章-节两级大纲，找不到所属章的节被忽略
+func BuildChapterOutline(chapters []CourseChapter) []CourseChapter {
+	outline := make([]CourseChapter, 0)
+	index := make(map[int]int)
+	for _, ch := range chapters {
+		if ch.ParentID == 0 {
+			index[ch.ChapterID] = len(outline)
+			outline = append(outline, ch)
+		}
+	}
+	for _, ch := range chapters {
+		if i, ok := index[ch.ParentID]; ok && ch.ParentID != 0 {
+			outline[i].Sections = append(outline[i].Sections, ch)
+		}
+	}
+	return outline
+}
+
+type SubmitChapterRequest struct {
+	Title    string `form:"title" json:"title" binding:"required,max=255"`
+	ParentID int    `form:"parent_id" json:"parent_id"` // 为 0 时提交章，否则提交该章下的节
+	Order    int    `form:"order" json:"order"`         // 为 0 时排在末尾
+}
+
+// ChapterProgress 用户在某一章节的学习进度
+type ChapterProgress struct {
+	ChapterID   int        `json:"chapter_id"`
+	ParentID    int        `json:"parent_id"`
+	Title       string     `json:"title"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// CourseProgress 用户在某门课程的学习进度，只统计审核通过的章节
+type CourseProgress struct {
+	CourseID          int               `json:"course_id"`
+	CompletedChapters int               `json:"completed_chapters"`
+	TotalChapters     int               `json:"total_chapters"`
+	Percent           float64           `json:"percent"`
+	Chapters          []ChapterProgress `json:"chapters,omitempty"`
+}
+
+type UpdateProgressRequest struct {
+	Completed *bool `form:"completed" json:"completed"` // 为空时视为 true
+}
+
+// CompletionPercent 计算完成百分比，保留一位小数
+func CompletionPercent(completed, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(completed)*1000/float64(total)) / 10
+}
+
+// CourseRating 用户对一次开课（课程 + 教师 + 学期）的多维度评分，各维度取值 1-5；
+// Semester 为开课学期的名称，迁移前无法对应开课的旧评分 OfferingID 为 0
+type CourseRating struct {
+	ID         int       `json:"rating_id"`
+	CourseID   int       `json:"course_id"`
+	OfferingID int       `json:"offering_id"`
+	UserID     int       `json:"-"`
+	Username   string    `json:"username,omitempty"`
+	Teacher    string    `json:"teacher"`
+	Semester   string    `json:"semester"`
+	Difficulty int       `json:"difficulty"` // 难度，越高越难
+	Workload   int       `json:"workload"`   // 课业负担，越高越重
+	Teaching   int       `json:"teaching"`   // 教学质量
+	Usefulness int       `json:"usefulness"` // 实用性
+	Review     string    `json:"review"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RatingSummary 评分汇总，Teacher/Semester 为空时表示整门课程
+type RatingSummary struct {
+	Teacher    string  `json:"teacher,omitempty"`
+	Semester   string  `json:"semester,omitempty"`
+	Count      int     `json:"count"`
+	Difficulty float64 `json:"difficulty"`
+	Workload   float64 `json:"workload"`
+	Teaching   float64 `json:"teaching"`
+	Usefulness float64 `json:"usefulness"`
+}
+
+type RateCourseRequest struct {
+	Teacher    string `form:"teacher" json:"teacher" binding:"max=100"`           // 课程有任课教师时必填
+	Semester   string `form:"semester" json:"semester" binding:"required,max=50"` // 课程开课的学期，如 2024秋季、Fall 2024
+	Difficulty int    `form:"difficulty" json:"difficulty" binding:"required,min=1,max=5"`
+	Workload   int    `form:"workload" json:"workload" binding:"required,min=1,max=5"`
+	Teaching   int    `form:"teaching" json:"teaching" binding:"required,min=1,max=5"`
+	Usefulness int    `form:"usefulness" json:"usefulness" binding:"required,min=1,max=5"`
+	Review     string `form:"review" json:"review" binding:"max=2000"`
+}
+
+// CourseAnalysis 分析课程链接得到的课程提交预填信息
+type CourseAnalysis struct {
+	SourceURL   string             `json:"source_url"` // 跟随重定向后的页面地址
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Cover       string             `json:"cover"` // Open Graph 图片，已本地化
+	Resources   []ProposedResource `json:"resources"`
+}
+
+// ProposedResource 建议写入 course_resources_web 的资源
+type ProposedResource struct {
+	ResourceIntro string `json:"resource_intro"`
+	ResourceURL   string `json:"resource_url"`
+	Type          string `json:"type"` // pdf / slides
+}
+
+type AnalyzeCourseRequest struct {
+	URL string `form:"url" json:"url" binding:"required,max=500"`
+}
@@ -0,0 +1,54 @@
+package model
+
+// CourseImportRow 课程目录文件中的一门课程，课程名称和学期（按规范名称）为自然键；
+// Credit 为空时不修改已有课程的学分
+type CourseImportRow struct {
+	Name            string                 `json:"name" yaml:"name"`
+	Semester        string                 `json:"semester" yaml:"semester"`
+	Credit          *int                   `json:"credit" yaml:"credit"`
+	Cover           string                 `json:"cover" yaml:"cover"`
+	Teachers        []string               `json:"teachers" yaml:"teachers"`
+	Categories      []string               `json:"categories" yaml:"categories"`
+	WebResources    []CourseImportResource `json:"web_resources" yaml:"web_resources"`
+	UploadResources []CourseImportResource `json:"upload_resources" yaml:"upload_resources"`
+}
+
+// CourseImportResource 导入的课程资源，上传资源从 URL 下载到本地存储；同一课程内按 URL 去重
+type CourseImportResource struct {
+	Intro    string `json:"intro" yaml:"intro"`
+	URL      string `json:"url" yaml:"url"`
+	FileName string `json:"file_name" yaml:"file_name"` // 仅上传资源使用，为空时取 URL 中的文件名
+}
+
+// CourseCatalogEntry 数据库中按自然键找到的课程及其关联数据，用于和导入文件比较
+type CourseCatalogEntry struct {
+	CourseID   int
+	Name       string
+	Semester   string
+	Credit     int
+	Cover      string
+	Status     string
+	Teachers   []string // 任课教师的规范姓名
+	Categories []string
+	WebURLs    []string
+	UploadURLs []string // 导入的文件为原始地址
+	Offered    bool     // 已有该学期的开课记录
+}
+
+// CourseImportChange 导入一门课程需要写入的内容，CourseID 为 0 时新建课程；
+// Teachers 为全部任课教师（已关联的会被忽略），Categories 和资源只包含需要新增的
+type CourseImportChange struct {
+	CourseID        int
+	Name            string
+	Semester        string
+	SemesterYear    int // 能识别的学期，为 0 时不关联学期目录
+	SemesterTerm    int
+	Credit          *int    // 为空时不修改
+	Cover           *string // 为空时不修改，只在课程没有封面时设置
+	Status          string  // 新建课程的审核状态
+	SubmitterID     int     // 提交者，同时记为课程贡献者
+	Teachers        []string
+	Categories      []string
+	WebResources    []CourseImportResource
+	UploadResources []CourseImportResource
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// Identity 绑定到用户的第三方账号
+type Identity struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	Provider    string     `json:"provider" db:"provider"`
+	Subject     string     `json:"-" db:"subject"` // 提供方内的用户ID
+	Email       string     `json:"email" db:"email"`
+	DisplayName string     `json:"display_name" db:"display_name"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at" db:"last_login_at"`
+}
+
+// OAuthState 发起授权时保存的请求状态，回调时一次性取出
+type OAuthState struct {
+	StateHash      string    `db:"state_hash"`
+	Provider       string    `db:"provider"`
+	CodeVerifier   string    `db:"code_verifier"` // PKCE
+	UserID         int       `db:"user_id"`       // 非 0 表示已登录用户绑定账号，否则为登录
+	InvitationCode string    `db:"invitation_code"`
+	ExpiresAt      time.Time `db:"expires_at"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// OAuthStart 发起授权的结果：State 需要写入发起授权的浏览器（Cookie），回调时必须与参数一致
+type OAuthStart struct {
+	AuthURL   string
+	State     string
+	ExpiresIn int // State 的有效期（秒）
+}
+
+// OAuthCallbackResult 回调处理结果：登录返回 Login，绑定返回 Identity
+type OAuthCallbackResult struct {
+	Login    *LoginResult
+	Identity *Identity
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+type Invitation struct {
+	ID          int                    `json:"id" db:"id"`
+	Code        string                 `json:"code" db:"code"`
+	MaxUses     int                    `json:"max_uses" db:"max_uses"`
+	UsedCount   int                    `json:"used_count" db:"used_count"`
+	ExpiresAt   *time.Time             `json:"expires_at" db:"expires_at"`
+	Role        string                 `json:"role" db:"role"`
+	Cohort      string                 `json:"cohort" db:"cohort"`
+	Note        string                 `json:"note" db:"note"`
+	CreatedBy   int                    `json:"created_by" db:"created_by"`
+	RevokedAt   *time.Time             `json:"revoked_at" db:"revoked_at"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	Redemptions []InvitationRedemption `json:"redemptions,omitempty"`
+}
+
+type InvitationRedemption struct {
+	UserID     int       `json:"user_id" db:"user_id"`
+	Username   string    `json:"username" db:"username"`
+	Email      string    `json:"email" db:"email"`
+	RedeemedAt time.Time `json:"redeemed_at" db:"redeemed_at"`
+}
+
+type CreateInvitationRequest struct {
+	Code           string `form:"code" json:"code"`                         // 为空则自动生成
+	MaxUses        int    `form:"max_uses" json:"max_uses" binding:"min=0"` // 0 表示默认 1 次
+	ExpiresInHours int    `form:"expires_in_hours" json:"expires_in_hours" binding:"min=0"`
+	Role           string `form:"role" json:"role"`
+	Cohort         string `form:"cohort" json:"cohort"`
+	Note           string `form:"note" json:"note"`
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+)
+
+// 学习计划中课程的状态
+const (
+	PlanStatusPlanned    = "planned"     // 计划修读
+	PlanStatusInProgress = "in_progress" // 正在修读
+	PlanStatusCompleted  = "completed"   // 已修完
+)
+
+// PlanStatuses 全部学习计划状态
+var PlanStatuses = []string{PlanStatusPlanned, PlanStatusInProgress, PlanStatusCompleted}
+
+// ValidPlanStatus 判断学习计划状态是否有效
+func ValidPlanStatus(status string) bool {
+	for _, s := range PlanStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// LearningPlanItem 学习计划中的一门课程
+type LearningPlanItem struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"-" db:"user_id"`
+	CourseID   int       `json:"course_id" db:"course_id"`
+	CourseName string    `json:"course_name" db:"name"`
+	Credit     int       `json:"credit" db:"credit"`
+	Semester   string    `json:"semester" db:"semester"` // 计划修读的学期，和课程开课学期无关
+	SortOrder  int       `json:"order" db:"sort_order"`  // 同一学期内的顺序，从 1 开始
+	Status     string    `json:"status" db:"status"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// 课程章节学习进度
+	CompletedChapters int     `json:"completed_chapters"`
+	TotalChapters     int     `json:"total_chapters"`
+	Completion        float64 `json:"completion"` // 完成百分比
+}
+
+// LearningPlanSemester 学习计划中一个学期的课程和学分合计
+type LearningPlanSemester struct {
+	Semester  string             `json:"semester"`
+	Credits   int                `json:"credits"`
+	OverLimit bool               `json:"over_limit"` // 学分超过配置的上限
+	Courses   []LearningPlanItem `json:"courses"`
+}
+
+// LearningPlan 用户的学习计划
+type LearningPlan struct {
+	Semesters        []LearningPlanSemester `json:"semesters"`
+	TotalCredits     int                    `json:"total_credits"`
+	CompletedCredits int                    `json:"completed_credits"`
+	CreditLimit      int                    `json:"credit_limit"` // 每学期学分上限，0 表示不限制
+	Warnings         []string               `json:"warnings"`
+}
+
+type LearningPlanRequest struct {
+	Semester string `form:"semester" json:"semester" binding:"required,max=50"`
+	Status   string `form:"status" json:"status"` // 为空时新加入的课程为 planned，已有课程保持不变
+}
+
+// ReorderLearningPlanRequest 按给定顺序重排同一学期的课程
+type ReorderLearningPlanRequest struct {
+	Semester  string `form:"semester" json:"semester" binding:"required,max=50"`
+	CourseIDs []int  `form:"course_ids" json:"course_ids" binding:"required,min=1"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+)
+
+// UserMFA 用户的 TOTP 两步验证配置，EnabledAt 为空表示已生成密钥但尚未确认启用
+type UserMFA struct {
+	UserID      int        `json:"user_id" db:"user_id"`
+	Secret      string     `json:"-" db:"secret"`
+	LastCounter int64      `json:"-" db:"last_counter"` // 最近一次使用的时间步，防止验证码重放
+	EnabledAt   *time.Time `json:"enabled_at" db:"enabled_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// MFAStatus 两步验证状态
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	Required               bool       `json:"required"` // 当前角色是否被要求启用
+}
+
+// MFASetup 开始绑定时返回的密钥和 otpauth 链接（前端渲染为二维码）
+type MFASetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// LoginResult 登录结果：未启用两步验证时直接返回令牌对，否则返回待验证令牌
+type LoginResult struct {
+	Tokens           *TokenPair
+	MFARequired      bool
+	MFAToken         string
+	MFAExpiresIn     int64
+	MFASetupRequired bool // 角色要求两步验证但尚未启用
+}
+
+// MFALoginRequest 登录第二步，验证码和恢复码二选一
+type MFALoginRequest struct {
+	MFAToken     string `form:"mfa_token" json:"mfa_token" binding:"required"`
+	Code         string `form:"code" json:"code"`
+	RecoveryCode string `form:"recovery_code" json:"recovery_code"`
+}
+
+type MFACodeRequest struct {
+	Code string `form:"code" json:"code" binding:"required"`
+}
+
+type MFADisableRequest struct {
+	Password string `form:"password" json:"password" binding:"required"`
+	Code     string `form:"code" json:"code"`
+	// RecoveryCode 丢失验证器时可使用恢复码关闭
+	RecoveryCode string `form:"recovery_code" json:"recovery_code"`
+}
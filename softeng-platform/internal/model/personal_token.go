@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+)
+
+// PersonalTokenPrefix 个人访问令牌前缀，用于和 JWT 区分
+const PersonalTokenPrefix = "pat_"
+
+// 个人访问令牌授权范围
+const (
+	ScopeRead    = "read"    // 读取需要登录的接口
+	ScopeSubmit  = "submit"  // 提交/更新工具、课程、项目及上传图片
+	ScopeComment = "comment" // 发表/删除评论和回复
+)
+
+// Scopes 全部可用的授权范围
+var Scopes = []string{ScopeRead, ScopeSubmit, ScopeComment}
+
+type PersonalToken struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	Prefix     string     `json:"prefix" db:"token_prefix"` // 令牌开头几位，便于用户辨认
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// HasScope 判断令牌是否包含指定授权范围
+func (t *PersonalToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type CreatePersonalTokenRequest struct {
+	Name          string   `form:"name" json:"name" binding:"required,max=100"`
+	Scopes        []string `form:"scopes" json:"scopes" binding:"required,min=1,dive,oneof=read submit comment"`
+	ExpiresInDays int      `form:"expires_in_days" json:"expires_in_days" binding:"min=0,max=365"` // 0 表示默认 90 天
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 课程之间的修读关系
+const (
+	RelationPrerequisite = "prerequisite" // 先修：必须在更早的学期修读
+	RelationCorequisite  = "corequisite"  // 同修：在同一学期或更早修读
+)
+
+// ValidRelation 判断修读关系类型是否有效
+func ValidRelation(relation string) bool {
+	return relation == RelationPrerequisite || relation == RelationCorequisite
+}
+
+// CoursePrerequisite 课程 CourseID 对课程 RequiredID 的先修或同修要求
+type CoursePrerequisite struct {
+	RelationID   int       `json:"relation_id" db:"relation_id"`
+	CourseID     int       `json:"course_id" db:"course_id"`
+	CourseName   string    `json:"course_name"`
+	RequiredID   int       `json:"required_course_id" db:"required_course_id"`
+	RequiredName string    `json:"required_course_name"`
+	Relation     string    `json:"relation" db:"relation_type"`
+	Note         string    `json:"note" db:"note"`
+	Status       string    `json:"status" db:"status"`
+	SubmitterID  int       `json:"-" db:"submitter_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+type SubmitPrerequisiteRequest struct {
+	RequiredCourseID int    `form:"required_course_id" json:"required_course_id" binding:"required,min=1"`
+	Relation         string `form:"relation" json:"relation"` // 为空时为 prerequisite
+	Note             string `form:"note" json:"note" binding:"max=500"`
+}
+
+// CurriculumNode 课程关系图中的课程
+type CurriculumNode struct {
+	CourseID int    `json:"course_id"`
+	Name     string `json:"name"`
+	Semester string `json:"semester"`
+	Credit   int    `json:"credit"`
+}
+
+// CurriculumEdge 课程关系图中的边，From 需要在 To 之前（同修时不晚于 To）修读
+type CurriculumEdge struct {
+	From     int    `json:"from"`
+	To       int    `json:"to"`
+	Relation string `json:"relation"`
+}
+
+// CurriculumGraph 全部审核通过的课程和修读关系，Cycles 为检测到的先修环
+type CurriculumGraph struct {
+	Nodes  []CurriculumNode `json:"nodes"`
+	Edges  []CurriculumEdge `json:"edges"`
+	Cycles [][]int          `json:"cycles"`
+}
+
+// PrerequisiteNode 间接先修课程，Depth 为经过的先修关系数，Path 为从该课程到目标课程的一条最短路径
+type PrerequisiteNode struct {
+	CourseID int    `json:"course_id"`
+	Name     string `json:"name"`
+	Depth    int    `json:"depth"`
+	Path     []int  `json:"path"`
+}
+
+// CoursePrerequisites 课程的先修、同修要求和依赖它的课程
+type CoursePrerequisites struct {
+	CourseID      int                  `json:"course_id"`
+	Name          string               `json:"name"`
+	Prerequisites []CoursePrerequisite `json:"prerequisites"` // 直接先修
+	Corequisites  []CoursePrerequisite `json:"corequisites"`  // 直接同修
+	Transitive    []PrerequisiteNode   `json:"transitive"`    // 全部先修（含间接），按层级排列
+	RequiredBy    []CoursePrerequisite `json:"required_by"`   // 以该课程为先修或同修的课程
+	Cycles        [][]int              `json:"cycles"`        // 从该课程出发能到达的先修环
+}
+
+// PrerequisiteCheckSemester 待检查的一个学期及其课程
+type PrerequisiteCheckSemester struct {
+	Semester  string `json:"semester" binding:"required,max=50"`
+	CourseIDs []int  `json:"course_ids"`
+}
+
+// PrerequisiteCheckRequest 按时间顺序排列的修读安排
+type PrerequisiteCheckRequest struct {
+	Semesters []PrerequisiteCheckSemester `json:"semesters" binding:"required,min=1,dive"`
+}
+
+// 修读安排的问题类型
+const (
+	CheckProblemLater        = "later"         // 先修 / 同修课程安排在更晚的学期
+	CheckProblemSameSemester = "same_semester" // 先修课程安排在同一学期
+	CheckProblemMissing      = "missing"       // 先修 / 同修课程不在安排中（可能已修过，只作提醒）
+)
+
+// PrerequisiteIssue 修读安排中的一个问题
+type PrerequisiteIssue struct {
+	CourseID         int    `json:"course_id"`
+	CourseName       string `json:"course_name"`
+	Semester         string `json:"semester"`
+	RequiredID       int    `json:"required_course_id"`
+	RequiredName     string `json:"required_course_name"`
+	RequiredSemester string `json:"required_semester"` // 不在安排中时为空
+	Relation         string `json:"relation"`
+	Problem          string `json:"problem"`
+}
+
+// PrerequisiteCheckResult 检查结果，Valid 只取决于 Issues，Warnings 为不在安排中的先修课程
+type PrerequisiteCheckResult struct {
+	Valid    bool                `json:"valid"`
+	Issues   []PrerequisiteIssue `json:"issues"`
+	Warnings []PrerequisiteIssue `json:"warnings"`
+}
+
+// DOT 将课程关系图输出为 Graphviz DOT 格式：箭头从先修课程指向后续课程，同修关系为虚线，先修环上的边标红
+func (g *CurriculumGraph) DOT() string {
+	inCycle := make(map[[2]int]bool)
+	for _, cycle := range g.Cycles {
+		// 环按"课程 -> 其先修课程"的顺序排列，与图中箭头方向相反
+		for i := 0; i+1 < len(cycle); i++ {
+			inCycle[[2]int{cycle[i+1], cycle[i]}] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph curriculum {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, node := range g.Nodes {
+		label := node.Name
+		if node.Semester != "" {
+			label += "\n" + node.Semester
+		}
+		fmt.Fprintf(&b, "  c%d [label=%s];\n", node.CourseID, dotQuote(label))
+	}
+	for _, edge := range g.Edges {
+		var attrs []string
+		if edge.Relation == RelationCorequisite {
+			attrs = append(attrs, "style=dashed", `label="同修"`)
+		}
+		if inCycle[[2]int{edge.From, edge.To}] {
+			attrs = append(attrs, "color=red")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "  c%d -> c%d [%s];\n", edge.From, edge.To, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "  c%d -> c%d;\n", edge.From, edge.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote 转义为 DOT 的双引号字符串
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
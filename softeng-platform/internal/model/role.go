@@ -0,0 +1,49 @@
+package model
+
+// 角色
+const (
+	RoleUser         = "user"
+	RoleModerator    = "moderator"     // 版主：审核工具/项目、管理评论
+	RoleCourseEditor = "course_editor" // 课程编辑（助教）：审核课程内容、管理评论
+	RoleAdmin        = "admin"
+)
+
+// 权限
+const (
+	PermReviewTool      = "review:tool"
+	PermReviewCourse    = "review:course"
+	PermReviewProject   = "review:project"
+	PermCommentModerate = "comment:moderate"
+	PermUserManage      = "user:manage"
+)
+
+// Roles 按权限从低到高排列的全部角色
+var Roles = []string{RoleUser, RoleModerator, RoleCourseEditor, RoleAdmin}
+
+// RolePermissions 角色与权限的对应关系
+var RolePermissions = map[string][]string{
+	RoleUser:         {},
+	RoleModerator:    {PermReviewTool, PermReviewProject, PermCommentModerate},
+	RoleCourseEditor: {PermReviewCourse, PermCommentModerate},
+	RoleAdmin:        {PermReviewTool, PermReviewCourse, PermReviewProject, PermCommentModerate, PermUserManage},
+}
+
+// ValidRole 判断角色是否存在
+func ValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// HasPermission 判断角色是否拥有指定权限
+func HasPermission(role, permission string) bool {
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type AssignRoleRequest struct {
+	Role string `form:"role" json:"role" binding:"required"`
+}
@@ -0,0 +1,181 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 学期类型，学年从秋季学期开始
+const (
+	SemesterTermFall   = 1 // 秋季学期（第一学期）
+	SemesterTermSpring = 2 // 春季学期（第二学期）
+	SemesterTermSummer = 3 // 夏季学期（小学期）
+)
+
+// SemesterDateLayout 学期起止日期的格式
+const SemesterDateLayout = "2006-01-02"
+
+// Semester 学期，AcademicYear 为学年的起始年份（2024-2025 学年为 2024）
+type Semester struct {
+	SemesterID   int       `json:"semester_id" db:"semester_id"`
+	Name         string    `json:"name" db:"name"`
+	AcademicYear int       `json:"academic_year" db:"academic_year"`
+	Term         int       `json:"term" db:"term"`
+	StartDate    string    `json:"start_date" db:"start_date"` // YYYY-MM-DD，未设置时为空
+	EndDate      string    `json:"end_date" db:"end_date"`
+	CourseCount  int       `json:"course_count"` // 该学期开设的审核通过的课程数
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OrderKey 学期的先后顺序，数值越大越晚
+func (s Semester) OrderKey() int {
+	return SemesterOrderKey(s.AcademicYear, s.Term)
+}
+
+// SemesterOrderKey 学期的先后顺序，与 SQL 中的 academic_year * 10 + term 一致
+func SemesterOrderKey(academicYear, term int) int {
+	return academicYear*10 + term
+}
+
+// CourseOffering 课程在某个学期的一次开课
+type CourseOffering struct {
+	OfferingID int          `json:"offering_id" db:"offering_id"`
+	CourseID   int          `json:"course_id" db:"course_id"`
+	SemesterID int          `json:"semester_id" db:"semester_id"`
+	Semester   string       `json:"semester"`
+	Teachers   []TeacherRef `json:"teachers"`
+}
+
+// TeacherRef 教师ID和姓名
+type TeacherRef struct {
+	TeacherID int    `json:"teacher_id"`
+	Name      string `json:"name"`
+}
+
+// SemesterFilter 课程列表的学期筛选：IDs 为指定学期，FromID / ToID 为学期范围的两端（含，0 表示不限），
+// Name 为学期名称，兼容尚未映射到学期的旧数据
+type SemesterFilter struct {
+	IDs    []int
+	FromID int
+	ToID   int
+	Name   string
+}
+
+// Empty 判断是否没有学期筛选条件
+func (f SemesterFilter) Empty() bool {
+	return len(f.IDs) == 0 && f.FromID == 0 && f.ToID == 0 && f.Name == ""
+}
+
+type CreateSemesterRequest struct {
+	AcademicYear int    `form:"academic_year" json:"academic_year" binding:"required,min=1990,max=2100"`
+	Term         int    `form:"term" json:"term" binding:"required,oneof=1 2 3"`
+	Name         string `form:"name" json:"name" binding:"max=50"` // 为空时按学年和学期生成
+	StartDate    string `form:"start_date" json:"start_date"`
+	EndDate      string `form:"end_date" json:"end_date"`
+}
+
+// UpdateSemesterRequest 修改学期名称和起止日期，学年和学期类型不能修改
+type UpdateSemesterRequest struct {
+	Name      string `form:"name" json:"name" binding:"required,max=50"`
+	StartDate string `form:"start_date" json:"start_date"`
+	EndDate   string `form:"end_date" json:"end_date"`
+}
+
+// AddOfferingRequest 为课程添加一次开课，教师按姓名或别名关联
+type AddOfferingRequest struct {
+	SemesterID int      `form:"semester_id" json:"semester_id" binding:"required,min=1"`
+	Teachers   []string `form:"teachers" json:"teachers"`
+}
+
+// SemesterName 学期的规范名称：秋季学期使用学年起始年份，春季和夏季学期使用下一年，如 2024秋季、2025春季
+func SemesterName(academicYear, term int) string {
+	switch term {
+	case SemesterTermFall:
+		return fmt.Sprintf("%d秋季", academicYear)
+	case SemesterTermSpring:
+		return fmt.Sprintf("%d春季", academicYear+1)
+	case SemesterTermSummer:
+		return fmt.Sprintf("%d夏季", academicYear+1)
+	}
+	return ""
+}
+
+var (
+	semesterYearPattern    = regexp.MustCompile(`(?:19|20)\d{2}`)
+	semesterFallPattern    = regexp.MustCompile(`(?i)秋|fall|autumn`)
+	semesterSpringPattern  = regexp.MustCompile(`(?i)春|spring`)
+	semesterSummerPattern  = regexp.MustCompile(`(?i)夏|summer|小学期`)
+	semesterOrdinalPattern = regexp.MustCompile(`第\s*([一二三123])\s*学期|[-_ ]([123])$`)
+)
+
+// ParseSemester 从自由文本中识别学期，返回学年起始年份和学期类型：
+// "2024秋"、"Fall 2024"、"2024-Fall" 为 2024 学年秋季学期，"2025春季" 为 2024 学年春季学期，
+// "2024-2025学年第二学期"、"2024-2025-2" 按学年和序号识别；"大三上" 等相对学期无法识别。
+// 规则与 database/migration_add_semesters.sql 中的迁移语句一致
+func ParseSemester(text string) (int, int, bool) {
+	text = strings.TrimSpace(text)
+	years := semesterYearPattern.FindAllString(text, 2)
+	if len(years) == 0 {
+		return 0, 0, false
+	}
+	year, _ := strconv.Atoi(years[0])
+	span := len(years) == 2 // 写明了学年的两个年份
+
+	term := 0
+	switch {
+	case semesterFallPattern.MatchString(text):
+		term = SemesterTermFall
+	case semesterSpringPattern.MatchString(text):
+		term = SemesterTermSpring
+	case semesterSummerPattern.MatchString(text):
+		term = SemesterTermSummer
+	case span:
+		if m := semesterOrdinalPattern.FindStringSubmatch(text); m != nil {
+			switch m[1] + m[2] {
+			case "一", "1":
+				term = SemesterTermFall
+			case "二", "2":
+				term = SemesterTermSpring
+			case "三", "3":
+				term = SemesterTermSummer
+			}
+		}
+	}
+	if term == 0 {
+		return 0, 0, false
+	}
+
+	// 只写了一个年份时为日历年，春季和夏季学期属于上一学年
+	if !span && term != SemesterTermFall {
+		year--
+	}
+	return year, term, true
+}
+
+// NormalizeSemester 能识别的学期返回规范名称，否则返回去除首尾空白的原文
+func NormalizeSemester(text string) string {
+	if year, term, ok := ParseSemester(text); ok {
+		return SemesterName(year, term)
+	}
+	return strings.TrimSpace(text)
+}
+
+// CompareSemesters 比较两个学期写法的先后，返回负数、0 或正数；能识别的学期按学年和学期类型排序，
+// 并排在无法识别的写法之前，无法识别的写法按原文排序
+func CompareSemesters(a, b string) int {
+	yearA, termA, okA := ParseSemester(a)
+	yearB, termB, okB := ParseSemester(b)
+	switch {
+	case okA && okB:
+		return SemesterOrderKey(yearA, termA) - SemesterOrderKey(yearB, termB)
+	case okA:
+		return -1
+	case okB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
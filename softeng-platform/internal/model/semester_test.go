@@ -0,0 +1,81 @@
+package model
+
+import "testing"
+
+func TestParseSemester(t *testing.T) {
+	tests := []struct {
+		text     string
+		wantYear int
+		wantTerm int
+		wantOK   bool
+		wantName string
+	}{
+		{"2024秋", 2024, SemesterTermFall, true, "2024秋季"},
+		{" 2024秋季 ", 2024, SemesterTermFall, true, "2024秋季"},
+		{"Fall 2024", 2024, SemesterTermFall, true, "2024秋季"},
+		{"2024-Fall", 2024, SemesterTermFall, true, "2024秋季"},
+		{"autumn 2024", 2024, SemesterTermFall, true, "2024秋季"},
+		{"2025春季", 2024, SemesterTermSpring, true, "2025春季"},
+		{"Spring 2025", 2024, SemesterTermSpring, true, "2025春季"},
+		{"2025夏", 2024, SemesterTermSummer, true, "2025夏季"},
+		{"2025小学期", 2024, SemesterTermSummer, true, "2025夏季"},
+		{"2024-2025学年第一学期", 2024, SemesterTermFall, true, "2024秋季"},
+		{"2024-2025学年第二学期", 2024, SemesterTermSpring, true, "2025春季"},
+		{"2024-2025学年第 3 学期", 2024, SemesterTermSummer, true, "2025夏季"},
+		{"2024-2025-2", 2024, SemesterTermSpring, true, "2025春季"},
+		{"2024-2025 春", 2024, SemesterTermSpring, true, "2025春季"},
+		// 只有一个年份时序号无法确定学年
+		{"2024-2", 0, 0, false, "2024-2"},
+		{"2024", 0, 0, false, "2024"},
+		{"大三上", 0, 0, false, "大三上"},
+		{"", 0, 0, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			year, term, ok := ParseSemester(tt.text)
+			if year != tt.wantYear || term != tt.wantTerm || ok != tt.wantOK {
+				t.Errorf("ParseSemester(%q) = %d, %d, %v, want %d, %d, %v", tt.text, year, term, ok, tt.wantYear, tt.wantTerm, tt.wantOK)
+			}
+			if got := NormalizeSemester(tt.text); got != tt.wantName {
+				t.Errorf("NormalizeSemester(%q) = %q, want %q", tt.text, got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestCompareSemesters(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int // 只比较符号
+	}{
+		{"2024春季", "2024夏季", -1},
+		{"2024夏季", "2024秋季", -1},
+		{"2024秋季", "2025春季", -1},
+		{"Fall 2024", "2024秋", 0},
+		{"2024-2025学年第二学期", "2024秋季", 1},
+		{"2030春季", "大三上", -1},
+		{"大三上", "大三下", -1},
+		{"大三上", "大三上", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			got := CompareSemesters(tt.a, tt.b)
+			if sign(got) != tt.want {
+				t.Errorf("CompareSemesters(%q, %q) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+			}
+			if sign(CompareSemesters(tt.b, tt.a)) != -tt.want {
+				t.Errorf("CompareSemesters(%q, %q) is not antisymmetric", tt.b, tt.a)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
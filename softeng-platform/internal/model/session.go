@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// Session 登录会话（设备），与一个刷新令牌家族一一对应
+type Session struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	FamilyID   string     `json:"-" db:"family_id"`
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IP         string     `json:"ip" db:"ip"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	MFA        bool       `json:"mfa" db:"mfa_verified"` // 登录时是否通过了两步验证
+	Current    bool       `json:"current"`               // 是否为发起请求的会话
+}
+
+// ClientInfo 发起登录/刷新请求的客户端信息
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Teacher 教师，Name 为规范写法，Aliases 为同一教师的其他写法
+type Teacher struct {
+	TeacherID   int       `json:"teacher_id" db:"teacher_id"`
+	Name        string    `json:"name" db:"name"`
+	Title       string    `json:"title" db:"title"` // 职称
+	Department  string    `json:"department" db:"department"`
+	Email       string    `json:"email" db:"email"`
+	Homepage    string    `json:"homepage" db:"homepage"`
+	Avatar      string    `json:"avatar" db:"avatar"`
+	Bio         string    `json:"bio" db:"bio"`
+	Aliases     []string  `json:"aliases"`
+	CourseCount int       `json:"course_count"` // 讲授的审核通过的课程数
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TeacherCourse 教师讲授的一门课程，Rating 只统计对该教师的评分
+type TeacherCourse struct {
+	CourseID    int           `json:"course_id"`
+	Name        string        `json:"name"`
+	Semester    string        `json:"semester"`
+	Credit      int           `json:"credit"`
+	Cover       string        `json:"cover"`
+	Views       int           `json:"views"`
+	Likes       int           `json:"likes"`
+	Collections int           `json:"collections"`
+	Rating      RatingSummary `json:"rating"`
+}
+
+// TeacherSemester 教师在一个学期讲授的课程
+type TeacherSemester struct {
+	Semester string          `json:"semester"`
+	Courses  []TeacherCourse `json:"courses"`
+}
+
+// TeacherStats 教师所有课程的汇总数据
+type TeacherStats struct {
+	CourseCount      int           `json:"course_count"`
+	SemesterCount    int           `json:"semester_count"`
+	TotalCredits     int           `json:"total_credits"`
+	TotalViews       int           `json:"total_views"`
+	TotalLikes       int           `json:"total_likes"`
+	TotalCollections int           `json:"total_collections"`
+	Rating           RatingSummary `json:"rating"`
+}
+
+// TeacherDetail 教师详情：资料、按学期分组的课程和汇总数据
+type TeacherDetail struct {
+	Teacher
+	Semesters []TeacherSemester `json:"semesters"`
+	Stats     TeacherStats      `json:"stats"`
+}
+
+// UpdateTeacherRequest 修改教师资料，未提供 Aliases 时别名保持不变，提供时整体替换
+type UpdateTeacherRequest struct {
+	Name       string   `form:"name" json:"name" binding:"required,max=100"`
+	Title      string   `form:"title" json:"title" binding:"max=100"`
+	Department string   `form:"department" json:"department" binding:"max=255"`
+	Email      string   `form:"email" json:"email" binding:"omitempty,email,max=255"`
+	Homepage   string   `form:"homepage" json:"homepage" binding:"omitempty,url,max=500"`
+	Avatar     string   `form:"avatar" json:"avatar"`
+	Bio        string   `form:"bio" json:"bio" binding:"max=2000"`
+	Aliases    []string `form:"aliases" json:"aliases"`
+}
+
+// MergeTeacherRequest 将另一位教师（同一人的不同写法）合并到当前教师
+type MergeTeacherRequest struct {
+	SourceID int `form:"source_id" json:"source_id" binding:"required,min=1"`
+}
+
+// TeacherNameKey 教师姓名的匹配键：去掉全部空白，用于识别同一姓名的不同写法
+func TeacherNameKey(name string) string {
+	return strings.Join(strings.Fields(name), "")
+}
+
+// NormalizeTeacherName 去除首尾空白并合并连续空白，作为保存的写法
+func NormalizeTeacherName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
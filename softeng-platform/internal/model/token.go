@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，同一次登录轮换出的令牌属于同一家族（FamilyID）
+type RefreshToken struct {
+	ID              int        `json:"id" db:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	FamilyID        string     `json:"family_id" db:"family_id"`
+	TokenHash       string     `json:"-" db:"token_hash"`
+	AccessJTI       string     `json:"-" db:"access_jti"`        // 同时签发的访问令牌ID
+	AccessExpiresAt time.Time  `json:"-" db:"access_expires_at"` // 同时签发的访问令牌过期时间
+	ExpiresAt       time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt          *time.Time `json:"used_at" db:"used_at"`
+	RevokedAt       *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TokenPair 登录/刷新返回的令牌对
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // 访问令牌剩余有效秒数
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `form:"refresh_token" json:"refresh_token" binding:"required"`
+}
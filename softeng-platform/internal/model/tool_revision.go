@@ -0,0 +1,211 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 工具修改提议的审核状态
+const (
+	ToolRevisionStatusPending  = "pending"  // 待审核
+	ToolRevisionStatusApproved = "approved" // 审核通过，修改已应用到工具
+	ToolRevisionStatusRejected = "rejected" // 审核驳回
+)
+
+// 工具修改提议可以编辑的字段，与工具详情中的字段名一致
+const (
+	ToolFieldName              = "name"
+	ToolFieldLink              = "link"
+	ToolFieldDescription       = "description"
+	ToolFieldDescriptionDetail = "description_detail"
+	ToolFieldCategory          = "catagory"
+	ToolFieldTags              = "tags"
+	ToolFieldImages            = "image"
+)
+
+// ToolFields 可编辑字段，差异按此顺序排列
+var ToolFields = []string{
+	ToolFieldName, ToolFieldLink, ToolFieldDescription, ToolFieldDescriptionDetail,
+	ToolFieldCategory, ToolFieldTags, ToolFieldImages,
+}
+
+// isToolListField 标签和图片为字符串列表，其余字段为文本
+func isToolListField(field string) bool {
+	return field == ToolFieldTags || field == ToolFieldImages
+}
+
+// ToolContent 工具中可以通过修改提议编辑的内容；标签由 NormalizeToolTags 整理，图片按展示顺序排列
+type ToolContent struct {
+	Name              string   `json:"name"`
+	Link              string   `json:"link"`
+	Description       string   `json:"description"`
+	DescriptionDetail string   `json:"description_detail"`
+	Category          string   `json:"catagory"`
+	Tags              []string `json:"tags"`
+	Images            []string `json:"image"`
+	Approved          bool     `json:"-"` // 工具是否已审核通过，不属于可编辑的字段
+}
+
+// NormalizeToolTags 去除首尾空白、空标签和重复标签并排序。tool_tags 按不区分大小写的排序规则比较，
+// 只有大小写不同的标签在数据库中是同一个，只保留第一个；排序也忽略大小写，
+// 提议的标签和数据库中读出的标签都经过它整理，比较结果不受数据库排序规则影响
+func NormalizeToolTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i]) < strings.ToLower(result[j])
+	})
+	return result
+}
+
+// Value 返回字段的值，文本字段为 string，标签和图片为 []string
+func (c *ToolContent) Value(field string) interface{} {
+	switch field {
+	case ToolFieldName:
+		return c.Name
+	case ToolFieldLink:
+		return c.Link
+	case ToolFieldDescription:
+		return c.Description
+	case ToolFieldDescriptionDetail:
+		return c.DescriptionDetail
+	case ToolFieldCategory:
+		return c.Category
+	case ToolFieldTags:
+		return c.Tags
+	case ToolFieldImages:
+		return c.Images
+	}
+	return nil
+}
+
+// SetValue 设置字段的值，值的类型与 Value 相同
+func (c *ToolContent) SetValue(field string, value interface{}) {
+	text, _ := value.(string)
+	list, _ := value.([]string)
+	switch field {
+	case ToolFieldName:
+		c.Name = text
+	case ToolFieldLink:
+		c.Link = text
+	case ToolFieldDescription:
+		c.Description = text
+	case ToolFieldDescriptionDetail:
+		c.DescriptionDetail = text
+	case ToolFieldCategory:
+		c.Category = text
+	case ToolFieldTags:
+		c.Tags = list
+	case ToolFieldImages:
+		c.Images = list
+	}
+}
+
+// ToolFieldChange 修改提议中一个字段的差异，Before 为提议时工具的值，After 为提议的值
+type ToolFieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// UnmarshalJSON 按字段类型解析 Before 和 After，使其与 ToolContent.Value 的类型一致
+func (c *ToolFieldChange) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Field  string          `json:"field"`
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	c.Field = raw.Field
+	decode := func(data json.RawMessage) (interface{}, error) {
+		if isToolListField(raw.Field) {
+			list := []string{}
+			if len(data) > 0 {
+				err := json.Unmarshal(data, &list)
+				return list, err
+			}
+			return list, nil
+		}
+		text := ""
+		if len(data) > 0 {
+			err := json.Unmarshal(data, &text)
+			return text, err
+		}
+		return text, nil
+	}
+	var err error
+	if c.Before, err = decode(raw.Before); err != nil {
+		return err
+	}
+	c.After, err = decode(raw.After)
+	return err
+}
+
+// SameToolValue 判断两个字段值是否相同，空列表与 nil 视为相同
+func SameToolValue(a, b interface{}) bool {
+	listA, okA := a.([]string)
+	listB, okB := b.([]string)
+	if okA || okB {
+		if len(listA) != len(listB) {
+			return false
+		}
+		for i := range listA {
+			if listA[i] != listB[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// DiffToolContent 返回从 before 修改为 after 的字段级差异
+func DiffToolContent(before, after *ToolContent) []ToolFieldChange {
+	changes := make([]ToolFieldChange, 0)
+	for _, field := range ToolFields {
+		old, value := before.Value(field), after.Value(field)
+		if !SameToolValue(old, value) {
+			changes = append(changes, ToolFieldChange{Field: field, Before: old, After: value})
+		}
+	}
+	return changes
+}
+
+// ToolRevision 工具修改提议，审核通过后一次性应用到工具，提议者成为工具贡献者
+type ToolRevision struct {
+	RevisionID   int               `json:"revision_id" db:"revision_id"`
+	ToolID       int               `json:"tool_id" db:"tool_id"`
+	EditorID     int               `json:"-" db:"editor_id"`
+	Editor       string            `json:"editor"`
+	Summary      string            `json:"summary" db:"summary"`
+	Changes      []ToolFieldChange `json:"changes" db:"changes"`
+	Status       string            `json:"status" db:"status"`
+	RejectReason string            `json:"reject_reason,omitempty" db:"reject_reason"`
+	AuditTime    *time.Time        `json:"audit_time" db:"audit_time"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+}
+
+// ToolEditRequest 工具修改提议，未提供的字段保持不变；tags、image 提供时整体替换（JSON 中传空数组表示清空）
+type ToolEditRequest struct {
+	Name              *string  `form:"name" json:"name"`
+	Link              *string  `form:"link" json:"link"`
+	Description       *string  `form:"description" json:"description"`
+	DescriptionDetail *string  `form:"description_detail" json:"description_detail"`
+	Category          *string  `form:"catagory" json:"catagory"`
+	Tags              []string `form:"tags" json:"tags"`
+	Images            []string `form:"image" json:"image"`
+	Summary           string   `form:"summary" json:"summary" binding:"max=255"` // 修改说明
+}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSameToolValue(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{"same text", "GitHub", "GitHub", true},
+		{"different text", "GitHub", "Github", false},
+		{"empty text", "", "", true},
+		{"same list", []string{"git", "vcs"}, []string{"git", "vcs"}, true},
+		{"list order matters", []string{"git", "vcs"}, []string{"vcs", "git"}, false},
+		{"different length", []string{"git"}, []string{"git", "vcs"}, false},
+		{"nil and empty list", []string(nil), []string{}, true},
+		{"nil list and empty list value", []string{}, nil, true},
+		{"text and list", "git", []string{"git"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameToolValue(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameToolValue(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffToolContent(t *testing.T) {
+	base := ToolContent{
+		Name:        "GitHub",
+		Link:        "https://github.com",
+		Description: "代码托管",
+		Category:    "开发工具",
+		Tags:        []string{"git", "vcs"},
+		Images:      []string{"/uploads/a.png"},
+	}
+	tests := []struct {
+		name   string
+		edit   func(c *ToolContent)
+		fields []string
+	}{
+		{"unchanged", func(c *ToolContent) {}, nil},
+		{"empty text set again", func(c *ToolContent) { c.DescriptionDetail = "" }, nil},
+		{"text fields", func(c *ToolContent) { c.Link = "https://github.com/"; c.Name = "Github" }, []string{ToolFieldName, ToolFieldLink}},
+		{"cleared images", func(c *ToolContent) { c.Images = []string{} }, []string{ToolFieldImages}},
+		{"reordered tags", func(c *ToolContent) { c.Tags = []string{"vcs", "git"} }, []string{ToolFieldTags}},
+		{"all fields in order", func(c *ToolContent) {
+			*c = ToolContent{Name: "a", Link: "b", Description: "c", DescriptionDetail: "d", Category: "e", Tags: []string{"f"}}
+		}, ToolFields},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := base
+			after := base
+			after.Tags = append([]string(nil), base.Tags...)
+			after.Images = append([]string(nil), base.Images...)
+			tt.edit(&after)
+
+			changes := DiffToolContent(&before, &after)
+			if len(changes) != len(tt.fields) {
+				t.Fatalf("changes = %+v, want fields %v", changes, tt.fields)
+			}
+			for i, change := range changes {
+				if change.Field != tt.fields[i] {
+					t.Errorf("change %d field = %q, want %q", i, change.Field, tt.fields[i])
+				}
+				if !SameToolValue(change.Before, before.Value(change.Field)) || !SameToolValue(change.After, after.Value(change.Field)) {
+					t.Errorf("change %d = %+v", i, change)
+				}
+			}
+
+			// 保存到数据库的 JSON 解析后类型与 ToolContent.Value 一致，可以再次应用
+			data, err := json.Marshal(changes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded []ToolFieldChange
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			applied := before
+			for _, change := range decoded {
+				applied.SetValue(change.Field, change.After)
+			}
+			if diff := DiffToolContent(&applied, &after); len(diff) != 0 {
+				t.Errorf("applying decoded changes leaves %+v", diff)
+			}
+		})
+	}
+}
+
+func TestToolFieldChangeUnmarshalNull(t *testing.T) {
+	var change ToolFieldChange
+	if err := json.Unmarshal([]byte(`{"field": "tags", "before": null, "after": ["git"]}`), &change); err != nil {
+		t.Fatal(err)
+	}
+	if before, ok := change.Before.([]string); !ok || len(before) != 0 {
+		t.Errorf("before = %#v, want empty list", change.Before)
+	}
+	if _, ok := change.After.([]string); !ok {
+		t.Errorf("after = %#v, want []string", change.After)
+	}
+}
+
+func TestNormalizeToolTags(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{"empty", nil, []string{}},
+		{"trim and drop empty", []string{" git ", "", "  "}, []string{"git"}},
+		{"sorted ignoring case", []string{"vcs", "Git", "api"}, []string{"api", "Git", "vcs"}},
+		{"case variants merged", []string{"Git", "git", "GIT"}, []string{"Git"}},
+		{"same result in any order", []string{"git", "API", "Vcs"}, []string{"API", "git", "Vcs"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeToolTags(tt.in)
+			if got == nil || !SameToolValue(got, tt.want) {
+				t.Errorf("NormalizeToolTags(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ type User struct {
 	Description string    `json:"description" db:"description"`
 	FacePhoto   string    `json:"face_photo" db:"face_photo"`
 	Role        string    `json:"role" db:"role"`
+	Cohort      string    `json:"cohort" db:"cohort"` // 注册时由邀请码分配的届别/班级
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -21,7 +22,7 @@ type User struct {
 type RegisterRequest struct {
 	Username        string `form:"username" json:"username" binding:"required"`
 	Email           string `form:"email" json:"email" binding:"required,email"`
-	Password        string `form:"password" json:"password" binding:"required,min=6"`
+	Password        string `form:"password" json:"password" binding:"required"` // 密码规则由 utils.PasswordPolicy 校验
 	EmailPassword   string `form:"email_password" json:"email_password" binding:"required"`
 	CertifyPassword string `form:"certify_password" json:"certify_password" binding:"required"`
 }
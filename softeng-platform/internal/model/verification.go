@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+)
+
+// 验证码用途
+const (
+	CodePurposeRegister    = "register"
+	CodePurposeReset       = "reset"
+	CodePurposeChangeEmail = "change-email"
+)
+
+type VerificationCode struct {
+	ID          int        `json:"id" db:"id"`
+	Email       string     `json:"email" db:"email"`
+	Purpose     string     `json:"purpose" db:"purpose"`
+	CodeHash    string     `json:"-" db:"code_hash"`
+	Attempts    int        `json:"attempts" db:"attempts"`
+	MaxAttempts int        `json:"max_attempts" db:"max_attempts"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	ConsumedAt  *time.Time `json:"consumed_at" db:"consumed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type SendCodeRequest struct {
+	Email   string `form:"email" json:"email" binding:"required,email"`
+	Purpose string `form:"purpose" json:"purpose" binding:"required,oneof=register reset change-email"`
+}
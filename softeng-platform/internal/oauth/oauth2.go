@@ -0,0 +1,246 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"softeng-platform/internal/config"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// githubEmailsURL GitHub 用户未公开邮箱时需要单独查询
+const githubEmailsURL = "https://api.github.com/user/emails"
+
+// oauth2Provider 通用 OAuth2/OIDC 提供方
+type oauth2Provider struct {
+	cfg        config.OAuthProvider
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	discovered bool
+}
+
+func newOAuth2Provider(cfg config.OAuthProvider, httpClient *http.Client) *oauth2Provider {
+	if cfg.Type == "github" {
+		if cfg.AuthURL == "" {
+			cfg.AuthURL = "https://github.com/login/oauth/authorize"
+		}
+		if cfg.TokenURL == "" {
+			cfg.TokenURL = "https://github.com/login/oauth/access_token"
+		}
+		if cfg.UserInfoURL == "" {
+			cfg.UserInfoURL = "https://api.github.com/user"
+		}
+	}
+	return &oauth2Provider{cfg: cfg, httpClient: httpClient}
+}
+
+func (p *oauth2Provider) Name() string {
+	return p.cfg.Name
+}
+
+// endpoints 返回授权、令牌、用户信息端点；OIDC 提供方首次使用时通过 discovery 补全未配置的端点
+func (p *oauth2Provider) endpoints(ctx context.Context) (string, string, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cfg.Type == "oidc" && !p.discovered && p.cfg.Issuer != "" &&
+		(p.cfg.AuthURL == "" || p.cfg.TokenURL == "" || p.cfg.UserInfoURL == "") {
+		var doc struct {
+			AuthorizationEndpoint string `json:"authorization_endpoint"`
+			TokenEndpoint         string `json:"token_endpoint"`
+			UserinfoEndpoint      string `json:"userinfo_endpoint"`
+		}
+		discoveryURL := strings.TrimRight(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := p.getJSON(ctx, discoveryURL, "", &doc); err != nil {
+			return "", "", "", fmt.Errorf("failed to discover oidc endpoints: %w", err)
+		}
+		if p.cfg.AuthURL == "" {
+			p.cfg.AuthURL = doc.AuthorizationEndpoint
+		}
+		if p.cfg.TokenURL == "" {
+			p.cfg.TokenURL = doc.TokenEndpoint
+		}
+		if p.cfg.UserInfoURL == "" {
+			p.cfg.UserInfoURL = doc.UserinfoEndpoint
+		}
+		p.discovered = true
+	}
+
+	if p.cfg.AuthURL == "" || p.cfg.TokenURL == "" || p.cfg.UserInfoURL == "" {
+		return "", "", "", fmt.Errorf("oauth provider %s is not fully configured", p.cfg.Name)
+	}
+	return p.cfg.AuthURL, p.cfg.TokenURL, p.cfg.UserInfoURL, nil
+}
+
+func (p *oauth2Provider) AuthCodeURL(ctx context.Context, state, codeChallenge, redirectURL string) (string, error) {
+	authURL, _, _, err := p.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", redirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(authURL, "?") {
+		sep = "&"
+	}
+	return authURL + sep + params.Encode(), nil
+}
+
+func (p *oauth2Provider) Exchange(ctx context.Context, code, codeVerifier, redirectURL string) (string, error) {
+	_, tokenURL, _, err := p.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json") // GitHub 默认返回表单格式
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.doJSON(req, &token); err != nil {
+		return "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("failed to exchange authorization code: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("failed to exchange authorization code: empty access token")
+	}
+	return token.AccessToken, nil
+}
+
+func (p *oauth2Provider) UserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	_, _, userInfoURL, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.cfg.Type == "github" {
+		return p.githubUserInfo(ctx, userInfoURL, accessToken)
+	}
+
+	// 令牌通过服务端直连令牌端点获得，用户信息以 userinfo 端点为准
+	var claims struct {
+		Subject           string `json:"sub"`
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+		Picture           string `json:"picture"`
+	}
+	if err := p.getJSON(ctx, userInfoURL, accessToken, &claims); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("failed to get user info: missing subject")
+	}
+
+	return &UserInfo{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Username:      claims.PreferredUsername,
+		AvatarURL:     claims.Picture,
+	}, nil
+}
+
+func (p *oauth2Provider) githubUserInfo(ctx context.Context, userInfoURL, accessToken string) (*UserInfo, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.getJSON(ctx, userInfoURL, accessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	if user.ID == 0 {
+		return nil, errors.New("failed to get user info: missing user id")
+	}
+
+	info := &UserInfo{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Name:      user.Name,
+		Username:  user.Login,
+		AvatarURL: user.AvatarURL,
+	}
+
+	// /user 返回的公开邮箱未必已验证，以 /user/emails 中已验证的主邮箱为准
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, githubEmailsURL, accessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to get user emails: %w", err)
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			info.Email = e.Email
+			info.EmailVerified = true
+			break
+		}
+	}
+	return info, nil
+}
+
+func (p *oauth2Provider) getJSON(ctx context.Context, rawURL, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return p.doJSON(req, out)
+}
+
+func (p *oauth2Provider) doJSON(req *http.Request, out interface{}) error {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	// 令牌端点出错时也可能返回 400 + JSON 错误描述，交给调用方解析
+	if resp.StatusCode >= 300 && !(resp.StatusCode == http.StatusBadRequest && json.Valid(body)) {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,24 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// NewPKCE 生成 PKCE code_verifier 及其 S256 code_challenge（RFC 7636）
+func NewPKCE() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(b)
+	return verifier, CodeChallengeS256(verifier), nil
+}
+
+// CodeChallengeS256 计算 code_challenge = BASE64URL(SHA256(code_verifier))
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
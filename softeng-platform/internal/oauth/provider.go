@@ -0,0 +1,79 @@
+package oauth
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"softeng-platform/internal/config"
+	"sort"
+	"time"
+)
+
+// UserInfo 提供方返回的用户信息（各提供方字段统一后的结果）
+type UserInfo struct {
+	Subject       string // 提供方内的唯一用户ID
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string
+	AvatarURL     string
+}
+
+// Provider 第三方登录提供方（OAuth2 授权码模式 + PKCE）
+type Provider interface {
+	Name() string
+	// AuthCodeURL 生成跳转到提供方授权页的地址
+	AuthCodeURL(ctx context.Context, state, codeChallenge, redirectURL string) (string, error)
+	// Exchange 使用授权码和 PKCE code_verifier 换取访问令牌
+	Exchange(ctx context.Context, code, codeVerifier, redirectURL string) (string, error)
+	// UserInfo 使用访问令牌获取用户信息
+	UserInfo(ctx context.Context, accessToken string) (*UserInfo, error)
+}
+
+// Registry 已配置的提供方
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry 根据配置创建提供方，httpClient 为空时使用带超时的默认客户端（测试时可注入指向 mock 提供方的客户端）
+func NewRegistry(cfg *config.Config, httpClient *http.Client) *Registry {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	registry := &Registry{providers: make(map[string]Provider)}
+	for _, p := range cfg.OAuthProviders {
+		if p.ClientID == "" {
+			log.Printf("OAuth provider %q has no client id, skipped", p.Name)
+			continue
+		}
+		switch p.Type {
+		case "oidc", "github":
+			registry.providers[p.Name] = newOAuth2Provider(p, httpClient)
+		default:
+			log.Printf("Unknown OAuth provider type %q for %q, skipped", p.Type, p.Name)
+		}
+	}
+	return registry
+}
+
+// Register 注册自定义提供方
+func (r *Registry) Register(provider Provider) {
+	r.providers[provider.Name()] = provider
+}
+
+// Get 按名称获取提供方
+func (r *Registry) Get(name string) (Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// Names 已配置的提供方名称
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
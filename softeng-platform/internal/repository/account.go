@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+// 匿名化后的用户显示名
+const deletedUserNickname = "已注销用户"
+
+type AccountRepository interface {
+	// GetExport 汇总用户的评论、收藏、点赞及提交的内容（不含个人资料）
+	GetExport(ctx context.Context, userID int) (*model.AccountExport, error)
+
+	// ScheduleDeletion 记录注销申请，同一事务内吊销全部个人访问令牌并删除日历订阅令牌（撤销注销后不会恢复）
+	ScheduleDeletion(ctx context.Context, userID int, purgeAfter time.Time) (*model.AccountDeletion, error)
+	GetDeletion(ctx context.Context, userID int) (*model.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID int) error
+	// ListDueDeletions 冷静期已结束、尚未执行的注销申请
+	ListDueDeletions(ctx context.Context, now time.Time) ([]int, error)
+	// Anonymize 在同一事务内清除个人数据并把用户行改为匿名占位：
+	// 评论和提交的内容保留并显示为已注销用户，避免 ON DELETE CASCADE 删除整个讨论串
+	Anonymize(ctx context.Context, userID int, username, email string) error
+}
+
+type accountRepository struct {
+	db *Database
+}
+
+func NewAccountRepository(db *Database) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) GetExport(ctx context.Context, userID int) (*model.AccountExport, error) {
+	export := &model.AccountExport{ExportedAt: time.Now()}
+
+	var err error
+	if export.Comments, err = r.exportComments(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Collections, err = r.exportReactions(ctx, "collections", userID); err != nil {
+		return nil, err
+	}
+	if export.Likes, err = r.exportReactions(ctx, "likes", userID); err != nil {
+		return nil, err
+	}
+	if export.CommentLikes, err = r.exportCommentLikes(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Tools, err = r.exportSubmissions(ctx, `
+		SELECT t.resource_id, t.resource_name, t.description, t.resource_link, t.status, '', t.created_at
+		FROM tools t
+		WHERE t.submitter_id = ?
+			OR t.resource_id IN (SELECT tool_id FROM tool_contributors WHERE user_id = ?)
+		ORDER BY t.resource_id
+	`, `SELECT image_url FROM tool_images WHERE tool_id = ? ORDER BY sort_order, id`, userID, userID); err != nil {
+		return nil, err
+	}
+	if export.Courses, err = r.exportSubmissions(ctx, `
+		SELECT c.course_id, c.name, '', '', '', c.cover, c.created_at
+		FROM courses c
+		JOIN course_contributors cc ON cc.course_id = c.course_id
+		WHERE cc.user_id = ?
+		ORDER BY c.course_id
+	`, "", userID); err != nil {
+		return nil, err
+	}
+	if export.Projects, err = r.exportSubmissions(ctx, `
+		SELECT p.project_id, p.name, p.description, p.github_url, '', p.cover, p.created_at
+		FROM projects p
+		JOIN project_authors pa ON pa.project_id = p.project_id
+		WHERE pa.user_id = ?
+		ORDER BY p.project_id
+	`, `SELECT image_url FROM project_images WHERE project_id = ? ORDER BY sort_order, id`, userID); err != nil {
+		return nil, err
+	}
+	if export.LearningPlan, err = listLearningPlan(ctx, r.db, userID); err != nil {
+		return nil, err
+	}
+	if export.Ratings, err = r.exportRatings(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+func (r *accountRepository) exportRatings(ctx context.Context, userID int) ([]model.CourseRating, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, course_id, teacher_name, semester, difficulty, workload, teaching, usefulness, COALESCE(review, ''), created_at, updated_at
+		FROM course_ratings
+		WHERE user_id = ?
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export course ratings: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.CourseRating, 0)
+	for rows.Next() {
+		var rating model.CourseRating
+		if err := rows.Scan(&rating.ID, &rating.CourseID, &rating.Teacher, &rating.Semester, &rating.Difficulty, &rating.Workload,
+			&rating.Teaching, &rating.Usefulness, &rating.Review, &rating.CreatedAt, &rating.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan course rating: %v", err)
+		}
+		result = append(result, rating)
+	}
+	return result, rows.Err()
+}
+
+func (r *accountRepository) exportComments(ctx context.Context, userID int) ([]model.ExportComment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT comment_id, resource_type, resource_id, parent_id, content, love_count, created_at, deleted_at
+		FROM comments
+		WHERE user_id = ?
+		ORDER BY created_at, comment_id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.ExportComment, 0)
+	for rows.Next() {
+		var c model.ExportComment
+		var parentID sql.NullInt64
+		var deletedAt sql.NullTime
+		if err := rows.Scan(&c.ID, &c.ResourceType, &c.ResourceID, &parentID, &c.Content, &c.LoveCount, &c.CreatedAt, &deletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %v", err)
+		}
+		if parentID.Valid {
+			id := int(parentID.Int64)
+			c.ParentID = &id
+		}
+		if deletedAt.Valid {
+			c.DeletedAt = &deletedAt.Time
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
+// exportReactions 导出收藏或点赞记录，table 只能是 collections / likes
+func (r *accountRepository) exportReactions(ctx context.Context, table string, userID int) ([]model.ExportReaction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT x.resource_type, x.resource_id, COALESCE(t.resource_name, c.name, p.name, ''), x.created_at
+		FROM `+table+` x
+		LEFT JOIN tools t ON x.resource_type = 'tool' AND t.resource_id = x.resource_id
+		LEFT JOIN courses c ON x.resource_type = 'course' AND c.course_id = x.resource_id
+		LEFT JOIN projects p ON x.resource_type = 'project' AND p.project_id = x.resource_id
+		WHERE x.user_id = ?
+		ORDER BY x.created_at, x.id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	result := make([]model.ExportReaction, 0)
+	for rows.Next() {
+		var item model.ExportReaction
+		if err := rows.Scan(&item.ResourceType, &item.ResourceID, &item.ResourceName, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %v", table, err)
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+func (r *accountRepository) exportCommentLikes(ctx context.Context, userID int) ([]model.ExportReaction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT comment_id, created_at FROM comment_likes WHERE user_id = ? ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comment likes: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.ExportReaction, 0)
+	for rows.Next() {
+		item := model.ExportReaction{ResourceType: "comment"}
+		if err := rows.Scan(&item.ResourceID, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment like: %v", err)
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+// exportSubmissions 执行列表查询，imagesQuery 非空时逐条补充图片
+func (r *accountRepository) exportSubmissions(ctx context.Context, query, imagesQuery string, args ...interface{}) ([]model.ExportSubmission, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query submissions: %v", err)
+	}
+
+	result := make([]model.ExportSubmission, 0)
+	for rows.Next() {
+		var item model.ExportSubmission
+		var description, link, status, cover sql.NullString
+		if err := rows.Scan(&item.ID, &item.Name, &description, &link, &status, &cover, &item.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan submission: %v", err)
+		}
+		item.Description = nullString(description)
+		item.Link = nullString(link)
+		item.Status = nullString(status)
+		item.Cover = nullString(cover)
+		result = append(result, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate submissions: %v", err)
+	}
+
+	if imagesQuery == "" {
+		return result, nil
+	}
+	for i := range result {
+		images, err := r.queryStrings(ctx, imagesQuery, result[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		result[i].Images = images
+	}
+	return result, nil
+}
+
+func (r *accountRepository) queryStrings(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query images: %v", err)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, fmt.Errorf("failed to scan image: %v", err)
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+func (r *accountRepository) ScheduleDeletion(ctx context.Context, userID int, purgeAfter time.Time) (*model.AccountDeletion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO account_deletions (user_id, requested_at, purge_after, completed_at)
+		VALUES (?, ?, ?, NULL)
+		ON DUPLICATE KEY UPDATE requested_at = VALUES(requested_at), purge_after = VALUES(purge_after)
+	`, userID, now, purgeAfter); err != nil {
+		return nil, fmt.Errorf("failed to schedule account deletion: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE personal_access_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL
+	`, now, userID); err != nil {
+		return nil, fmt.Errorf("failed to revoke personal tokens: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM calendar_feed_tokens WHERE user_id = ?`, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete calendar feed token: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return &model.AccountDeletion{UserID: userID, RequestedAt: now, PurgeAfter: purgeAfter}, nil
+}
+
+func (r *accountRepository) GetDeletion(ctx context.Context, userID int) (*model.AccountDeletion, error) {
+	deletion := &model.AccountDeletion{}
+	var completedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, requested_at, purge_after, completed_at FROM account_deletions WHERE user_id = ?
+	`, userID).Scan(&deletion.UserID, &deletion.RequestedAt, &deletion.PurgeAfter, &completedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get account deletion: %v", err)
+	}
+
+	if completedAt.Valid {
+		deletion.CompletedAt = &completedAt.Time
+	}
+	return deletion, nil
+}
+
+func (r *accountRepository) CancelDeletion(ctx context.Context, userID int) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM account_deletions WHERE user_id = ? AND completed_at IS NULL
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel account deletion: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no pending account deletion")
+	}
+	return nil
+}
+
+func (r *accountRepository) ListDueDeletions(ctx context.Context, now time.Time) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id FROM account_deletions WHERE completed_at IS NULL AND purge_after <= ? ORDER BY purge_after
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due account deletions: %v", err)
+	}
+	defer rows.Close()
+
+	var result []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan account deletion: %v", err)
+		}
+		result = append(result, id)
+	}
+	return result, rows.Err()
+}
+
+func (r *accountRepository) Anonymize(ctx context.Context, userID int, username, email string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 撤销点赞/收藏时同步回退各资源的计数
+	counters := []struct {
+		table, column string
+	}{
+		{"likes", "loves"},
+		{"collections", "collections"},
+	}
+	for _, c := range counters {
+		for _, target := range []struct{ resourceType, table, key string }{
+			{"tool", "tools", "resource_id"},
+			{"course", "courses", "course_id"},
+			{"project", "projects", "project_id"},
+		} {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE `+target.table+`
+				SET `+c.column+` = GREATEST(`+c.column+` - 1, 0)
+				WHERE `+target.key+` IN (
+					SELECT resource_id FROM `+c.table+` WHERE user_id = ? AND resource_type = ?
+				)
+			`, userID, target.resourceType); err != nil {
+				return fmt.Errorf("failed to update %s counters: %v", c.table, err)
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE comments SET love_count = GREATEST(love_count - 1, 0)
+		WHERE comment_id IN (SELECT comment_id FROM comment_likes WHERE user_id = ?)
+	`, userID); err != nil {
+		return fmt.Errorf("failed to update comment like counters: %v", err)
+	}
+
+	// 仅属于个人的数据直接删除
+	for _, table := range []string{
+		"likes",
+		"collections",
+		"comment_likes",
+		"learning_plans",
+		"chapter_progress",
+		"refresh_tokens",
+		"user_sessions",
+		"personal_access_tokens",
+		"calendar_feed_tokens",
+		"mfa_recovery_codes",
+		"user_mfa",
+		"user_identities",
+		"oauth_states",
+		"auth_events",
+	} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id = ?`, userID); err != nil {
+			return fmt.Errorf("failed to delete %s: %v", table, err)
+		}
+	}
+
+	// 用户行保留为匿名占位，评论、提交记录仍指向它
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET username = ?, nickname = ?, email = ?, password = '', avatar = '', description = '', face_photo = '',
+			role = ?, cohort = NULL
+		WHERE id = ?
+	`, username, deletedUserNickname, email, model.RoleUser, userID); err != nil {
+		return fmt.Errorf("failed to anonymize user: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE account_deletions SET completed_at = ? WHERE user_id = ?
+	`, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to complete account deletion: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
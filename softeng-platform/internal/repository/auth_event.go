@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+type AuthEventRepository interface {
+	Create(ctx context.Context, event *model.AuthEvent) error
+	// List 按 ID 倒序查询审计日志，cursor 为上一页最后一条的 ID
+	List(ctx context.Context, filter model.AuthEventFilter) ([]model.AuthEvent, error)
+	// DeleteBefore 清理早于指定时间的审计日志
+	DeleteBefore(ctx context.Context, before time.Time) error
+}
+
+type authEventRepository struct {
+	db *Database
+}
+
+func NewAuthEventRepository(db *Database) AuthEventRepository {
+	return &authEventRepository{db: db}
+}
+
+func (r *authEventRepository) Create(ctx context.Context, event *model.AuthEvent) error {
+	var userID interface{}
+	if event.UserID > 0 {
+		userID = event.UserID
+	}
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO auth_events (user_id, identifier, event_type, ip, user_agent, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, userID, event.Identifier, event.Type, event.IP, event.UserAgent, event.Detail, now)
+	if err != nil {
+		return fmt.Errorf("failed to create auth event: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	event.ID = int(id)
+	event.CreatedAt = now
+
+	return nil
+}
+
+func (r *authEventRepository) List(ctx context.Context, filter model.AuthEventFilter) ([]model.AuthEvent, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := `SELECT id, user_id, identifier, event_type, ip, user_agent, detail, created_at FROM auth_events WHERE 1 = 1`
+	var args []interface{}
+	if filter.UserID > 0 {
+		query += ` AND user_id = ?`
+		args = append(args, filter.UserID)
+	}
+	if filter.Identifier != "" {
+		// 按输入的用户名/邮箱匹配，同时包含该账号未记录用户名的事件
+		query += ` AND (identifier = ? OR user_id IN (SELECT id FROM users WHERE username = ? OR email = ?))`
+		args = append(args, filter.Identifier, filter.Identifier, filter.Identifier)
+	}
+	if filter.Type != "" {
+		query += ` AND event_type = ?`
+		args = append(args, filter.Type)
+	}
+	if filter.IP != "" {
+		query += ` AND ip = ?`
+		args = append(args, filter.IP)
+	}
+	if !filter.From.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		query += ` AND created_at < ?`
+		args = append(args, filter.To)
+	}
+	if filter.Cursor > 0 {
+		query += ` AND id < ?`
+		args = append(args, filter.Cursor)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query auth events: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.AuthEvent, 0)
+	for rows.Next() {
+		var event model.AuthEvent
+		var userID sql.NullInt64
+		var identifier, ip, userAgent, detail sql.NullString
+		if err := rows.Scan(&event.ID, &userID, &identifier, &event.Type, &ip, &userAgent, &detail, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan auth event: %v", err)
+		}
+		event.UserID = int(userID.Int64)
+		event.Identifier = nullString(identifier)
+		event.IP = nullString(ip)
+		event.UserAgent = nullString(userAgent)
+		event.Detail = nullString(detail)
+		result = append(result, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate auth events: %v", err)
+	}
+
+	return result, nil
+}
+
+func (r *authEventRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM auth_events WHERE created_at < ?`, before); err != nil {
+		return fmt.Errorf("failed to delete auth events: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"strings"
+	"time"
+)
+
+type CalendarRepository interface {
+	// ListOfferings 获取审核通过课程的开课及其学期和任课教师，courseIDs 为空时不限课程
+	ListOfferings(ctx context.Context, filter model.SemesterFilter, courseIDs []int) ([]model.CalendarOffering, error)
+	// ListCourseEvents 获取课程的日历事件，按开始时间排列
+	ListCourseEvents(ctx context.Context, courseIDs []int) ([]model.CourseEvent, error)
+	GetCourseEvent(ctx context.Context, eventID int) (*model.CourseEvent, error)
+	CreateCourseEvent(ctx context.Context, event *model.CourseEvent) error
+	DeleteCourseEvent(ctx context.Context, eventID int) error
+	IsCourseContributor(ctx context.Context, courseID, userID int) (bool, error)
+	// ListUserCourses 获取用户收藏或加入学习计划的审核通过的课程
+	ListUserCourses(ctx context.Context, userID int) ([]model.CalendarPlannedCourse, error)
+	// SaveFeedToken 保存用户的日历订阅令牌哈希，已有令牌时替换
+	SaveFeedToken(ctx context.Context, userID int, tokenHash string) (time.Time, error)
+	// GetFeedTokenUser 按令牌哈希查找用户ID，令牌不存在时返回 0
+	GetFeedTokenUser(ctx context.Context, tokenHash string) (int, error)
+	TouchFeedToken(ctx context.Context, userID int) error
+	DeleteFeedToken(ctx context.Context, userID int) error
+}
+
+type calendarRepository struct {
+	db *Database
+}
+
+func NewCalendarRepository(db *Database) CalendarRepository {
+	return &calendarRepository{db: db}
+}
+
+// intArgs 将 ID 列表转换为查询参数
+func intArgs(ids []int) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+func (r *calendarRepository) ListOfferings(ctx context.Context, filter model.SemesterFilter, courseIDs []int) ([]model.CalendarOffering, error) {
+	conds := []string{"c.status = 'approved'"}
+	var args []interface{}
+	if len(courseIDs) > 0 {
+		conds = append(conds, "o.course_id IN ("+placeholders(len(courseIDs))+")")
+		args = append(args, intArgs(courseIDs)...)
+	}
+	if len(filter.IDs) > 0 {
+		conds = append(conds, "o.semester_id IN ("+placeholders(len(filter.IDs))+")")
+		args = append(args, intArgs(filter.IDs)...)
+	}
+	if filter.FromID > 0 {
+		conds = append(conds, semesterOrderSQL("s")+" >= (SELECT "+semesterOrderSQL("sf")+" FROM semesters sf WHERE sf.semester_id = ?)")
+		args = append(args, filter.FromID)
+	}
+	if filter.ToID > 0 {
+		conds = append(conds, semesterOrderSQL("s")+" <= (SELECT "+semesterOrderSQL("st")+" FROM semesters st WHERE st.semester_id = ?)")
+		args = append(args, filter.ToID)
+	}
+	if filter.Name != "" {
+		conds = append(conds, "s.name = ?")
+		args = append(args, filter.Name)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT o.offering_id, o.course_id, c.name, COALESCE(c.credit, 0),
+			s.semester_id, s.name, s.academic_year, s.term, s.start_date, s.end_date, t.name
+		FROM course_offerings o
+		JOIN courses c ON c.course_id = o.course_id
+		JOIN semesters s ON s.semester_id = o.semester_id
+		LEFT JOIN course_offering_teachers ot ON ot.offering_id = o.offering_id
+		LEFT JOIN teachers t ON t.teacher_id = ot.teacher_id
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY `+semesterOrderSQL("s")+` ASC, o.offering_id ASC, t.name ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar offerings: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.CalendarOffering, 0)
+	for rows.Next() {
+		var (
+			offering           model.CalendarOffering
+			startDate, endDate sql.NullTime
+			teacherName        sql.NullString
+		)
+		s := &offering.Semester
+		if err := rows.Scan(&offering.OfferingID, &offering.CourseID, &offering.CourseName, &offering.Credit,
+			&s.SemesterID, &s.Name, &s.AcademicYear, &s.Term, &startDate, &endDate, &teacherName); err != nil {
+			return nil, fmt.Errorf("failed to scan calendar offering: %v", err)
+		}
+		if startDate.Valid {
+			s.StartDate = startDate.Time.Format(model.SemesterDateLayout)
+		}
+		if endDate.Valid {
+			s.EndDate = endDate.Time.Format(model.SemesterDateLayout)
+		}
+		// 同一次开课的多位教师是连续的行
+		n := len(result)
+		if n == 0 || result[n-1].OfferingID != offering.OfferingID {
+			result = append(result, offering)
+			n++
+		}
+		if teacherName.Valid {
+			result[n-1].Teachers = append(result[n-1].Teachers, teacherName.String)
+		}
+	}
+	return result, rows.Err()
+}
+
+const courseEventColumns = `e.event_id, e.course_id, c.name, e.title, e.description, e.kind, e.start_at, e.end_at, e.all_day, e.location, e.creator_id, e.created_at`
+
+func scanCourseEvent(row rowScanner) (*model.CourseEvent, error) {
+	e := &model.CourseEvent{}
+	var (
+		description, location sql.NullString
+		endAt                 sql.NullTime
+		creatorID             sql.NullInt64
+	)
+	if err := row.Scan(&e.EventID, &e.CourseID, &e.CourseName, &e.Title, &description, &e.Kind, &e.StartAt, &endAt, &e.AllDay, &location, &creatorID, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	e.Description = nullString(description)
+	e.Location = nullString(location)
+	if endAt.Valid {
+		e.EndAt = &endAt.Time
+	}
+	e.CreatorID = int(creatorID.Int64)
+	return e, nil
+}
+
+func (r *calendarRepository) ListCourseEvents(ctx context.Context, courseIDs []int) ([]model.CourseEvent, error) {
+	result := make([]model.CourseEvent, 0)
+	if len(courseIDs) == 0 {
+		return result, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+courseEventColumns+`
+		FROM course_events e
+		JOIN courses c ON c.course_id = e.course_id
+		WHERE e.course_id IN (`+placeholders(len(courseIDs))+`)
+		ORDER BY e.start_at ASC, e.event_id ASC
+	`, intArgs(courseIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course events: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanCourseEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan course event: %v", err)
+		}
+		result = append(result, *e)
+	}
+	return result, rows.Err()
+}
+
+func (r *calendarRepository) GetCourseEvent(ctx context.Context, eventID int) (*model.CourseEvent, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+courseEventColumns+`
+		FROM course_events e
+		JOIN courses c ON c.course_id = e.course_id
+		WHERE e.event_id = ?
+	`, eventID)
+	e, err := scanCourseEvent(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get course event: %v", err)
+	}
+	return e, nil
+}
+
+func (r *calendarRepository) CreateCourseEvent(ctx context.Context, event *model.CourseEvent) error {
+	var endAt interface{}
+	if event.EndAt != nil {
+		endAt = *event.EndAt
+	}
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO course_events (course_id, title, description, kind, start_at, end_at, all_day, location, creator_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.CourseID, event.Title, event.Description, event.Kind, event.StartAt, endAt, event.AllDay, event.Location, event.CreatorID, now)
+	if err != nil {
+		return fmt.Errorf("failed to create course event: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	event.EventID = int(id)
+	event.CreatedAt = now
+	return nil
+}
+
+func (r *calendarRepository) DeleteCourseEvent(ctx context.Context, eventID int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM course_events WHERE event_id = ?`, eventID); err != nil {
+		return fmt.Errorf("failed to delete course event: %v", err)
+	}
+	return nil
+}
+
+func (r *calendarRepository) IsCourseContributor(ctx context.Context, courseID, userID int) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM course_contributors WHERE course_id = ? AND user_id = ?
+	`, courseID, userID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check course contributor: %v", err)
+	}
+	return count > 0, nil
+}
+
+func (r *calendarRepository) ListUserCourses(ctx context.Context, userID int) ([]model.CalendarPlannedCourse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.course_id, c.name, lp.semester,
+			EXISTS (SELECT 1 FROM collections col
+				WHERE col.user_id = ? AND col.resource_type = 'course' AND col.resource_id = c.course_id)
+		FROM courses c
+		LEFT JOIN learning_plans lp ON lp.course_id = c.course_id AND lp.user_id = ?
+		WHERE c.status = 'approved' AND (lp.id IS NOT NULL OR c.course_id IN (
+			SELECT resource_id FROM collections WHERE user_id = ? AND resource_type = 'course'))
+		ORDER BY c.course_id ASC
+	`, userID, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user courses: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.CalendarPlannedCourse, 0)
+	for rows.Next() {
+		var (
+			course   model.CalendarPlannedCourse
+			semester sql.NullString
+		)
+		if err := rows.Scan(&course.CourseID, &course.CourseName, &semester, &course.Collected); err != nil {
+			return nil, fmt.Errorf("failed to scan user course: %v", err)
+		}
+		course.PlanSemester = nullString(semester)
+		result = append(result, course)
+	}
+	return result, rows.Err()
+}
+
+func (r *calendarRepository) SaveFeedToken(ctx context.Context, userID int, tokenHash string) (time.Time, error) {
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO calendar_feed_tokens (user_id, token_hash, created_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE token_hash = VALUES(token_hash), created_at = VALUES(created_at), last_used_at = NULL
+	`, userID, tokenHash, now); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save calendar feed token: %v", err)
+	}
+	return now, nil
+}
+
+func (r *calendarRepository) GetFeedTokenUser(ctx context.Context, tokenHash string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `SELECT user_id FROM calendar_feed_tokens WHERE token_hash = ?`, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get calendar feed token: %v", err)
+	}
+	return userID, nil
+}
+
+func (r *calendarRepository) TouchFeedToken(ctx context.Context, userID int) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE calendar_feed_tokens SET last_used_at = ? WHERE user_id = ?`, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to update calendar feed token: %v", err)
+	}
+	return nil
+}
+
+func (r *calendarRepository) DeleteFeedToken(ctx context.Context, userID int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM calendar_feed_tokens WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete calendar feed token: %v", err)
+	}
+	return nil
+}
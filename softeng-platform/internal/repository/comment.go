@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// CommentRepository 跨资源类型的评论管理（供版主使用，不校验评论作者）
+type CommentRepository interface {
+	// ModerateDelete 软删除评论及其回复
+	ModerateDelete(ctx context.Context, commentID int) error
+}
+
+type commentRepository struct {
+	db *Database
+}
+
+func NewCommentRepository(db *Database) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) ModerateDelete(ctx context.Context, commentID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var parentID sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		SELECT parent_id FROM comments WHERE comment_id = ? AND deleted_at IS NULL
+	`, commentID).Scan(&parentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("comment not found")
+		}
+		return fmt.Errorf("failed to read comment: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE comments
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE (comment_id = ? OR parent_id = ?) AND deleted_at IS NULL
+	`, commentID, commentID); err != nil {
+		return fmt.Errorf("failed to delete comment: %v", err)
+	}
+
+	// 删除的是回复时同步父评论的回复数
+	if parentID.Valid {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE comments
+			SET reply_total = GREATEST(reply_total - 1, 0), updated_at = NOW()
+			WHERE comment_id = ?
+		`, parentID.Int64); err != nil {
+			return fmt.Errorf("failed to decrement parent reply_total: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
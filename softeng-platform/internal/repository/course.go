@@ -4,17 +4,23 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
+	"softeng-platform/internal/model"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type CourseRepository interface {
-	GetCourses(ctx context.Context, semester string, category []string, sort string, limit, cursor int) ([]map[string]interface{}, error)
+	GetCourses(ctx context.Context, semester model.SemesterFilter, category []string, sort string, limit, cursor int) ([]map[string]interface{}, error)
 	GetByID(ctx context.Context, courseID string, userID int) (map[string]interface{}, error)
 	Search(ctx context.Context, keyword string, category []string, limit, cursor int) ([]map[string]interface{}, error)
 	UploadResource(ctx context.Context, userID int, courseID string, data map[string]interface{}) (map[string]interface{}, error)
-	DownloadTextbook(ctx context.Context, courseID, textbookID string) (string, error)
+	GetResources(ctx context.Context, courseID string) (map[string]interface{}, error) // 仅返回审核通过的资源
+	GetPendingResources(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error)
+	UpdateResourceStatus(ctx context.Context, kind, resourceID, status, rejectReason string) error
+	GetUploadResource(ctx context.Context, courseID, resourceID string) (*model.CourseResourceFile, error)
+	IncrementDownloads(ctx context.Context, resourceID int) error
 	AddComment(ctx context.Context, userID int, courseID, content string) (map[string]interface{}, error)
 	DeleteComment(ctx context.Context, userID int, courseID string, commentID string) (map[string]interface{}, error) // commentID 为空则删除该用户最新一条
 	ReplyComment(ctx context.Context, userID int, courseID, commentID, content string) (map[string]interface{}, error)
@@ -25,6 +31,31 @@ type CourseRepository interface {
 	LikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
 	UnlikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
 	GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) // 新增方法
+	Create(ctx context.Context, userID int, data map[string]interface{}) (map[string]interface{}, error)
+	// UpdateCourse 修改课程的学分和封面并补充任课教师、开课和分类，data 中没有的字段保持不变；userID 记为课程贡献者
+	UpdateCourse(ctx context.Context, userID, courseID int, data map[string]interface{}) error
+	UpdateCourseStatus(ctx context.Context, courseID, status, rejectReason string) error
+	GetCourseSubmitter(ctx context.Context, courseID string) (int, string, error)
+	GetChapters(ctx context.Context, courseID int) ([]model.CourseChapter, error)
+	GetChapter(ctx context.Context, chapterID int) (*model.CourseChapter, error)
+	CreateChapter(ctx context.Context, chapter *model.CourseChapter) error
+	GetPendingChapters(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error)
+	UpdateChapterStatus(ctx context.Context, chapterID, status, rejectReason string) error
+	GetChapterProgress(ctx context.Context, userID, courseID int) (map[int]time.Time, error)
+	SetChapterProgress(ctx context.Context, userID, chapterID int, completed bool) error
+	GetCourseCompletion(ctx context.Context, userID, courseID int) (int, int, error)
+	SaveRating(ctx context.Context, rating *model.CourseRating) error
+	GetRatingSummary(ctx context.Context, courseID int) (*model.RatingSummary, []model.RatingSummary, error)
+	ListRatings(ctx context.Context, courseID, userID, cursor, limit int) ([]model.CourseRating, error)
+	GetCourseTeachers(ctx context.Context, courseID int) ([]string, error)
+	GetCourseOffering(ctx context.Context, courseID, academicYear, term int) (*model.CourseOffering, error)
+	GetPrerequisite(ctx context.Context, courseID, requiredID int) (*model.CoursePrerequisite, error)
+	GetPrerequisiteByID(ctx context.Context, relationID int) (*model.CoursePrerequisite, error)
+	CreatePrerequisite(ctx context.Context, p *model.CoursePrerequisite) error
+	ListApprovedPrerequisites(ctx context.Context) ([]model.CoursePrerequisite, error)
+	ListCurriculumNodes(ctx context.Context) ([]model.CurriculumNode, error)
+	GetPendingPrerequisites(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error)
+	UpdatePrerequisiteStatus(ctx context.Context, relationID, status, rejectReason string) error
 }
 
 type courseRepository struct {
@@ -35,7 +66,7 @@ func NewCourseRepository(db *Database) CourseRepository {
 	return &courseRepository{db: db}
 }
 
-func (r *courseRepository) GetCourses(ctx context.Context, semester string, category []string, sort string, limit, cursor int) ([]map[string]interface{}, error) {
+func (r *courseRepository) GetCourses(ctx context.Context, semester model.SemesterFilter, category []string, sort string, limit, cursor int) ([]map[string]interface{}, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -45,9 +76,13 @@ func (r *courseRepository) GetCourses(ctx context.Context, semester string, cate
 		args       []interface{}
 	)
 
-	if semester != "" {
-		whereParts = append(whereParts, "c.semester = ?")
-		args = append(args, semester)
+	// 只展示审核通过的课程
+	whereParts = append(whereParts, "c.status = 'approved'")
+
+	if !semester.Empty() {
+		cond, condArgs := semesterFilterSQL(semester)
+		whereParts = append(whereParts, cond)
+		args = append(args, condArgs...)
 	}
 
 	if len(category) > 0 {
@@ -78,6 +113,10 @@ func (r *courseRepository) GetCourses(ctx context.Context, semester string, cate
 		orderBy = "c.collections DESC, c.course_id DESC"
 	case "loves", "likes":
 		orderBy = "c.loves DESC, c.course_id DESC"
+	default:
+		if ratingOrder, ok := courseRatingOrder(strings.ToLower(sort)); ok {
+			orderBy = ratingOrder
+		}
 	}
 
 	query := fmt.Sprintf(`
@@ -93,15 +132,23 @@ func (r *courseRepository) GetCourses(ctx context.Context, semester string, cate
 			c.views,
 			c.loves,
 			c.collections,
-			c.created_at
+			c.created_at,
+			cr.rating_count,
+			cr.avg_difficulty,
+			cr.avg_workload,
+			cr.avg_teaching,
+			cr.avg_usefulness
 		FROM courses c
 		LEFT JOIN course_teachers ct ON ct.course_id = c.course_id
 		LEFT JOIN course_categories cc ON cc.course_id = c.course_id
+		LEFT JOIN (%s
+		) cr ON cr.course_id = c.course_id
 		%s
-		GROUP BY c.course_id, c.resource_type, c.name, c.semester, c.credit, c.cover, c.views, c.loves, c.collections, c.created_at
+		GROUP BY c.course_id, c.resource_type, c.name, c.semester, c.credit, c.cover, c.views, c.loves, c.collections, c.created_at,
+			cr.rating_count, cr.avg_difficulty, cr.avg_workload, cr.avg_teaching, cr.avg_usefulness
 		ORDER BY %s
 		LIMIT ?
-	`, whereSQL, orderBy)
+	`, courseRatingAggSQL, whereSQL, orderBy)
 	args = append(args, limit)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -125,6 +172,11 @@ func (r *courseRepository) GetCourses(ctx context.Context, semester string, cate
 			loves        int
 			collections  int
 			createdAt    time.Time
+			ratingCount  sql.NullInt64
+			difficulty   sql.NullFloat64
+			workload     sql.NullFloat64
+			teaching     sql.NullFloat64
+			usefulness   sql.NullFloat64
 		)
 
 		if err := rows.Scan(
@@ -140,6 +192,11 @@ func (r *courseRepository) GetCourses(ctx context.Context, semester string, cate
 			&loves,
 			&collections,
 			&createdAt,
+			&ratingCount,
+			&difficulty,
+			&workload,
+			&teaching,
+			&usefulness,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan course row: %v", err)
 		}
@@ -157,6 +214,13 @@ func (r *courseRepository) GetCourses(ctx context.Context, semester string, cate
 			"loves":        loves,
 			"collections":  collections,
 			"createdat":    createdAt.Format("2006-01-02"),
+			"rating": model.RatingSummary{
+				Count:      int(ratingCount.Int64),
+				Difficulty: roundRating(difficulty),
+				Workload:   roundRating(workload),
+				Teaching:   roundRating(teaching),
+				Usefulness: roundRating(usefulness),
+			},
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -178,7 +242,11 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID string, userID
 			views,
 			loves,
 			collections,
-			created_at
+			created_at,
+			status,
+			submitter_id,
+			audit_time,
+			reject_reason
 		FROM courses
 		WHERE course_id = ?
 		LIMIT 1
@@ -195,6 +263,10 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID string, userID
 		loves        int
 		collections  int
 		createdAt    time.Time
+		status       sql.NullString
+		submitterID  sql.NullInt64
+		auditTime    sql.NullTime
+		rejectReason sql.NullString
 	)
 
 	err := r.db.QueryRowContext(ctx, query, courseID).Scan(
@@ -208,6 +280,10 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID string, userID
 		&loves,
 		&collections,
 		&createdAt,
+		&status,
+		&submitterID,
+		&auditTime,
+		&rejectReason,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -216,6 +292,11 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID string, userID
 		return nil, fmt.Errorf("failed to get course by id: %v", err)
 	}
 
+	// 未审核通过的课程只对提交者可见
+	if nullString(status) != model.CourseStatusApproved && (userID == 0 || int(submitterID.Int64) != userID) {
+		return nil, nil
+	}
+
 	categories, err := r.fetchCourseCategories(ctx, id)
 	if err != nil {
 		return nil, err
@@ -224,6 +305,14 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID string, userID
 	if err != nil {
 		return nil, err
 	}
+	teacherList, err := r.fetchCourseTeacherRefs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	offerings, err := listCourseOfferings(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
 	urlForm, err := r.fetchCourseWebResources(ctx, id)
 	if err != nil {
 		return nil, err
@@ -236,16 +325,40 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID string, userID
 	if err != nil {
 		return nil, err
 	}
+	chapters, err := r.GetChapters(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rating, ratingOfferings, err := r.GetRatingSummary(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
 	catagory := ""
 	if len(categories) > 0 {
 		catagory = categories[0]
 	}
 
-	// 登录态：返回 isliked/iscollected
+	// 登录态：返回 isliked/iscollected 和学习进度
 	isLiked := false
 	isCollected := false
+	var progress interface{}
+	myRatings := make([]model.CourseRating, 0)
 	if userID > 0 {
+		if myRatings, err = r.ListRatings(ctx, id, userID, 0, 100); err != nil {
+			return nil, err
+		}
+		completed, total, err := courseCompletion(ctx, r.db, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		progress = model.CourseProgress{
+			CourseID:          id,
+			CompletedChapters: completed,
+			TotalChapters:     total,
+			Percent:           model.CompletionPercent(completed, total),
+		}
+
 		if v, err := r.isCourseLiked(ctx, userID, id); err == nil {
 			isLiked = v
 		} else {
@@ -271,7 +384,9 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID string, userID
 		"url_form":     urlForm,
 		"upload_form":  uploadForm,
 		"teacher":      teachers,
+		"teacherList":  teacherList,
 		"semester":     nullString(semesterNS),
+		"offerings":    offerings,
 		"credit":       credit,
 		"cover":        nullString(cover),
 		"contributor":  contributors,
@@ -283,9 +398,25 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID string, userID
 		"comment_total": commentTotal,
 		"comments":     comments,
 		"createdAt":    createdAt.Format("2006-01-02"),
+		"chapters":     model.BuildChapterOutline(chapters),
+		"rating":       rating,
+		"ratingOfferings": ratingOfferings,
+		"myRatings":    myRatings,
+		"progress":     progress,
+		"auditStatus":  nullString(status),
+		"auditTime":    formatNullTime(auditTime),
+		"rejectReason": nullString(rejectReason),
 	}, nil
 }
 
+// formatNullTime 格式化可空时间，为空时返回 nil
+func formatNullTime(t sql.NullTime) interface{} {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time.Format("2006-01-02 15:04:05")
+}
+
 func (r *courseRepository) Search(ctx context.Context, keyword string, category []string, limit, cursor int) ([]map[string]interface{}, error) {
 	if limit <= 0 {
 		limit = 10
@@ -296,6 +427,9 @@ func (r *courseRepository) Search(ctx context.Context, keyword string, category
 		args       []interface{}
 	)
 
+	// 只展示审核通过的课程
+	whereParts = append(whereParts, "c.status = 'approved'")
+
 	if keyword != "" {
 		whereParts = append(whereParts, "c.name LIKE ?")
 		args = append(args, "%"+keyword+"%")
@@ -408,30 +542,480 @@ func (r *courseRepository) Search(ctx context.Context, keyword string, category
 }
 
 func (r *courseRepository) UploadResource(ctx context.Context, userID int, courseID string, data map[string]interface{}) (map[string]interface{}, error) {
-	// 实现上传课程资源的逻辑
+	cid, err := strconv.Atoi(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid course id")
+	}
+
+	var exists int
+	if err := r.db.QueryRowContext(ctx, `SELECT 1 FROM courses WHERE course_id = ?`, cid).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("course not found")
+		}
+		return nil, fmt.Errorf("failed to check course: %v", err)
+	}
+
+	description, _ := data["description"].(string)
+	link, _ := data["resource"].(string)
+	filePath, _ := data["file_path"].(string)
+	fileName, _ := data["file_name"].(string)
+	sourceURL, _ := data["source_url"].(string)
+	fileSize, _ := data["file_size"].(int64)
+	contentType, _ := data["content_type"].(string)
+	chapterID, _ := data["chapter_id"].(int)
+
+	// 资源可以关联到课程中审核通过的章节
+	var chapter interface{}
+	if chapterID > 0 {
+		if err := r.db.QueryRowContext(ctx, `
+			SELECT 1 FROM course_chapters ch WHERE ch.chapter_id = ? AND ch.course_id = ? AND `+approvedChapterCond+`
+		`, chapterID, cid).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("chapter not found")
+			}
+			return nil, fmt.Errorf("failed to check chapter: %v", err)
+		}
+		chapter = chapterID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	result := map[string]interface{}{
+		"courseId":     cid,
+		"chapterId":    chapter,
+		"auditStatus":  "pending",
+		"submitTime":   now.Format("2006-01-02 15:04:05"),
+		"auditTime":    nil,
+		"rejectReason": nil,
+	}
+
+	// URL 资源写入 course_resources_web
+	if link != "" {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO course_resources_web (course_id, chapter_id, resource_intro, resource_url, submitter_id, status, created_at)
+			VALUES (?, ?, ?, ?, ?, 'pending', ?)
+		`, cid, chapter, description, link, userID, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create course web resource: %v", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last insert id: %v", err)
+		}
+		result["resource1"] = map[string]interface{}{
+			"resource_intro": description,
+			"resource_url":   link,
+			"resource_id":    int(id),
+		}
+	}
+
+	// 上传文件写入 course_resources_upload，文件通过下载接口访问；导入的文件记录原始地址
+	if filePath != "" {
+		var source interface{}
+		if sourceURL != "" {
+			source = sourceURL
+		}
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO course_resources_upload (course_id, chapter_id, resource_intro, resource_upload, file_name, file_size, content_type, storage_path, source_url, submitter_id, status, created_at)
+			VALUES (?, ?, ?, '', ?, ?, ?, ?, ?, ?, 'pending', ?)
+		`, cid, chapter, description, fileName, fileSize, contentType, filePath, source, userID, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create course upload resource: %v", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last insert id: %v", err)
+		}
+		downloadURL := courseResourceDownloadURL(cid, int(id))
+		if _, err := tx.ExecContext(ctx, `UPDATE course_resources_upload SET resource_upload = ? WHERE resource_id = ?`, downloadURL, id); err != nil {
+			return nil, fmt.Errorf("failed to update course upload resource: %v", err)
+		}
+		result["resource2"] = map[string]interface{}{
+			"resource_intro":  description,
+			"resource_upload": downloadURL,
+			"file_name":       fileName,
+			"file_size":       fileSize,
+			"resource_id":     int(id),
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return result, nil
+}
+
+// courseResourceDownloadURL 本地上传资源的下载地址
+func courseResourceDownloadURL(courseID, resourceID int) string {
+	return fmt.Sprintf("/course/%d/textbooks/%d/download", courseID, resourceID)
+}
+
+func (r *courseRepository) GetResources(ctx context.Context, courseID string) (map[string]interface{}, error) {
+	cid, err := strconv.Atoi(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid course id")
+	}
+
+	var name sql.NullString
+	if err := r.db.QueryRowContext(ctx, `SELECT name FROM courses WHERE course_id = ? AND status = 'approved'`, cid).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get course: %v", err)
+	}
+
+	urlForm, err := r.fetchCourseWebResources(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	uploadForm, err := r.fetchCourseUploadResources(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"courseId":    cid,
+		"name":        nullString(name),
+		"url_form":    urlForm,
+		"upload_form": uploadForm,
+	}, nil
+}
+
+// GetPendingResources 获取待审核的课程资源（URL 和上传文件），cursor 为偏移量
+func (r *courseRepository) GetPendingResources(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT * FROM (
+			SELECT 'course_resource_web' AS kind, w.resource_id, w.course_id, c.name, w.resource_intro, w.resource_url AS link, '' AS file_name, u.username, w.created_at
+			FROM course_resources_web w
+			JOIN courses c ON c.course_id = w.course_id
+			LEFT JOIN users u ON u.id = w.submitter_id
+			WHERE w.status = 'pending'
+			UNION ALL
+			SELECT 'course_resource_upload' AS kind, up.resource_id, up.course_id, c.name, up.resource_intro, up.resource_upload AS link, COALESCE(up.file_name, '') AS file_name, u.username, up.created_at
+			FROM course_resources_upload up
+			JOIN courses c ON c.course_id = up.course_id
+			LEFT JOIN users u ON u.id = up.submitter_id
+			WHERE up.status = 'pending'
+		) pending
+		ORDER BY created_at ASC, resource_id ASC
+		LIMIT ? OFFSET ?
+	`, limit, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending course resources: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var (
+			kind       string
+			resourceID int
+			cid        int
+			courseName sql.NullString
+			intro      sql.NullString
+			link       sql.NullString
+			fileName   sql.NullString
+			submitter  sql.NullString
+			createdAt  time.Time
+		)
+		if err := rows.Scan(&kind, &resourceID, &cid, &courseName, &intro, &link, &fileName, &submitter, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending course resource: %v", err)
+		}
+		result = append(result, map[string]interface{}{
+			"submitor":     nullString(submitter),
+			"submitDate":   createdAt.Format("2006-01-02 15:04:05"),
+			"reourceId":    resourceID,
+			"resourceType": kind,
+			"resourcename": nullString(courseName),
+			"courseId":     cid,
+			"catagory":     "课程资源",
+			"link":         nullString(link),
+			"description":  nullString(intro),
+			"tags":         []string{},
+			"file":         nullString(fileName),
+		})
+	}
+	return result, rows.Err()
+}
+
+// UpdateResourceStatus 审核课程资源，kind 为 web 或 upload
+func (r *courseRepository) UpdateResourceStatus(ctx context.Context, kind, resourceID, status, rejectReason string) error {
+	var table string
+	switch kind {
+	case "web":
+		table = "course_resources_web"
+	case "upload":
+		table = "course_resources_upload"
+	default:
+		return fmt.Errorf("invalid course resource kind: %s", kind)
+	}
+
+	return r.updateReviewStatus(ctx, table, "resource_id", resourceID, status, rejectReason)
+}
+
+// UpdateCourseStatus 变更课程审核状态，只允许 model 中定义的状态流转
+func (r *courseRepository) UpdateCourseStatus(ctx context.Context, courseID, status, rejectReason string) error {
+	return r.updateReviewStatus(ctx, "courses", "course_id", courseID, status, rejectReason)
+}
+
+// updateReviewStatus 按状态流转规则条件更新，原状态不允许流转到目标状态时返回错误
+func (r *courseRepository) updateReviewStatus(ctx context.Context, table, idColumn, id, status, rejectReason string) error {
+	sources := model.CourseStatusSources(status)
+	if len(sources) == 0 {
+		return fmt.Errorf("invalid status: %s", status)
+	}
+
+	// 驳回原因只在驳回时保留，其他状态清空
+	var reason interface{}
+	if status == model.CourseStatusRejected && rejectReason != "" {
+		reason = rejectReason
+	}
+
+	args := []interface{}{status, time.Now(), reason, id}
+	for _, s := range sources {
+		args = append(args, s)
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE `+table+` SET status = ?, audit_time = ?, reject_reason = ?
+		WHERE `+idColumn+` = ? AND status IN (`+placeholders(len(sources))+`)
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update %s status: %v", table, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows > 0 {
+		return nil
+	}
+
+	// 区分记录不存在和状态不允许流转
+	var current sql.NullString
+	err = r.db.QueryRowContext(ctx, `SELECT status FROM `+table+` WHERE `+idColumn+` = ?`, id).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("not found")
+		}
+		return fmt.Errorf("failed to get %s status: %v", table, err)
+	}
+	return fmt.Errorf("cannot change status from %s to %s", nullString(current), status)
+}
+
+// GetCourseSubmitter 获取课程的提交者ID和当前状态，课程不存在时返回 0 和空字符串
+func (r *courseRepository) GetCourseSubmitter(ctx context.Context, courseID string) (int, string, error) {
+	var submitterID sql.NullInt64
+	var status sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT submitter_id, status FROM courses WHERE course_id = ?`, courseID).Scan(&submitterID, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", nil
+		}
+		return 0, "", fmt.Errorf("failed to get course submitter: %v", err)
+	}
+	return int(submitterID.Int64), nullString(status), nil
+}
+
+func (r *courseRepository) Create(ctx context.Context, userID int, data map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := data["name"].(string)
+	semester, _ := data["semester"].(string)
+	semesterYear, _ := data["semester_year"].(int)
+	semesterTerm, _ := data["semester_term"].(int)
+	credit, _ := data["credit"].(int)
+	cover, _ := data["cover"].(string)
+	teachers, _ := data["teachers"].([]string)
+	categories, _ := data["categories"].([]string)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// 识别出的学期关联到学期目录，课程中保存目录中的学期名称
+	semesterID := 0
+	if semesterYear > 0 {
+		semesterID, semester, err = resolveSemester(ctx, tx, semesterYear, semesterTerm)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO courses (resource_type, name, semester, credit, cover, status, submitter_id)
+		VALUES ('course', ?, ?, ?, ?, 'pending', ?)
+	`, name, semester, credit, cover, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert course: %v", err)
+	}
+
+	newID64, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inserted id: %v", err)
+	}
+	newID := int(newID64)
+
+	if err := addCourseRelations(ctx, tx, newID, semesterID, teachers, categories); err != nil {
+		return nil, err
+	}
+	// 提交者即为课程贡献者
+	if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO course_contributors (course_id, user_id) VALUES (?, ?)`, newID, userID); err != nil {
+		return nil, fmt.Errorf("failed to insert course contributor: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %v", err)
+	}
+
 	return map[string]interface{}{
-		"resourceId":   1,
-		"resourceType": "teach",
-		"resource1": map[string]interface{}{
-			"resource_intro": data["description"],
-			"resource_url":   data["resource"],
-			"resource_id":    1,
-		},
-		"resource2": map[string]interface{}{
-			"resource_intro":  data["description"],
-			"resource_upload": data["file"],
-			"resource_id":     2,
-		},
+		"resourceId":   newID,
+		"resourceType": "course",
+		"name":         name,
 		"auditStatus":  "pending",
-		"submitTime":   "2023-12-01 10:00:00",
+		"submitTime":   time.Now().Format("2006-01-02 15:04:05"),
 		"auditTime":    nil,
 		"rejectReason": nil,
 	}, nil
 }
 
-func (r *courseRepository) DownloadTextbook(ctx context.Context, courseID, textbookID string) (string, error) {
-	// 实现下载课本的逻辑
-	return "Textbook content for course " + courseID + " textbook " + textbookID, nil
+func (r *courseRepository) UpdateCourse(ctx context.Context, userID, courseID int, data map[string]interface{}) error {
+	semesterYear, _ := data["semester_year"].(int)
+	semesterTerm, _ := data["semester_term"].(int)
+	teachers, _ := data["teachers"].([]string)
+	categories, _ := data["categories"].([]string)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if credit, ok := data["credit"].(int); ok {
+		if _, err := tx.ExecContext(ctx, `UPDATE courses SET credit = ? WHERE course_id = ?`, credit, courseID); err != nil {
+			return fmt.Errorf("failed to update course credit: %v", err)
+		}
+	}
+	if cover, ok := data["cover"].(string); ok {
+		if _, err := tx.ExecContext(ctx, `UPDATE courses SET cover = ? WHERE course_id = ?`, cover, courseID); err != nil {
+			return fmt.Errorf("failed to update course cover: %v", err)
+		}
+	}
+
+	semesterID := 0
+	if semesterYear > 0 {
+		if semesterID, _, err = resolveSemester(ctx, tx, semesterYear, semesterTerm); err != nil {
+			return err
+		}
+	}
+	if err := addCourseRelations(ctx, tx, courseID, semesterID, teachers, categories); err != nil {
+		return err
+	}
+	if userID > 0 {
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO course_contributors (course_id, user_id) VALUES (?, ?)`, courseID, userID); err != nil {
+			return fmt.Errorf("failed to insert course contributor: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// addCourseRelations 关联任课教师、开课（semesterID 为 0 时不创建）和分类；教师按姓名和别名关联到已有教师，
+// 没有时新建，已关联的教师和开课会被忽略
+func addCourseRelations(ctx context.Context, tx *sql.Tx, courseID, semesterID int, teachers, categories []string) error {
+	teacherIDs := make([]int, 0, len(teachers))
+	for _, teacher := range teachers {
+		teacherID, canonical, err := resolveTeacher(ctx, tx, teacher)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO course_teachers (course_id, teacher_id, teacher_name) VALUES (?, ?, ?)`, courseID, teacherID, canonical); err != nil {
+			return fmt.Errorf("failed to insert course teacher: %v", err)
+		}
+		teacherIDs = append(teacherIDs, teacherID)
+	}
+	if semesterID > 0 {
+		if err := createOffering(ctx, tx, courseID, semesterID, teacherIDs); err != nil {
+			return err
+		}
+	}
+	for _, category := range categories {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO course_categories (course_id, category) VALUES (?, ?)`, courseID, category); err != nil {
+			return fmt.Errorf("failed to insert course category: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *courseRepository) GetUploadResource(ctx context.Context, courseID, resourceID string) (*model.CourseResourceFile, error) {
+	res := &model.CourseResourceFile{}
+	var (
+		intro       sql.NullString
+		fileName    sql.NullString
+		fileSize    sql.NullInt64
+		contentType sql.NullString
+		storagePath sql.NullString
+		status      sql.NullString
+	)
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT up.resource_id, up.course_id, up.resource_intro, up.resource_upload, up.file_name, up.file_size, up.content_type, up.storage_path,
+			CASE WHEN c.status = 'approved' THEN up.status ELSE c.status END AS status,
+			up.download_count, up.created_at
+		FROM course_resources_upload up
+		JOIN courses c ON c.course_id = up.course_id
+		WHERE up.resource_id = ? AND up.course_id = ?
+	`, resourceID, courseID).Scan(
+		&res.ResourceID,
+		&res.CourseID,
+		&intro,
+		&res.URL,
+		&fileName,
+		&fileSize,
+		&contentType,
+		&storagePath,
+		&status,
+		&res.Downloads,
+		&res.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get course upload resource: %v", err)
+	}
+
+	res.Intro = nullString(intro)
+	res.FileName = nullString(fileName)
+	res.FileSize = fileSize.Int64
+	res.ContentType = nullString(contentType)
+	res.StoragePath = nullString(storagePath)
+	res.Status = nullString(status)
+	return res, nil
+}
+
+func (r *courseRepository) IncrementDownloads(ctx context.Context, resourceID int) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE course_resources_upload SET download_count = download_count + 1 WHERE resource_id = ?
+	`, resourceID); err != nil {
+		return fmt.Errorf("failed to update download count: %v", err)
+	}
+	return nil
 }
 
 func (r *courseRepository) AddComment(ctx context.Context, userID int, courseID, content string) (map[string]interface{}, error) {
@@ -1011,21 +1595,83 @@ func (r *courseRepository) fetchCourseComments(ctx context.Context, courseID int
 	return out, commentTotal, nil
 }
 
+// GetPending 获取待审核课程，cursor 为偏移量，按提交时间先后排列
 func (r *courseRepository) GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) {
-	return []map[string]interface{}{
-		{
-			"submitor":     "user1",
-			"submitDate":   "2023-12-01 10:00:00",
-			"reourceId":    1,
+	if limit <= 0 {
+		limit = 10
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			c.course_id,
+			c.name,
+			c.semester,
+			COALESCE(c.credit, 0) AS credit,
+			COALESCE(c.cover, '') AS cover,
+			COALESCE(GROUP_CONCAT(DISTINCT ct.teacher_name SEPARATOR ','), '') AS teachers_csv,
+			COALESCE(GROUP_CONCAT(DISTINCT cc.category SEPARATOR ','), '') AS categories_csv,
+			u.username,
+			c.created_at
+		FROM courses c
+		LEFT JOIN course_teachers ct ON ct.course_id = c.course_id
+		LEFT JOIN course_categories cc ON cc.course_id = c.course_id
+		LEFT JOIN users u ON u.id = c.submitter_id
+		WHERE c.status = 'pending'
+		GROUP BY c.course_id, c.name, c.semester, c.credit, c.cover, u.username, c.created_at
+		ORDER BY c.created_at ASC, c.course_id ASC
+		LIMIT ? OFFSET ?
+	`, limit, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending courses: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var (
+			courseID      int
+			name          sql.NullString
+			semesterNS    sql.NullString
+			credit        int
+			cover         string
+			teachersCSV   string
+			categoriesCSV string
+			submitter     sql.NullString
+			createdAt     time.Time
+		)
+		if err := rows.Scan(&courseID, &name, &semesterNS, &credit, &cover, &teachersCSV, &categoriesCSV, &submitter, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending course: %v", err)
+		}
+
+		categories := splitCSV(categoriesCSV)
+		catagory := ""
+		if len(categories) > 0 {
+			catagory = categories[0]
+		}
+		result = append(result, map[string]interface{}{
+			"submitor":     nullString(submitter),
+			"submitDate":   createdAt.Format("2006-01-02 15:04:05"),
+			"reourceId":    courseID,
 			"resourceType": "course",
-			"resourcename": "新课程资源",
-			"catagory":     "教学资料",
-			"link":         "https://example.com/resource",
-			"description":  "课程相关资源",
-			"tags":         []string{"讲义", "视频"},
-			"file":         "resource.pdf",
-		},
-	}, nil
+			"resourcename": nullString(name),
+			"catagory":     catagory,
+			"link":         cover,
+			"description":  fmt.Sprintf("%s %d学分", nullString(semesterNS), credit),
+			"teacher":      splitCSV(teachersCSV),
+			"semester":     nullString(semesterNS),
+			"credit":       credit,
+			"tags":         categories,
+			"file":         "",
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pending courses: %v", err)
+	}
+
+	return result, nil
 }
 
 func (r *courseRepository) fetchCourseTeachers(ctx context.Context, courseID int) ([]string, error) {
@@ -1048,6 +1694,37 @@ func (r *courseRepository) fetchCourseTeachers(ctx context.Context, courseID int
 	return teachers, rows.Err()
 }
 
+// fetchCourseTeacherRefs 获取任课教师的ID和姓名，用于跳转到教师详情
+func (r *courseRepository) fetchCourseTeacherRefs(ctx context.Context, courseID int) ([]map[string]interface{}, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.teacher_id, t.name
+		FROM course_teachers ct
+		JOIN teachers t ON t.teacher_id = ct.teacher_id
+		WHERE ct.course_id = ?
+		ORDER BY t.name ASC
+	`, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course teachers: %v", err)
+	}
+	defer rows.Close()
+
+	teachers := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var (
+			teacherID int
+			name      string
+		)
+		if err := rows.Scan(&teacherID, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan teacher: %v", err)
+		}
+		teachers = append(teachers, map[string]interface{}{
+			"teacherId": teacherID,
+			"name":      name,
+		})
+	}
+	return teachers, rows.Err()
+}
+
 func (r *courseRepository) fetchCourseCategories(ctx context.Context, courseID int) ([]string, error) {
 	rows, err := r.db.QueryContext(ctx, `SELECT category FROM course_categories WHERE course_id = ? ORDER BY category ASC`, courseID)
 	if err != nil {
@@ -1070,9 +1747,9 @@ func (r *courseRepository) fetchCourseCategories(ctx context.Context, courseID i
 
 func (r *courseRepository) fetchCourseWebResources(ctx context.Context, courseID int) ([]map[string]interface{}, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT resource_id, resource_intro, resource_url
+		SELECT resource_id, chapter_id, resource_intro, resource_url
 		FROM course_resources_web
-		WHERE course_id = ?
+		WHERE course_id = ? AND status = 'approved'
 		ORDER BY sort_order ASC, resource_id ASC
 	`, courseID)
 	if err != nil {
@@ -1083,17 +1760,19 @@ func (r *courseRepository) fetchCourseWebResources(ctx context.Context, courseID
 	var res []map[string]interface{}
 	for rows.Next() {
 		var (
-			id    int
-			intro sql.NullString
-			url   sql.NullString
+			id        int
+			chapterID sql.NullInt64
+			intro     sql.NullString
+			url       sql.NullString
 		)
-		if err := rows.Scan(&id, &intro, &url); err != nil {
+		if err := rows.Scan(&id, &chapterID, &intro, &url); err != nil {
 			return nil, fmt.Errorf("failed to scan course web resource: %v", err)
 		}
 		res = append(res, map[string]interface{}{
 			"resource_intro": nullString(intro),
 			"resource_url":   nullString(url),
 			"resource_id":    id,
+			"chapter_id":     int(chapterID.Int64),
 		})
 	}
 	return res, rows.Err()
@@ -1101,9 +1780,9 @@ func (r *courseRepository) fetchCourseWebResources(ctx context.Context, courseID
 
 func (r *courseRepository) fetchCourseUploadResources(ctx context.Context, courseID int) ([]map[string]interface{}, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT resource_id, resource_intro, resource_upload
+		SELECT resource_id, chapter_id, resource_intro, resource_upload, COALESCE(file_name, ''), COALESCE(file_size, 0), download_count
 		FROM course_resources_upload
-		WHERE course_id = ?
+		WHERE course_id = ? AND status = 'approved'
 		ORDER BY sort_order ASC, resource_id ASC
 	`, courseID)
 	if err != nil {
@@ -1114,17 +1793,25 @@ func (r *courseRepository) fetchCourseUploadResources(ctx context.Context, cours
 	var res []map[string]interface{}
 	for rows.Next() {
 		var (
-			id     int
-			intro  sql.NullString
-			upload sql.NullString
+			id        int
+			chapterID sql.NullInt64
+			intro     sql.NullString
+			upload    sql.NullString
+			fileName  string
+			fileSize  int64
+			downloads int
 		)
-		if err := rows.Scan(&id, &intro, &upload); err != nil {
+		if err := rows.Scan(&id, &chapterID, &intro, &upload, &fileName, &fileSize, &downloads); err != nil {
 			return nil, fmt.Errorf("failed to scan course upload resource: %v", err)
 		}
 		res = append(res, map[string]interface{}{
 			"resource_intro":  nullString(intro),
 			"resource_upload": nullString(upload),
 			"resource_id":     id,
+			"file_name":       fileName,
+			"file_size":       fileSize,
+			"downloads":       downloads,
+			"chapter_id":      int(chapterID.Int64),
 		})
 	}
 	return res, rows.Err()
@@ -1155,3 +1842,619 @@ func (r *courseRepository) fetchCourseContributors(ctx context.Context, courseID
 	}
 	return users, rows.Err()
 }
+
+// approvedChapterCond 章节审核通过，且为节时所属章也审核通过；ch 为 course_chapters 的别名
+const approvedChapterCond = `ch.status = 'approved' AND (ch.parent_id IS NULL OR EXISTS (
+	SELECT 1 FROM course_chapters parent WHERE parent.chapter_id = ch.parent_id AND parent.status = 'approved'))`
+
+// courseCompletion 统计用户在课程中已完成的章节数和章节总数
+func courseCompletion(ctx context.Context, db *Database, userID, courseID int) (int, int, error) {
+	var completed, total int
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(p.id)
+		FROM course_chapters ch
+		LEFT JOIN chapter_progress p ON p.chapter_id = ch.chapter_id AND p.user_id = ?
+		WHERE ch.course_id = ? AND `+approvedChapterCond+`
+	`, userID, courseID).Scan(&total, &completed)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get course completion: %v", err)
+	}
+	return completed, total, nil
+}
+
+func scanCourseChapter(row rowScanner) (*model.CourseChapter, error) {
+	ch := &model.CourseChapter{}
+	var parentID, submitterID sql.NullInt64
+	var status sql.NullString
+	if err := row.Scan(&ch.ChapterID, &ch.CourseID, &parentID, &ch.Title, &ch.SortOrder, &status, &submitterID, &ch.CreatedAt); err != nil {
+		return nil, err
+	}
+	ch.ParentID = int(parentID.Int64)
+	ch.SubmitterID = int(submitterID.Int64)
+	ch.Status = nullString(status)
+	return ch, nil
+}
+
+// GetChapters 获取课程审核通过的章节，按章、节的顺序排列
+func (r *courseRepository) GetChapters(ctx context.Context, courseID int) ([]model.CourseChapter, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ch.chapter_id, ch.course_id, ch.parent_id, ch.title, ch.sort_order, ch.status, ch.submitter_id, ch.created_at
+		FROM course_chapters ch
+		WHERE ch.course_id = ? AND `+approvedChapterCond+`
+		ORDER BY ch.sort_order ASC, ch.chapter_id ASC
+	`, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course chapters: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.CourseChapter, 0)
+	for rows.Next() {
+		ch, err := scanCourseChapter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan course chapter: %v", err)
+		}
+		result = append(result, *ch)
+	}
+	return result, rows.Err()
+}
+
+// GetChapter 获取章节（任意审核状态），不存在时返回 nil
+func (r *courseRepository) GetChapter(ctx context.Context, chapterID int) (*model.CourseChapter, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT chapter_id, course_id, parent_id, title, sort_order, status, submitter_id, created_at
+		FROM course_chapters
+		WHERE chapter_id = ?
+	`, chapterID)
+	ch, err := scanCourseChapter(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get course chapter: %v", err)
+	}
+	return ch, nil
+}
+
+// CreateChapter 提交章节，进入待审核状态；SortOrder 为 0 时排在同级末尾
+func (r *courseRepository) CreateChapter(ctx context.Context, chapter *model.CourseChapter) error {
+	var parentID interface{}
+	if chapter.ParentID > 0 {
+		parentID = chapter.ParentID
+	}
+
+	if chapter.SortOrder <= 0 {
+		err := r.db.QueryRowContext(ctx, `
+			SELECT COALESCE(MAX(sort_order), 0) + 1 FROM course_chapters
+			WHERE course_id = ? AND parent_id <=> ?
+		`, chapter.CourseID, parentID).Scan(&chapter.SortOrder)
+		if err != nil {
+			return fmt.Errorf("failed to get chapter order: %v", err)
+		}
+	}
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO course_chapters (course_id, parent_id, title, sort_order, status, submitter_id, created_at)
+		VALUES (?, ?, ?, ?, 'pending', ?, ?)
+	`, chapter.CourseID, parentID, chapter.Title, chapter.SortOrder, chapter.SubmitterID, now)
+	if err != nil {
+		return fmt.Errorf("failed to create course chapter: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	chapter.ChapterID = int(id)
+	chapter.Status = model.CourseStatusPending
+	chapter.CreatedAt = now
+	return nil
+}
+
+// GetPendingChapters 获取待审核的课程章节，cursor 为偏移量
+func (r *courseRepository) GetPendingChapters(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ch.chapter_id, ch.course_id, c.name, ch.title, COALESCE(parent.title, ''), u.username, ch.created_at
+		FROM course_chapters ch
+		JOIN courses c ON c.course_id = ch.course_id
+		LEFT JOIN course_chapters parent ON parent.chapter_id = ch.parent_id
+		LEFT JOIN users u ON u.id = ch.submitter_id
+		WHERE ch.status = 'pending'
+		ORDER BY ch.created_at ASC, ch.chapter_id ASC
+		LIMIT ? OFFSET ?
+	`, limit, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending course chapters: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var (
+			chapterID   int
+			cid         int
+			courseName  sql.NullString
+			title       string
+			parentTitle string
+			submitter   sql.NullString
+			createdAt   time.Time
+		)
+		if err := rows.Scan(&chapterID, &cid, &courseName, &title, &parentTitle, &submitter, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending course chapter: %v", err)
+		}
+		description := title
+		if parentTitle != "" {
+			description = parentTitle + " / " + title
+		}
+		result = append(result, map[string]interface{}{
+			"submitor":     nullString(submitter),
+			"submitDate":   createdAt.Format("2006-01-02 15:04:05"),
+			"reourceId":    chapterID,
+			"resourceType": "course_chapter",
+			"resourcename": nullString(courseName),
+			"courseId":     cid,
+			"catagory":     "课程章节",
+			"link":         "",
+			"description":  description,
+			"tags":         []string{},
+			"file":         "",
+		})
+	}
+	return result, rows.Err()
+}
+
+// UpdateChapterStatus 审核课程章节
+func (r *courseRepository) UpdateChapterStatus(ctx context.Context, chapterID, status, rejectReason string) error {
+	return r.updateReviewStatus(ctx, "course_chapters", "chapter_id", chapterID, status, rejectReason)
+}
+
+// GetChapterProgress 获取用户在课程中已完成的章节及完成时间
+func (r *courseRepository) GetChapterProgress(ctx context.Context, userID, courseID int) (map[int]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.chapter_id, p.completed_at
+		FROM chapter_progress p
+		JOIN course_chapters ch ON ch.chapter_id = p.chapter_id
+		WHERE p.user_id = ? AND ch.course_id = ?
+	`, userID, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chapter progress: %v", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int]time.Time)
+	for rows.Next() {
+		var chapterID int
+		var completedAt time.Time
+		if err := rows.Scan(&chapterID, &completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan chapter progress: %v", err)
+		}
+		result[chapterID] = completedAt
+	}
+	return result, rows.Err()
+}
+
+// SetChapterProgress 标记章节已完成或取消完成
+func (r *courseRepository) SetChapterProgress(ctx context.Context, userID, chapterID int, completed bool) error {
+	if !completed {
+		if _, err := r.db.ExecContext(ctx, `DELETE FROM chapter_progress WHERE user_id = ? AND chapter_id = ?`, userID, chapterID); err != nil {
+			return fmt.Errorf("failed to clear chapter progress: %v", err)
+		}
+		return nil
+	}
+
+	// 重复标记时保留最早的完成时间
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT IGNORE INTO chapter_progress (user_id, chapter_id, completed_at) VALUES (?, ?, ?)
+	`, userID, chapterID, time.Now()); err != nil {
+		return fmt.Errorf("failed to save chapter progress: %v", err)
+	}
+	return nil
+}
+
+// GetCourseCompletion 统计用户在课程中已完成的章节数和章节总数
+func (r *courseRepository) GetCourseCompletion(ctx context.Context, userID, courseID int) (int, int, error) {
+	return courseCompletion(ctx, r.db, userID, courseID)
+}
+
+// courseRatingAggSQL 按课程汇总评分的子查询，用于课程列表的展示和排序
+const courseRatingAggSQL = `
+		SELECT course_id,
+			COUNT(*) AS rating_count,
+			AVG(difficulty) AS avg_difficulty,
+			AVG(workload) AS avg_workload,
+			AVG(teaching) AS avg_teaching,
+			AVG(usefulness) AS avg_usefulness
+		FROM course_ratings
+		GROUP BY course_id`
+
+// courseRatingOrder 评分排序：维度名按平均分降序，加 _asc 后缀为升序，rating 为教学质量和实用性的平均分；
+// 没有评分的课程总是排在最后
+func courseRatingOrder(sort string) (string, bool) {
+	columns := map[string]string{
+		"rating":     "(cr.avg_teaching + cr.avg_usefulness) / 2",
+		"difficulty": "cr.avg_difficulty",
+		"workload":   "cr.avg_workload",
+		"teaching":   "cr.avg_teaching",
+		"usefulness": "cr.avg_usefulness",
+	}
+	direction := "DESC"
+	if strings.HasSuffix(sort, "_asc") {
+		sort = strings.TrimSuffix(sort, "_asc")
+		direction = "ASC"
+	}
+	column, ok := columns[sort]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("cr.rating_count IS NULL, %s %s, c.course_id DESC", column, direction), true
+}
+
+// roundRating 平均分保留一位小数
+func roundRating(v sql.NullFloat64) float64 {
+	return math.Round(v.Float64*10) / 10
+}
+
+// SaveRating 保存评分，同一用户对同一次开课重复评分时覆盖原评分（包括任课教师）；教师姓名是规范写法，按姓名记录教师ID
+func (r *courseRepository) SaveRating(ctx context.Context, rating *model.CourseRating) error {
+	var review interface{}
+	if rating.Review != "" {
+		review = rating.Review
+	}
+
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO course_ratings (course_id, user_id, offering_id, teacher_id, teacher_name, semester, difficulty, workload, teaching, usefulness, review, created_at, updated_at)
+		VALUES (?, ?, ?, (SELECT teacher_id FROM teachers WHERE name = ?), ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			teacher_id = VALUES(teacher_id),
+			teacher_name = VALUES(teacher_name),
+			difficulty = VALUES(difficulty),
+			workload = VALUES(workload),
+			teaching = VALUES(teaching),
+			usefulness = VALUES(usefulness),
+			review = VALUES(review),
+			updated_at = VALUES(updated_at)
+	`, rating.CourseID, rating.UserID, rating.OfferingID, rating.Teacher, rating.Teacher, rating.Semester, rating.Difficulty, rating.Workload, rating.Teaching, rating.Usefulness, review, now, now); err != nil {
+		return fmt.Errorf("failed to save course rating: %v", err)
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, created_at FROM course_ratings
+		WHERE user_id = ? AND offering_id = ?
+	`, rating.UserID, rating.OfferingID).Scan(&rating.ID, &rating.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to get course rating: %v", err)
+	}
+	rating.UpdatedAt = now
+	return nil
+}
+
+// GetRatingSummary 获取课程的总体评分汇总和按开课（教师 + 学期）的评分汇总
+func (r *courseRepository) GetRatingSummary(ctx context.Context, courseID int) (*model.RatingSummary, []model.RatingSummary, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT teacher_name, semester, COUNT(*), AVG(difficulty), AVG(workload), AVG(teaching), AVG(usefulness)
+		FROM course_ratings
+		WHERE course_id = ?
+		GROUP BY teacher_name, semester WITH ROLLUP
+	`, courseID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query course rating summary: %v", err)
+	}
+	defer rows.Close()
+
+	overall := &model.RatingSummary{}
+	offerings := make([]model.RatingSummary, 0)
+	for rows.Next() {
+		var (
+			teacher, semester                          sql.NullString
+			count                                      int
+			difficulty, workload, teaching, usefulness sql.NullFloat64
+		)
+		if err := rows.Scan(&teacher, &semester, &count, &difficulty, &workload, &teaching, &usefulness); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan course rating summary: %v", err)
+		}
+		summary := model.RatingSummary{
+			Teacher:    nullString(teacher),
+			Semester:   nullString(semester),
+			Count:      count,
+			Difficulty: roundRating(difficulty),
+			Workload:   roundRating(workload),
+			Teaching:   roundRating(teaching),
+			Usefulness: roundRating(usefulness),
+		}
+		// WITH ROLLUP：教师为 NULL 的是总计行，学期为 NULL 的是教师小计行
+		switch {
+		case !teacher.Valid:
+			*overall = summary
+		case semester.Valid:
+			offerings = append(offerings, summary)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate course rating summary: %v", err)
+	}
+	return overall, offerings, nil
+}
+
+// ListRatings 获取课程的评分和评价，按更新时间倒序，cursor 为偏移量；userID 大于 0 时只返回该用户的评分
+func (r *courseRepository) ListRatings(ctx context.Context, courseID, userID, cursor, limit int) ([]model.CourseRating, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	query := `
+		SELECT cr.id, cr.course_id, cr.user_id, COALESCE(u.username, ''), cr.offering_id, cr.teacher_name, cr.semester,
+			cr.difficulty, cr.workload, cr.teaching, cr.usefulness, cr.review, cr.created_at, cr.updated_at
+		FROM course_ratings cr
+		LEFT JOIN users u ON u.id = cr.user_id
+		WHERE cr.course_id = ?`
+	args := []interface{}{courseID}
+	if userID > 0 {
+		query += ` AND cr.user_id = ?`
+		args = append(args, userID)
+	}
+	query += ` ORDER BY cr.updated_at DESC, cr.id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, cursor)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course ratings: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.CourseRating, 0)
+	for rows.Next() {
+		var rating model.CourseRating
+		var review sql.NullString
+		if err := rows.Scan(&rating.ID, &rating.CourseID, &rating.UserID, &rating.Username, &rating.OfferingID, &rating.Teacher, &rating.Semester,
+			&rating.Difficulty, &rating.Workload, &rating.Teaching, &rating.Usefulness, &review, &rating.CreatedAt, &rating.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan course rating: %v", err)
+		}
+		rating.Review = nullString(review)
+		result = append(result, rating)
+	}
+	return result, rows.Err()
+}
+
+// GetCourseTeachers 获取课程的任课教师
+func (r *courseRepository) GetCourseTeachers(ctx context.Context, courseID int) ([]string, error) {
+	return r.fetchCourseTeachers(ctx, courseID)
+}
+
+// GetCourseOffering 按学年和学期类型获取课程的一次开课及其任课教师，课程在该学期没有开课时返回 nil
+func (r *courseRepository) GetCourseOffering(ctx context.Context, courseID, academicYear, term int) (*model.CourseOffering, error) {
+	var offeringID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT o.offering_id
+		FROM course_offerings o
+		JOIN semesters s ON s.semester_id = o.semester_id
+		WHERE o.course_id = ? AND s.academic_year = ? AND s.term = ?
+	`, courseID, academicYear, term).Scan(&offeringID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get course offering: %v", err)
+	}
+
+	offerings, err := listCourseOfferings(ctx, r.db, courseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range offerings {
+		if offerings[i].OfferingID == offeringID {
+			return &offerings[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// prerequisiteColumns 查询修读关系的列，cp、c、rc 分别为 course_prerequisites、课程和被依赖课程的别名
+const prerequisiteColumns = `cp.relation_id, cp.course_id, c.name, cp.required_course_id, rc.name,
+	cp.relation_type, cp.note, cp.status, cp.submitter_id, cp.created_at`
+
+func scanCoursePrerequisite(row rowScanner) (*model.CoursePrerequisite, error) {
+	p := &model.CoursePrerequisite{}
+	var courseName, requiredName, note, status sql.NullString
+	var submitterID sql.NullInt64
+	if err := row.Scan(&p.RelationID, &p.CourseID, &courseName, &p.RequiredID, &requiredName,
+		&p.Relation, &note, &status, &submitterID, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	p.CourseName = nullString(courseName)
+	p.RequiredName = nullString(requiredName)
+	p.Note = nullString(note)
+	p.Status = nullString(status)
+	p.SubmitterID = int(submitterID.Int64)
+	return p, nil
+}
+
+// GetPrerequisite 获取课程对另一门课程的修读关系（任意审核状态），不存在时返回 nil
+func (r *courseRepository) GetPrerequisite(ctx context.Context, courseID, requiredID int) (*model.CoursePrerequisite, error) {
+	return r.getPrerequisite(ctx, `cp.course_id = ? AND cp.required_course_id = ?`, courseID, requiredID)
+}
+
+// GetPrerequisiteByID 按ID获取修读关系（任意审核状态），不存在时返回 nil
+func (r *courseRepository) GetPrerequisiteByID(ctx context.Context, relationID int) (*model.CoursePrerequisite, error) {
+	return r.getPrerequisite(ctx, `cp.relation_id = ?`, relationID)
+}
+
+func (r *courseRepository) getPrerequisite(ctx context.Context, cond string, args ...interface{}) (*model.CoursePrerequisite, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+prerequisiteColumns+`
+		FROM course_prerequisites cp
+		JOIN courses c ON c.course_id = cp.course_id
+		JOIN courses rc ON rc.course_id = cp.required_course_id
+		WHERE `+cond, args...)
+	p, err := scanCoursePrerequisite(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get course prerequisite: %v", err)
+	}
+	return p, nil
+}
+
+// CreatePrerequisite 提交修读关系，进入待审核状态；已驳回或下线的同一关系重新提交时覆盖原记录
+func (r *courseRepository) CreatePrerequisite(ctx context.Context, p *model.CoursePrerequisite) error {
+	var note, submitterID interface{}
+	if p.Note != "" {
+		note = p.Note
+	}
+	if p.SubmitterID > 0 {
+		submitterID = p.SubmitterID
+	}
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO course_prerequisites (course_id, required_course_id, relation_type, note, submitter_id, status, created_at)
+		VALUES (?, ?, ?, ?, ?, 'pending', ?)
+		ON DUPLICATE KEY UPDATE
+			relation_id = LAST_INSERT_ID(relation_id),
+			relation_type = VALUES(relation_type), note = VALUES(note), submitter_id = VALUES(submitter_id),
+			status = 'pending', audit_time = NULL, reject_reason = NULL, created_at = VALUES(created_at)
+	`, p.CourseID, p.RequiredID, p.Relation, note, submitterID, now)
+	if err != nil {
+		return fmt.Errorf("failed to create course prerequisite: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	p.RelationID = int(id)
+	p.Status = model.CourseStatusPending
+	p.CreatedAt = now
+	return nil
+}
+
+// ListApprovedPrerequisites 获取全部审核通过的修读关系，两端课程都必须审核通过
+func (r *courseRepository) ListApprovedPrerequisites(ctx context.Context) ([]model.CoursePrerequisite, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+prerequisiteColumns+`
+		FROM course_prerequisites cp
+		JOIN courses c ON c.course_id = cp.course_id
+		JOIN courses rc ON rc.course_id = cp.required_course_id
+		WHERE cp.status = 'approved' AND c.status = 'approved' AND rc.status = 'approved'
+		ORDER BY cp.course_id ASC, cp.required_course_id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course prerequisites: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.CoursePrerequisite, 0)
+	for rows.Next() {
+		p, err := scanCoursePrerequisite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan course prerequisite: %v", err)
+		}
+		result = append(result, *p)
+	}
+	return result, rows.Err()
+}
+
+// ListCurriculumNodes 获取全部审核通过的课程，作为课程关系图的节点
+func (r *courseRepository) ListCurriculumNodes(ctx context.Context) ([]model.CurriculumNode, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT course_id, name, semester, credit
+		FROM courses
+		WHERE status = 'approved'
+		ORDER BY course_id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query curriculum courses: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.CurriculumNode, 0)
+	for rows.Next() {
+		var node model.CurriculumNode
+		var semester sql.NullString
+		var credit sql.NullInt64
+		if err := rows.Scan(&node.CourseID, &node.Name, &semester, &credit); err != nil {
+			return nil, fmt.Errorf("failed to scan curriculum course: %v", err)
+		}
+		node.Semester = nullString(semester)
+		node.Credit = int(credit.Int64)
+		result = append(result, node)
+	}
+	return result, rows.Err()
+}
+
+// GetPendingPrerequisites 获取待审核的修读关系，cursor 为偏移量
+func (r *courseRepository) GetPendingPrerequisites(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+prerequisiteColumns+`, u.username
+		FROM course_prerequisites cp
+		JOIN courses c ON c.course_id = cp.course_id
+		JOIN courses rc ON rc.course_id = cp.required_course_id
+		LEFT JOIN users u ON u.id = cp.submitter_id
+		WHERE cp.status = 'pending'
+		ORDER BY cp.created_at ASC, cp.relation_id ASC
+		LIMIT ? OFFSET ?
+	`, limit, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending course prerequisites: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var (
+			p                                                 model.CoursePrerequisite
+			courseName, requiredName, note, status, submitter sql.NullString
+			submitterID                                       sql.NullInt64
+		)
+		if err := rows.Scan(&p.RelationID, &p.CourseID, &courseName, &p.RequiredID, &requiredName,
+			&p.Relation, &note, &status, &submitterID, &p.CreatedAt, &submitter); err != nil {
+			return nil, fmt.Errorf("failed to scan pending course prerequisite: %v", err)
+		}
+		label := "先修"
+		if p.Relation == model.RelationCorequisite {
+			label = "同修"
+		}
+		description := fmt.Sprintf("%s：%s", label, nullString(requiredName))
+		if nullString(note) != "" {
+			description += "（" + nullString(note) + "）"
+		}
+		result = append(result, map[string]interface{}{
+			"submitor":     nullString(submitter),
+			"submitDate":   p.CreatedAt.Format("2006-01-02 15:04:05"),
+			"reourceId":    p.RelationID,
+			"resourceType": "course_prerequisite",
+			"resourcename": nullString(courseName),
+			"courseId":     p.CourseID,
+			"catagory":     "修读关系",
+			"link":         "",
+			"description":  description,
+			"tags":         []string{p.Relation},
+			"file":         "",
+		})
+	}
+	return result, rows.Err()
+}
+
+// UpdatePrerequisiteStatus 审核修读关系
+func (r *courseRepository) UpdatePrerequisiteStatus(ctx context.Context, relationID, status, rejectReason string) error {
+	return r.updateReviewStatus(ctx, "course_prerequisites", "relation_id", relationID, status, rejectReason)
+}
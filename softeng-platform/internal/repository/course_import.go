@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+)
+
+// CourseImportRepository 批量导入课程目录（cmd/import）按自然键查找已有数据，写入使用 CourseRepository
+type CourseImportRepository interface {
+	// FindCourse 按自然键查找课程：名称相同，且学期名称相同或在该学期（year、term 为 0 时不比较）有开课记录
+	FindCourse(ctx context.Context, name, semester string, year, term int) (*model.CourseCatalogEntry, error)
+	// CanonicalTeacher 按姓名或别名查找教师的规范姓名，找不到时返回整理后的原姓名
+	CanonicalTeacher(ctx context.Context, name string) (string, error)
+}
+
+type courseImportRepository struct {
+	db *Database
+}
+
+func NewCourseImportRepository(db *Database) CourseImportRepository {
+	return &courseImportRepository{db: db}
+}
+
+func (r *courseImportRepository) FindCourse(ctx context.Context, name, semester string, year, term int) (*model.CourseCatalogEntry, error) {
+	entry := &model.CourseCatalogEntry{}
+	var (
+		courseSemester, cover, status sql.NullString
+		credit                        sql.NullInt64
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT c.course_id, c.name, c.semester, c.credit, c.cover, c.status,
+			EXISTS (SELECT 1 FROM course_offerings o JOIN semesters s ON s.semester_id = o.semester_id
+				WHERE o.course_id = c.course_id AND s.academic_year = ? AND s.term = ?)
+		FROM courses c
+		WHERE c.name = ? AND (COALESCE(c.semester, '') = ? OR EXISTS (
+			SELECT 1 FROM course_offerings o JOIN semesters s ON s.semester_id = o.semester_id
+			WHERE o.course_id = c.course_id AND s.academic_year = ? AND s.term = ?))
+		ORDER BY c.course_id ASC
+		LIMIT 1
+	`, year, term, name, semester, year, term).Scan(&entry.CourseID, &entry.Name, &courseSemester, &credit, &cover, &status, &entry.Offered)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find course: %v", err)
+	}
+	entry.Semester = nullString(courseSemester)
+	entry.Credit = int(credit.Int64)
+	entry.Cover = nullString(cover)
+	entry.Status = nullString(status)
+
+	lists := []struct {
+		query  string
+		target *[]string
+	}{
+		{`SELECT teacher_name FROM course_teachers WHERE course_id = ? ORDER BY id`, &entry.Teachers},
+		{`SELECT category FROM course_categories WHERE course_id = ? ORDER BY id`, &entry.Categories},
+		{`SELECT resource_url FROM course_resources_web WHERE course_id = ? ORDER BY resource_id`, &entry.WebURLs},
+		// 导入的文件按原始地址比较
+		{`SELECT COALESCE(source_url, resource_upload) FROM course_resources_upload WHERE course_id = ? ORDER BY resource_id`, &entry.UploadURLs},
+	}
+	for _, list := range lists {
+		values, err := r.queryStrings(ctx, list.query, entry.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		*list.target = values
+	}
+	return entry, nil
+}
+
+// queryStrings 查询单列字符串
+func (r *courseImportRepository) queryStrings(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course catalog: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]string, 0)
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan course catalog: %v", err)
+		}
+		result = append(result, value)
+	}
+	return result, rows.Err()
+}
+
+func (r *courseImportRepository) CanonicalTeacher(ctx context.Context, name string) (string, error) {
+	name = model.NormalizeTeacherName(name)
+	key := model.TeacherNameKey(name)
+
+	var canonical string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT t.name FROM teachers t WHERE `+teacherKeySQL("t.name")+` = ?
+		UNION
+		SELECT t.name FROM teacher_aliases a JOIN teachers t ON t.teacher_id = a.teacher_id WHERE `+teacherKeySQL("a.alias")+` = ?
+		LIMIT 1
+	`, key, key).Scan(&canonical)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return name, nil
+		}
+		return "", fmt.Errorf("failed to find teacher: %v", err)
+	}
+	return canonical, nil
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *model.Identity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*model.Identity, error)
+	ListByUser(ctx context.Context, userID int) ([]model.Identity, error)
+	Delete(ctx context.Context, userID int, provider string) error
+	TouchLogin(ctx context.Context, id int) error
+	// ProvisionWithInvitation 在同一事务内占用邀请码、创建用户并绑定第三方账号
+	ProvisionWithInvitation(ctx context.Context, invitationID int, user *model.User, identity *model.Identity) error
+
+	CreateState(ctx context.Context, state *model.OAuthState) error
+	// ConsumeState 取出并删除授权请求状态，保证每个 state 只能使用一次
+	ConsumeState(ctx context.Context, stateHash string) (*model.OAuthState, error)
+	DeleteExpiredStates(ctx context.Context) error
+}
+
+type identityRepository struct {
+	db *Database
+}
+
+func NewIdentityRepository(db *Database) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *model.Identity) error {
+	return insertIdentity(ctx, r.db, identity)
+}
+
+func insertIdentity(ctx context.Context, db execer, identity *model.Identity) error {
+	now := time.Now()
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO user_identities (user_id, provider, subject, email, display_name, created_at, last_login_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.DisplayName, now, identity.LastLoginAt)
+	if err != nil {
+		return fmt.Errorf("failed to create identity: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	identity.ID = int(id)
+	identity.CreatedAt = now
+
+	return nil
+}
+
+const identityColumns = `id, user_id, provider, subject, email, display_name, created_at, last_login_at`
+
+func scanIdentity(row rowScanner) (*model.Identity, error) {
+	identity := &model.Identity{}
+	var email, displayName sql.NullString
+	var lastLoginAt sql.NullTime
+	if err := row.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&email,
+		&displayName,
+		&identity.CreatedAt,
+		&lastLoginAt,
+	); err != nil {
+		return nil, err
+	}
+
+	identity.Email = nullString(email)
+	identity.DisplayName = nullString(displayName)
+	if lastLoginAt.Valid {
+		identity.LastLoginAt = &lastLoginAt.Time
+	}
+	return identity, nil
+}
+
+func (r *identityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*model.Identity, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+identityColumns+` FROM user_identities WHERE provider = ? AND subject = ?
+	`, provider, subject)
+	identity, err := scanIdentity(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get identity: %v", err)
+	}
+	return identity, nil
+}
+
+func (r *identityRepository) ListByUser(ctx context.Context, userID int) ([]model.Identity, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+identityColumns+` FROM user_identities WHERE user_id = ? ORDER BY id ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query identities: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.Identity, 0)
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %v", err)
+		}
+		result = append(result, *identity)
+	}
+	return result, rows.Err()
+}
+
+func (r *identityRepository) Delete(ctx context.Context, userID int, provider string) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_identities WHERE user_id = ? AND provider = ?
+	`, userID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete identity: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("identity not found")
+	}
+	return nil
+}
+
+func (r *identityRepository) TouchLogin(ctx context.Context, id int) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE user_identities SET last_login_at = ? WHERE id = ?
+	`, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to update identity: %v", err)
+	}
+	return nil
+}
+
+func (r *identityRepository) ProvisionWithInvitation(ctx context.Context, invitationID int, user *model.User, identity *model.Identity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := registerWithInvitationTx(ctx, tx, invitationID, user); err != nil {
+		return err
+	}
+
+	identity.UserID = user.ID
+	if err := insertIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+func (r *identityRepository) CreateState(ctx context.Context, state *model.OAuthState) error {
+	var userID, invitationCode interface{}
+	if state.UserID != 0 {
+		userID = state.UserID
+	}
+	if state.InvitationCode != "" {
+		invitationCode = state.InvitationCode
+	}
+
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO oauth_states (state_hash, provider, code_verifier, user_id, invitation_code, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, state.StateHash, state.Provider, state.CodeVerifier, userID, invitationCode, state.ExpiresAt, now); err != nil {
+		return fmt.Errorf("failed to create oauth state: %v", err)
+	}
+	state.CreatedAt = now
+	return nil
+}
+
+func (r *identityRepository) ConsumeState(ctx context.Context, stateHash string) (*model.OAuthState, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	state := &model.OAuthState{}
+	var userID sql.NullInt64
+	var invitationCode sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT state_hash, provider, code_verifier, user_id, invitation_code, expires_at, created_at
+		FROM oauth_states
+		WHERE state_hash = ?
+		FOR UPDATE
+	`, stateHash).Scan(
+		&state.StateHash,
+		&state.Provider,
+		&state.CodeVerifier,
+		&userID,
+		&invitationCode,
+		&state.ExpiresAt,
+		&state.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get oauth state: %v", err)
+	}
+	state.UserID = int(userID.Int64)
+	state.InvitationCode = nullString(invitationCode)
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM oauth_states WHERE state_hash = ?`, stateHash); err != nil {
+		return nil, fmt.Errorf("failed to delete oauth state: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return state, nil
+}
+
+func (r *identityRepository) DeleteExpiredStates(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oauth_states WHERE expires_at <= ?`, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete expired oauth states: %v", err)
+	}
+	return nil
+}
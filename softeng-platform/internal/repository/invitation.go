@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+// ErrInvitationUnavailable 邀请码不存在、已撤销、已过期或次数已用完
+var ErrInvitationUnavailable = errors.New("invitation code is invalid or no longer available")
+
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *model.Invitation) error
+	GetByID(ctx context.Context, id int) (*model.Invitation, error)
+	GetByCode(ctx context.Context, code string) (*model.Invitation, error)
+	List(ctx context.Context, cursor, limit int) ([]model.Invitation, error)
+	GetRedemptions(ctx context.Context, invitationID int) ([]model.InvitationRedemption, error)
+	Revoke(ctx context.Context, id int) error
+	// RegisterWithInvitation 在同一事务内使用注册验证码、占用一次邀请码、创建用户并记录兑换，
+	// 任一步失败时验证码仍可再次使用
+	RegisterWithInvitation(ctx context.Context, invitationID, verificationCodeID int, user *model.User) error
+}
+
+type invitationRepository struct {
+	db    *Database
+	codes VerificationRepository // 注册时在同一事务内使用验证码
+}
+
+func NewInvitationRepository(db *Database, codes VerificationRepository) InvitationRepository {
+	return &invitationRepository{db: db, codes: codes}
+}
+
+const invitationColumns = `id, code, max_uses, used_count, expires_at, role, cohort, note, created_by, revoked_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvitation(row rowScanner) (*model.Invitation, error) {
+	inv := &model.Invitation{}
+	var (
+		expiresAt sql.NullTime
+		revokedAt sql.NullTime
+		role      sql.NullString
+		cohort    sql.NullString
+		note      sql.NullString
+		createdBy sql.NullInt64
+	)
+	if err := row.Scan(
+		&inv.ID,
+		&inv.Code,
+		&inv.MaxUses,
+		&inv.UsedCount,
+		&expiresAt,
+		&role,
+		&cohort,
+		&note,
+		&createdBy,
+		&revokedAt,
+		&inv.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		inv.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		inv.RevokedAt = &revokedAt.Time
+	}
+	inv.Role = nullString(role)
+	inv.Cohort = nullString(cohort)
+	inv.Note = nullString(note)
+	inv.CreatedBy = int(createdBy.Int64)
+	return inv, nil
+}
+
+func (r *invitationRepository) Create(ctx context.Context, inv *model.Invitation) error {
+	var cohort, note interface{}
+	if inv.Cohort != "" {
+		cohort = inv.Cohort
+	}
+	if inv.Note != "" {
+		note = inv.Note
+	}
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO invitation_codes (code, max_uses, used_count, expires_at, role, cohort, note, created_by, created_at)
+		VALUES (?, ?, 0, ?, ?, ?, ?, ?, ?)
+	`, inv.Code, inv.MaxUses, inv.ExpiresAt, inv.Role, cohort, note, inv.CreatedBy, now)
+	if err != nil {
+		return fmt.Errorf("failed to create invitation: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	inv.ID = int(id)
+	inv.CreatedAt = now
+
+	return nil
+}
+
+func (r *invitationRepository) GetByID(ctx context.Context, id int) (*model.Invitation, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+invitationColumns+` FROM invitation_codes WHERE id = ?`, id)
+	inv, err := scanInvitation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invitation by id: %v", err)
+	}
+	return inv, nil
+}
+
+func (r *invitationRepository) GetByCode(ctx context.Context, code string) (*model.Invitation, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+invitationColumns+` FROM invitation_codes WHERE code = ?`, code)
+	inv, err := scanInvitation(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invitation by code: %v", err)
+	}
+	return inv, nil
+}
+
+func (r *invitationRepository) List(ctx context.Context, cursor, limit int) ([]model.Invitation, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	query := `SELECT ` + invitationColumns + ` FROM invitation_codes`
+	var args []interface{}
+	if cursor > 0 {
+		query += ` WHERE id < ?`
+		args = append(args, cursor)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invitations: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.Invitation, 0)
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %v", err)
+		}
+		result = append(result, *inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate invitations: %v", err)
+	}
+
+	return result, nil
+}
+
+func (r *invitationRepository) GetRedemptions(ctx context.Context, invitationID int) ([]model.InvitationRedemption, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ir.user_id, u.username, u.email, ir.redeemed_at
+		FROM invitation_redemptions ir
+		JOIN users u ON u.id = ir.user_id
+		WHERE ir.invitation_id = ?
+		ORDER BY ir.redeemed_at ASC, ir.id ASC
+	`, invitationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invitation redemptions: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.InvitationRedemption, 0)
+	for rows.Next() {
+		var rd model.InvitationRedemption
+		if err := rows.Scan(&rd.UserID, &rd.Username, &rd.Email, &rd.RedeemedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan invitation redemption: %v", err)
+		}
+		result = append(result, rd)
+	}
+	return result, rows.Err()
+}
+
+func (r *invitationRepository) Revoke(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE invitation_codes SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL
+	`, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("invitation not found or already revoked")
+	}
+	return nil
+}
+
+func (r *invitationRepository) RegisterWithInvitation(ctx context.Context, invitationID, verificationCodeID int, user *model.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	consumed, err := r.codes.MarkConsumedTx(ctx, tx, verificationCodeID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrVerificationCodeConsumed
+	}
+
+	if err := registerWithInvitationTx(ctx, tx, invitationID, user); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// registerWithInvitationTx 在调用方的事务内占用邀请码、创建用户并记录兑换
+func registerWithInvitationTx(ctx context.Context, tx *sql.Tx, invitationID int, user *model.User) error {
+	// 条件更新保证并发注册时不会超出最大使用次数
+	result, err := tx.ExecContext(ctx, `
+		UPDATE invitation_codes
+		SET used_count = used_count + 1
+		WHERE id = ?
+			AND revoked_at IS NULL
+			AND (expires_at IS NULL OR expires_at > ?)
+			AND used_count < max_uses
+	`, invitationID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to consume invitation: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if affected == 0 {
+		return ErrInvitationUnavailable
+	}
+
+	if err := insertUser(ctx, tx, user); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO invitation_redemptions (invitation_id, user_id, redeemed_at)
+		VALUES (?, ?, ?)
+	`, invitationID, user.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record invitation redemption: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"sort"
+	"time"
+)
+
+type LearningPlanRepository interface {
+	// IsCourseApproved 课程存在且审核通过时返回 true
+	IsCourseApproved(ctx context.Context, courseID int) (bool, error)
+	Get(ctx context.Context, userID, courseID int) (*model.LearningPlanItem, error)
+	// List 获取用户的学习计划，按学期先后和学期内顺序排列
+	List(ctx context.Context, userID int) ([]model.LearningPlanItem, error)
+	// Save 加入课程，课程已在计划中时更新学期、顺序和状态
+	Save(ctx context.Context, item *model.LearningPlanItem) error
+	// NextSortOrder 返回学期末尾的顺序号
+	NextSortOrder(ctx context.Context, userID int, semester string) (int, error)
+	// Delete 移除课程，返回 false 表示课程不在计划中
+	Delete(ctx context.Context, userID, courseID int) (bool, error)
+	// Reorder 按 courseIDs 的顺序重排学期内的课程
+	Reorder(ctx context.Context, userID int, semester string, courseIDs []int) error
+}
+
+type learningPlanRepository struct {
+	db *Database
+}
+
+func NewLearningPlanRepository(db *Database) LearningPlanRepository {
+	return &learningPlanRepository{db: db}
+}
+
+const learningPlanColumns = `lp.id, lp.user_id, lp.course_id, c.name, COALESCE(c.credit, 0), lp.semester, lp.sort_order, lp.status, lp.created_at, lp.updated_at,
+	(SELECT COUNT(*) FROM course_chapters ch WHERE ch.course_id = lp.course_id AND ` + approvedChapterCond + `),
+	(SELECT COUNT(*) FROM chapter_progress p JOIN course_chapters ch ON ch.chapter_id = p.chapter_id
+		WHERE p.user_id = lp.user_id AND ch.course_id = lp.course_id AND ` + approvedChapterCond + `)`
+
+func scanLearningPlanItem(row rowScanner) (*model.LearningPlanItem, error) {
+	item := &model.LearningPlanItem{}
+	if err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.CourseID,
+		&item.CourseName,
+		&item.Credit,
+		&item.Semester,
+		&item.SortOrder,
+		&item.Status,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+		&item.TotalChapters,
+		&item.CompletedChapters,
+	); err != nil {
+		return nil, err
+	}
+	item.Completion = model.CompletionPercent(item.CompletedChapters, item.TotalChapters)
+	return item, nil
+}
+
+func (r *learningPlanRepository) IsCourseApproved(ctx context.Context, courseID int) (bool, error) {
+	var exists int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT 1 FROM courses WHERE course_id = ? AND status = 'approved'
+	`, courseID).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check course: %v", err)
+	}
+	return true, nil
+}
+
+func (r *learningPlanRepository) Get(ctx context.Context, userID, courseID int) (*model.LearningPlanItem, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+learningPlanColumns+`
+		FROM learning_plans lp
+		JOIN courses c ON c.course_id = lp.course_id
+		WHERE lp.user_id = ? AND lp.course_id = ?
+	`, userID, courseID)
+	item, err := scanLearningPlanItem(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get learning plan item: %v", err)
+	}
+	return item, nil
+}
+
+func (r *learningPlanRepository) List(ctx context.Context, userID int) ([]model.LearningPlanItem, error) {
+	return listLearningPlan(ctx, r.db, userID)
+}
+
+// listLearningPlan 查询用户的学习计划，个人数据导出也复用此查询
+func listLearningPlan(ctx context.Context, db *Database, userID int) ([]model.LearningPlanItem, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+learningPlanColumns+`
+		FROM learning_plans lp
+		JOIN courses c ON c.course_id = lp.course_id
+		WHERE lp.user_id = ?
+		ORDER BY lp.semester, lp.sort_order, lp.id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query learning plan: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.LearningPlanItem, 0)
+	for rows.Next() {
+		item, err := scanLearningPlanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan learning plan item: %v", err)
+		}
+		result = append(result, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate learning plan: %v", err)
+	}
+
+	// 学期名称按字符串排序时 "2024夏季" 会排在 "2024春季" 之前，按学期先后重排，学期内保持查询顺序
+	sort.SliceStable(result, func(i, j int) bool {
+		return model.CompareSemesters(result[i].Semester, result[j].Semester) < 0
+	})
+	return result, nil
+}
+
+func (r *learningPlanRepository) Save(ctx context.Context, item *model.LearningPlanItem) error {
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO learning_plans (user_id, course_id, semester, sort_order, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			semester = VALUES(semester),
+			sort_order = VALUES(sort_order),
+			status = VALUES(status),
+			updated_at = VALUES(updated_at)
+	`, item.UserID, item.CourseID, item.Semester, item.SortOrder, item.Status, now, now); err != nil {
+		return fmt.Errorf("failed to save learning plan item: %v", err)
+	}
+	return nil
+}
+
+func (r *learningPlanRepository) NextSortOrder(ctx context.Context, userID int, semester string) (int, error) {
+	var max int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(sort_order), 0) FROM learning_plans WHERE user_id = ? AND semester = ?
+	`, userID, semester).Scan(&max)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get learning plan order: %v", err)
+	}
+	return max + 1, nil
+}
+
+func (r *learningPlanRepository) Delete(ctx context.Context, userID, courseID int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM learning_plans WHERE user_id = ? AND course_id = ?
+	`, userID, courseID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete learning plan item: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *learningPlanRepository) Reorder(ctx context.Context, userID int, semester string, courseIDs []int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	for i, courseID := range courseIDs {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE learning_plans SET sort_order = ?, updated_at = ?
+			WHERE user_id = ? AND course_id = ? AND semester = ?
+		`, i+1, now, userID, courseID, semester); err != nil {
+			return fmt.Errorf("failed to reorder learning plan: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID int) (*model.UserMFA, error)
+	// SaveSecret 保存待确认的密钥，覆盖之前未启用的密钥
+	SaveSecret(ctx context.Context, userID int, secret string) error
+	// Enable 启用两步验证并写入恢复码（哈希）
+	Enable(ctx context.Context, userID int, counter int64, recoveryCodeHashes []string) error
+	// UseCounter 记录已使用的时间步，返回 false 表示该时间步已被使用（重放）
+	UseCounter(ctx context.Context, userID int, counter int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error
+	// UseRecoveryCode 使用一次恢复码，返回 false 表示恢复码不存在或已使用
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+	// Delete 关闭两步验证，同时删除恢复码
+	Delete(ctx context.Context, userID int) error
+}
+
+type mfaRepository struct {
+	db *Database
+}
+
+func NewMFARepository(db *Database) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) GetByUserID(ctx context.Context, userID int) (*model.UserMFA, error) {
+	mfa := &model.UserMFA{}
+	var enabledAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, secret, last_counter, enabled_at, created_at
+		FROM user_mfa
+		WHERE user_id = ?
+	`, userID).Scan(&mfa.UserID, &mfa.Secret, &mfa.LastCounter, &enabledAt, &mfa.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user mfa: %v", err)
+	}
+
+	if enabledAt.Valid {
+		mfa.EnabledAt = &enabledAt.Time
+	}
+	return mfa, nil
+}
+
+func (r *mfaRepository) SaveSecret(ctx context.Context, userID int, secret string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_mfa (user_id, secret, last_counter, enabled_at, created_at)
+		VALUES (?, ?, 0, NULL, ?)
+		ON DUPLICATE KEY UPDATE secret = VALUES(secret), last_counter = 0, created_at = VALUES(created_at)
+	`, userID, secret, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save mfa secret: %v", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) Enable(ctx context.Context, userID int, counter int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_mfa SET enabled_at = ?, last_counter = ? WHERE user_id = ? AND enabled_at IS NULL
+	`, time.Now(), counter, userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable mfa: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("mfa setup not found or already enabled")
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) UseCounter(ctx context.Context, userID int, counter int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_mfa SET last_counter = ? WHERE user_id = ? AND last_counter < ?
+	`, counter, userID, counter)
+	if err != nil {
+		return false, fmt.Errorf("failed to update mfa counter: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 删除旧恢复码并写入新的一组
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int, hashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %v", err)
+	}
+
+	now := time.Now()
+	for _, hash := range hashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)
+		`, userID, hash, now); err != nil {
+			return fmt.Errorf("failed to create recovery code: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE mfa_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+	`, time.Now(), userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = ? AND used_at IS NULL
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %v", err)
+	}
+	return count, nil
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete user mfa: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"strings"
+	"time"
+)
+
+type PersonalTokenRepository interface {
+	Create(ctx context.Context, token *model.PersonalToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*model.PersonalToken, error)
+	ListByUser(ctx context.Context, userID int) ([]model.PersonalToken, error)
+	CountActive(ctx context.Context, userID int) (int, error)
+	Revoke(ctx context.Context, userID, id int) error
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+type personalTokenRepository struct {
+	db *Database
+}
+
+func NewPersonalTokenRepository(db *Database) PersonalTokenRepository {
+	return &personalTokenRepository{db: db}
+}
+
+const personalTokenColumns = `id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func scanPersonalToken(row rowScanner) (*model.PersonalToken, error) {
+	token := &model.PersonalToken{}
+	var (
+		scopes     string
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+		revokedAt  sql.NullTime
+	)
+	if err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.Prefix,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	token.Scopes = splitCSV(scopes)
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return token, nil
+}
+
+func (r *personalTokenRepository) Create(ctx context.Context, token *model.PersonalToken) error {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, token.UserID, token.Name, token.TokenHash, token.Prefix, strings.Join(token.Scopes, ","), token.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create personal token: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	token.ID = int(id)
+	token.CreatedAt = now
+
+	return nil
+}
+
+func (r *personalTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.PersonalToken, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+personalTokenColumns+` FROM personal_access_tokens WHERE token_hash = ?`, tokenHash)
+	token, err := scanPersonalToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get personal token: %v", err)
+	}
+	return token, nil
+}
+
+func (r *personalTokenRepository) ListByUser(ctx context.Context, userID int) ([]model.PersonalToken, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+personalTokenColumns+`
+		FROM personal_access_tokens
+		WHERE user_id = ? AND revoked_at IS NULL
+		ORDER BY created_at DESC, id DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query personal tokens: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.PersonalToken, 0)
+	for rows.Next() {
+		token, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan personal token: %v", err)
+		}
+		result = append(result, *token)
+	}
+	return result, rows.Err()
+}
+
+func (r *personalTokenRepository) CountActive(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM personal_access_tokens
+		WHERE user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+	`, userID, time.Now()).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count personal tokens: %v", err)
+	}
+	return count, nil
+}
+
+func (r *personalTokenRepository) Revoke(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE personal_access_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`, time.Now(), id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke personal token: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("token not found")
+	}
+	return nil
+}
+
+func (r *personalTokenRepository) TouchLastUsed(ctx context.Context, id int) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE personal_access_tokens SET last_used_at = ? WHERE id = ?`, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to update personal token last used: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"strings"
+	"time"
+)
+
+// ErrSemesterExists 学年和学期类型或名称已被其他学期使用
+var ErrSemesterExists = errors.New("semester already exists")
+
+type SemesterRepository interface {
+	// List 获取全部学期，按时间先后排列
+	List(ctx context.Context) ([]model.Semester, error)
+	GetByID(ctx context.Context, semesterID int) (*model.Semester, error)
+	Create(ctx context.Context, semester *model.Semester) error
+	// Update 修改学期名称和起止日期，改名时同步课程中保存的学期名称
+	Update(ctx context.Context, semester *model.Semester) error
+	// ListOfferings 获取课程的开课记录，按学期先后排列
+	ListOfferings(ctx context.Context, courseID int) ([]model.CourseOffering, error)
+	// AddOffering 为课程添加开课，同一学期已有开课时合并任课教师
+	AddOffering(ctx context.Context, courseID, semesterID int, teachers []string) error
+}
+
+type semesterRepository struct {
+	db *Database
+}
+
+func NewSemesterRepository(db *Database) SemesterRepository {
+	return &semesterRepository{db: db}
+}
+
+// semesterOrderSQL 与 model.SemesterOrderKey 对应的 SQL 表达式
+func semesterOrderSQL(alias string) string {
+	return fmt.Sprintf("(%s.academic_year * 10 + %s.term)", alias, alias)
+}
+
+const semesterColumns = `s.semester_id, s.name, s.academic_year, s.term, s.start_date, s.end_date, s.created_at, s.updated_at,
+	(SELECT COUNT(DISTINCT o.course_id) FROM course_offerings o JOIN courses c ON c.course_id = o.course_id
+		WHERE o.semester_id = s.semester_id AND c.status = 'approved')`
+
+func scanSemester(row rowScanner) (*model.Semester, error) {
+	s := &model.Semester{}
+	var startDate, endDate sql.NullTime
+	if err := row.Scan(&s.SemesterID, &s.Name, &s.AcademicYear, &s.Term, &startDate, &endDate, &s.CreatedAt, &s.UpdatedAt, &s.CourseCount); err != nil {
+		return nil, err
+	}
+	if startDate.Valid {
+		s.StartDate = startDate.Time.Format(model.SemesterDateLayout)
+	}
+	if endDate.Valid {
+		s.EndDate = endDate.Time.Format(model.SemesterDateLayout)
+	}
+	return s, nil
+}
+
+// nullDate 空字符串写入 NULL
+func nullDate(date string) interface{} {
+	if date == "" {
+		return nil
+	}
+	return date
+}
+
+func (r *semesterRepository) List(ctx context.Context) ([]model.Semester, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+semesterColumns+`
+		FROM semesters s
+		ORDER BY `+semesterOrderSQL("s")+` ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query semesters: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.Semester, 0)
+	for rows.Next() {
+		s, err := scanSemester(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan semester: %v", err)
+		}
+		result = append(result, *s)
+	}
+	return result, rows.Err()
+}
+
+func (r *semesterRepository) GetByID(ctx context.Context, semesterID int) (*model.Semester, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+semesterColumns+` FROM semesters s WHERE s.semester_id = ?`, semesterID)
+	s, err := scanSemester(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get semester: %v", err)
+	}
+	return s, nil
+}
+
+func (r *semesterRepository) Create(ctx context.Context, semester *model.Semester) error {
+	var exists int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM semesters WHERE (academic_year = ? AND term = ?) OR name = ?
+	`, semester.AcademicYear, semester.Term, semester.Name).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check semester: %v", err)
+	}
+	if exists > 0 {
+		return ErrSemesterExists
+	}
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO semesters (name, academic_year, term, start_date, end_date, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, semester.Name, semester.AcademicYear, semester.Term, nullDate(semester.StartDate), nullDate(semester.EndDate), now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create semester: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	semester.SemesterID = int(id)
+	semester.CreatedAt = now
+	semester.UpdatedAt = now
+	return nil
+}
+
+func (r *semesterRepository) Update(ctx context.Context, semester *model.Semester) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var oldName string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM semesters WHERE semester_id = ? FOR UPDATE`, semester.SemesterID).Scan(&oldName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("semester not found")
+		}
+		return fmt.Errorf("failed to get semester: %v", err)
+	}
+	var taken int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM semesters WHERE name = ? AND semester_id <> ?
+	`, semester.Name, semester.SemesterID).Scan(&taken); err != nil {
+		return fmt.Errorf("failed to check semester name: %v", err)
+	}
+	if taken > 0 {
+		return ErrSemesterExists
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE semesters SET name = ?, start_date = ?, end_date = ?, updated_at = ? WHERE semester_id = ?
+	`, semester.Name, nullDate(semester.StartDate), nullDate(semester.EndDate), time.Now(), semester.SemesterID); err != nil {
+		return fmt.Errorf("failed to update semester: %v", err)
+	}
+	if oldName != semester.Name {
+		if _, err := tx.ExecContext(ctx, `UPDATE courses SET semester = ? WHERE semester = ?`, semester.Name, oldName); err != nil {
+			return fmt.Errorf("failed to rename course semester: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+func (r *semesterRepository) ListOfferings(ctx context.Context, courseID int) ([]model.CourseOffering, error) {
+	return listCourseOfferings(ctx, r.db, courseID)
+}
+
+func (r *semesterRepository) AddOffering(ctx context.Context, courseID, semesterID int, teachers []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	teacherIDs := make([]int, 0, len(teachers))
+	for _, teacher := range teachers {
+		teacherID, canonical, err := resolveTeacher(ctx, tx, teacher)
+		if err != nil {
+			return err
+		}
+		// 开课教师同时也是课程的任课教师
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO course_teachers (course_id, teacher_id, teacher_name) VALUES (?, ?, ?)`, courseID, teacherID, canonical); err != nil {
+			return fmt.Errorf("failed to insert course teacher: %v", err)
+		}
+		teacherIDs = append(teacherIDs, teacherID)
+	}
+	if err := createOffering(ctx, tx, courseID, semesterID, teacherIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// resolveSemester 按学年和学期类型查找学期，找不到时按规范名称创建，返回学期ID和名称
+func resolveSemester(ctx context.Context, tx *sql.Tx, academicYear, term int) (int, string, error) {
+	var (
+		semesterID int
+		name       string
+	)
+	err := tx.QueryRowContext(ctx, `
+		SELECT semester_id, name FROM semesters WHERE academic_year = ? AND term = ?
+	`, academicYear, term).Scan(&semesterID, &name)
+	if err == nil {
+		return semesterID, name, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, "", fmt.Errorf("failed to find semester: %v", err)
+	}
+
+	name = model.SemesterName(academicYear, term)
+	res, err := tx.ExecContext(ctx, `INSERT INTO semesters (name, academic_year, term) VALUES (?, ?, ?)`, name, academicYear, term)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create semester: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get semester id: %v", err)
+	}
+	return int(id), name, nil
+}
+
+// createOffering 创建开课（已存在时复用）并关联任课教师
+func createOffering(ctx context.Context, tx *sql.Tx, courseID, semesterID int, teacherIDs []int) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT IGNORE INTO course_offerings (course_id, semester_id) VALUES (?, ?)
+	`, courseID, semesterID); err != nil {
+		return fmt.Errorf("failed to create course offering: %v", err)
+	}
+	var offeringID int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT offering_id FROM course_offerings WHERE course_id = ? AND semester_id = ?
+	`, courseID, semesterID).Scan(&offeringID); err != nil {
+		return fmt.Errorf("failed to get course offering: %v", err)
+	}
+	for _, teacherID := range teacherIDs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT IGNORE INTO course_offering_teachers (offering_id, teacher_id) VALUES (?, ?)
+		`, offeringID, teacherID); err != nil {
+			return fmt.Errorf("failed to insert offering teacher: %v", err)
+		}
+	}
+	return nil
+}
+
+// listCourseOfferings 获取课程的开课记录和各次开课的任课教师，按学期先后排列
+func listCourseOfferings(ctx context.Context, db *Database, courseID int) ([]model.CourseOffering, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT o.offering_id, o.course_id, o.semester_id, s.name, t.teacher_id, t.name
+		FROM course_offerings o
+		JOIN semesters s ON s.semester_id = o.semester_id
+		LEFT JOIN course_offering_teachers ot ON ot.offering_id = o.offering_id
+		LEFT JOIN teachers t ON t.teacher_id = ot.teacher_id
+		WHERE o.course_id = ?
+		ORDER BY `+semesterOrderSQL("s")+` ASC, o.offering_id ASC, t.name ASC
+	`, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query course offerings: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.CourseOffering, 0)
+	for rows.Next() {
+		var (
+			offering    model.CourseOffering
+			teacherID   sql.NullInt64
+			teacherName sql.NullString
+		)
+		if err := rows.Scan(&offering.OfferingID, &offering.CourseID, &offering.SemesterID, &offering.Semester, &teacherID, &teacherName); err != nil {
+			return nil, fmt.Errorf("failed to scan course offering: %v", err)
+		}
+		// 同一次开课的多位教师是连续的行
+		n := len(result)
+		if n == 0 || result[n-1].OfferingID != offering.OfferingID {
+			offering.Teachers = make([]model.TeacherRef, 0)
+			result = append(result, offering)
+			n++
+		}
+		if teacherID.Valid {
+			result[n-1].Teachers = append(result[n-1].Teachers, model.TeacherRef{TeacherID: int(teacherID.Int64), Name: nullString(teacherName)})
+		}
+	}
+	return result, rows.Err()
+}
+
+// semesterFilterSQL 生成课程列表的学期筛选条件，c 为 courses 的别名；学期ID和范围要求同一次开课同时满足，
+// 范围两端的学期不存在时没有课程满足条件
+func semesterFilterSQL(f model.SemesterFilter) (string, []interface{}) {
+	var (
+		parts []string
+		args  []interface{}
+	)
+
+	var conds []string
+	if len(f.IDs) > 0 {
+		conds = append(conds, "o.semester_id IN ("+placeholders(len(f.IDs))+")")
+		for _, id := range f.IDs {
+			args = append(args, id)
+		}
+	}
+	if f.FromID > 0 {
+		conds = append(conds, semesterOrderSQL("s")+" >= (SELECT "+semesterOrderSQL("sf")+" FROM semesters sf WHERE sf.semester_id = ?)")
+		args = append(args, f.FromID)
+	}
+	if f.ToID > 0 {
+		conds = append(conds, semesterOrderSQL("s")+" <= (SELECT "+semesterOrderSQL("st")+" FROM semesters st WHERE st.semester_id = ?)")
+		args = append(args, f.ToID)
+	}
+	if len(conds) > 0 {
+		parts = append(parts, `EXISTS (SELECT 1 FROM course_offerings o JOIN semesters s ON s.semester_id = o.semester_id
+			WHERE o.course_id = c.course_id AND `+strings.Join(conds, " AND ")+`)`)
+	}
+
+	// 按名称筛选时兼容没有开课记录的旧数据
+	if f.Name != "" {
+		parts = append(parts, `(c.semester = ? OR EXISTS (SELECT 1 FROM course_offerings o JOIN semesters s ON s.semester_id = o.semester_id
+			WHERE o.course_id = c.course_id AND s.name = ?))`)
+		args = append(args, f.Name, f.Name)
+	}
+	return strings.Join(parts, " AND "), args
+}
@@ -0,0 +1,432 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+var (
+	ErrTeacherNotFound = errors.New("teacher not found")
+	// ErrTeacherNameTaken 姓名或别名已属于另一位教师
+	ErrTeacherNameTaken = errors.New("name or alias already belongs to another teacher")
+)
+
+type TeacherRepository interface {
+	// List 获取讲授审核通过课程的教师，keyword 匹配姓名和别名，cursor 为偏移量
+	List(ctx context.Context, keyword string, cursor, limit int) ([]model.Teacher, error)
+	GetByID(ctx context.Context, teacherID int) (*model.Teacher, error)
+	// ListCourses 获取教师讲授的审核通过的课程，按学期倒序排列
+	ListCourses(ctx context.Context, teacherID int) ([]model.TeacherCourse, error)
+	// GetRatingSummary 汇总所有课程中对该教师的评分
+	GetRatingSummary(ctx context.Context, teacherID int) (model.RatingSummary, error)
+	// Update 修改教师资料，aliases 为 nil 时不修改别名；改名时同步课程和评分中的教师姓名
+	Update(ctx context.Context, teacher *model.Teacher, aliases []string) error
+	// Merge 将 sourceID 的课程、评分和写法合并到 targetID，并删除 sourceID
+	Merge(ctx context.Context, targetID, sourceID int) error
+}
+
+type teacherRepository struct {
+	db *Database
+}
+
+func NewTeacherRepository(db *Database) TeacherRepository {
+	return &teacherRepository{db: db}
+}
+
+// teacherKeySQL 与 model.TeacherNameKey 对应的 SQL 表达式，比较时大小写不敏感（由排序规则保证）
+func teacherKeySQL(column string) string {
+	return fmt.Sprintf("REPLACE(REPLACE(REPLACE(%s, ' ', ''), '　', ''), '\t', '')", column)
+}
+
+const teacherColumns = `t.teacher_id, t.name, COALESCE(t.title, ''), COALESCE(t.department, ''), COALESCE(t.email, ''),
+	COALESCE(t.homepage, ''), COALESCE(t.avatar, ''), COALESCE(t.bio, ''), t.created_at, t.updated_at,
+	(SELECT COUNT(DISTINCT ct.course_id) FROM course_teachers ct JOIN courses c ON c.course_id = ct.course_id
+		WHERE ct.teacher_id = t.teacher_id AND c.status = 'approved')`
+
+func scanTeacher(row rowScanner) (*model.Teacher, error) {
+	t := &model.Teacher{}
+	if err := row.Scan(
+		&t.TeacherID,
+		&t.Name,
+		&t.Title,
+		&t.Department,
+		&t.Email,
+		&t.Homepage,
+		&t.Avatar,
+		&t.Bio,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.CourseCount,
+	); err != nil {
+		return nil, err
+	}
+	t.Aliases = make([]string, 0)
+	return t, nil
+}
+
+func (r *teacherRepository) List(ctx context.Context, keyword string, cursor, limit int) ([]model.Teacher, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	whereSQL := `WHERE EXISTS (SELECT 1 FROM course_teachers ct JOIN courses c ON c.course_id = ct.course_id
+		WHERE ct.teacher_id = t.teacher_id AND c.status = 'approved')`
+	args := []interface{}{}
+	if keyword != "" {
+		whereSQL += ` AND (t.name LIKE ? OR EXISTS (SELECT 1 FROM teacher_aliases a WHERE a.teacher_id = t.teacher_id AND a.alias LIKE ?))`
+		args = append(args, "%"+keyword+"%", "%"+keyword+"%")
+	}
+	args = append(args, limit, cursor)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+teacherColumns+`
+		FROM teachers t
+		`+whereSQL+`
+		ORDER BY t.name ASC, t.teacher_id ASC
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teachers: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.Teacher, 0)
+	for rows.Next() {
+		t, err := scanTeacher(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan teacher: %v", err)
+		}
+		result = append(result, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate teachers: %v", err)
+	}
+	if len(result) == 0 {
+		return result, nil
+	}
+
+	// 一次查询补齐本页教师的别名
+	ids := make([]interface{}, len(result))
+	index := make(map[int]int, len(result))
+	for i, t := range result {
+		ids[i] = t.TeacherID
+		index[t.TeacherID] = i
+	}
+	aliasRows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT teacher_id, alias FROM teacher_aliases WHERE teacher_id IN (%s) ORDER BY alias ASC
+	`, placeholders(len(ids))), ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teacher aliases: %v", err)
+	}
+	defer aliasRows.Close()
+	for aliasRows.Next() {
+		var (
+			teacherID int
+			alias     string
+		)
+		if err := aliasRows.Scan(&teacherID, &alias); err != nil {
+			return nil, fmt.Errorf("failed to scan teacher alias: %v", err)
+		}
+		t := &result[index[teacherID]]
+		t.Aliases = append(t.Aliases, alias)
+	}
+	return result, aliasRows.Err()
+}
+
+func (r *teacherRepository) GetByID(ctx context.Context, teacherID int) (*model.Teacher, error) {
+	t, err := scanTeacher(r.db.QueryRowContext(ctx, `
+		SELECT `+teacherColumns+`
+		FROM teachers t
+		WHERE t.teacher_id = ?
+	`, teacherID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get teacher: %v", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT alias FROM teacher_aliases WHERE teacher_id = ? ORDER BY alias ASC`, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teacher aliases: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("failed to scan teacher alias: %v", err)
+		}
+		t.Aliases = append(t.Aliases, alias)
+	}
+	return t, rows.Err()
+}
+
+func (r *teacherRepository) ListCourses(ctx context.Context, teacherID int) ([]model.TeacherCourse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.course_id, c.name, COALESCE(c.semester, ''), COALESCE(c.credit, 0), COALESCE(c.cover, ''),
+			c.views, c.loves, c.collections,
+			COUNT(cr.id), AVG(cr.difficulty), AVG(cr.workload), AVG(cr.teaching), AVG(cr.usefulness)
+		FROM course_teachers ct
+		JOIN courses c ON c.course_id = ct.course_id
+		LEFT JOIN course_ratings cr ON cr.course_id = c.course_id AND cr.teacher_id = ct.teacher_id
+		WHERE ct.teacher_id = ? AND c.status = 'approved'
+		GROUP BY c.course_id, c.name, c.semester, c.credit, c.cover, c.views, c.loves, c.collections
+		ORDER BY c.semester DESC, c.name ASC, c.course_id ASC
+	`, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teacher courses: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.TeacherCourse, 0)
+	for rows.Next() {
+		var (
+			course                                     model.TeacherCourse
+			difficulty, workload, teaching, usefulness sql.NullFloat64
+		)
+		if err := rows.Scan(
+			&course.CourseID,
+			&course.Name,
+			&course.Semester,
+			&course.Credit,
+			&course.Cover,
+			&course.Views,
+			&course.Likes,
+			&course.Collections,
+			&course.Rating.Count,
+			&difficulty,
+			&workload,
+			&teaching,
+			&usefulness,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan teacher course: %v", err)
+		}
+		course.Rating.Difficulty = roundRating(difficulty)
+		course.Rating.Workload = roundRating(workload)
+		course.Rating.Teaching = roundRating(teaching)
+		course.Rating.Usefulness = roundRating(usefulness)
+		result = append(result, course)
+	}
+	return result, rows.Err()
+}
+
+func (r *teacherRepository) GetRatingSummary(ctx context.Context, teacherID int) (model.RatingSummary, error) {
+	var (
+		summary                                    model.RatingSummary
+		difficulty, workload, teaching, usefulness sql.NullFloat64
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(cr.id), AVG(cr.difficulty), AVG(cr.workload), AVG(cr.teaching), AVG(cr.usefulness)
+		FROM course_ratings cr
+		JOIN courses c ON c.course_id = cr.course_id
+		WHERE cr.teacher_id = ? AND c.status = 'approved'
+			AND EXISTS (SELECT 1 FROM course_teachers ct WHERE ct.course_id = cr.course_id AND ct.teacher_id = cr.teacher_id)
+	`, teacherID).Scan(&summary.Count, &difficulty, &workload, &teaching, &usefulness)
+	if err != nil {
+		return summary, fmt.Errorf("failed to get teacher rating: %v", err)
+	}
+	summary.Difficulty = roundRating(difficulty)
+	summary.Workload = roundRating(workload)
+	summary.Teaching = roundRating(teaching)
+	summary.Usefulness = roundRating(usefulness)
+	return summary, nil
+}
+
+// checkTeacherNames 检查姓名和别名没有被其他教师占用
+func checkTeacherNames(ctx context.Context, tx *sql.Tx, teacherID int, names []string) error {
+	for _, name := range names {
+		var owner int
+		err := tx.QueryRowContext(ctx, `
+			SELECT teacher_id FROM teachers WHERE `+teacherKeySQL("name")+` = ? AND teacher_id <> ?
+			UNION
+			SELECT teacher_id FROM teacher_aliases WHERE `+teacherKeySQL("alias")+` = ? AND teacher_id <> ?
+			LIMIT 1
+		`, model.TeacherNameKey(name), teacherID, model.TeacherNameKey(name), teacherID).Scan(&owner)
+		if err == nil {
+			return ErrTeacherNameTaken
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check teacher name: %v", err)
+		}
+	}
+	return nil
+}
+
+// renameTeacher 同步课程和评分中保存的教师姓名
+func renameTeacher(ctx context.Context, tx *sql.Tx, teacherID int, newName string) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE course_teachers SET teacher_name = ? WHERE teacher_id = ?`, newName, teacherID); err != nil {
+		return fmt.Errorf("failed to rename course teacher: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE course_ratings SET teacher_name = ? WHERE teacher_id = ?
+	`, newName, teacherID); err != nil {
+		return fmt.Errorf("failed to rename rating teacher: %v", err)
+	}
+	return nil
+}
+
+func (r *teacherRepository) Update(ctx context.Context, teacher *model.Teacher, aliases []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var oldName string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM teachers WHERE teacher_id = ? FOR UPDATE`, teacher.TeacherID).Scan(&oldName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTeacherNotFound
+		}
+		return fmt.Errorf("failed to get teacher: %v", err)
+	}
+
+	if err := checkTeacherNames(ctx, tx, teacher.TeacherID, append([]string{teacher.Name}, aliases...)); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE teachers SET name = ?, title = ?, department = ?, email = ?, homepage = ?, avatar = ?, bio = ?, updated_at = ?
+		WHERE teacher_id = ?
+	`, teacher.Name, teacher.Title, teacher.Department, teacher.Email, teacher.Homepage, teacher.Avatar, teacher.Bio, time.Now(), teacher.TeacherID); err != nil {
+		return fmt.Errorf("failed to update teacher: %v", err)
+	}
+
+	if oldName != teacher.Name {
+		if err := renameTeacher(ctx, tx, teacher.TeacherID, teacher.Name); err != nil {
+			return err
+		}
+		// 原写法保留为别名，之后按原写法提交的课程仍能关联到该教师
+		if aliases == nil && model.TeacherNameKey(oldName) != model.TeacherNameKey(teacher.Name) {
+			if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO teacher_aliases (teacher_id, alias) VALUES (?, ?)`, teacher.TeacherID, oldName); err != nil {
+				return fmt.Errorf("failed to insert teacher alias: %v", err)
+			}
+		}
+	}
+
+	if aliases != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM teacher_aliases WHERE teacher_id = ?`, teacher.TeacherID); err != nil {
+			return fmt.Errorf("failed to delete teacher aliases: %v", err)
+		}
+		for _, alias := range aliases {
+			if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO teacher_aliases (teacher_id, alias) VALUES (?, ?)`, teacher.TeacherID, alias); err != nil {
+				return fmt.Errorf("failed to insert teacher alias: %v", err)
+			}
+		}
+	}
+	// 与新姓名相同的别名没有意义
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM teacher_aliases WHERE teacher_id = ? AND `+teacherKeySQL("alias")+` = ?
+	`, teacher.TeacherID, model.TeacherNameKey(teacher.Name)); err != nil {
+		return fmt.Errorf("failed to delete teacher alias: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+func (r *teacherRepository) Merge(ctx context.Context, targetID, sourceID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var targetName, sourceName string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM teachers WHERE teacher_id = ? FOR UPDATE`, targetID).Scan(&targetName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTeacherNotFound
+		}
+		return fmt.Errorf("failed to get teacher: %v", err)
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM teachers WHERE teacher_id = ? FOR UPDATE`, sourceID).Scan(&sourceName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("source %w", ErrTeacherNotFound)
+		}
+		return fmt.Errorf("failed to get teacher: %v", err)
+	}
+
+	// 评分改为目标教师的姓名后再移动到目标教师，删除来源教师会清空仍指向它的评分
+	if err := renameTeacher(ctx, tx, sourceID, targetName); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE course_ratings SET teacher_id = ? WHERE teacher_id = ?`, targetID, sourceID); err != nil {
+		return fmt.Errorf("failed to move teacher ratings: %v", err)
+	}
+	// 两人同时讲授的课程只保留一条关联
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE IGNORE course_teachers SET teacher_id = ? WHERE teacher_id = ?
+	`, targetID, sourceID); err != nil {
+		return fmt.Errorf("failed to move course teachers: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM course_teachers WHERE teacher_id = ?`, sourceID); err != nil {
+		return fmt.Errorf("failed to delete course teachers: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE IGNORE course_offering_teachers SET teacher_id = ? WHERE teacher_id = ?
+	`, targetID, sourceID); err != nil {
+		return fmt.Errorf("failed to move offering teachers: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM course_offering_teachers WHERE teacher_id = ?`, sourceID); err != nil {
+		return fmt.Errorf("failed to delete offering teachers: %v", err)
+	}
+
+	// 来源教师的姓名和别名都成为目标教师的别名
+	if _, err := tx.ExecContext(ctx, `UPDATE teacher_aliases SET teacher_id = ? WHERE teacher_id = ?`, targetID, sourceID); err != nil {
+		return fmt.Errorf("failed to move teacher aliases: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM teachers WHERE teacher_id = ?`, sourceID); err != nil {
+		return fmt.Errorf("failed to delete teacher: %v", err)
+	}
+	if model.TeacherNameKey(sourceName) != model.TeacherNameKey(targetName) {
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO teacher_aliases (teacher_id, alias) VALUES (?, ?)`, targetID, sourceName); err != nil {
+			return fmt.Errorf("failed to insert teacher alias: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// resolveTeacher 按姓名或别名查找教师，找不到时创建，返回教师ID和规范写法
+func resolveTeacher(ctx context.Context, tx *sql.Tx, name string) (int, string, error) {
+	name = model.NormalizeTeacherName(name)
+	key := model.TeacherNameKey(name)
+
+	var (
+		teacherID int
+		canonical string
+	)
+	err := tx.QueryRowContext(ctx, `
+		SELECT t.teacher_id, t.name FROM teachers t WHERE `+teacherKeySQL("t.name")+` = ?
+		UNION
+		SELECT t.teacher_id, t.name FROM teacher_aliases a JOIN teachers t ON t.teacher_id = a.teacher_id WHERE `+teacherKeySQL("a.alias")+` = ?
+		LIMIT 1
+	`, key, key).Scan(&teacherID, &canonical)
+	if err == nil {
+		return teacherID, canonical, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, "", fmt.Errorf("failed to find teacher: %v", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO teachers (name) VALUES (?)`, name)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create teacher: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get teacher id: %v", err)
+	}
+	return int(id), name, nil
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	// MarkRefreshTokenUsed 标记刷新令牌已轮换，返回 false 表示令牌已被使用或吊销
+	MarkRefreshTokenUsed(ctx context.Context, id int) (bool, error)
+	// RevokeFamily 吊销整个令牌家族，并将其未过期的访问令牌加入吊销列表
+	RevokeFamily(ctx context.Context, familyID string) error
+	// RevokeUserFamilies 吊销用户的全部令牌家族，exceptFamilyID 非空时保留该家族
+	RevokeUserFamilies(ctx context.Context, userID int, exceptFamilyID string) error
+	RevokeAccessToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// DeleteExpired 清理已过期的刷新令牌、会话和吊销记录
+	DeleteExpired(ctx context.Context) error
+
+	CreateSession(ctx context.Context, session *model.Session) error
+	GetSessionByFamily(ctx context.Context, familyID string) (*model.Session, error)
+	GetSessionByID(ctx context.Context, id int) (*model.Session, error)
+	ListActiveSessions(ctx context.Context, userID int) ([]model.Session, error)
+	// TouchSession 更新会话最后活跃时间，ip/userAgent/expiresAt 为零值时不修改
+	TouchSession(ctx context.Context, familyID, ip, userAgent string, expiresAt time.Time) error
+}
+
+type tokenRepository struct {
+	db *Database
+}
+
+func NewTokenRepository(db *Database) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, access_jti, access_expires_at, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, token.UserID, token.FamilyID, token.TokenHash, token.AccessJTI, token.AccessExpiresAt, token.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	token.ID = int(id)
+	token.CreatedAt = now
+
+	return nil
+}
+
+func (r *tokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	token := &model.RefreshToken{}
+	var usedAt, revokedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, token_hash, access_jti, access_expires_at, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = ?
+	`, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.AccessJTI,
+		&token.AccessExpiresAt,
+		&token.ExpiresAt,
+		&usedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %v", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return token, nil
+}
+
+func (r *tokenRepository) MarkRefreshTokenUsed(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL
+	`, time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token used: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *tokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.revokeWhere(ctx, "family_id = ?", familyID)
+}
+
+func (r *tokenRepository) RevokeUserFamilies(ctx context.Context, userID int, exceptFamilyID string) error {
+	if exceptFamilyID != "" {
+		return r.revokeWhere(ctx, "user_id = ? AND family_id <> ?", userID, exceptFamilyID)
+	}
+	return r.revokeWhere(ctx, "user_id = ?", userID)
+}
+
+// revokeWhere 在同一事务内吊销匹配的会话和刷新令牌，并把仍有效的访问令牌写入吊销列表
+func (r *tokenRepository) revokeWhere(ctx context.Context, cond string, condArgs ...interface{}) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	args := append([]interface{}{now}, condArgs...)
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT IGNORE INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		SELECT access_jti, user_id, access_expires_at, ?
+		FROM refresh_tokens
+		WHERE `+cond+` AND access_expires_at > ?
+	`, append(args, now)...); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = ? WHERE `+cond+` AND revoked_at IS NULL
+	`, args...); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_sessions SET revoked_at = ? WHERE `+cond+` AND revoked_at IS NULL
+	`, args...); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+func (r *tokenRepository) RevokeAccessToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT IGNORE INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES (?, ?, ?, ?)
+	`, jti, userID, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %v", err)
+	}
+	return nil
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var exists int
+	err := r.db.QueryRowContext(ctx, `SELECT 1 FROM revoked_tokens WHERE jti = ?`, jti).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check revoked token: %v", err)
+	}
+	return true, nil
+}
+
+func (r *tokenRepository) DeleteExpired(ctx context.Context) error {
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= ?`, now); err != nil {
+		return fmt.Errorf("failed to delete expired revoked tokens: %v", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at <= ?`, now); err != nil {
+		return fmt.Errorf("failed to delete expired refresh tokens: %v", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM user_sessions WHERE expires_at <= ?`, now); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %v", err)
+	}
+	return nil
+}
+
+func (r *tokenRepository) CreateSession(ctx context.Context, session *model.Session) error {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_sessions (user_id, family_id, user_agent, ip, mfa_verified, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.UserID, session.FamilyID, session.UserAgent, session.IP, session.MFA, now, now, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	session.ID = int(id)
+	session.CreatedAt = now
+	session.LastSeenAt = now
+
+	return nil
+}
+
+const sessionColumns = `id, user_id, family_id, user_agent, ip, mfa_verified, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(row rowScanner) (*model.Session, error) {
+	session := &model.Session{}
+	var userAgent, ip sql.NullString
+	var revokedAt sql.NullTime
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.FamilyID,
+		&userAgent,
+		&ip,
+		&session.MFA,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&revokedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	session.UserAgent = nullString(userAgent)
+	session.IP = nullString(ip)
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return session, nil
+}
+
+func (r *tokenRepository) GetSessionByFamily(ctx context.Context, familyID string) (*model.Session, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM user_sessions WHERE family_id = ?`, familyID)
+	session, err := scanSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session: %v", err)
+	}
+	return session, nil
+}
+
+func (r *tokenRepository) GetSessionByID(ctx context.Context, id int) (*model.Session, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM user_sessions WHERE id = ?`, id)
+	session, err := scanSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session: %v", err)
+	}
+	return session, nil
+}
+
+func (r *tokenRepository) ListActiveSessions(ctx context.Context, userID int) ([]model.Session, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sessionColumns+`
+		FROM user_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_seen_at DESC
+	`, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %v", err)
+		}
+		result = append(result, *session)
+	}
+	return result, rows.Err()
+}
+
+func (r *tokenRepository) TouchSession(ctx context.Context, familyID, ip, userAgent string, expiresAt time.Time) error {
+	query := `UPDATE user_sessions SET last_seen_at = ?`
+	args := []interface{}{time.Now()}
+	if ip != "" {
+		query += `, ip = ?`
+		args = append(args, ip)
+	}
+	if userAgent != "" {
+		query += `, user_agent = ?`
+		args = append(args, userAgent)
+	}
+	if !expiresAt.IsZero() {
+		query += `, expires_at = ?`
+		args = append(args, expiresAt)
+	}
+	query += ` WHERE family_id = ?`
+	args = append(args, familyID)
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update session: %v", err)
+	}
+	return nil
+}
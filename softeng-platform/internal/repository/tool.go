@@ -3,12 +3,18 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"softeng-platform/internal/model"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// ErrToolRevisionConflict 提议之后工具的相关字段已被修改
+var ErrToolRevisionConflict = errors.New("tool has changed since the revision was proposed")
+
 type ToolRepository interface {
 	GetTools(ctx context.Context, category, tags []string, sort, cursor string, pageSize int) ([]map[string]interface{}, error)
 	GetByID(ctx context.Context, resourceID string, userID int) (map[string]interface{}, error)
@@ -35,6 +41,13 @@ type ToolRepository interface {
 	AddView(ctx context.Context, resourceID string) (int, error)
 
 	GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) // 新增方法
+
+	// 修改提议（tool_revisions 表）
+	GetContent(ctx context.Context, toolID int) (*model.ToolContent, error) // 工具不存在时返回 nil
+	CreateRevision(ctx context.Context, revision *model.ToolRevision) error
+	GetRevisions(ctx context.Context, toolID int) ([]model.ToolRevision, error)
+	GetPendingRevisions(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error)
+	UpdateRevisionStatus(ctx context.Context, revisionID, status, rejectReason string) error // 通过时在同一事务中应用修改
 }
 
 type toolRepository struct {
@@ -889,6 +902,324 @@ func (r *toolRepository) GetPending(ctx context.Context, cursor, limit int) ([]m
 	}, nil
 }
 
+// queryer 由 *Database 和 *sql.Tx 实现
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// GetContent 获取工具可编辑的内容
+func (r *toolRepository) GetContent(ctx context.Context, toolID int) (*model.ToolContent, error) {
+	content, err := queryToolContent(ctx, r.db, toolID, false)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return content, err
+}
+
+// queryToolContent 读取工具可编辑的内容，lock 为 true 时锁定工具行；工具不存在时返回 sql.ErrNoRows
+func queryToolContent(ctx context.Context, q queryer, toolID int, lock bool) (*model.ToolContent, error) {
+	query := `
+		SELECT resource_name, resource_link, description, description_detail, category, status = 'approved'
+		FROM tools WHERE resource_id = ?
+	`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+	content := &model.ToolContent{}
+	var link, description, descriptionDetail, category sql.NullString
+	var approved sql.NullBool
+	if err := q.QueryRowContext(ctx, query, toolID).Scan(&content.Name, &link, &description, &descriptionDetail, &category, &approved); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get tool content: %v", err)
+	}
+	content.Link = nullString(link)
+	content.Description = nullString(description)
+	content.DescriptionDetail = nullString(descriptionDetail)
+	content.Category = nullString(category)
+	content.Approved = approved.Bool
+
+	lists := []struct {
+		query  string
+		target *[]string
+	}{
+		{`SELECT tag FROM tool_tags WHERE tool_id = ? ORDER BY tag ASC`, &content.Tags},
+		{`SELECT image_url FROM tool_images WHERE tool_id = ? ORDER BY sort_order ASC, id ASC`, &content.Images},
+	}
+	for _, list := range lists {
+		rows, err := q.QueryContext(ctx, list.query, toolID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tool content: %v", err)
+		}
+		values := make([]string, 0)
+		for rows.Next() {
+			var value string
+			if err := rows.Scan(&value); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan tool content: %v", err)
+			}
+			values = append(values, value)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to query tool content: %v", err)
+		}
+		*list.target = values
+	}
+	// 数据库按排序规则排列标签，与提议的标签比较前按同样的方式整理
+	content.Tags = model.NormalizeToolTags(content.Tags)
+	return content, nil
+}
+
+// CreateRevision 保存修改提议，进入待审核状态
+func (r *toolRepository) CreateRevision(ctx context.Context, revision *model.ToolRevision) error {
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision changes: %v", err)
+	}
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO tool_revisions (tool_id, editor_id, summary, changes, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, revision.ToolID, revision.EditorID, revision.Summary, string(changes), model.ToolRevisionStatusPending, now)
+	if err != nil {
+		return fmt.Errorf("failed to create tool revision: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	revision.RevisionID = int(id)
+	revision.Status = model.ToolRevisionStatusPending
+	revision.CreatedAt = now
+	return nil
+}
+
+// GetRevisions 获取工具的全部修改提议，最新的在前
+func (r *toolRepository) GetRevisions(ctx context.Context, toolID int) ([]model.ToolRevision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rv.revision_id, rv.tool_id, rv.editor_id, COALESCE(u.username, ''), rv.summary, rv.changes,
+			rv.status, rv.reject_reason, rv.audit_time, rv.created_at
+		FROM tool_revisions rv
+		LEFT JOIN users u ON u.id = rv.editor_id
+		WHERE rv.tool_id = ?
+		ORDER BY rv.created_at DESC, rv.revision_id DESC
+	`, toolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tool revisions: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.ToolRevision, 0)
+	for rows.Next() {
+		var (
+			rv                    model.ToolRevision
+			editorID              sql.NullInt64
+			summary, rejectReason sql.NullString
+			status                sql.NullString
+			changes               string
+			auditTime             sql.NullTime
+		)
+		if err := rows.Scan(&rv.RevisionID, &rv.ToolID, &editorID, &rv.Editor, &summary, &changes,
+			&status, &rejectReason, &auditTime, &rv.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tool revision: %v", err)
+		}
+		if err := json.Unmarshal([]byte(changes), &rv.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode revision changes: %v", err)
+		}
+		rv.EditorID = int(editorID.Int64)
+		rv.Summary = nullString(summary)
+		rv.Status = nullString(status)
+		rv.RejectReason = nullString(rejectReason)
+		if auditTime.Valid {
+			rv.AuditTime = &auditTime.Time
+		}
+		result = append(result, rv)
+	}
+	return result, rows.Err()
+}
+
+// GetPendingRevisions 获取待审核的工具修改提议，cursor 为偏移量
+func (r *toolRepository) GetPendingRevisions(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rv.revision_id, rv.tool_id, t.resource_name, t.resource_link, rv.summary, rv.changes, u.username, rv.created_at
+		FROM tool_revisions rv
+		JOIN tools t ON t.resource_id = rv.tool_id
+		LEFT JOIN users u ON u.id = rv.editor_id
+		WHERE rv.status = 'pending'
+		ORDER BY rv.created_at ASC, rv.revision_id ASC
+		LIMIT ? OFFSET ?
+	`, limit, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending tool revisions: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var (
+			revisionID, toolID int
+			toolName           string
+			link, summary      sql.NullString
+			changesJSON        string
+			submitter          sql.NullString
+			createdAt          time.Time
+		)
+		if err := rows.Scan(&revisionID, &toolID, &toolName, &link, &summary, &changesJSON, &submitter, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending tool revision: %v", err)
+		}
+		var changes []model.ToolFieldChange
+		if err := json.Unmarshal([]byte(changesJSON), &changes); err != nil {
+			return nil, fmt.Errorf("failed to decode revision changes: %v", err)
+		}
+		fields := make([]string, 0, len(changes))
+		for _, change := range changes {
+			fields = append(fields, change.Field)
+		}
+		description := "修改：" + strings.Join(fields, ", ")
+		if s := nullString(summary); s != "" {
+			description = s + "（" + description + "）"
+		}
+		result = append(result, map[string]interface{}{
+			"submitor":     nullString(submitter),
+			"submitDate":   createdAt.Format("2006-01-02 15:04:05"),
+			"reourceId":    revisionID,
+			"resourceType": "tool_revision",
+			"resourcename": toolName,
+			"toolId":       toolID,
+			"catagory":     "工具修改",
+			"link":         nullString(link),
+			"description":  description,
+			"tags":         []string{},
+			"file":         "",
+			"changes":      changes,
+		})
+	}
+	return result, rows.Err()
+}
+
+// UpdateRevisionStatus 审核修改提议。通过时在同一事务中锁定工具、确认相关字段未被修改后应用全部修改，
+// 并将提议者加入工具贡献者；字段已被修改时返回 ErrToolRevisionConflict，提议保持待审核
+func (r *toolRepository) UpdateRevisionStatus(ctx context.Context, revisionID, status, rejectReason string) error {
+	if status != model.ToolRevisionStatusApproved && status != model.ToolRevisionStatusRejected {
+		return fmt.Errorf("invalid status: %s", status)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		toolID      int
+		editorID    sql.NullInt64
+		changesJSON string
+		current     sql.NullString
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT tool_id, editor_id, changes, status FROM tool_revisions WHERE revision_id = ? FOR UPDATE
+	`, revisionID).Scan(&toolID, &editorID, &changesJSON, &current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("not found")
+		}
+		return fmt.Errorf("failed to get tool revision: %v", err)
+	}
+	if nullString(current) != model.ToolRevisionStatusPending {
+		return fmt.Errorf("cannot change status from %s to %s", nullString(current), status)
+	}
+
+	if status == model.ToolRevisionStatusApproved {
+		var changes []model.ToolFieldChange
+		if err := json.Unmarshal([]byte(changesJSON), &changes); err != nil {
+			return fmt.Errorf("failed to decode revision changes: %v", err)
+		}
+		if err := applyToolChanges(ctx, tx, toolID, changes); err != nil {
+			return err
+		}
+		if editorID.Valid {
+			if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO tool_contributors (tool_id, user_id) VALUES (?, ?)`, toolID, editorID.Int64); err != nil {
+				return fmt.Errorf("failed to insert tool contributor: %v", err)
+			}
+		}
+	}
+
+	// 驳回原因只在驳回时保留
+	var reason interface{}
+	if status == model.ToolRevisionStatusRejected && rejectReason != "" {
+		reason = rejectReason
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE tool_revisions SET status = ?, audit_time = ?, reject_reason = ? WHERE revision_id = ?
+	`, status, time.Now(), reason, revisionID); err != nil {
+		return fmt.Errorf("failed to update tool revision status: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tx: %v", err)
+	}
+	return nil
+}
+
+// applyToolChanges 在事务中把修改写入工具，标签和图片整体替换
+func applyToolChanges(ctx context.Context, tx *sql.Tx, toolID int, changes []model.ToolFieldChange) error {
+	content, err := queryToolContent(ctx, tx, toolID, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("tool not found")
+		}
+		return err
+	}
+	changed := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		if !model.SameToolValue(content.Value(change.Field), change.Before) {
+			return fmt.Errorf("%w: %s", ErrToolRevisionConflict, change.Field)
+		}
+		content.SetValue(change.Field, change.After)
+		changed[change.Field] = true
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE tools SET resource_name = ?, resource_link = ?, description = ?, description_detail = ?, category = ?
+		WHERE resource_id = ?
+	`, content.Name, content.Link, content.Description, content.DescriptionDetail, content.Category, toolID); err != nil {
+		return fmt.Errorf("failed to update tool: %v", err)
+	}
+	if changed[model.ToolFieldTags] {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tool_tags WHERE tool_id = ?`, toolID); err != nil {
+			return fmt.Errorf("failed to clear tool tags: %v", err)
+		}
+		for _, tag := range content.Tags {
+			if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO tool_tags (tool_id, tag) VALUES (?, ?)`, toolID, tag); err != nil {
+				return fmt.Errorf("failed to insert tool tag: %v", err)
+			}
+		}
+	}
+	if changed[model.ToolFieldImages] {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tool_images WHERE tool_id = ?`, toolID); err != nil {
+			return fmt.Errorf("failed to clear tool images: %v", err)
+		}
+		for i, image := range content.Images {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO tool_images (tool_id, image_url, sort_order) VALUES (?, ?, ?)`, toolID, image, i); err != nil {
+				return fmt.Errorf("failed to insert tool image: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
 func (r *toolRepository) fetchToolImages(ctx context.Context, toolID int) ([]string, error) {
 	rows, err := r.db.QueryContext(ctx, `SELECT image_url FROM tool_images WHERE tool_id = ? ORDER BY sort_order ASC, id ASC`, toolID)
 	if err != nil {
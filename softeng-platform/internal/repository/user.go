@@ -16,6 +16,8 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
 	UpdatePassword(ctx context.Context, userID int, hashedPassword string) error
+	UpdateEmail(ctx context.Context, userID int, email string) error
+	UpdateRole(ctx context.Context, userID int, role string) error
 }
 
 type userRepository struct {
@@ -26,13 +28,27 @@ func NewUserRepository(db *Database) UserRepository {
 	return &userRepository{db: db}
 }
 
+// execer 由 *Database 与 *sql.Tx 共同实现，便于在事务内复用写入逻辑
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 func (r *userRepository) Create(ctx context.Context, user *model.User) error {
+	return insertUser(ctx, r.db, user)
+}
+
+func insertUser(ctx context.Context, db execer, user *model.User) error {
 	query := `
-		INSERT INTO users (username, nickname, email, password, avatar, description, face_photo, role, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (username, nickname, email, password, avatar, description, face_photo, role, cohort, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	var cohort interface{}
+	if user.Cohort != "" {
+		cohort = user.Cohort
+	}
+
+	result, err := db.ExecContext(ctx, query,
 		user.Username,
 		user.Nickname,
 		user.Email,
@@ -41,6 +57,7 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 		user.Description,
 		user.FacePhoto,
 		user.Role,
+		cohort,
 		time.Now(),
 		time.Now(),
 	)
@@ -63,7 +80,7 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
 	query := `
 
-		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, created_at, updated_at
+		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, cohort, created_at, updated_at
 
 		FROM users WHERE id = ?
 	`
@@ -74,6 +91,7 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, erro
 		avatar      sql.NullString
 		description sql.NullString
 		facePhoto   sql.NullString
+		cohort      sql.NullString
 	)
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
@@ -85,6 +103,7 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, erro
 		&description,
 		&facePhoto,
 		&user.Role,
+		&cohort,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -100,13 +119,14 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, erro
 	user.Avatar = nullStringUser(avatar)
 	user.Description = nullStringUser(description)
 	user.FacePhoto = nullStringUser(facePhoto)
+	user.Cohort = nullStringUser(cohort)
 
 	return user, nil
 }
 
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	query := `
-		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, created_at, updated_at
+		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, cohort, created_at, updated_at
 		FROM users WHERE username = ?
 	`
 
@@ -116,6 +136,7 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 		avatar      sql.NullString
 		description sql.NullString
 		facePhoto   sql.NullString
+		cohort      sql.NullString
 	)
 	err := r.db.QueryRowContext(ctx, query, username).Scan(
 		&user.ID,
@@ -127,6 +148,7 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 		&description,
 		&facePhoto,
 		&user.Role,
+		&cohort,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -142,13 +164,14 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 	user.Avatar = nullStringUser(avatar)
 	user.Description = nullStringUser(description)
 	user.FacePhoto = nullStringUser(facePhoto)
+	user.Cohort = nullStringUser(cohort)
 
 	return user, nil
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	query := `
-		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, created_at, updated_at
+		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, cohort, created_at, updated_at
 		FROM users WHERE email = ?
 	`
 
@@ -158,6 +181,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 		avatar      sql.NullString
 		description sql.NullString
 		facePhoto   sql.NullString
+		cohort      sql.NullString
 	)
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
@@ -169,6 +193,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 		&description,
 		&facePhoto,
 		&user.Role,
+		&cohort,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -184,6 +209,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 	user.Avatar = nullStringUser(avatar)
 	user.Description = nullStringUser(description)
 	user.FacePhoto = nullStringUser(facePhoto)
+	user.Cohort = nullStringUser(cohort)
 
 	return user, nil
 }
@@ -246,3 +272,41 @@ func (r *userRepository) UpdatePassword(ctx context.Context, userID int, hashedP
 	return nil
 
 }
+
+func (r *userRepository) UpdateEmail(ctx context.Context, userID int, email string) error {
+	query := `UPDATE users SET email = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, email, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update email: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, userID int, role string) error {
+	query := `UPDATE users SET role = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, role, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
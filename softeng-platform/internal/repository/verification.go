@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+// ErrVerificationCodeConsumed 验证码已被使用（并发提交时由后完成的一方返回）
+var ErrVerificationCodeConsumed = errors.New("verification code has already been used")
+
+type VerificationRepository interface {
+	Create(ctx context.Context, code *model.VerificationCode) error
+	GetLatest(ctx context.Context, email, purpose string) (*model.VerificationCode, error)
+	// UseAttempt 占用一次校验次数，返回 false 表示次数已用完或验证码已被使用
+	UseAttempt(ctx context.Context, id int) (bool, error)
+	MarkConsumed(ctx context.Context, id int) (bool, error)
+	// MarkConsumedTx 在调用方的事务内标记验证码已使用，事务回滚时验证码仍可使用
+	MarkConsumedTx(ctx context.Context, tx *sql.Tx, id int) (bool, error)
+	InvalidateActive(ctx context.Context, email, purpose string) error
+}
+
+type verificationRepository struct {
+	db *Database
+}
+
+func NewVerificationRepository(db *Database) VerificationRepository {
+	return &verificationRepository{db: db}
+}
+
+func (r *verificationRepository) Create(ctx context.Context, code *model.VerificationCode) error {
+	query := `
+		INSERT INTO verification_codes (email, purpose, code_hash, attempts, max_attempts, expires_at, created_at)
+		VALUES (?, ?, ?, 0, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query,
+		code.Email,
+		code.Purpose,
+		code.CodeHash,
+		code.MaxAttempts,
+		code.ExpiresAt,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create verification code: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %v", err)
+	}
+	code.ID = int(id)
+	code.CreatedAt = now
+
+	return nil
+}
+
+// GetLatest 获取某邮箱某用途下最近一条未使用的验证码
+func (r *verificationRepository) GetLatest(ctx context.Context, email, purpose string) (*model.VerificationCode, error) {
+	query := `
+		SELECT id, email, purpose, code_hash, attempts, max_attempts, expires_at, consumed_at, created_at
+		FROM verification_codes
+		WHERE email = ? AND purpose = ? AND consumed_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	code := &model.VerificationCode{}
+	var consumedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, email, purpose).Scan(
+		&code.ID,
+		&code.Email,
+		&code.Purpose,
+		&code.CodeHash,
+		&code.Attempts,
+		&code.MaxAttempts,
+		&code.ExpiresAt,
+		&consumedAt,
+		&code.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get verification code: %v", err)
+	}
+
+	if consumedAt.Valid {
+		code.ConsumedAt = &consumedAt.Time
+	}
+
+	return code, nil
+}
+
+// UseAttempt 在同一条语句中检查并增加校验次数，并发的校验请求不会超过 max_attempts
+func (r *verificationRepository) UseAttempt(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE verification_codes
+		SET attempts = attempts + 1
+		WHERE id = ? AND attempts < max_attempts AND consumed_at IS NULL
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to increment attempts: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rows > 0, nil
+}
+
+// MarkConsumed 标记验证码已使用，返回 false 表示已被并发请求使用
+func (r *verificationRepository) MarkConsumed(ctx context.Context, id int) (bool, error) {
+	return markCodeConsumed(ctx, r.db, id)
+}
+
+func (r *verificationRepository) MarkConsumedTx(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+	return markCodeConsumed(ctx, tx, id)
+}
+
+func markCodeConsumed(ctx context.Context, db execer, id int) (bool, error) {
+	result, err := db.ExecContext(ctx, `
+		UPDATE verification_codes
+		SET consumed_at = ?
+		WHERE id = ? AND consumed_at IS NULL
+	`, time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume verification code: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rows > 0, nil
+}
+
+// InvalidateActive 作废某邮箱某用途下所有未使用的验证码（重新发送时调用）
+func (r *verificationRepository) InvalidateActive(ctx context.Context, email, purpose string) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE verification_codes
+		SET consumed_at = ?
+		WHERE email = ? AND purpose = ? AND consumed_at IS NULL
+	`, time.Now(), email, purpose); err != nil {
+		return fmt.Errorf("failed to invalidate verification codes: %v", err)
+	}
+	return nil
+}
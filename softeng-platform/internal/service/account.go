@@ -0,0 +1,264 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"strings"
+	"time"
+)
+
+var ErrPasswordNotSet = errors.New("please set a password before deleting your account")
+
+type AccountService interface {
+	// Export 导出个人资料及评论、收藏、点赞、提交的内容
+	Export(ctx context.Context, userID int) (*model.AccountExport, error)
+	// WriteExportZip 将导出数据（data.json）和本地上传的图片打包写入 w
+	WriteExportZip(ctx context.Context, userID int, w io.Writer) error
+	// RequestDeletion 校验密码（及两步验证）后进入冷静期，冷静期内可撤销；
+	// 校验失败与登录共用失败计数和锁定，申请后吊销其他会话、个人访问令牌和日历订阅令牌
+	RequestDeletion(ctx context.Context, userID int, sessionID string, req model.DeleteAccountRequest) (*model.AccountDeletion, error)
+	GetDeletion(ctx context.Context, userID int) (*model.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID int) error
+	// PurgeDue 匿名化冷静期已结束的账号
+	PurgeDue(ctx context.Context) error
+}
+
+type accountService struct {
+	accountRepo  repository.AccountRepository
+	userRepo     repository.UserRepository
+	identityRepo repository.IdentityRepository
+	mfaService   MFAService
+	tokenService TokenService
+	loginGuard   *loginguard.Guard
+	auditService AuditService
+	grace        time.Duration
+}
+
+func NewAccountService(accountRepo repository.AccountRepository, userRepo repository.UserRepository, identityRepo repository.IdentityRepository, mfaService MFAService, tokenService TokenService, loginGuard *loginguard.Guard, auditService AuditService, cfg *config.Config) AccountService {
+	return &accountService{
+		accountRepo:  accountRepo,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		mfaService:   mfaService,
+		tokenService: tokenService,
+		loginGuard:   loginGuard,
+		auditService: auditService,
+		grace:        cfg.AccountDeletionGrace,
+	}
+}
+
+func (s *accountService) Export(ctx context.Context, userID int) (*model.AccountExport, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	export, err := s.accountRepo.GetExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Profile = user
+
+	if export.Identities, err = s.identityRepo.ListByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// 汇总所有引用的图片，ZIP 导出时打包其中的本地文件
+	seen := make(map[string]bool)
+	addImage := func(url string) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			export.Images = append(export.Images, url)
+		}
+	}
+	addImage(user.Avatar)
+	addImage(user.FacePhoto)
+	for _, group := range [][]model.ExportSubmission{export.Tools, export.Courses, export.Projects} {
+		for _, item := range group {
+			addImage(item.Cover)
+			for _, image := range item.Images {
+				addImage(image)
+			}
+		}
+	}
+	if export.Images == nil {
+		export.Images = []string{}
+	}
+	return export, nil
+}
+
+func (s *accountService) WriteExportZip(ctx context.Context, userID int, w io.Writer) error {
+	export, err := s.Export(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	data, err := zw.Create("data.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(data)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return err
+	}
+
+	for _, url := range export.Images {
+		filePath, ok := localUploadPath(url)
+		if !ok {
+			continue
+		}
+		if err := addFileToZip(zw, filePath, "images/"+path.Base(url)); err != nil {
+			// 文件缺失不影响其余数据的导出
+			log.Printf("Export user %d: skip image %s: %v", userID, url, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// localUploadPath 将 /uploads/ 开头的地址转换为本地文件路径，拒绝越出上传目录的路径
+func localUploadPath(url string) (string, bool) {
+	if !strings.HasPrefix(url, "/uploads/") {
+		return "", false
+	}
+	cleaned := path.Clean(url)
+	if !strings.HasPrefix(cleaned, "/uploads/") {
+		return "", false
+	}
+	return filepath.FromSlash(strings.TrimPrefix(cleaned, "/")), true
+}
+
+func addFileToZip(zw *zip.Writer, filePath, name string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entry, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, f)
+	return err
+}
+
+func (s *accountService) RequestDeletion(ctx context.Context, userID int, sessionID string, req model.DeleteAccountRequest) (*model.AccountDeletion, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	// 仅通过第三方账号登录的用户需要先设置密码
+	if user.Password == "" {
+		return nil, ErrPasswordNotSet
+	}
+	keys := guardKeys(ctx, userID)
+	if err := s.loginGuard.Check(ctx, keys...); err != nil {
+		return nil, err
+	}
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		if err := s.loginGuard.Fail(ctx, keys...); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("invalid password")
+	}
+
+	mfaEnabled, err := s.mfaService.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfaEnabled {
+		if req.Code == "" {
+			return nil, errors.New("two-factor authentication code is required")
+		}
+		if err := s.mfaService.VerifyAttempt(ctx, userID, req.Code, ""); err != nil {
+			return nil, err
+		}
+	} else if err := s.loginGuard.Succeed(ctx, keys[0]); err != nil {
+		return nil, err
+	}
+
+	deletion, err := s.accountRepo.ScheduleDeletion(ctx, userID, time.Now().Add(s.grace))
+	if err != nil {
+		return nil, err
+	}
+
+	// 冷静期内只保留当前会话，用于撤销注销
+	if err := s.tokenService.RevokeAllSessions(ctx, userID, sessionID); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{
+		UserID:     userID,
+		Identifier: user.Username,
+		Type:       model.AuthEventDeletionRequested,
+		Detail:     "purge after " + deletion.PurgeAfter.Format(time.RFC3339),
+	})
+	return deletion, nil
+}
+
+func (s *accountService) GetDeletion(ctx context.Context, userID int) (*model.AccountDeletion, error) {
+	return s.accountRepo.GetDeletion(ctx, userID)
+}
+
+func (s *accountService) CancelDeletion(ctx context.Context, userID int) error {
+	return s.accountRepo.CancelDeletion(ctx, userID)
+}
+
+func (s *accountService) PurgeDue(ctx context.Context) error {
+	userIDs, err := s.accountRepo.ListDueDeletions(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// 占位用户名/邮箱使用随机后缀，邮箱使用保留域名 .invalid，避免与真实账号冲突
+		suffix, err := utils.GenerateRandomToken(6)
+		if err != nil {
+			return err
+		}
+		username := "deleted_" + suffix
+		email := fmt.Sprintf("deleted_%s@deleted.invalid", suffix)
+
+		if err := s.accountRepo.Anonymize(ctx, userID, username, email); err != nil {
+			return err
+		}
+
+		if user != nil {
+			for _, image := range []string{user.Avatar, user.FacePhoto} {
+				if err := utils.DeleteImageFile(image); err != nil {
+					log.Printf("Failed to delete image of deleted user %d: %v", userID, err)
+				}
+			}
+		}
+		log.Printf("Account %d anonymized", userID)
+	}
+	return nil
+}
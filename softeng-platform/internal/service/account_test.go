@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"testing"
+	"time"
+)
+
+type fakeAccountRepo struct {
+	repository.AccountRepository
+	scheduled []int
+}
+
+func (r *fakeAccountRepo) ScheduleDeletion(ctx context.Context, userID int, purgeAfter time.Time) (*model.AccountDeletion, error) {
+	r.scheduled = append(r.scheduled, userID)
+	return &model.AccountDeletion{UserID: userID, RequestedAt: time.Now(), PurgeAfter: purgeAfter}, nil
+}
+
+type fakeSessionTokens struct {
+	TokenService
+	kept []string // 每次吊销时保留的会话
+}
+
+func (s *fakeSessionTokens) RevokeAllSessions(ctx context.Context, userID int, exceptSessionID string) error {
+	s.kept = append(s.kept, exceptSessionID)
+	return nil
+}
+
+func TestRequestDeletion(t *testing.T) {
+	hashed, err := utils.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		LoginFreeAttempts:    2,
+		LoginBackoffBase:     time.Minute,
+		LoginBackoffMax:      time.Hour,
+		LoginLockThreshold:   5,
+		LoginLockDuration:    time.Hour,
+		AccountDeletionGrace: 24 * time.Hour,
+	}
+
+	tests := []struct {
+		name       string
+		mfa        bool
+		requests   []model.DeleteAccountRequest
+		wantErr    error // 最后一次请求的错误：nil、ErrInvalidMFACode、errBlocked 或 errInvalidPassword
+		wantEvents []string
+	}{
+		{
+			name:       "scheduled",
+			requests:   []model.DeleteAccountRequest{{Password: testPassword}},
+			wantEvents: []string{model.AuthEventDeletionRequested},
+		},
+		{
+			name: "wrong passwords are rate limited",
+			requests: []model.DeleteAccountRequest{
+				{Password: "wrong"}, {Password: "wrong"}, {Password: "wrong"}, {Password: testPassword},
+			},
+			wantErr: errBlocked,
+		},
+		{
+			name:       "wrong code",
+			mfa:        true,
+			requests:   []model.DeleteAccountRequest{{Password: testPassword, Code: "abcdef"}},
+			wantErr:    ErrInvalidMFACode,
+			wantEvents: []string{model.AuthEventMFAFailed},
+		},
+		{
+			name: "wrong codes are rate limited",
+			mfa:  true,
+			requests: []model.DeleteAccountRequest{
+				{Password: testPassword, Code: "abcdef"}, {Password: testPassword, Code: "abcdef"}, {Password: testPassword, Code: "abcdef"},
+			},
+			wantErr:    errBlocked,
+			wantEvents: []string{model.AuthEventMFAFailed, model.AuthEventMFAFailed, model.AuthEventMFAFailed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfaRepo := newFakeMFARepo(t)
+			if !tt.mfa {
+				mfaRepo.mfa = nil
+			}
+			users := &fakeMFAUsers{user: &model.User{ID: 1, Username: "alice", Password: hashed, Role: model.RoleUser}}
+			audit := &countingAudit{}
+			guard := loginguard.NewGuard(loginguard.NewMemoryTracker(), cfg)
+			accounts := &fakeAccountRepo{}
+			tokens := &fakeSessionTokens{}
+			svc := NewAccountService(accounts, users, nil, NewMFAService(mfaRepo, users, guard, audit, cfg), tokens, guard, audit, cfg)
+			ctx := model.ContextWithClientInfo(context.Background(), model.ClientInfo{IP: "192.0.2.1"})
+
+			var deletion *model.AccountDeletion
+			for _, req := range tt.requests {
+				deletion, err = svc.RequestDeletion(ctx, 1, "current-session", req)
+			}
+
+			var blocked *loginguard.BlockedError
+			switch {
+			case tt.wantErr == errBlocked:
+				if !errors.As(err, &blocked) {
+					t.Fatalf("error = %v, want blocked", err)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil {
+				if deletion == nil || len(accounts.scheduled) != 1 {
+					t.Fatalf("deletion = %+v, scheduled = %v", deletion, accounts.scheduled)
+				}
+				if len(tokens.kept) != 1 || tokens.kept[0] != "current-session" {
+					t.Errorf("sessions revoked keeping %v, want current-session", tokens.kept)
+				}
+			} else if len(accounts.scheduled) != 0 || len(tokens.kept) != 0 {
+				t.Errorf("scheduled = %v, revoked = %v after a failed request", accounts.scheduled, tokens.kept)
+			}
+
+			if len(audit.events) != len(tt.wantEvents) {
+				t.Fatalf("audit events = %v, want %v", audit.events, tt.wantEvents)
+			}
+			for i := range audit.events {
+				if audit.events[i] != tt.wantEvents[i] {
+					t.Errorf("audit events = %v, want %v", audit.events, tt.wantEvents)
+				}
+			}
+		})
+	}
+}
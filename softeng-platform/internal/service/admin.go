@@ -2,52 +2,113 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/repository"
+	"strconv"
+	"strings"
 )
 
+var ErrPermissionDenied = errors.New("permission denied")
+
 type AdminService interface {
-	GetPending(ctx context.Context, itemType string, cursor, limit int, sort string) (map[string]interface{}, error)
-	ReviewItem(ctx context.Context, itemID, action, resourceType, rejectReason string) error
+	GetPending(ctx context.Context, role, itemType string, cursor, limit int, sort string) (map[string]interface{}, error)
+	ReviewItem(ctx context.Context, role, itemID, action, resourceType, rejectReason string) error
+	GetRoles(ctx context.Context) []map[string]interface{}
+	AssignRole(ctx context.Context, operatorID, userID int, role string) (*model.User, error)
+	DeleteComment(ctx context.Context, commentID int) error
 }
 
 type adminService struct {
-	toolRepo    repository.ToolRepository
-	courseRepo  repository.CourseRepository
-	projectRepo repository.ProjectRepository
+	toolRepo     repository.ToolRepository
+	courseRepo   repository.CourseRepository
+	projectRepo  repository.ProjectRepository
+	userRepo     repository.UserRepository
+	commentRepo  repository.CommentRepository
+	tokenService TokenService
+	auditService AuditService
 }
 
-func NewAdminService(toolRepo repository.ToolRepository, courseRepo repository.CourseRepository, projectRepo repository.ProjectRepository) AdminService {
+func NewAdminService(toolRepo repository.ToolRepository, courseRepo repository.CourseRepository, projectRepo repository.ProjectRepository, userRepo repository.UserRepository, commentRepo repository.CommentRepository, tokenService TokenService, auditService AuditService) AdminService {
 	return &adminService{
-		toolRepo:    toolRepo,
-		courseRepo:  courseRepo,
-		projectRepo: projectRepo,
+		toolRepo:     toolRepo,
+		courseRepo:   courseRepo,
+		projectRepo:  projectRepo,
+		userRepo:     userRepo,
+		commentRepo:  commentRepo,
+		tokenService: tokenService,
+		auditService: auditService,
+	}
+}
+
+// reviewPermission 返回审核某类资源所需的权限
+func reviewPermission(resourceType string) string {
+	switch resourceType {
+	case "courses", "course", "课程", "course_resources", "course_resource", "course_resource_web", "course_resource_upload", "课程资源",
+		"course_chapters", "course_chapter", "课程章节", "course_prerequisites", "course_prerequisite", "修读关系":
+		return model.PermReviewCourse
+	case "projects", "project", "项目":
+		return model.PermReviewProject
+	default:
+		// 未指定类型时按工具处理
+		return model.PermReviewTool
 	}
 }
 
-func (s *adminService) GetPending(ctx context.Context, itemType string, cursor, limit int, sort string) (map[string]interface{}, error) {
+func (s *adminService) GetPending(ctx context.Context, role, itemType string, cursor, limit int, sort string) (map[string]interface{}, error) {
 	var data []map[string]interface{}
 	var err error
 
 	// 支持前端传递的英文类型名
+	switch itemType {
+	case "工具", "tools", "tool", "工具修改", "tool_revisions", "tool_revision", "课程", "courses", "course", "项目", "projects", "project",
+		"课程资源", "course_resources", "course_resource", "课程章节", "course_chapters", "course_chapter", "修读关系", "course_prerequisites", "course_prerequisite":
+		if !model.HasPermission(role, reviewPermission(itemType)) {
+			return nil, ErrPermissionDenied
+		}
+	}
+
 	switch itemType {
 	case "工具", "tools", "tool":
 		data, err = s.toolRepo.GetPending(ctx, cursor, limit)
+	case "工具修改", "tool_revisions", "tool_revision":
+		data, err = s.toolRepo.GetPendingRevisions(ctx, cursor, limit)
 	case "课程", "courses", "course":
 		data, err = s.courseRepo.GetPending(ctx, cursor, limit)
+	case "课程资源", "course_resources", "course_resource":
+		data, err = s.courseRepo.GetPendingResources(ctx, cursor, limit)
+	case "课程章节", "course_chapters", "course_chapter":
+		data, err = s.courseRepo.GetPendingChapters(ctx, cursor, limit)
+	case "修读关系", "course_prerequisites", "course_prerequisite":
+		data, err = s.courseRepo.GetPendingPrerequisites(ctx, cursor, limit)
 	case "项目", "projects", "project":
 		data, err = s.projectRepo.GetPending(ctx, cursor, limit)
 	case "评论", "comments", "comment":
 		// 获取待审核评论
 		data = []map[string]interface{}{}
 	default:
-		// 如果没有指定类型，返回所有类型的待审核项
-		toolData, _ := s.toolRepo.GetPending(ctx, cursor, limit)
-		courseData, _ := s.courseRepo.GetPending(ctx, cursor, limit)
-		projectData, _ := s.projectRepo.GetPending(ctx, cursor, limit)
-		data = append(data, toolData...)
-		data = append(data, courseData...)
-		data = append(data, projectData...)
+		// 如果没有指定类型，返回当前角色有权审核的所有类型的待审核项
+		if model.HasPermission(role, model.PermReviewTool) {
+			toolData, _ := s.toolRepo.GetPending(ctx, cursor, limit)
+			data = append(data, toolData...)
+			revisionData, _ := s.toolRepo.GetPendingRevisions(ctx, cursor, limit)
+			data = append(data, revisionData...)
+		}
+		if model.HasPermission(role, model.PermReviewCourse) {
+			courseData, _ := s.courseRepo.GetPending(ctx, cursor, limit)
+			data = append(data, courseData...)
+			resourceData, _ := s.courseRepo.GetPendingResources(ctx, cursor, limit)
+			data = append(data, resourceData...)
+			chapterData, _ := s.courseRepo.GetPendingChapters(ctx, cursor, limit)
+			data = append(data, chapterData...)
+			prerequisiteData, _ := s.courseRepo.GetPendingPrerequisites(ctx, cursor, limit)
+			data = append(data, prerequisiteData...)
+		}
+		if model.HasPermission(role, model.PermReviewProject) {
+			projectData, _ := s.projectRepo.GetPending(ctx, cursor, limit)
+			data = append(data, projectData...)
+		}
 	}
 
 	if err != nil {
@@ -62,7 +123,11 @@ func (s *adminService) GetPending(ctx context.Context, itemType string, cursor,
 	}, nil
 }
 
-func (s *adminService) ReviewItem(ctx context.Context, itemID, action, resourceType, rejectReason string) error {
+func (s *adminService) ReviewItem(ctx context.Context, role, itemID, action, resourceType, rejectReason string) error {
+	if !model.HasPermission(role, reviewPermission(resourceType)) {
+		return ErrPermissionDenied
+	}
+
 	// 根据resourceType判断资源类型
 	switch resourceType {
 	case "tools", "tool", "工具":
@@ -71,12 +136,45 @@ func (s *adminService) ReviewItem(ctx context.Context, itemID, action, resourceT
 			return fmt.Errorf("failed to review tool: %w", err)
 		}
 		return nil
+	case "tool_revisions", "tool_revision", "工具修改":
+		// 通过时在同一事务中应用修改，工具已被他人修改时返回 ErrToolRevisionConflict
+		err := s.toolRepo.UpdateRevisionStatus(ctx, itemID, action, rejectReason)
+		if err != nil {
+			return fmt.Errorf("failed to review tool revision: %w", err)
+		}
+		return nil
 	case "courses", "course", "课程":
 		err := s.courseRepo.UpdateCourseStatus(ctx, itemID, action, rejectReason)
 		if err != nil {
 			return fmt.Errorf("failed to review course: %w", err)
 		}
 		return nil
+	case "course_resource_web", "course_resource_upload":
+		// 待审核列表中的 resourceType 区分 URL 资源和上传文件
+		kind := strings.TrimPrefix(resourceType, "course_resource_")
+		err := s.courseRepo.UpdateResourceStatus(ctx, kind, itemID, action, rejectReason)
+		if err != nil {
+			return fmt.Errorf("failed to review course resource: %w", err)
+		}
+		return nil
+	case "course_chapters", "course_chapter", "课程章节":
+		err := s.courseRepo.UpdateChapterStatus(ctx, itemID, action, rejectReason)
+		if err != nil {
+			return fmt.Errorf("failed to review course chapter: %w", err)
+		}
+		return nil
+	case "course_prerequisites", "course_prerequisite", "修读关系":
+		// 审核期间可能有其他关系生效，通过前重新检查先修环
+		if action == model.CourseStatusApproved {
+			if err := s.checkPrerequisiteApproval(ctx, itemID); err != nil {
+				return err
+			}
+		}
+		err := s.courseRepo.UpdatePrerequisiteStatus(ctx, itemID, action, rejectReason)
+		if err != nil {
+			return fmt.Errorf("failed to review course prerequisite: %w", err)
+		}
+		return nil
 	case "projects", "project", "项目":
 		err := s.projectRepo.UpdateProjectStatus(ctx, itemID, action, rejectReason)
 		if err != nil {
@@ -92,3 +190,77 @@ func (s *adminService) ReviewItem(ctx context.Context, itemID, action, resourceT
 		return fmt.Errorf("item not found or cannot be reviewed: %w", err)
 	}
 }
+
+// checkPrerequisiteApproval 确认修读关系生效后不会与已生效的关系构成先修环
+func (s *adminService) checkPrerequisiteApproval(ctx context.Context, itemID string) error {
+	relationID, err := strconv.Atoi(itemID)
+	if err != nil {
+		return fmt.Errorf("invalid prerequisite ID: %s", itemID)
+	}
+	p, err := s.courseRepo.GetPrerequisiteByID(ctx, relationID)
+	if err != nil || p == nil {
+		// 不存在时由状态更新返回 not found
+		return err
+	}
+	edges, err := s.courseRepo.ListApprovedPrerequisites(ctx)
+	if err != nil {
+		return err
+	}
+	return prerequisiteCycleError(edges, p)
+}
+
+// GetRoles 获取全部角色及其权限
+func (s *adminService) GetRoles(ctx context.Context) []map[string]interface{} {
+	roles := make([]map[string]interface{}, 0, len(model.Roles))
+	for _, role := range model.Roles {
+		roles = append(roles, map[string]interface{}{
+			"role":        role,
+			"permissions": model.RolePermissions[role],
+		})
+	}
+	return roles
+}
+
+// AssignRole 为用户分配角色
+func (s *adminService) AssignRole(ctx context.Context, operatorID, userID int, role string) (*model.User, error) {
+	if !model.ValidRole(role) {
+		return nil, fmt.Errorf("invalid role: %s", role)
+	}
+	// 防止管理员误操作撤掉自己的权限
+	if operatorID == userID {
+		return nil, errors.New("cannot change your own role")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{
+		UserID:     userID,
+		Identifier: user.Username,
+		Type:       model.AuthEventRoleChanged,
+		Detail:     fmt.Sprintf("%s -> %s by user %d", user.Role, role, operatorID),
+	})
+	user.Role = role
+
+	// 角色写在访问令牌中，吊销该用户的会话使新角色立即生效
+	if err := s.tokenService.RevokeAllSessions(ctx, userID, ""); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteComment 版主删除任意评论
+func (s *adminService) DeleteComment(ctx context.Context, commentID int) error {
+	return s.commentRepo.ModerateDelete(ctx, commentID)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"time"
+)
+
+type AuditService interface {
+	// Record 写入一条认证审计记录；写入失败只记日志，不影响业务流程
+	Record(ctx context.Context, event model.AuthEvent)
+	// ListForUser 获取用户自己的安全日志
+	ListForUser(ctx context.Context, userID int, eventType string, cursor, limit int) (map[string]interface{}, error)
+	// Search 管理员按用户、事件类型、IP 和时间范围检索审计日志
+	Search(ctx context.Context, filter model.AuthEventFilter) (map[string]interface{}, error)
+	// Cleanup 清理超过保留期的审计日志
+	Cleanup(ctx context.Context) error
+}
+
+type auditService struct {
+	authEventRepo repository.AuthEventRepository
+	retention     time.Duration
+}
+
+func NewAuditService(authEventRepo repository.AuthEventRepository, cfg *config.Config) AuditService {
+	return &auditService{
+		authEventRepo: authEventRepo,
+		retention:     cfg.AuthEventRetention,
+	}
+}
+
+func (s *auditService) Record(ctx context.Context, event model.AuthEvent) {
+	// 未显式传入客户端信息时从请求 context 中获取
+	if event.IP == "" && event.UserAgent == "" {
+		client := model.ClientInfoFromContext(ctx)
+		event.IP = client.IP
+		event.UserAgent = client.UserAgent
+	}
+	event.UserAgent = truncate(event.UserAgent, 255)
+	event.Identifier = truncate(event.Identifier, 100)
+	event.Detail = truncate(event.Detail, 255)
+
+	if err := s.authEventRepo.Create(ctx, &event); err != nil {
+		log.Printf("Failed to record auth event %s for user %d: %v", event.Type, event.UserID, err)
+	}
+}
+
+func (s *auditService) ListForUser(ctx context.Context, userID int, eventType string, cursor, limit int) (map[string]interface{}, error) {
+	return s.Search(ctx, model.AuthEventFilter{
+		UserID: userID,
+		Type:   eventType,
+		Cursor: cursor,
+		Limit:  limit,
+	})
+}
+
+func (s *auditService) Search(ctx context.Context, filter model.AuthEventFilter) (map[string]interface{}, error) {
+	if filter.Type != "" && !model.ValidAuthEventType(filter.Type) {
+		return nil, errors.New("invalid event type")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, errors.New("from must be earlier than to")
+	}
+
+	events, err := s.authEventRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := 0
+	if len(events) > 0 {
+		nextCursor = events[len(events)-1].ID
+	}
+
+	return map[string]interface{}{
+		"message": "success",
+		"data":    events,
+		"cursor":  nextCursor,
+	}, nil
+}
+
+func (s *auditService) Cleanup(ctx context.Context) error {
+	return s.authEventRepo.DeleteBefore(ctx, time.Now().Add(-s.retention))
+}
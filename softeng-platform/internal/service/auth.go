@@ -3,115 +3,308 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"softeng-platform/internal/loginguard"
 	"softeng-platform/internal/model"
 	"softeng-platform/internal/repository"
 	"softeng-platform/internal/utils"
+	"strings"
 )
 
 type AuthService interface {
-	Register(ctx context.Context, req model.RegisterRequest) (string, error)
-	Login(ctx context.Context, req model.LoginRequest) (string, error)
+	Register(ctx context.Context, req model.RegisterRequest, client model.ClientInfo) (*model.TokenPair, error)
+	// Login 校验密码；已启用两步验证时返回待验证令牌，需再调用 VerifyMFA 完成登录
+	Login(ctx context.Context, req model.LoginRequest, client model.ClientInfo) (*model.LoginResult, error)
+	// VerifyMFA 登录第二步，校验 TOTP 验证码或恢复码后签发令牌对
+	VerifyMFA(ctx context.Context, req model.MFALoginRequest, client model.ClientInfo) (*model.TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string, client model.ClientInfo) (*model.TokenPair, error)
+	Logout(ctx context.Context, claims *utils.Claims) error
+	LogoutAll(ctx context.Context, userID int) error
 	ForgotPassword(ctx context.Context, email, newPassword, code string) error
+	SendCode(ctx context.Context, email, purpose string) error
+	ListLoginLocks(ctx context.Context) ([]loginguard.Record, error)
+	ClearLoginLock(ctx context.Context, key string) error
 }
 
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo            repository.UserRepository
+	invitationRepo      repository.InvitationRepository
+	verificationService VerificationService
+	tokenService        TokenService
+	mfaService          MFAService
+	loginGuard          *loginguard.Guard
+	auditService        AuditService
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
-	return &authService{userRepo: userRepo}
+func NewAuthService(userRepo repository.UserRepository, invitationRepo repository.InvitationRepository, verificationService VerificationService, tokenService TokenService, mfaService MFAService, loginGuard *loginguard.Guard, auditService AuditService) AuthService {
+	return &authService{
+		userRepo:            userRepo,
+		invitationRepo:      invitationRepo,
+		verificationService: verificationService,
+		tokenService:        tokenService,
+		mfaService:          mfaService,
+		loginGuard:          loginGuard,
+		auditService:        auditService,
+	}
 }
 
-func (s *authService) Register(ctx context.Context, req model.RegisterRequest) (string, error) {
+func (s *authService) Register(ctx context.Context, req model.RegisterRequest, client model.ClientInfo) (*model.TokenPair, error) {
+	// 邮箱统一去空格并转小写后保存，与验证码、找回密码使用相同的写法
+	req.Email = normalizeEmail(req.Email)
+
 	// 检查用户名是否已存在
 	existingUser, _ := s.userRepo.GetByUsername(ctx, req.Username)
 	if existingUser != nil {
-		return "", errors.New("username already exists")
+		return nil, errors.New("username already exists")
 	}
 
-	// 检查邮箱是否已存在
-	existingEmail, _ := s.userRepo.GetByEmail(ctx, req.Email)
-	if existingEmail != nil {
-		return "", errors.New("email already exists")
+	// 检查密码策略
+	if err := utils.CheckPasswordPolicy(req.Password, req.Username, req.Email); err != nil {
+		return nil, err
 	}
 
-	// 验证邮箱验证码和邀请码（这里需要实现具体的验证逻辑）
-	if !s.validateEmailCode(req.Email, req.EmailPassword) {
-		return "", errors.New("invalid email verification code")
+	// 预检查邀请码
+	invitation, err := s.invitationRepo.GetByCode(ctx, strings.TrimSpace(req.CertifyPassword))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkInvitationUsable(invitation); err != nil {
+		return nil, err
 	}
 
-	if !s.validateCertifyCode(req.CertifyPassword) {
-		return "", errors.New("invalid invitation code")
+	// 校验注册邮箱验证码，与创建用户在同一事务内使用，注册失败时验证码仍然有效
+	codeID, err := s.verificationService.CheckCode(ctx, req.Email, model.CodePurposeRegister, req.EmailPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已注册的邮箱收不到注册验证码，验证码通过后再检查，只有邮箱的持有者能看到该提示
+	existingEmail, _ := s.userRepo.GetByEmail(ctx, req.Email)
+	if existingEmail != nil {
+		return nil, errors.New("email already exists")
 	}
 
 	// 加密密码
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// 创建用户
+	// 创建用户，角色和届别由邀请码决定
+	role := invitation.Role
+	if role == "" {
+		role = "user"
+	}
 	user := &model.User{
 		Username: req.Username,
 		Email:    req.Email,
 		Password: hashedPassword,
 		Nickname: req.Username, // 默认昵称为用户名
-		Role:     "user",
+		Role:     role,
+		Cohort:   invitation.Cohort,
 	}
 
-	err = s.userRepo.Create(ctx, user)
+	// 使用验证码、占用邀请码、创建用户、记录兑换在同一事务内完成
+	err = s.invitationRepo.RegisterWithInvitation(ctx, invitation.ID, codeID, user)
 	if err != nil {
-		return "", err
-	}
-
-	// 生成 JWT
-	token, err := utils.GenerateToken(user.ID, user.Username, user.Role)
-	if err != nil {
-		return "", err
+		if errors.Is(err, repository.ErrInvitationUnavailable) {
+			return nil, ErrInvalidInvitation
+		}
+		if errors.Is(err, repository.ErrVerificationCodeConsumed) {
+			return nil, ErrInvalidCode
+		}
+		return nil, err
 	}
 
-	return token, nil
+	// 创建会话并签发访问令牌和刷新令牌
+	return s.tokenService.IssueTokens(ctx, user, client, false)
 }
 
-func (s *authService) Login(ctx context.Context, req model.LoginRequest) (string, error) {
+func (s *authService) Login(ctx context.Context, req model.LoginRequest, client model.ClientInfo) (*model.LoginResult, error) {
 	var user *model.User
 	var err error
 
 	// 根据用户名或邮箱查找用户
 	if contains(req.UsernameOrEmail, "@") {
-		user, err = s.userRepo.GetByEmail(ctx, req.UsernameOrEmail)
+		user, err = s.userRepo.GetByEmail(ctx, normalizeEmail(req.UsernameOrEmail))
 	} else {
 		user, err = s.userRepo.GetByUsername(ctx, req.UsernameOrEmail)
 	}
 
-	if err != nil || user == nil {
-		return "", errors.New("invalid credentials")
+	// 失败计数：账号存在时按用户ID，否则按输入的用户名/邮箱；同时按客户端IP
+	accountKey := loginguard.IdentifierKey(req.UsernameOrEmail)
+	if err == nil && user != nil {
+		accountKey = loginguard.UserKey(user.ID)
+	}
+	ipKey := loginguard.IPKey(client.IP)
+
+	// 审计记录：账号不存在时只记录输入的用户名/邮箱
+	event := model.AuthEvent{Identifier: req.UsernameOrEmail, IP: client.IP, UserAgent: client.UserAgent}
+	if err == nil && user != nil {
+		event.UserID = user.ID
+		event.Identifier = user.Username
+	}
+
+	// 处于退避期或锁定中时直接拒绝，不再校验密码
+	if err := s.loginGuard.Check(ctx, accountKey, ipKey); err != nil {
+		event.Type = model.AuthEventLoginBlocked
+		s.auditService.Record(ctx, event)
+		return nil, err
 	}
 
 	// 验证密码
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		return "", errors.New("invalid credentials")
+	if err != nil || user == nil || !utils.CheckPasswordHash(req.Password, user.Password) {
+		event.Type = model.AuthEventLoginFailed
+		s.auditService.Record(ctx, event)
+		if err := s.loginGuard.Fail(ctx, accountKey, ipKey); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("invalid credentials")
+	}
+
+	// 哈希成本低于当前配置时使用本次提交的明文重新哈希
+	if utils.NeedsRehash(user.Password) {
+		if hashed, err := utils.HashPassword(req.Password); err == nil {
+			if err := s.userRepo.UpdatePassword(ctx, user.ID, hashed); err != nil {
+				log.Printf("Failed to rehash password for user %d: %v", user.ID, err)
+			}
+		}
 	}
 
-	// 生成 JWT
-	token, err := utils.GenerateToken(user.ID, user.Username, user.Role)
+	mfaEnabled, err := s.mfaService.IsEnabled(ctx, user.ID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if mfaEnabled {
+		// 失败计数留到第二步通过后再清零，避免反复提交密码来重置验证码的尝试次数
+		token, expiresIn, err := s.mfaService.IssuePendingToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginResult{
+			MFARequired:  true,
+			MFAToken:     token,
+			MFAExpiresIn: expiresIn,
+		}, nil
 	}
 
-	return token, nil
+	if err := s.loginGuard.Succeed(ctx, accountKey); err != nil {
+		return nil, err
+	}
+
+	// 创建会话并签发访问令牌和刷新令牌
+	tokens, err := s.tokenService.IssueTokens(ctx, user, client, false)
+	if err != nil {
+		return nil, err
+	}
+	event.Type = model.AuthEventLogin
+	s.auditService.Record(ctx, event)
+	return &model.LoginResult{
+		Tokens:           tokens,
+		MFASetupRequired: s.mfaService.RequiredFor(user.Role),
+	}, nil
 }
 
-func (s *authService) ForgotPassword(ctx context.Context, email, newPassword, certifyPassword string) error {
-	// 验证邀请码（根据API文档，forgot-password使用certify_password作为邀请码）
-	if !s.validateCertifyCode(certifyPassword) {
-		return errors.New("invalid invitation code")
+func (s *authService) VerifyMFA(ctx context.Context, req model.MFALoginRequest, client model.ClientInfo) (*model.TokenPair, error) {
+	if req.Code == "" && req.RecoveryCode == "" {
+		return nil, errors.New("code or recovery_code is required")
 	}
 
-	// 查找用户
+	claims, err := utils.ValidateMFAPendingToken(req.MFAToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired mfa token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("invalid or expired mfa token")
+	}
+
+	// 验证码错误与密码错误共用同一套退避和锁定
+	accountKey := loginguard.UserKey(user.ID)
+	ipKey := loginguard.IPKey(client.IP)
+	event := model.AuthEvent{UserID: user.ID, Identifier: user.Username, IP: client.IP, UserAgent: client.UserAgent}
+	if err := s.loginGuard.Check(ctx, accountKey, ipKey); err != nil {
+		event.Type = model.AuthEventLoginBlocked
+		s.auditService.Record(ctx, event)
+		return nil, err
+	}
+
+	if err := s.mfaService.Verify(ctx, user.ID, req.Code, req.RecoveryCode); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			event.Type = model.AuthEventMFAFailed
+			s.auditService.Record(ctx, event)
+			if err := s.loginGuard.Fail(ctx, accountKey, ipKey); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.loginGuard.Succeed(ctx, accountKey); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.tokenService.IssueTokens(ctx, user, client, true)
+	if err != nil {
+		return nil, err
+	}
+	event.Type = model.AuthEventLogin
+	event.Detail = "mfa"
+	if req.RecoveryCode != "" {
+		event.Detail = "recovery_code"
+	}
+	s.auditService.Record(ctx, event)
+	return tokens, nil
+}
+
+// RefreshToken 使用刷新令牌换取新的令牌对
+func (s *authService) RefreshToken(ctx context.Context, refreshToken string, client model.ClientInfo) (*model.TokenPair, error) {
+	return s.tokenService.Refresh(ctx, refreshToken, client)
+}
+
+// Logout 登出当前会话
+func (s *authService) Logout(ctx context.Context, claims *utils.Claims) error {
+	if err := s.tokenService.RevokeSession(ctx, claims); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: claims.UserID, Identifier: claims.Username, Type: model.AuthEventLogout})
+	return nil
+}
+
+// LogoutAll 登出全部设备
+func (s *authService) LogoutAll(ctx context.Context, userID int) error {
+	if err := s.tokenService.RevokeAllSessions(ctx, userID, ""); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Type: model.AuthEventLogoutAll})
+	return nil
+}
+
+func (s *authService) ForgotPassword(ctx context.Context, email, newPassword, code string) error {
+	// 先校验验证码：未注册的邮箱收不到重置验证码，与验证码错误返回相同的结果，不泄露账号是否存在
+	email = normalizeEmail(email)
+	codeID, err := s.verificationService.CheckCode(ctx, email, model.CodePurposeReset, code)
+	if err != nil {
+		return err
+	}
 	user, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil || user == nil {
-		return errors.New("user not found")
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidCode
+	}
+
+	// 检查密码策略，不符合时验证码仍然有效
+	if err := utils.CheckPasswordPolicy(newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+	if err := s.verificationService.ConsumeChecked(ctx, codeID); err != nil {
+		return err
 	}
 
 	// 加密新密码
@@ -121,22 +314,49 @@ func (s *authService) ForgotPassword(ctx context.Context, email, newPassword, ce
 	}
 
 	// 更新密码
-	return s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword)
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, model.AuthEvent{UserID: user.ID, Identifier: user.Username, Type: model.AuthEventPasswordReset})
+
+	// 重置密码后使所有已登录会话失效
+	return s.tokenService.RevokeAllSessions(ctx, user.ID, "")
 }
 
-func (s *authService) validateEmailCode(email, code string) bool {
-	// 实现邮箱验证码验证逻辑
-	return true // 临时返回true
+// SendCode 发送邮箱验证码，按用途检查邮箱状态
+func (s *authService) SendCode(ctx context.Context, email, purpose string) error {
+	email = normalizeEmail(email)
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	switch purpose {
+	// 注册和更换邮箱时邮箱已注册、找回密码时邮箱未注册都静默返回，响应与发送成功相同，避免泄露账号是否存在
+	case model.CodePurposeRegister, model.CodePurposeChangeEmail:
+		if existing != nil {
+			return nil
+		}
+	case model.CodePurposeReset:
+		if existing == nil {
+			return nil
+		}
+	default:
+		return errors.New("invalid purpose")
+	}
+
+	return s.verificationService.SendCode(ctx, email, purpose)
 }
 
-func (s *authService) validateCertifyCode(code string) bool {
-	// 实现邀请码验证逻辑
-	return true // 临时返回true
+// ListLoginLocks 获取当前被封禁的账号和IP
+func (s *authService) ListLoginLocks(ctx context.Context) ([]loginguard.Record, error) {
+	return s.loginGuard.ListBlocked(ctx)
 }
 
-func (s *authService) validateResetCode(email, code string) bool {
-	// 实现重置密码验证码验证逻辑
-	return true // 临时返回true
+// ClearLoginLock 解除账号或IP的封禁
+func (s *authService) ClearLoginLock(ctx context.Context, key string) error {
+	return s.loginGuard.Clear(ctx, key)
 }
 
 func contains(s, substr string) bool {
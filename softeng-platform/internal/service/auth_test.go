@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"testing"
+)
+
+// fakeEmailUsers 按邮箱原样匹配，记录查询时使用的邮箱
+type fakeEmailUsers struct {
+	repository.UserRepository
+	user    *model.User
+	lookups []string
+}
+
+func (r *fakeEmailUsers) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.lookups = append(r.lookups, email)
+	if email == r.user.Email {
+		return r.user, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeEmailUsers) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	return nil
+}
+
+type fakeCodes struct {
+	VerificationService
+	emails []string
+}
+
+func (v *fakeCodes) SendCode(ctx context.Context, email, purpose string) error {
+	v.emails = append(v.emails, email)
+	return nil
+}
+
+func (v *fakeCodes) ConsumeCode(ctx context.Context, email, purpose, code string) error {
+	v.emails = append(v.emails, email)
+	return nil
+}
+
+func (v *fakeCodes) CheckCode(ctx context.Context, email, purpose, code string) (int, error) {
+	v.emails = append(v.emails, email)
+	return 1, nil
+}
+
+func (v *fakeCodes) ConsumeChecked(ctx context.Context, codeID int) error {
+	return nil
+}
+
+func TestPasswordResetNormalizesEmail(t *testing.T) {
+	const email = "alice@example.edu"
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"as registered", email},
+		{"mixed case", "Alice@Example.EDU"},
+		{"surrounding spaces", "  alice@example.edu\t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeEmailUsers{user: &model.User{ID: 1, Username: "alice", Email: email}}
+			codes := &fakeCodes{}
+			svc := NewAuthService(users, nil, codes, &fakeSessionTokens{}, nil, nil, &countingAudit{})
+
+			if err := svc.SendCode(context.Background(), tt.input, model.CodePurposeReset); err != nil {
+				t.Fatalf("SendCode: %v", err)
+			}
+			if err := svc.ForgotPassword(context.Background(), tt.input, "N3w-Passw0rd!2024", "123456"); err != nil {
+				t.Fatalf("ForgotPassword: %v", err)
+			}
+
+			for _, got := range append(users.lookups, codes.emails...) {
+				if got != email {
+					t.Errorf("email used = %q, want %q", got, email)
+				}
+			}
+			if len(codes.emails) != 2 {
+				t.Errorf("verification calls = %v, want send and consume", codes.emails)
+			}
+		})
+	}
+}
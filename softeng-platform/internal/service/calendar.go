@@ -0,0 +1,522 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"softeng-platform/internal/calendar"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// calendarRefresh 建议日历 App 刷新订阅的间隔
+	calendarRefresh = 6 * time.Hour
+	// calendarUIDDomain 日历事件 UID 的后缀，保证不同来源的 UID 不冲突
+	calendarUIDDomain = "softeng-platform"
+	// calendarFeedTokenBytes 订阅令牌的随机字节数
+	calendarFeedTokenBytes = 24
+)
+
+var (
+	ErrCourseEventNotFound  = errors.New("course event not found")
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+)
+
+// courseEventLabels 日历事件类型在标题中的前缀
+var courseEventLabels = map[string]string{
+	model.CourseEventDeadline: "截止",
+	model.CourseEventExam:     "考试",
+	model.CourseEventOther:    "活动",
+}
+
+type CalendarService interface {
+	// CourseCalendar 公共课程日历：开课学期和课程事件，未指定学期时只包含尚未结束的
+	CourseCalendar(ctx context.Context, filter model.SemesterFilter, courseIDs []int) ([]byte, error)
+	// UserCalendar 按订阅令牌生成个人日历，包含收藏和加入学习计划的课程
+	UserCalendar(ctx context.Context, token string) ([]byte, error)
+	// CreateFeed 生成个人日历订阅地址，之前的地址随即失效
+	CreateFeed(ctx context.Context, userID int) (*model.CalendarFeed, error)
+	RevokeFeed(ctx context.Context, userID int) error
+	ListCourseEvents(ctx context.Context, courseID string) ([]model.CourseEvent, error)
+	// AddCourseEvent 课程贡献者和课程审核员可以添加截止时间、考试和活动
+	AddCourseEvent(ctx context.Context, userID int, role, courseID string, req model.CreateCourseEventRequest) (*model.CourseEvent, error)
+	// DeleteCourseEvent 添加者、课程贡献者和课程审核员可以删除
+	DeleteCourseEvent(ctx context.Context, userID int, role, courseID string, eventID int) error
+}
+
+type calendarService struct {
+	calendarRepo repository.CalendarRepository
+	courseRepo   repository.CourseRepository
+	semesterRepo repository.SemesterRepository
+	feedBaseURL  string
+	terms        map[int]termDates
+}
+
+func NewCalendarService(calendarRepo repository.CalendarRepository, courseRepo repository.CourseRepository, semesterRepo repository.SemesterRepository, cfg *config.Config) CalendarService {
+	return &calendarService{
+		calendarRepo: calendarRepo,
+		courseRepo:   courseRepo,
+		semesterRepo: semesterRepo,
+		feedBaseURL:  strings.TrimRight(cfg.CalendarFeedBaseURL, "/"),
+		terms: map[int]termDates{
+			model.SemesterTermFall:   parseTermDates("CALENDAR_FALL_TERM", cfg.CalendarFallTerm, "09-01/01-15"),
+			model.SemesterTermSpring: parseTermDates("CALENDAR_SPRING_TERM", cfg.CalendarSpringTerm, "02-24/07-05"),
+			model.SemesterTermSummer: parseTermDates("CALENDAR_SUMMER_TERM", cfg.CalendarSummerTerm, "07-06/08-31"),
+		},
+	}
+}
+
+// termDates 学期未设置起止日期时的默认月日
+type termDates struct {
+	startMonth, startDay int
+	endMonth, endDay     int
+}
+
+// parseTermDates 解析 MM-DD/MM-DD 格式的默认学期日期，格式错误时使用 fallback
+func parseTermDates(key, value, fallback string) termDates {
+	parse := func(value string) (termDates, bool) {
+		bounds := strings.Split(strings.TrimSpace(value), "/")
+		if len(bounds) != 2 {
+			return termDates{}, false
+		}
+		var nums [4]int
+		for i, bound := range bounds {
+			parts := strings.Split(strings.TrimSpace(bound), "-")
+			if len(parts) != 2 {
+				return termDates{}, false
+			}
+			month, err1 := strconv.Atoi(parts[0])
+			day, err2 := strconv.Atoi(parts[1])
+			if err1 != nil || err2 != nil || month < 1 || month > 12 || day < 1 || day > 31 {
+				return termDates{}, false
+			}
+			nums[i*2], nums[i*2+1] = month, day
+		}
+		return termDates{startMonth: nums[0], startDay: nums[1], endMonth: nums[2], endDay: nums[3]}, true
+	}
+
+	if t, ok := parse(value); ok {
+		return t
+	}
+	log.Printf("invalid %s %q, using %s", key, value, fallback)
+	t, _ := parse(fallback)
+	return t
+}
+
+// semesterRange 学期的起止日期（含），未设置时按学年和配置的默认日期推算：
+// 秋季学期从学年起始年份开始，春季和夏季学期在下一年，结束月日早于开始月日时跨年
+func (s *calendarService) semesterRange(semester model.Semester) (time.Time, time.Time, bool) {
+	t, ok := s.terms[semester.Term]
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	year := semester.AcademicYear
+	if semester.Term != model.SemesterTermFall {
+		year++
+	}
+	start := time.Date(year, time.Month(t.startMonth), t.startDay, 0, 0, 0, 0, time.Local)
+	end := time.Date(year, time.Month(t.endMonth), t.endDay, 0, 0, 0, 0, time.Local)
+	if end.Before(start) {
+		end = end.AddDate(1, 0, 0)
+	}
+
+	if semester.StartDate != "" {
+		if d, err := time.ParseInLocation(model.SemesterDateLayout, semester.StartDate, time.Local); err == nil {
+			start = d
+		}
+	}
+	if semester.EndDate != "" {
+		if d, err := time.ParseInLocation(model.SemesterDateLayout, semester.EndDate, time.Local); err == nil {
+			end = d
+		}
+	}
+	if end.Before(start) {
+		end = start
+	}
+	return start, end, true
+}
+
+// dateRange 日历中的一段日期，end 为最后一天（含）
+type dateRange struct {
+	start, end time.Time
+}
+
+// contains 判断事件是否与日期范围有交集
+func (r dateRange) contains(e model.CourseEvent) bool {
+	return !courseEventEnd(e).Before(r.start) && e.StartAt.Before(r.end.AddDate(0, 0, 1))
+}
+
+func (s *calendarService) CourseCalendar(ctx context.Context, filter model.SemesterFilter, courseIDs []int) ([]byte, error) {
+	// "2024-Fall" 等写法按规范名称匹配
+	filter.Name = model.NormalizeSemester(filter.Name)
+	offerings, err := s.calendarRepo.ListOfferings(ctx, filter, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	today := startOfDay(time.Now())
+	cal := &calendar.Calendar{Name: "课程日历", Refresh: calendarRefresh}
+	ranges := make(map[int][]dateRange)
+	for _, offering := range offerings {
+		start, end, ok := s.semesterRange(offering.Semester)
+		if !ok || (filter.Empty() && end.Before(today)) {
+			continue
+		}
+		cal.Events = append(cal.Events, offeringEvent(offering, start, end, ""))
+		ranges[offering.CourseID] = append(ranges[offering.CourseID], dateRange{start: start, end: end})
+	}
+	if len(courseIDs) == 1 && len(offerings) > 0 {
+		cal.Name = offerings[0].CourseName + " · 课程日历"
+	}
+
+	// 未指定课程时只包含日历中有开课的课程的事件
+	eventCourses := courseIDs
+	if len(eventCourses) == 0 {
+		for courseID := range ranges {
+			eventCourses = append(eventCourses, courseID)
+		}
+		sort.Ints(eventCourses)
+	}
+	events, err := s.calendarRepo.ListCourseEvents(ctx, eventCourses)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if filter.Empty() {
+			if courseEventEnd(e).Before(today) {
+				continue
+			}
+		} else if !anyRangeContains(ranges[e.CourseID], e) {
+			// 指定学期时只包含落在所选学期内的事件
+			continue
+		}
+		cal.Events = append(cal.Events, courseEventEntry(e))
+	}
+	return cal.Bytes(time.Now()), nil
+}
+
+func (s *calendarService) UserCalendar(ctx context.Context, token string) ([]byte, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrCalendarFeedNotFound
+	}
+	userID, err := s.calendarRepo.GetFeedTokenUser(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if userID == 0 {
+		return nil, ErrCalendarFeedNotFound
+	}
+	if err := s.calendarRepo.TouchFeedToken(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	courses, err := s.calendarRepo.ListUserCourses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	cal := &calendar.Calendar{Name: "我的课程日历", Refresh: calendarRefresh}
+	if len(courses) == 0 {
+		return cal.Bytes(time.Now()), nil
+	}
+
+	courseIDs := make([]int, 0, len(courses))
+	planned := make(map[int]int) // 课程ID -> 学习计划学期的顺序
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.CourseID)
+		if year, term, ok := model.ParseSemester(course.PlanSemester); ok {
+			planned[course.CourseID] = model.SemesterOrderKey(year, term)
+		}
+	}
+
+	offerings, err := s.calendarRepo.ListOfferings(ctx, model.SemesterFilter{}, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+	today := startOfDay(time.Now())
+	covered := make(map[int]bool) // 学习计划学期已有开课的课程
+	for _, offering := range offerings {
+		note := ""
+		if key, ok := planned[offering.CourseID]; ok && key == offering.Semester.OrderKey() {
+			note = "已加入学习计划"
+			covered[offering.CourseID] = true
+		}
+		start, end, ok := s.semesterRange(offering.Semester)
+		if !ok || end.Before(today) {
+			continue
+		}
+		cal.Events = append(cal.Events, offeringEvent(offering, start, end, note))
+	}
+
+	// 学习计划中的学期没有开课记录时，按学期日期添加计划修读事件
+	if len(planned) > len(covered) {
+		semesters, err := s.semesterRepo.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		catalog := make(map[int]model.Semester, len(semesters))
+		for _, semester := range semesters {
+			catalog[semester.OrderKey()] = semester
+		}
+		for _, course := range courses {
+			key, ok := planned[course.CourseID]
+			if !ok || covered[course.CourseID] {
+				continue
+			}
+			semester, ok := catalog[key]
+			if !ok {
+				semester = model.Semester{AcademicYear: key / 10, Term: key % 10, Name: model.SemesterName(key/10, key%10)}
+			}
+			start, end, ok := s.semesterRange(semester)
+			if !ok || end.Before(today) {
+				continue
+			}
+			cal.Events = append(cal.Events, calendar.Event{
+				UID:         fmt.Sprintf("plan-%d-%d@%s", course.CourseID, key, calendarUIDDomain),
+				Summary:     fmt.Sprintf("计划修读：%s（%s）", course.CourseName, semester.Name),
+				Description: "学习计划中的课程，该学期暂无开课记录",
+				Categories:  []string{"学习计划"},
+				Start:       start,
+				End:         end,
+				AllDay:      true,
+			})
+		}
+	}
+
+	events, err := s.calendarRepo.ListCourseEvents(ctx, courseIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if !courseEventEnd(e).Before(today) {
+			cal.Events = append(cal.Events, courseEventEntry(e))
+		}
+	}
+	return cal.Bytes(time.Now()), nil
+}
+
+func (s *calendarService) CreateFeed(ctx context.Context, userID int) (*model.CalendarFeed, error) {
+	token, err := utils.GenerateRandomToken(calendarFeedTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := s.calendarRepo.SaveFeedToken(ctx, userID, utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	return &model.CalendarFeed{
+		URL:       s.feedBaseURL + "/course/calendar/" + token + ".ics",
+		Token:     token,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func (s *calendarService) RevokeFeed(ctx context.Context, userID int) error {
+	return s.calendarRepo.DeleteFeedToken(ctx, userID)
+}
+
+func (s *calendarService) ListCourseEvents(ctx context.Context, courseID string) ([]model.CourseEvent, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return s.calendarRepo.ListCourseEvents(ctx, []int{cid})
+}
+
+func (s *calendarService) AddCourseEvent(ctx context.Context, userID int, role, courseID string, req model.CreateCourseEventRequest) (*model.CourseEvent, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEventPermission(ctx, userID, role, cid, 0); err != nil {
+		return nil, err
+	}
+
+	kind := strings.TrimSpace(req.Kind)
+	if kind == "" {
+		kind = model.CourseEventOther
+	}
+	if !model.ValidCourseEventKind(kind) {
+		return nil, errors.New("kind must be deadline, exam or event")
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	startAt, allDay, err := parseEventTime(req.StartAt)
+	if err != nil {
+		return nil, errors.New("start_at must be YYYY-MM-DD, YYYY-MM-DD HH:MM or RFC 3339")
+	}
+	event := &model.CourseEvent{
+		CourseID:    cid,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+		Kind:        kind,
+		StartAt:     startAt,
+		AllDay:      allDay,
+		Location:    strings.TrimSpace(req.Location),
+		CreatorID:   userID,
+	}
+	if strings.TrimSpace(req.EndAt) != "" {
+		endAt, endAllDay, err := parseEventTime(req.EndAt)
+		if err != nil {
+			return nil, errors.New("end_at must be YYYY-MM-DD, YYYY-MM-DD HH:MM or RFC 3339")
+		}
+		if endAllDay != allDay {
+			return nil, errors.New("start_at and end_at must both be dates or both be times")
+		}
+		if endAt.Before(startAt) {
+			return nil, errors.New("end_at must not be earlier than start_at")
+		}
+		event.EndAt = &endAt
+	}
+
+	if err := s.calendarRepo.CreateCourseEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	return s.calendarRepo.GetCourseEvent(ctx, event.EventID)
+}
+
+func (s *calendarService) DeleteCourseEvent(ctx context.Context, userID int, role, courseID string, eventID int) error {
+	cid, err := strconv.Atoi(courseID)
+	if err != nil {
+		return ErrCourseEventNotFound
+	}
+	event, err := s.calendarRepo.GetCourseEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if event == nil || event.CourseID != cid {
+		return ErrCourseEventNotFound
+	}
+	if err := s.checkEventPermission(ctx, userID, role, cid, event.CreatorID); err != nil {
+		return err
+	}
+	return s.calendarRepo.DeleteCourseEvent(ctx, eventID)
+}
+
+// checkEventPermission 课程审核员、课程贡献者和事件添加者可以管理课程事件，添加时 creatorID 为 0
+func (s *calendarService) checkEventPermission(ctx context.Context, userID int, role string, courseID, creatorID int) error {
+	if model.HasPermission(role, model.PermReviewCourse) || (creatorID != 0 && creatorID == userID) {
+		return nil
+	}
+	ok, err := s.calendarRepo.IsCourseContributor(ctx, courseID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// approvedCourseID 解析课程ID并确认课程已审核通过
+func (s *calendarService) approvedCourseID(ctx context.Context, courseID string) (int, error) {
+	cid, err := strconv.Atoi(courseID)
+	if err != nil {
+		return 0, ErrCourseNotFound
+	}
+	_, status, err := s.courseRepo.GetCourseSubmitter(ctx, courseID)
+	if err != nil {
+		return 0, err
+	}
+	if status != model.CourseStatusApproved {
+		return 0, ErrCourseNotFound
+	}
+	return cid, nil
+}
+
+// offeringEvent 开课对应的全天事件，覆盖整个学期
+func offeringEvent(offering model.CalendarOffering, start, end time.Time, note string) calendar.Event {
+	var lines []string
+	if len(offering.Teachers) > 0 {
+		lines = append(lines, "任课教师："+strings.Join(offering.Teachers, "、"))
+	}
+	if offering.Credit > 0 {
+		lines = append(lines, fmt.Sprintf("学分：%d", offering.Credit))
+	}
+	if note != "" {
+		lines = append(lines, note)
+	}
+	return calendar.Event{
+		UID:         fmt.Sprintf("offering-%d@%s", offering.OfferingID, calendarUIDDomain),
+		Summary:     fmt.Sprintf("%s（%s）", offering.CourseName, offering.Semester.Name),
+		Description: strings.Join(lines, "\n"),
+		Categories:  []string{"开课"},
+		Start:       start,
+		End:         end,
+		AllDay:      true,
+	}
+}
+
+// courseEventEntry 课程事件对应的日历事件，标题带类型前缀
+func courseEventEntry(e model.CourseEvent) calendar.Event {
+	label := courseEventLabels[e.Kind]
+	event := calendar.Event{
+		UID:         fmt.Sprintf("event-%d@%s", e.EventID, calendarUIDDomain),
+		Summary:     fmt.Sprintf("[%s] %s：%s", label, e.CourseName, e.Title),
+		Description: e.Description,
+		Location:    e.Location,
+		Categories:  []string{label},
+		Start:       e.StartAt,
+		AllDay:      e.AllDay,
+		Updated:     e.CreatedAt,
+	}
+	if e.EndAt != nil {
+		event.End = *e.EndAt
+	}
+	return event
+}
+
+// courseEventEnd 课程事件的结束时间，全天事件为最后一天的开始
+func courseEventEnd(e model.CourseEvent) time.Time {
+	end := e.StartAt
+	if e.EndAt != nil {
+		end = *e.EndAt
+	}
+	if e.AllDay {
+		return startOfDay(end)
+	}
+	return end
+}
+
+func anyRangeContains(ranges []dateRange, e model.CourseEvent) bool {
+	for _, r := range ranges {
+		if r.contains(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEventTime 解析课程事件时间，只有日期时为全天事件；不带时区时按服务器时区解析
+func parseEventTime(value string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation(model.SemesterDateLayout, value, time.Local); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, false, nil
+		}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, false, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
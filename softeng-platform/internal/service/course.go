@@ -2,15 +2,60 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"softeng-platform/internal/analyzer"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 type CourseService interface {
-	GetCourses(ctx context.Context, semester string, category []string, sort string, limit, cursor int, resourceType string) (map[string]interface{}, error)
+	// GetCourses 获取课程列表，semester 可按学期ID、学期范围或学期名称筛选
+	GetCourses(ctx context.Context, semester model.SemesterFilter, category []string, sort string, limit, cursor int, resourceType string) (map[string]interface{}, error)
 	GetCourse(ctx context.Context, courseID, resourceType string, userID int) (map[string]interface{}, error)
+	// SubmitCourse 提交新课程，审核通过前只对提交者可见
+	SubmitCourse(ctx context.Context, userID int, req CourseSubmitRequest) (map[string]interface{}, error)
+	// AnalyzeCourse 抓取课程链接，返回课程提交的预填信息和建议的网络资源
+	AnalyzeCourse(ctx context.Context, rawURL string) (*model.CourseAnalysis, error)
 	SearchCourses(ctx context.Context, keyword string, category []string, limit, cursor int, resourceType string) (map[string]interface{}, error)
 	UploadResource(ctx context.Context, userID int, courseID, resourceType string, req CourseUploadRequest) (map[string]interface{}, error)
-	DownloadTextbook(ctx context.Context, courseID, textbookID string) (map[string]interface{}, error)
+	GetResources(ctx context.Context, courseID string) (map[string]interface{}, error)
+	// GetChapters 获取课程审核通过的章节大纲
+	GetChapters(ctx context.Context, courseID string) (map[string]interface{}, error)
+	// SubmitChapter 提交章节，审核通过后出现在大纲中
+	SubmitChapter(ctx context.Context, userID int, courseID string, req model.SubmitChapterRequest) (*model.CourseChapter, error)
+	// GetProgress 获取用户在课程中各章节的完成情况
+	GetProgress(ctx context.Context, userID int, courseID string) (*model.CourseProgress, error)
+	// UpdateProgress 标记章节已完成或取消完成，返回更新后的课程进度
+	UpdateProgress(ctx context.Context, userID int, courseID, chapterID string, completed bool) (*model.CourseProgress, error)
+	// RateCourse 对一次开课（教师 + 学期）评分，学期必须是课程已有的开课学期，重复评分时覆盖
+	RateCourse(ctx context.Context, userID int, courseID string, req model.RateCourseRequest) (*model.CourseRating, error)
+	// GetRatings 获取课程评分汇总和评价列表
+	GetRatings(ctx context.Context, courseID string, cursor, limit int) (map[string]interface{}, error)
+	// GetPrerequisites 获取课程的先修、同修要求、全部间接先修课程和相关的先修环
+	GetPrerequisites(ctx context.Context, courseID string) (*model.CoursePrerequisites, error)
+	// SubmitPrerequisite 提交修读关系，审核通过后生效；会构成先修环的关系直接拒绝
+	SubmitPrerequisite(ctx context.Context, userID int, courseID string, req model.SubmitPrerequisiteRequest) (*model.CoursePrerequisite, error)
+	// GetCurriculumGraph 获取全部课程和修读关系构成的关系图
+	GetCurriculumGraph(ctx context.Context) (*model.CurriculumGraph, error)
+	// CheckPrerequisites 检查按学期排列的修读安排，标出先修课程安排在之后的课程
+	CheckPrerequisites(ctx context.Context, req model.PrerequisiteCheckRequest) (*model.PrerequisiteCheckResult, error)
+	// DownloadTextbook 获取可下载的课程上传资源，未审核或已删除的资源返回 ErrResourceNotFound
+	DownloadTextbook(ctx context.Context, courseID, textbookID string) (*TextbookDownload, error)
+	// RecordDownload 累加资源下载次数
+	RecordDownload(ctx context.Context, resourceID int)
 	AddComment(ctx context.Context, userID int, courseID, content string) (map[string]interface{}, error)
 	DeleteComment(ctx context.Context, userID int, courseID string, commentID string) (map[string]interface{}, error)
 	ReplyComment(ctx context.Context, userID int, courseID, commentID, content string) (map[string]interface{}, error)
@@ -23,22 +68,65 @@ type CourseService interface {
 }
 
 // CourseUploadRequest 课程资源上传请求
+// 文件通过 multipart 的 file 字段上传，Resource 为外部链接，两者至少提供一个
 type CourseUploadRequest struct {
-	File        string   `form:"file" json:"file"`
-	Resource    string   `form:"resource" json:"resource"`
-	Description string   `form:"description" json:"description" binding:"required"`
-	Tags        []string `form:"tags" json:"tags"`
+	Resource    string                `form:"resource" json:"resource"`
+	Description string                `form:"description" json:"description" binding:"required"`
+	Tags        []string              `form:"tags" json:"tags"`
+	ChapterID   int                   `form:"chapter_id" json:"chapter_id"` // 关联的章节，0 表示不关联
+	Upload      *multipart.FileHeader `form:"-" json:"-"` // 由 handler 从 multipart 中取出
+}
+
+// CourseSubmitRequest 课程提交请求结构体
+type CourseSubmitRequest struct {
+	Name     string   `form:"name" json:"name" binding:"required"`
+	Semester string   `form:"semester" json:"semester"`
+	Credit   int      `form:"credit" json:"credit"`
+	Cover    string   `form:"cover" json:"cover"`
+	Teacher  []string `form:"teacher" json:"teacher"`
+	Category []string `form:"category" json:"category"`
+}
+
+var (
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrResourceGone     = errors.New("resource file is no longer available")
+	ErrCourseNotFound   = errors.New("course not found")
+	ErrChapterNotFound  = errors.New("chapter not found")
+	// ErrPrerequisiteExists 同一关系已在审核中或已生效
+	ErrPrerequisiteExists = errors.New("prerequisite already submitted")
+	ErrPrerequisiteCycle  = errors.New("prerequisite would create a cycle")
+)
+
+// TextbookDownload 课本下载内容；RedirectURL 非空时为外部链接，否则 Content 为本地文件
+type TextbookDownload struct {
+	Resource    *model.CourseResourceFile
+	Content     *os.File
+	ModTime     time.Time
+	ETag        string
+	RedirectURL string
 }
 
 type courseService struct {
-	courseRepo repository.CourseRepository
+	courseRepo    repository.CourseRepository
+	storageDir    string
+	maxUploadSize int64
+	linkAnalyzer  *analyzer.Analyzer
+	// analyzedCovers 分析时已下载的封面，外部地址 -> 本地路径，同一链接再次分析时不重复下载
+	analyzedCovers sync.Map
 }
 
-func NewCourseService(courseRepo repository.CourseRepository) CourseService {
-	return &courseService{courseRepo: courseRepo}
+func NewCourseService(courseRepo repository.CourseRepository, cfg *config.Config, linkAnalyzer *analyzer.Analyzer) CourseService {
+	return &courseService{
+		courseRepo:    courseRepo,
+		storageDir:    cfg.ResourceStorageDir,
+		maxUploadSize: int64(cfg.MaxResourceSizeMB) * 1024 * 1024,
+		linkAnalyzer:  linkAnalyzer,
+	}
 }
 
-func (s *courseService) GetCourses(ctx context.Context, semester string, category []string, sort string, limit, cursor int, resourceType string) (map[string]interface{}, error) {
+func (s *courseService) GetCourses(ctx context.Context, semester model.SemesterFilter, category []string, sort string, limit, cursor int, resourceType string) (map[string]interface{}, error) {
+	// "2024-Fall" 等写法按规范名称匹配
+	semester.Name = model.NormalizeSemester(semester.Name)
 	courses, err := s.courseRepo.GetCourses(ctx, semester, category, sort, limit, cursor)
 	if err != nil {
 		return nil, err
@@ -55,6 +143,9 @@ func (s *courseService) GetCourse(ctx context.Context, courseID, resourceType st
 	if err != nil {
 		return nil, err
 	}
+	if course == nil {
+		return nil, errors.New("course not found")
+	}
 
 	return map[string]interface{}{
 		"message": "success",
@@ -62,6 +153,96 @@ func (s *courseService) GetCourse(ctx context.Context, courseID, resourceType st
 	}, nil
 }
 
+func (s *courseService) SubmitCourse(ctx context.Context, userID int, req CourseSubmitRequest) (map[string]interface{}, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if req.Credit < 0 {
+		return nil, errors.New("credit must not be negative")
+	}
+
+	// 封面图片统一本地化
+	cover := strings.TrimSpace(req.Cover)
+	if cover != "" {
+		localURL, err := utils.ProcessImageURL(cover)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cover: %v", err)
+		}
+		cover = localURL
+	}
+
+	courseData := map[string]interface{}{
+		"name":       name,
+		"semester":   strings.TrimSpace(req.Semester),
+		"credit":     req.Credit,
+		"cover":      cover,
+		"teachers":   trimNonEmpty(req.Teacher),
+		"categories": trimNonEmpty(req.Category),
+	}
+	// 能识别的学期关联到学期目录并创建开课记录，无法识别的（如"大三上"）按原文保存
+	if year, term, ok := model.ParseSemester(req.Semester); ok {
+		courseData["semester_year"] = year
+		courseData["semester_term"] = term
+	}
+
+	course, err := s.courseRepo.Create(ctx, userID, courseData)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"message": "Course submitted successfully, pending review",
+		"data":    course,
+	}, nil
+}
+
+func (s *courseService) AnalyzeCourse(ctx context.Context, rawURL string) (*model.CourseAnalysis, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("url must be an http or https link")
+	}
+
+	result, err := s.linkAnalyzer.Analyze(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// 封面和提交课程时一样本地化（下载时拒绝内网和本机地址），失败时保留原地址
+	if result.Cover != "" && !strings.HasPrefix(result.Cover, "/uploads/") {
+		result.Cover = s.localizeCover(result.Cover)
+	}
+	return result, nil
+}
+
+// localizeCover 下载分析得到的封面，已下载过且本地文件仍在时直接返回本地路径
+func (s *courseService) localizeCover(cover string) string {
+	if local, ok := s.analyzedCovers.Load(cover); ok && utils.LocalImageExists(local.(string)) {
+		return local.(string)
+	}
+	local, err := utils.ProcessImageURL(cover)
+	if err != nil {
+		log.Printf("failed to process analyzed cover %s: %v", cover, err)
+		return cover
+	}
+	if local != cover {
+		s.analyzedCovers.Store(cover, local)
+	}
+	return local
+}
+
+// trimNonEmpty 去除首尾空白并丢弃空字符串
+func trimNonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
 func (s *courseService) SearchCourses(ctx context.Context, keyword string, category []string, limit, cursor int, resourceType string) (map[string]interface{}, error) {
 	courses, err := s.courseRepo.Search(ctx, keyword, category, limit, cursor)
 	if err != nil {
@@ -75,38 +256,139 @@ func (s *courseService) SearchCourses(ctx context.Context, keyword string, categ
 }
 
 func (s *courseService) UploadResource(ctx context.Context, userID int, courseID, resourceType string, req CourseUploadRequest) (map[string]interface{}, error) {
+	if resourceType != "course" {
+		return nil, fmt.Errorf("unsupported resourceType %q", resourceType)
+	}
+	description := strings.TrimSpace(req.Description)
+	if description == "" {
+		return nil, errors.New("description is required")
+	}
+	link := strings.TrimSpace(req.Resource)
+	if link == "" && req.Upload == nil {
+		return nil, errors.New("file or resource url is required")
+	}
+	if link != "" {
+		if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.New("resource must be an http(s) url")
+		}
+	}
+
 	// 将结构体转换为 map 传递给 repository
 	resourceData := map[string]interface{}{
-		"file":        req.File,
-		"resource":    req.Resource,
-		"description": req.Description,
+		"resource":    link,
+		"description": description,
 		"tags":        req.Tags,
+		"chapter_id":  req.ChapterID,
+	}
+
+	if req.Upload != nil {
+		if req.Upload.Size > s.maxUploadSize {
+			return nil, fmt.Errorf("file size exceeds %d MB", s.maxUploadSize/1024/1024)
+		}
+		// 文件头中的大小由客户端提供，保存时再按实际写入的字节数限制
+		key, size, err := utils.SaveResourceFile(s.storageDir, req.Upload, s.maxUploadSize)
+		if err != nil {
+			return nil, err
+		}
+		resourceData["file_path"] = key
+		resourceData["file_name"] = req.Upload.Filename
+		resourceData["file_size"] = size
+		resourceData["content_type"] = utils.ResourceContentType(req.Upload.Filename)
 	}
 
 	resource, err := s.courseRepo.UploadResource(ctx, userID, courseID, resourceData)
 	if err != nil {
+		// 写库失败时删除已保存的文件
+		if key, ok := resourceData["file_path"].(string); ok {
+			if err := utils.DeleteResourceFile(s.storageDir, key); err != nil {
+				log.Printf("Failed to delete orphan resource file %s: %v", key, err)
+			}
+		}
 		return nil, err
 	}
 
 	return map[string]interface{}{
-		"message": "Resource uploaded successfully",
+		"message": "Resource submitted successfully, pending review",
 		"data":    resource,
 	}, nil
 }
 
-// 其他方法保持不变...
-func (s *courseService) DownloadTextbook(ctx context.Context, courseID, textbookID string) (map[string]interface{}, error) {
-	content, err := s.courseRepo.DownloadTextbook(ctx, courseID, textbookID)
+func (s *courseService) GetResources(ctx context.Context, courseID string) (map[string]interface{}, error) {
+	resources, err := s.courseRepo.GetResources(ctx, courseID)
 	if err != nil {
 		return nil, err
 	}
+	if resources == nil {
+		return nil, errors.New("course not found")
+	}
 
 	return map[string]interface{}{
 		"message": "success",
-		"content": content,
+		"data":    resources,
+	}, nil
+}
+
+// DownloadTextbook 获取审核通过的上传资源；本地文件返回已打开的文件，调用方负责关闭
+func (s *courseService) DownloadTextbook(ctx context.Context, courseID, textbookID string) (*TextbookDownload, error) {
+	resource, err := s.courseRepo.GetUploadResource(ctx, courseID, textbookID)
+	if err != nil {
+		return nil, err
+	}
+	// 未审核、被拒绝或已删除的资源一律按不存在处理
+	if resource == nil || resource.Status != "approved" {
+		return nil, ErrResourceNotFound
+	}
+
+	// 历史导入的资源只有外部链接
+	if resource.StoragePath == "" {
+		if resource.URL == "" {
+			return nil, ErrResourceGone
+		}
+		return &TextbookDownload{Resource: resource, RedirectURL: resource.URL}, nil
+	}
+
+	path, err := utils.ResourceFilePath(s.storageDir, resource.StoragePath)
+	if err != nil {
+		return nil, ErrResourceGone
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrResourceGone
+		}
+		return nil, fmt.Errorf("failed to open resource file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, ErrResourceGone
+	}
+
+	if resource.ContentType == "" {
+		resource.ContentType = utils.ResourceContentType(resource.FileName)
+	}
+	if resource.ContentType == "" {
+		resource.ContentType = "application/octet-stream"
+	}
+	if resource.FileName == "" {
+		resource.FileName = filepath.Base(path)
+	}
+	resource.FileSize = info.Size()
+
+	return &TextbookDownload{
+		Resource: resource,
+		Content:  file,
+		ModTime:  info.ModTime(),
+		ETag:     fmt.Sprintf(`"%d-%x-%x"`, resource.ResourceID, info.Size(), info.ModTime().UnixNano()),
 	}, nil
 }
 
+func (s *courseService) RecordDownload(ctx context.Context, resourceID int) {
+	if err := s.courseRepo.IncrementDownloads(ctx, resourceID); err != nil {
+		log.Printf("Failed to record download for resource %d: %v", resourceID, err)
+	}
+}
+
 func (s *courseService) AddComment(ctx context.Context, userID int, courseID, content string) (map[string]interface{}, error) {
 	comment, err := s.courseRepo.AddComment(ctx, userID, courseID, content)
 	if err != nil {
@@ -216,3 +498,577 @@ func (s *courseService) UnlikeCourse(ctx context.Context, userID int, courseID s
 		"data":    result,
 	}, nil
 }
+
+func (s *courseService) GetChapters(ctx context.Context, courseID string) (map[string]interface{}, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	chapters, err := s.courseRepo.GetChapters(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"message": "success",
+		"data":    model.BuildChapterOutline(chapters),
+	}, nil
+}
+
+func (s *courseService) SubmitChapter(ctx context.Context, userID int, courseID string, req model.SubmitChapterRequest) (*model.CourseChapter, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	// 只支持章、节两级，节必须挂在本课程审核通过的章下
+	if req.ParentID > 0 {
+		parent, err := s.courseRepo.GetChapter(ctx, req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil || parent.CourseID != cid || parent.Status != model.CourseStatusApproved {
+			return nil, ErrChapterNotFound
+		}
+		if parent.ParentID != 0 {
+			return nil, errors.New("sections cannot contain sub-sections")
+		}
+	}
+
+	chapter := &model.CourseChapter{
+		CourseID:    cid,
+		ParentID:    req.ParentID,
+		Title:       title,
+		SortOrder:   req.Order,
+		SubmitterID: userID,
+	}
+	if err := s.courseRepo.CreateChapter(ctx, chapter); err != nil {
+		return nil, err
+	}
+	return chapter, nil
+}
+
+func (s *courseService) GetProgress(ctx context.Context, userID int, courseID string) (*model.CourseProgress, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	chapters, err := s.courseRepo.GetChapters(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	done, err := s.courseRepo.GetChapterProgress(ctx, userID, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按大纲顺序输出，节紧跟在所属章之后
+	progress := &model.CourseProgress{CourseID: cid, Chapters: make([]model.ChapterProgress, 0, len(chapters))}
+	appendChapter := func(ch model.CourseChapter) {
+		item := model.ChapterProgress{ChapterID: ch.ChapterID, ParentID: ch.ParentID, Title: ch.Title}
+		if completedAt, ok := done[ch.ChapterID]; ok {
+			item.Completed = true
+			item.CompletedAt = &completedAt
+			progress.CompletedChapters++
+		}
+		progress.Chapters = append(progress.Chapters, item)
+	}
+	for _, ch := range model.BuildChapterOutline(chapters) {
+		appendChapter(ch)
+		for _, section := range ch.Sections {
+			appendChapter(section)
+		}
+	}
+	progress.TotalChapters = len(progress.Chapters)
+	progress.Percent = model.CompletionPercent(progress.CompletedChapters, progress.TotalChapters)
+	return progress, nil
+}
+
+func (s *courseService) UpdateProgress(ctx context.Context, userID int, courseID, chapterID string, completed bool) (*model.CourseProgress, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	chid, err := strconv.Atoi(chapterID)
+	if err != nil {
+		return nil, ErrChapterNotFound
+	}
+
+	chapter, err := s.courseRepo.GetChapter(ctx, chid)
+	if err != nil {
+		return nil, err
+	}
+	if chapter == nil || chapter.CourseID != cid || chapter.Status != model.CourseStatusApproved {
+		return nil, ErrChapterNotFound
+	}
+
+	if err := s.courseRepo.SetChapterProgress(ctx, userID, chid, completed); err != nil {
+		return nil, err
+	}
+	return s.GetProgress(ctx, userID, courseID)
+}
+
+// approvedCourseID 解析课程ID并确认课程已审核通过
+func (s *courseService) approvedCourseID(ctx context.Context, courseID string) (int, error) {
+	cid, err := strconv.Atoi(courseID)
+	if err != nil {
+		return 0, ErrCourseNotFound
+	}
+	_, status, err := s.courseRepo.GetCourseSubmitter(ctx, courseID)
+	if err != nil {
+		return 0, err
+	}
+	if status != model.CourseStatusApproved {
+		return 0, ErrCourseNotFound
+	}
+	return cid, nil
+}
+
+func (s *courseService) RateCourse(ctx context.Context, userID int, courseID string, req model.RateCourseRequest) (*model.CourseRating, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 评分对应课程的一次开课，同一学期的不同写法（2024秋、Fall 2024）对应同一次开课
+	year, term, ok := model.ParseSemester(req.Semester)
+	if !ok {
+		return nil, errors.New("semester must be a semester such as 2024秋季 or Fall 2024")
+	}
+	offering, err := s.courseRepo.GetCourseOffering(ctx, cid, year, term)
+	if err != nil {
+		return nil, err
+	}
+	if offering == nil {
+		return nil, fmt.Errorf("course is not offered in %s", model.SemesterName(year, term))
+	}
+
+	// 有任课教师时评分必须对应其中一位（开课未记录教师时使用课程的任课教师），
+	// 空白和大小写不同的写法统一为教师的规范写法
+	teacher := strings.TrimSpace(req.Teacher)
+	teachers := make([]string, 0, len(offering.Teachers))
+	for _, t := range offering.Teachers {
+		teachers = append(teachers, t.Name)
+	}
+	if len(teachers) == 0 {
+		if teachers, err = s.courseRepo.GetCourseTeachers(ctx, cid); err != nil {
+			return nil, err
+		}
+	}
+	if len(teachers) > 0 {
+		found := false
+		for _, t := range teachers {
+			if strings.EqualFold(model.TeacherNameKey(t), model.TeacherNameKey(teacher)) {
+				teacher = t
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("teacher must be one of: %s", strings.Join(teachers, ", "))
+		}
+	}
+
+	rating := &model.CourseRating{
+		CourseID:   cid,
+		UserID:     userID,
+		OfferingID: offering.OfferingID,
+		Teacher:    teacher,
+		Semester:   offering.Semester,
+		Difficulty: req.Difficulty,
+		Workload:   req.Workload,
+		Teaching:   req.Teaching,
+		Usefulness: req.Usefulness,
+		Review:     strings.TrimSpace(req.Review),
+	}
+	if err := s.courseRepo.SaveRating(ctx, rating); err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
+
+func (s *courseService) GetRatings(ctx context.Context, courseID string, cursor, limit int) (map[string]interface{}, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, offerings, err := s.courseRepo.GetRatingSummary(ctx, cid)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := s.courseRepo.ListRatings(ctx, cid, 0, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"message":   "success",
+		"summary":   summary,
+		"offerings": offerings,
+		"data":      ratings,
+		"cursor":    cursor + len(ratings),
+	}, nil
+}
+
+func (s *courseService) GetPrerequisites(ctx context.Context, courseID string) (*model.CoursePrerequisites, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.courseRepo.ListCurriculumNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.courseRepo.ListApprovedPrerequisites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	graph := newPrerequisiteGraph(edges)
+
+	result := &model.CoursePrerequisites{
+		CourseID:      cid,
+		Prerequisites: make([]model.CoursePrerequisite, 0),
+		Corequisites:  make([]model.CoursePrerequisite, 0),
+		RequiredBy:    make([]model.CoursePrerequisite, 0),
+		Transitive:    graph.transitive(cid),
+		Cycles:        make([][]int, 0),
+	}
+	for _, node := range nodes {
+		if node.CourseID == cid {
+			result.Name = node.Name
+			break
+		}
+	}
+	for _, edge := range edges {
+		switch {
+		case edge.CourseID == cid && edge.Relation == model.RelationCorequisite:
+			result.Corequisites = append(result.Corequisites, edge)
+		case edge.CourseID == cid:
+			result.Prerequisites = append(result.Prerequisites, edge)
+		case edge.RequiredID == cid:
+			result.RequiredBy = append(result.RequiredBy, edge)
+		}
+	}
+
+	// 只返回从该课程出发能到达的环
+	reachable := graph.reachable(cid)
+	for _, cycle := range graph.cycles() {
+		if reachable[cycle[0]] {
+			result.Cycles = append(result.Cycles, cycle)
+		}
+	}
+	return result, nil
+}
+
+func (s *courseService) SubmitPrerequisite(ctx context.Context, userID int, courseID string, req model.SubmitPrerequisiteRequest) (*model.CoursePrerequisite, error) {
+	cid, err := s.approvedCourseID(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.approvedCourseID(ctx, strconv.Itoa(req.RequiredCourseID)); err != nil {
+		return nil, err
+	}
+	if req.RequiredCourseID == cid {
+		return nil, errors.New("a course cannot require itself")
+	}
+	relation := strings.TrimSpace(req.Relation)
+	if relation == "" {
+		relation = model.RelationPrerequisite
+	}
+	if !model.ValidRelation(relation) {
+		return nil, fmt.Errorf("invalid relation: %s", relation)
+	}
+
+	// 待审核和已生效的关系不能重复提交，已驳回或下线的可以重新提交
+	existing, err := s.courseRepo.GetPrerequisite(ctx, cid, req.RequiredCourseID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && (existing.Status == model.CourseStatusPending || existing.Status == model.CourseStatusApproved) {
+		return nil, ErrPrerequisiteExists
+	}
+
+	p := &model.CoursePrerequisite{
+		CourseID:    cid,
+		RequiredID:  req.RequiredCourseID,
+		Relation:    relation,
+		Note:        strings.TrimSpace(req.Note),
+		SubmitterID: userID,
+	}
+	if err := s.checkPrerequisiteCycle(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := s.courseRepo.CreatePrerequisite(ctx, p); err != nil {
+		return nil, err
+	}
+	return s.courseRepo.GetPrerequisiteByID(ctx, p.RelationID)
+}
+
+// checkPrerequisiteCycle 确认加入关系 p 后已生效的关系中不会出现先修环
+func (s *courseService) checkPrerequisiteCycle(ctx context.Context, p *model.CoursePrerequisite) error {
+	edges, err := s.courseRepo.ListApprovedPrerequisites(ctx)
+	if err != nil {
+		return err
+	}
+	return prerequisiteCycleError(edges, p)
+}
+
+// prerequisiteCycleError 加入关系 p 会与 edges 构成先修环时返回 ErrPrerequisiteCycle，审核通过时也会调用
+func prerequisiteCycleError(edges []model.CoursePrerequisite, p *model.CoursePrerequisite) error {
+	graph := newPrerequisiteGraph(edges)
+	// 从被依赖课程沿已有关系能回到本课程即成环；全部为同修关系的环允许存在（同一学期修读）
+	path := graph.path(p.RequiredID, p.CourseID, p.Relation != model.RelationPrerequisite)
+	if path == nil {
+		return nil
+	}
+	names := make([]string, 0, len(path)+1)
+	names = append(names, graph.name(p.CourseID, p.CourseName))
+	for _, id := range path {
+		name := ""
+		if id == p.RequiredID {
+			name = p.RequiredName
+		}
+		names = append(names, graph.name(id, name))
+	}
+	return fmt.Errorf("%w: %s", ErrPrerequisiteCycle, strings.Join(names, " -> "))
+}
+
+func (s *courseService) GetCurriculumGraph(ctx context.Context) (*model.CurriculumGraph, error) {
+	nodes, err := s.courseRepo.ListCurriculumNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.courseRepo.ListApprovedPrerequisites(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &model.CurriculumGraph{
+		Nodes:  nodes,
+		Edges:  make([]model.CurriculumEdge, 0, len(edges)),
+		Cycles: newPrerequisiteGraph(edges).cycles(),
+	}
+	for _, edge := range edges {
+		graph.Edges = append(graph.Edges, model.CurriculumEdge{From: edge.RequiredID, To: edge.CourseID, Relation: edge.Relation})
+	}
+	return graph, nil
+}
+
+func (s *courseService) CheckPrerequisites(ctx context.Context, req model.PrerequisiteCheckRequest) (*model.PrerequisiteCheckResult, error) {
+	nodes, err := s.courseRepo.ListCurriculumNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(nodes))
+	for _, node := range nodes {
+		names[node.CourseID] = node.Name
+	}
+
+	// 课程所在学期的下标，下标越大越晚
+	position := make(map[int]int)
+	for i, semester := range req.Semesters {
+		for _, id := range semester.CourseIDs {
+			if _, ok := names[id]; !ok {
+				return nil, fmt.Errorf("course %d not found", id)
+			}
+			if _, ok := position[id]; ok {
+				return nil, fmt.Errorf("course %d appears more than once", id)
+			}
+			position[id] = i
+		}
+	}
+
+	edges, err := s.courseRepo.ListApprovedPrerequisites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	graph := newPrerequisiteGraph(edges)
+
+	result := &model.PrerequisiteCheckResult{
+		Issues:   make([]model.PrerequisiteIssue, 0),
+		Warnings: make([]model.PrerequisiteIssue, 0),
+	}
+	for i, semester := range req.Semesters {
+		for _, id := range semester.CourseIDs {
+			for _, edge := range graph.requires[id] {
+				issue := model.PrerequisiteIssue{
+					CourseID:     id,
+					CourseName:   names[id],
+					Semester:     semester.Semester,
+					RequiredID:   edge.RequiredID,
+					RequiredName: edge.RequiredName,
+					Relation:     edge.Relation,
+				}
+				at, planned := position[edge.RequiredID]
+				if planned {
+					issue.RequiredSemester = req.Semesters[at].Semester
+				}
+				switch {
+				case !planned:
+					issue.Problem = model.CheckProblemMissing
+					result.Warnings = append(result.Warnings, issue)
+				case at > i:
+					issue.Problem = model.CheckProblemLater
+					result.Issues = append(result.Issues, issue)
+				case at == i && edge.Relation == model.RelationPrerequisite:
+					issue.Problem = model.CheckProblemSameSemester
+					result.Issues = append(result.Issues, issue)
+				}
+			}
+		}
+	}
+	result.Valid = len(result.Issues) == 0
+	return result, nil
+}
+
+// prerequisiteGraph 由审核通过的修读关系构成的图，边从课程指向它要求的课程
+type prerequisiteGraph struct {
+	requires map[int][]model.CoursePrerequisite
+	names    map[int]string
+}
+
+func newPrerequisiteGraph(edges []model.CoursePrerequisite) *prerequisiteGraph {
+	g := &prerequisiteGraph{
+		requires: make(map[int][]model.CoursePrerequisite),
+		names:    make(map[int]string),
+	}
+	for _, edge := range edges {
+		g.requires[edge.CourseID] = append(g.requires[edge.CourseID], edge)
+		g.names[edge.CourseID] = edge.CourseName
+		g.names[edge.RequiredID] = edge.RequiredName
+	}
+	return g
+}
+
+// name 返回课程名称，图中没有该课程时使用 fallback，仍为空时使用课程ID
+func (g *prerequisiteGraph) name(id int, fallback string) string {
+	if name := g.names[id]; name != "" {
+		return name
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return "#" + strconv.Itoa(id)
+}
+
+// transitive 沿先修关系广度优先遍历，返回全部直接和间接先修课程及最短路径，遇到环不会重复访问
+func (g *prerequisiteGraph) transitive(courseID int) []model.PrerequisiteNode {
+	result := make([]model.PrerequisiteNode, 0)
+	paths := map[int][]int{courseID: {courseID}}
+	queue := []int{courseID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.requires[current] {
+			if edge.Relation != model.RelationPrerequisite {
+				continue
+			}
+			if _, seen := paths[edge.RequiredID]; seen {
+				continue
+			}
+			path := append(append([]int{}, paths[current]...), edge.RequiredID)
+			paths[edge.RequiredID] = path
+			queue = append(queue, edge.RequiredID)
+			result = append(result, model.PrerequisiteNode{
+				CourseID: edge.RequiredID,
+				Name:     edge.RequiredName,
+				Depth:    len(path) - 1,
+				Path:     path,
+			})
+		}
+	}
+	return result
+}
+
+// reachable 返回从课程出发沿任意修读关系能到达的课程（包括自身）
+func (g *prerequisiteGraph) reachable(courseID int) map[int]bool {
+	seen := map[int]bool{courseID: true}
+	stack := []int{courseID}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, edge := range g.requires[current] {
+			if !seen[edge.RequiredID] {
+				seen[edge.RequiredID] = true
+				stack = append(stack, edge.RequiredID)
+			}
+		}
+	}
+	return seen
+}
+
+// path 返回从 from 沿修读关系到 to 的最短路径（包括两端），needStrict 为 true 时路径上至少要有一条先修关系；
+// 不存在时返回 nil
+func (g *prerequisiteGraph) path(from, to int, needStrict bool) []int {
+	type state struct {
+		course int
+		strict bool // 已经过先修关系
+	}
+	start := state{course: from}
+	prev := map[state]state{start: start}
+	queue := []state{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.course == to && (current.strict || !needStrict) {
+			var path []int
+			for s := current; ; s = prev[s] {
+				path = append([]int{s.course}, path...)
+				if s == start {
+					return path
+				}
+			}
+		}
+		for _, edge := range g.requires[current.course] {
+			next := state{course: edge.RequiredID, strict: current.strict || edge.Relation == model.RelationPrerequisite}
+			if _, seen := prev[next]; !seen {
+				prev[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// cycles 检测先修环：每条先修关系若能沿修读关系回到起点即构成环，同一组课程只报告一次；
+// 环按"课程 -> 其要求的课程"的顺序排列，首尾相同
+func (g *prerequisiteGraph) cycles() [][]int {
+	result := make([][]int, 0)
+	reported := make(map[string]bool)
+
+	courseIDs := make([]int, 0, len(g.requires))
+	for id := range g.requires {
+		courseIDs = append(courseIDs, id)
+	}
+	sort.Ints(courseIDs)
+
+	for _, id := range courseIDs {
+		for _, edge := range g.requires[id] {
+			if edge.Relation != model.RelationPrerequisite {
+				continue
+			}
+			back := g.path(edge.RequiredID, id, false)
+			if back == nil {
+				continue
+			}
+			cycle := append([]int{id}, back...)
+
+			members := append([]int{}, cycle[:len(cycle)-1]...)
+			sort.Ints(members)
+			key := fmt.Sprint(members)
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			result = append(result, cycle)
+		}
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/utils"
+	"sync/atomic"
+	"testing"
+)
+
+// prerequisiteEdges 按 "课程 关系 要求的课程" 构造修读关系，p 为先修，c 为同修
+func prerequisiteEdges(specs ...string) []model.CoursePrerequisite {
+	result := make([]model.CoursePrerequisite, 0, len(specs))
+	for _, spec := range specs {
+		var (
+			from, to int
+			kind     string
+		)
+		if _, err := fmt.Sscanf(spec, "%d %s %d", &from, &kind, &to); err != nil {
+			panic(spec)
+		}
+		relation := model.RelationPrerequisite
+		if kind == "c" {
+			relation = model.RelationCorequisite
+		}
+		result = append(result, model.CoursePrerequisite{CourseID: from, RequiredID: to, Relation: relation})
+	}
+	return result
+}
+
+func TestPrerequisiteGraphCycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []model.CoursePrerequisite
+		want  string
+	}{
+		{"none", prerequisiteEdges("1 p 2", "2 p 3", "1 p 3"), "[]"},
+		{"two courses", prerequisiteEdges("1 p 2", "2 p 1"), "[[1 2 1]]"},
+		{"three courses reported once", prerequisiteEdges("1 p 2", "2 p 3", "3 p 1"), "[[1 2 3 1]]"},
+		{"corequisites only", prerequisiteEdges("1 c 2", "2 c 1"), "[]"},
+		{"closed by a corequisite", prerequisiteEdges("1 p 2", "2 c 1"), "[[1 2 1]]"},
+		{"separate cycles", prerequisiteEdges("1 p 2", "2 p 1", "3 p 4", "4 p 3", "5 p 1"), "[[1 2 1] [3 4 3]]"},
+		{"shortest cycle per course set", prerequisiteEdges("1 p 2", "2 p 3", "2 p 1", "3 p 1"), "[[1 2 1] [2 3 1 2]]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(newPrerequisiteGraph(tt.edges).cycles()); got != tt.want {
+				t.Errorf("cycles() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrerequisiteGraphPath(t *testing.T) {
+	g := newPrerequisiteGraph(prerequisiteEdges("1 c 2", "2 c 3", "2 p 4", "4 c 3", "5 p 1"))
+	tests := []struct {
+		from, to   int
+		needStrict bool
+		want       string
+	}{
+		{1, 3, false, "[1 2 3]"},
+		{1, 3, true, "[1 2 4 3]"},
+		{1, 2, true, "[]"},
+		{5, 3, true, "[5 1 2 3]"},
+		{3, 1, false, "[]"},
+		{1, 1, false, "[1]"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d to %d strict %v", tt.from, tt.to, tt.needStrict), func(t *testing.T) {
+			if got := fmt.Sprint(g.path(tt.from, tt.to, tt.needStrict)); got != tt.want {
+				t.Errorf("path() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrerequisiteGraphTransitive(t *testing.T) {
+	g := newPrerequisiteGraph(prerequisiteEdges("1 p 2", "1 c 5", "2 p 3", "3 p 1", "2 p 4", "4 p 3"))
+	got := g.transitive(1)
+	want := []struct {
+		id, depth int
+		path      string
+	}{
+		{2, 1, "[1 2]"},
+		{3, 2, "[1 2 3]"},
+		{4, 2, "[1 2 4]"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("transitive(1) = %+v, want %d courses", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].CourseID != w.id || got[i].Depth != w.depth || fmt.Sprint(got[i].Path) != w.path {
+			t.Errorf("node %d = %+v, want course %d depth %d path %s", i, got[i], w.id, w.depth, w.path)
+		}
+	}
+}
+
+func TestLocalizeCoverReusesDownload(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+	cover := server.URL + "/cover.png"
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(dir) }()
+
+	local := "/" + utils.UploadDir + "/cover.png"
+	if err := os.MkdirAll(utils.UploadDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.FromSlash(local[1:]), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &courseService{}
+	s.analyzedCovers.Store(cover, local)
+	if got := s.localizeCover(cover); got != local {
+		t.Errorf("localizeCover = %q, want cached %q", got, local)
+	}
+
+	// 本地文件被删除后重新下载；测试服务器在本机，下载被拒绝时保留原地址
+	if err := os.Remove(filepath.FromSlash(local[1:])); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.localizeCover(cover); got != cover {
+		t.Errorf("localizeCover = %q, want original %q", got, cover)
+	}
+	if hits != 0 {
+		t.Errorf("cover server hit %d times", hits)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"strings"
+	"time"
+)
+
+const invitationCodeLength = 10
+
+var ErrInvalidInvitation = errors.New("invalid invitation code")
+
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, adminID int, req model.CreateInvitationRequest) (*model.Invitation, error)
+	ListInvitations(ctx context.Context, cursor, limit int) (map[string]interface{}, error)
+	GetInvitation(ctx context.Context, id int) (*model.Invitation, error)
+	RevokeInvitation(ctx context.Context, id int) error
+}
+
+type invitationService struct {
+	invitationRepo repository.InvitationRepository
+}
+
+func NewInvitationService(invitationRepo repository.InvitationRepository) InvitationService {
+	return &invitationService{invitationRepo: invitationRepo}
+}
+
+func (s *invitationService) CreateInvitation(ctx context.Context, adminID int, req model.CreateInvitationRequest) (*model.Invitation, error) {
+	role := strings.TrimSpace(req.Role)
+	if role == "" {
+		role = model.RoleUser
+	}
+	if !model.ValidRole(role) {
+		return nil, fmt.Errorf("invalid role: %s", role)
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		generated, err := utils.GenerateRandomString(invitationCodeLength, utils.CodeCharset)
+		if err != nil {
+			return nil, err
+		}
+		code = generated
+	} else {
+		existing, err := s.invitationRepo.GetByCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, errors.New("invitation code already exists")
+		}
+	}
+
+	maxUses := req.MaxUses
+	if maxUses <= 0 {
+		maxUses = 1
+	}
+
+	inv := &model.Invitation{
+		Code:      code,
+		MaxUses:   maxUses,
+		Role:      role,
+		Cohort:    strings.TrimSpace(req.Cohort),
+		Note:      strings.TrimSpace(req.Note),
+		CreatedBy: adminID,
+	}
+	if req.ExpiresInHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour)
+		inv.ExpiresAt = &expiresAt
+	}
+
+	if err := s.invitationRepo.Create(ctx, inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context, cursor, limit int) (map[string]interface{}, error) {
+	invitations, err := s.invitationRepo.List(ctx, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := 0
+	if len(invitations) > 0 {
+		nextCursor = invitations[len(invitations)-1].ID
+	}
+
+	return map[string]interface{}{
+		"message": "success",
+		"data":    invitations,
+		"cursor":  nextCursor,
+	}, nil
+}
+
+func (s *invitationService) GetInvitation(ctx context.Context, id int) (*model.Invitation, error) {
+	inv, err := s.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, errors.New("invitation not found")
+	}
+
+	redemptions, err := s.invitationRepo.GetRedemptions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	inv.Redemptions = redemptions
+	return inv, nil
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, id int) error {
+	return s.invitationRepo.Revoke(ctx, id)
+}
+
+// checkInvitationUsable 注册前预检查邀请码状态，真正占用在事务内完成
+func checkInvitationUsable(inv *model.Invitation) error {
+	if inv == nil || inv.RevokedAt != nil {
+		return ErrInvalidInvitation
+	}
+	if inv.ExpiresAt != nil && time.Now().After(*inv.ExpiresAt) {
+		return errors.New("invitation code expired")
+	}
+	if inv.UsedCount >= inv.MaxUses {
+		return errors.New("invitation code has been used up")
+	}
+	return nil
+}
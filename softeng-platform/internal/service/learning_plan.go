@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"strings"
+)
+
+var (
+	ErrPlanCourseNotFound = errors.New("course not found")
+	ErrPlanItemNotFound   = errors.New("course is not in the learning plan")
+)
+
+type LearningPlanService interface {
+	// GetPlan 获取学习计划，按学期汇总学分并给出超出上限的提醒
+	GetPlan(ctx context.Context, userID int) (*model.LearningPlan, error)
+	// AddCourse 将课程加入学习计划，课程已在计划中时更新学期和状态
+	AddCourse(ctx context.Context, userID, courseID int, req model.LearningPlanRequest) (*model.LearningPlan, error)
+	RemoveCourse(ctx context.Context, userID, courseID int) (*model.LearningPlan, error)
+	// Reorder 重排学期内课程的顺序，courseIDs 必须恰好包含该学期的全部课程
+	Reorder(ctx context.Context, userID int, req model.ReorderLearningPlanRequest) (*model.LearningPlan, error)
+}
+
+type learningPlanService struct {
+	learningPlanRepo repository.LearningPlanRepository
+	creditLimit      int
+}
+
+func NewLearningPlanService(learningPlanRepo repository.LearningPlanRepository, cfg *config.Config) LearningPlanService {
+	return &learningPlanService{
+		learningPlanRepo: learningPlanRepo,
+		creditLimit:      cfg.LearningPlanCreditLimit,
+	}
+}
+
+func (s *learningPlanService) GetPlan(ctx context.Context, userID int) (*model.LearningPlan, error) {
+	items, err := s.learningPlanRepo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &model.LearningPlan{
+		Semesters:   make([]model.LearningPlanSemester, 0),
+		CreditLimit: s.creditLimit,
+		Warnings:    make([]string, 0),
+	}
+	// 查询结果已按学期排序，相同学期的课程是连续的
+	for _, item := range items {
+		n := len(plan.Semesters)
+		if n == 0 || plan.Semesters[n-1].Semester != item.Semester {
+			plan.Semesters = append(plan.Semesters, model.LearningPlanSemester{Semester: item.Semester})
+			n++
+		}
+		semester := &plan.Semesters[n-1]
+		semester.Courses = append(semester.Courses, item)
+		semester.Credits += item.Credit
+
+		plan.TotalCredits += item.Credit
+		if item.Status == model.PlanStatusCompleted {
+			plan.CompletedCredits += item.Credit
+		}
+	}
+
+	if s.creditLimit > 0 {
+		for i := range plan.Semesters {
+			semester := &plan.Semesters[i]
+			if semester.Credits > s.creditLimit {
+				semester.OverLimit = true
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s 计划 %d 学分，超过每学期上限 %d 学分", semester.Semester, semester.Credits, s.creditLimit))
+			}
+		}
+	}
+	return plan, nil
+}
+
+func (s *learningPlanService) AddCourse(ctx context.Context, userID, courseID int, req model.LearningPlanRequest) (*model.LearningPlan, error) {
+	// 同一学期的不同写法（2024秋、Fall 2024）保存为同一个学期名称
+	semester := model.NormalizeSemester(req.Semester)
+	if semester == "" {
+		return nil, errors.New("semester is required")
+	}
+	status := strings.TrimSpace(req.Status)
+	if status != "" && !model.ValidPlanStatus(status) {
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+
+	approved, err := s.learningPlanRepo.IsCourseApproved(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return nil, ErrPlanCourseNotFound
+	}
+
+	existing, err := s.learningPlanRepo.Get(ctx, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &model.LearningPlanItem{UserID: userID, CourseID: courseID, Semester: semester, Status: status}
+	if existing != nil && existing.Semester == semester {
+		item.SortOrder = existing.SortOrder
+	} else {
+		// 新加入或换到其他学期的课程排在该学期末尾
+		if item.SortOrder, err = s.learningPlanRepo.NextSortOrder(ctx, userID, semester); err != nil {
+			return nil, err
+		}
+	}
+	if item.Status == "" {
+		item.Status = model.PlanStatusPlanned
+		if existing != nil {
+			item.Status = existing.Status
+		}
+	}
+
+	if err := s.learningPlanRepo.Save(ctx, item); err != nil {
+		return nil, err
+	}
+	return s.GetPlan(ctx, userID)
+}
+
+func (s *learningPlanService) RemoveCourse(ctx context.Context, userID, courseID int) (*model.LearningPlan, error) {
+	removed, err := s.learningPlanRepo.Delete(ctx, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, ErrPlanItemNotFound
+	}
+	return s.GetPlan(ctx, userID)
+}
+
+func (s *learningPlanService) Reorder(ctx context.Context, userID int, req model.ReorderLearningPlanRequest) (*model.LearningPlan, error) {
+	semester := model.NormalizeSemester(req.Semester)
+
+	items, err := s.learningPlanRepo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	inSemester := make(map[int]bool)
+	for _, item := range items {
+		if item.Semester == semester {
+			inSemester[item.CourseID] = true
+		}
+	}
+
+	// 必须恰好给出该学期的全部课程，避免出现重复或遗漏的顺序号
+	if len(req.CourseIDs) != len(inSemester) {
+		return nil, fmt.Errorf("course_ids must list all %d courses planned for %s", len(inSemester), semester)
+	}
+	seen := make(map[int]bool, len(req.CourseIDs))
+	for _, courseID := range req.CourseIDs {
+		if !inSemester[courseID] || seen[courseID] {
+			return nil, fmt.Errorf("course %d is not planned for %s or is listed twice", courseID, semester)
+		}
+		seen[courseID] = true
+	}
+
+	if err := s.learningPlanRepo.Reorder(ctx, userID, semester, req.CourseIDs); err != nil {
+		return nil, err
+	}
+	return s.GetPlan(ctx, userID)
+}
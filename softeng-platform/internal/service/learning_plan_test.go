@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"testing"
+)
+
+// fakeLearningPlans 内存中的学习计划，所有课程都已审核通过
+type fakeLearningPlans struct {
+	repository.LearningPlanRepository
+	items []model.LearningPlanItem
+}
+
+func (r *fakeLearningPlans) IsCourseApproved(ctx context.Context, courseID int) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeLearningPlans) Get(ctx context.Context, userID, courseID int) (*model.LearningPlanItem, error) {
+	for i := range r.items {
+		if r.items[i].CourseID == courseID {
+			return &r.items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeLearningPlans) List(ctx context.Context, userID int) ([]model.LearningPlanItem, error) {
+	return r.items, nil
+}
+
+func (r *fakeLearningPlans) Save(ctx context.Context, item *model.LearningPlanItem) error {
+	if existing, _ := r.Get(ctx, item.UserID, item.CourseID); existing != nil {
+		*existing = *item
+		return nil
+	}
+	r.items = append(r.items, *item)
+	return nil
+}
+
+func (r *fakeLearningPlans) NextSortOrder(ctx context.Context, userID int, semester string) (int, error) {
+	max := 0
+	for _, item := range r.items {
+		if item.Semester == semester && item.SortOrder > max {
+			max = item.SortOrder
+		}
+	}
+	return max + 1, nil
+}
+
+func (r *fakeLearningPlans) Reorder(ctx context.Context, userID int, semester string, courseIDs []int) error {
+	for i, courseID := range courseIDs {
+		for j := range r.items {
+			if r.items[j].CourseID == courseID && r.items[j].Semester == semester {
+				r.items[j].SortOrder = i + 1
+			}
+		}
+	}
+	return nil
+}
+
+func TestLearningPlanNormalizesSemester(t *testing.T) {
+	tests := []struct {
+		name      string
+		semesters []string // 依次加入课程 1、2、3 时填写的学期
+		reorder   string   // 重排课程 3、2、1 时填写的学期
+	}{
+		{"same spelling", []string{"2024秋季", "2024秋季", "2024秋季"}, "2024秋季"},
+		{"different spellings", []string{"Fall 2024", "2024秋", " 2024-2025学年第一学期 "}, "2024-Fall"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLearningPlans{}
+			svc := NewLearningPlanService(repo, &config.Config{})
+			for i, semester := range tt.semesters {
+				if _, err := svc.AddCourse(context.Background(), 1, i+1, model.LearningPlanRequest{Semester: semester}); err != nil {
+					t.Fatalf("AddCourse(%q): %v", semester, err)
+				}
+			}
+			plan, err := svc.Reorder(context.Background(), 1, model.ReorderLearningPlanRequest{Semester: tt.reorder, CourseIDs: []int{3, 2, 1}})
+			if err != nil {
+				t.Fatalf("Reorder(%q): %v", tt.reorder, err)
+			}
+
+			if len(plan.Semesters) != 1 || plan.Semesters[0].Semester != "2024秋季" {
+				t.Fatalf("semesters = %+v, want one 2024秋季", plan.Semesters)
+			}
+			for _, item := range repo.items {
+				if item.SortOrder != 4-item.CourseID {
+					t.Errorf("course %d order = %d, want %d", item.CourseID, item.SortOrder, 4-item.CourseID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var (
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+	ErrMFARequired       = errors.New("two-factor authentication is required for this role")
+)
+
+type MFAService interface {
+	GetStatus(ctx context.Context, userID int) (*model.MFAStatus, error)
+	// Setup 生成新的 TOTP 密钥，确认验证码后才真正启用
+	Setup(ctx context.Context, userID int) (*model.MFASetup, error)
+	// Enable 校验验证器生成的验证码并启用，返回只展示一次的恢复码
+	Enable(ctx context.Context, userID int, code string) ([]string, error)
+	Disable(ctx context.Context, userID int, req model.MFADisableRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error)
+	IsEnabled(ctx context.Context, userID int) (bool, error)
+	// RequiredFor 角色是否被要求启用两步验证
+	RequiredFor(role string) bool
+	// Verify 登录第二步校验验证码或恢复码（二选一）
+	Verify(ctx context.Context, userID int, code, recoveryCode string) error
+	// VerifyAttempt 已登录用户执行敏感操作时校验验证码或恢复码，与登录共用失败计数、退避和锁定
+	VerifyAttempt(ctx context.Context, userID int, code, recoveryCode string) error
+	// IssuePendingToken 密码验证通过后签发两步验证待完成令牌，返回令牌及有效秒数
+	IssuePendingToken(userID int) (string, int64, error)
+}
+
+type mfaService struct {
+	mfaRepo         repository.MFARepository
+	userRepo        repository.UserRepository
+	loginGuard      *loginguard.Guard
+	auditService    AuditService
+	issuer          string
+	pendingTTL      time.Duration
+	requireAdminMFA bool
+}
+
+func NewMFAService(mfaRepo repository.MFARepository, userRepo repository.UserRepository, loginGuard *loginguard.Guard, auditService AuditService, cfg *config.Config) MFAService {
+	return &mfaService{
+		mfaRepo:         mfaRepo,
+		userRepo:        userRepo,
+		loginGuard:      loginGuard,
+		auditService:    auditService,
+		issuer:          cfg.MFAIssuer,
+		pendingTTL:      cfg.MFAPendingTTL,
+		requireAdminMFA: cfg.RequireAdminMFA,
+	}
+}
+
+func (s *mfaService) GetStatus(ctx context.Context, userID int) (*model.MFAStatus, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	status := &model.MFAStatus{Required: s.RequiredFor(user.Role)}
+
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil || mfa.EnabledAt == nil {
+		return status, nil
+	}
+
+	remaining, err := s.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = true
+	status.EnabledAt = mfa.EnabledAt
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+func (s *mfaService) Setup(ctx context.Context, userID int) (*model.MFASetup, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	existing, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SaveSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.MFASetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+func (s *mfaService) Enable(ctx context.Context, userID int, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, errors.New("please set up two-factor authentication first")
+	}
+	if mfa.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	counter, ok := utils.ValidateTOTP(mfa.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(ctx, userID, counter, hashes); err != nil {
+		return nil, err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Type: model.AuthEventMFAEnabled})
+	return codes, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID int, req model.MFADisableRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if s.RequiredFor(user.Role) {
+		return ErrMFARequired
+	}
+
+	// 密码错误同样计入失败次数，避免借此接口猜测密码
+	keys := guardKeys(ctx, userID)
+	if err := s.loginGuard.Check(ctx, keys...); err != nil {
+		return err
+	}
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		if err := s.loginGuard.Fail(ctx, keys...); err != nil {
+			return err
+		}
+		return errors.New("invalid password")
+	}
+
+	if err := s.VerifyAttempt(ctx, userID, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Identifier: user.Username, Type: model.AuthEventMFADisabled})
+	return nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	// 重新生成需要验证器的验证码，恢复码本身不能用于生成新的恢复码
+	if err := s.VerifyAttempt(ctx, userID, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *mfaService) IsEnabled(ctx context.Context, userID int) (bool, error) {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa != nil && mfa.EnabledAt != nil, nil
+}
+
+func (s *mfaService) RequiredFor(role string) bool {
+	return s.requireAdminMFA && role == model.RoleAdmin
+}
+
+func (s *mfaService) Verify(ctx context.Context, userID int, code, recoveryCode string) error {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if mfa == nil || mfa.EnabledAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	if recoveryCode = normalizeRecoveryCode(recoveryCode); recoveryCode != "" {
+		ok, err := s.mfaRepo.UseRecoveryCode(ctx, userID, utils.HashToken(recoveryCode))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	counter, ok := utils.ValidateTOTP(mfa.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	// 同一时间步的验证码只能使用一次
+	used, err := s.mfaRepo.UseCounter(ctx, userID, counter)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *mfaService) VerifyAttempt(ctx context.Context, userID int, code, recoveryCode string) error {
+	keys := guardKeys(ctx, userID)
+	if err := s.loginGuard.Check(ctx, keys...); err != nil {
+		return err
+	}
+
+	if err := s.Verify(ctx, userID, code, recoveryCode); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Type: model.AuthEventMFAFailed})
+			if err := s.loginGuard.Fail(ctx, keys...); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+	return s.loginGuard.Succeed(ctx, keys[0])
+}
+
+// guardKeys 失败计数的键：账号，以及请求 context 中的客户端IP（如有）
+func guardKeys(ctx context.Context, userID int) []string {
+	keys := []string{loginguard.UserKey(userID)}
+	if ip := model.ClientInfoFromContext(ctx).IP; ip != "" {
+		keys = append(keys, loginguard.IPKey(ip))
+	}
+	return keys
+}
+
+func (s *mfaService) IssuePendingToken(userID int) (string, int64, error) {
+	token, err := utils.GenerateMFAPendingToken(userID, s.pendingTTL)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, int64(s.pendingTTL.Seconds()), nil
+}
+
+// generateRecoveryCodes 生成一组恢复码，返回展示给用户的明文（XXXXX-XXXXX）及其哈希
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRandomString(recoveryCodeLength, utils.CodeCharset)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode 忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
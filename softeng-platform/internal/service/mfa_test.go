@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"testing"
+	"time"
+)
+
+const (
+	testPassword     = "correct-password"
+	testRecoveryCode = "ABCDE-FGHIJ"
+)
+
+// fakeMFARepo 已启用两步验证的用户，只有一个恢复码
+type fakeMFARepo struct {
+	repository.MFARepository
+	mfa      *model.UserMFA
+	recovery map[string]bool
+}
+
+func newFakeMFARepo(t *testing.T) *fakeMFARepo {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	return &fakeMFARepo{
+		mfa:      &model.UserMFA{UserID: 1, Secret: secret, EnabledAt: &now},
+		recovery: map[string]bool{utils.HashToken(normalizeRecoveryCode(testRecoveryCode)): true},
+	}
+}
+
+func (r *fakeMFARepo) GetByUserID(ctx context.Context, userID int) (*model.UserMFA, error) {
+	return r.mfa, nil
+}
+
+func (r *fakeMFARepo) UseCounter(ctx context.Context, userID int, counter int64) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeMFARepo) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	ok := r.recovery[codeHash]
+	delete(r.recovery, codeHash)
+	return ok, nil
+}
+
+func (r *fakeMFARepo) ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	return nil
+}
+
+func (r *fakeMFARepo) Delete(ctx context.Context, userID int) error {
+	r.mfa = nil
+	return nil
+}
+
+type fakeMFAUsers struct {
+	repository.UserRepository
+	user *model.User
+}
+
+func (r *fakeMFAUsers) GetByID(ctx context.Context, id int) (*model.User, error) {
+	return r.user, nil
+}
+
+type countingAudit struct {
+	AuditService
+	events []string
+}
+
+func (a *countingAudit) Record(ctx context.Context, event model.AuthEvent) {
+	a.events = append(a.events, event.Type)
+}
+
+// mfaStep 一次关闭两步验证（password 非空）或重新生成恢复码的请求
+type mfaStep struct {
+	password     string
+	code         string
+	recoveryCode string
+	want         error // nil、ErrInvalidMFACode、errBlocked 或 errInvalidPassword
+}
+
+var (
+	errBlocked         = errors.New("blocked")
+	errInvalidPassword = errors.New("invalid password")
+)
+
+func TestMFASensitiveActionsUseLoginGuard(t *testing.T) {
+	hashed, err := utils.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		LoginFreeAttempts:  2,
+		LoginBackoffBase:   time.Minute,
+		LoginBackoffMax:    time.Hour,
+		LoginLockThreshold: 5,
+		LoginLockDuration:  time.Hour,
+	}
+
+	tests := []struct {
+		name       string
+		steps      []mfaStep
+		wantEvents int // 记录的 mfa_failed 事件数
+	}{
+		{
+			name: "wrong codes block regeneration and disabling",
+			steps: []mfaStep{
+				{code: "abcdef", want: ErrInvalidMFACode},
+				{code: "abcdef", want: ErrInvalidMFACode},
+				{code: "abcdef", want: errBlocked},
+				{password: testPassword, recoveryCode: testRecoveryCode, want: errBlocked},
+			},
+			wantEvents: 3,
+		},
+		{
+			name: "wrong passwords count as failures",
+			steps: []mfaStep{
+				{password: "wrong", recoveryCode: testRecoveryCode, want: errInvalidPassword},
+				{password: "wrong", recoveryCode: testRecoveryCode, want: errInvalidPassword},
+				{password: "wrong", recoveryCode: testRecoveryCode, want: errBlocked},
+			},
+		},
+		{
+			name: "success resets the account failures",
+			steps: []mfaStep{
+				{code: "abcdef", want: ErrInvalidMFACode},
+				{password: "wrong", recoveryCode: testRecoveryCode, want: errInvalidPassword},
+				{password: testPassword, recoveryCode: testRecoveryCode, want: nil},
+				{code: "abcdef", want: ErrMFANotEnabled},
+			},
+			wantEvents: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &countingAudit{}
+			guard := loginguard.NewGuard(loginguard.NewMemoryTracker(), cfg)
+			svc := NewMFAService(newFakeMFARepo(t), &fakeMFAUsers{user: &model.User{ID: 1, Username: "alice", Password: hashed, Role: model.RoleUser}}, guard, audit, cfg)
+			ctx := model.ContextWithClientInfo(context.Background(), model.ClientInfo{IP: "192.0.2.1"})
+
+			for i, step := range tt.steps {
+				var err error
+				if step.password != "" {
+					err = svc.Disable(ctx, 1, model.MFADisableRequest{Password: step.password, Code: step.code, RecoveryCode: step.recoveryCode})
+				} else {
+					_, err = svc.RegenerateRecoveryCodes(ctx, 1, step.code)
+				}
+
+				var blocked *loginguard.BlockedError
+				switch {
+				case step.want == errBlocked:
+					if !errors.As(err, &blocked) {
+						t.Fatalf("step %d: error = %v, want blocked", i+1, err)
+					}
+				case step.want == errInvalidPassword:
+					if err == nil || err.Error() != errInvalidPassword.Error() {
+						t.Fatalf("step %d: error = %v, want invalid password", i+1, err)
+					}
+				case !errors.Is(err, step.want):
+					t.Fatalf("step %d: error = %v, want %v", i+1, err, step.want)
+				}
+			}
+
+			failed := 0
+			for _, event := range audit.events {
+				if event == model.AuthEventMFAFailed {
+					failed++
+				}
+			}
+			if failed != tt.wantEvents {
+				t.Errorf("mfa_failed events = %d, want %d (%v)", failed, tt.wantEvents, audit.events)
+			}
+		})
+	}
+}
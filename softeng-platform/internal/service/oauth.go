@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/oauth"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOAuthProviderNotFound = errors.New("oauth provider not found")
+	ErrOAuthInvalidState     = errors.New("invalid or expired oauth state")
+	ErrOAuthEmailExists      = errors.New("an account with this email already exists, please log in and link this provider from your profile")
+	ErrOAuthIdentityTaken    = errors.New("this account is already linked to another user")
+	ErrOAuthLastLoginMethod  = errors.New("cannot unlink the only sign-in method, please set a password first")
+)
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+type OAuthService interface {
+	Providers() []string
+	// StartLogin 生成授权地址和 state；首次登录需要自动创建账号时必须提供邀请码
+	StartLogin(ctx context.Context, provider, invitationCode string) (*model.OAuthStart, error)
+	// StartLink 已登录用户绑定第三方账号，返回授权地址和 state
+	StartLink(ctx context.Context, userID int, provider string) (*model.OAuthStart, error)
+	// HandleCallback 处理提供方回调：browserState 为发起授权的浏览器保存的 state，必须与回调的 state 一致；
+	// 按 state 区分登录和绑定，登录与密码登录共用失败计数和锁定
+	HandleCallback(ctx context.Context, provider, code, state, browserState string, client model.ClientInfo) (*model.OAuthCallbackResult, error)
+	ListIdentities(ctx context.Context, userID int) ([]model.Identity, error)
+	Unlink(ctx context.Context, userID int, provider string) error
+	Cleanup(ctx context.Context) error
+}
+
+type oauthService struct {
+	registry       *oauth.Registry
+	identityRepo   repository.IdentityRepository
+	userRepo       repository.UserRepository
+	invitationRepo repository.InvitationRepository
+	tokenService   TokenService
+	mfaService     MFAService
+	loginGuard     *loginguard.Guard
+	auditService   AuditService
+	redirectBase   string
+	stateTTL       time.Duration
+}
+
+func NewOAuthService(registry *oauth.Registry, identityRepo repository.IdentityRepository, userRepo repository.UserRepository, invitationRepo repository.InvitationRepository, tokenService TokenService, mfaService MFAService, loginGuard *loginguard.Guard, auditService AuditService, cfg *config.Config) OAuthService {
+	return &oauthService{
+		registry:       registry,
+		identityRepo:   identityRepo,
+		userRepo:       userRepo,
+		invitationRepo: invitationRepo,
+		tokenService:   tokenService,
+		mfaService:     mfaService,
+		loginGuard:     loginGuard,
+		auditService:   auditService,
+		redirectBase:   strings.TrimRight(cfg.OAuthRedirectBaseURL, "/"),
+		stateTTL:       cfg.OAuthStateTTL,
+	}
+}
+
+func (s *oauthService) Providers() []string {
+	return s.registry.Names()
+}
+
+func (s *oauthService) redirectURL(provider string) string {
+	return s.redirectBase + "/auth/oauth/" + provider + "/callback"
+}
+
+func (s *oauthService) StartLogin(ctx context.Context, provider, invitationCode string) (*model.OAuthStart, error) {
+	invitationCode = strings.TrimSpace(invitationCode)
+	if invitationCode != "" {
+		// 提前检查邀请码，避免用户在提供方授权后才发现邀请码无效
+		invitation, err := s.invitationRepo.GetByCode(ctx, invitationCode)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkInvitationUsable(invitation); err != nil {
+			return nil, err
+		}
+	}
+	return s.start(ctx, provider, 0, invitationCode)
+}
+
+func (s *oauthService) StartLink(ctx context.Context, userID int, provider string) (*model.OAuthStart, error) {
+	return s.start(ctx, provider, userID, "")
+}
+
+func (s *oauthService) start(ctx context.Context, providerName string, userID int, invitationCode string) (*model.OAuthStart, error) {
+	provider, ok := s.registry.Get(providerName)
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, challenge, err := oauth.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.identityRepo.CreateState(ctx, &model.OAuthState{
+		StateHash:      utils.HashToken(state),
+		Provider:       providerName,
+		CodeVerifier:   verifier,
+		UserID:         userID,
+		InvitationCode: invitationCode,
+		ExpiresAt:      time.Now().Add(s.stateTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, challenge, s.redirectURL(providerName))
+	if err != nil {
+		return nil, err
+	}
+	return &model.OAuthStart{AuthURL: authURL, State: state, ExpiresIn: int(s.stateTTL.Seconds())}, nil
+}
+
+func (s *oauthService) HandleCallback(ctx context.Context, providerName, code, state, browserState string, client model.ClientInfo) (*model.OAuthCallbackResult, error) {
+	provider, ok := s.registry.Get(providerName)
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	// state 必须来自发起授权的同一浏览器，防止攻击者把自己发起的授权回调到受害者的浏览器（登录 CSRF）；
+	// 不一致时计入 IP 的失败次数
+	ipKey := loginguard.IPKey(client.IP)
+	event := model.AuthEvent{IP: client.IP, UserAgent: client.UserAgent, Detail: "oauth " + providerName}
+	if err := s.loginGuard.Check(ctx, ipKey); err != nil {
+		event.Type = model.AuthEventLoginBlocked
+		s.auditService.Record(ctx, event)
+		return nil, err
+	}
+	if browserState == "" || !utils.CompareHash(state, browserState) {
+		event.Type = model.AuthEventLoginFailed
+		event.Detail += ": state mismatch"
+		s.auditService.Record(ctx, event)
+		if err := s.loginGuard.Fail(ctx, ipKey); err != nil {
+			return nil, err
+		}
+		return nil, ErrOAuthInvalidState
+	}
+
+	saved, err := s.identityRepo.ConsumeState(ctx, utils.HashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil || saved.Provider != providerName || time.Now().After(saved.ExpiresAt) {
+		return nil, ErrOAuthInvalidState
+	}
+
+	accessToken, err := provider.Exchange(ctx, code, saved.CodeVerifier, s.redirectURL(providerName))
+	if err != nil {
+		return nil, err
+	}
+	info, err := provider.UserInfo(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.identityRepo.GetByProviderSubject(ctx, providerName, info.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if saved.UserID != 0 {
+		linked, err := s.link(ctx, saved.UserID, providerName, info, identity)
+		if err != nil {
+			return nil, err
+		}
+		if identity == nil {
+			s.auditService.Record(ctx, model.AuthEvent{UserID: saved.UserID, Type: model.AuthEventIdentityLinked, IP: client.IP, UserAgent: client.UserAgent, Detail: providerName})
+		}
+		return &model.OAuthCallbackResult{Identity: linked}, nil
+	}
+
+	var user *model.User
+	if identity != nil {
+		user, err = s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, errors.New("user not found")
+		}
+	} else {
+		user, err = s.provision(ctx, providerName, info, saved.InvitationCode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	login, err := s.login(ctx, user, event, client)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		if err := s.identityRepo.TouchLogin(ctx, identity.ID); err != nil {
+			log.Printf("Failed to update identity %d last login: %v", identity.ID, err)
+		}
+	}
+	return &model.OAuthCallbackResult{Login: login}, nil
+}
+
+// link 将第三方账号绑定到已登录用户
+func (s *oauthService) link(ctx context.Context, userID int, providerName string, info *oauth.UserInfo, existing *model.Identity) (*model.Identity, error) {
+	if existing != nil {
+		if existing.UserID != userID {
+			return nil, ErrOAuthIdentityTaken
+		}
+		return existing, nil
+	}
+
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, identity := range identities {
+		if identity.Provider == providerName {
+			return nil, fmt.Errorf("a %s account is already linked, please unlink it first", providerName)
+		}
+	}
+
+	identity := &model.Identity{
+		UserID:      userID,
+		Provider:    providerName,
+		Subject:     info.Subject,
+		Email:       normalizeEmail(info.Email),
+		DisplayName: displayName(info),
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// provision 首次使用第三方账号登录时创建用户，与注册一样需要可用的邀请码
+func (s *oauthService) provision(ctx context.Context, providerName string, info *oauth.UserInfo, invitationCode string) (*model.User, error) {
+	// 邮箱写法与注册时一致，大小写不同的邮箱视为同一个
+	email := normalizeEmail(info.Email)
+	if email == "" || !info.EmailVerified {
+		return nil, errors.New("the provider did not return a verified email")
+	}
+
+	// 不按邮箱自动合并已有账号，防止通过第三方账号接管他人账号
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrOAuthEmailExists
+	}
+
+	if invitationCode == "" {
+		return nil, errors.New("invitation code is required to create an account")
+	}
+	invitation, err := s.invitationRepo.GetByCode(ctx, invitationCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkInvitationUsable(invitation); err != nil {
+		return nil, err
+	}
+
+	username, err := s.availableUsername(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	role := invitation.Role
+	if role == "" {
+		role = model.RoleUser
+	}
+	now := time.Now()
+	user := &model.User{
+		Username: username,
+		Email:    email,
+		Password: "", // 未设置密码，只能通过第三方账号登录，可通过忘记密码设置
+		Nickname: displayName(info),
+		Avatar:   info.AvatarURL,
+		Role:     role,
+		Cohort:   invitation.Cohort,
+	}
+	identity := &model.Identity{
+		Provider:    providerName,
+		Subject:     info.Subject,
+		Email:       email,
+		DisplayName: displayName(info),
+		LastLoginAt: &now,
+	}
+
+	if err := s.identityRepo.ProvisionWithInvitation(ctx, invitation.ID, user, identity); err != nil {
+		if errors.Is(err, repository.ErrInvitationUnavailable) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername 由提供方用户名或邮箱前缀生成符合规则（3-20位字母数字下划线）且未被占用的用户名
+func (s *oauthService) availableUsername(ctx context.Context, info *oauth.UserInfo) (string, error) {
+	base := info.Username
+	if base == "" {
+		base = strings.SplitN(info.Email, "@", 2)[0]
+	}
+	base = usernameInvalidChars.ReplaceAllString(base, "_")
+	if len(base) > 14 {
+		base = base[:14]
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		existing, err := s.userRepo.GetByUsername(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+		suffix, err := utils.GenerateNumericCode(5)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "_" + suffix
+	}
+	return "", errors.New("failed to generate a unique username")
+}
+
+// login 与密码登录一致：账号或IP被封禁时拒绝，启用两步验证时只返回待验证令牌（第二步通过后再记录登录）
+func (s *oauthService) login(ctx context.Context, user *model.User, event model.AuthEvent, client model.ClientInfo) (*model.LoginResult, error) {
+	event.UserID = user.ID
+	event.Identifier = user.Username
+
+	accountKey := loginguard.UserKey(user.ID)
+	if err := s.loginGuard.Check(ctx, accountKey, loginguard.IPKey(client.IP)); err != nil {
+		event.Type = model.AuthEventLoginBlocked
+		s.auditService.Record(ctx, event)
+		return nil, err
+	}
+
+	mfaEnabled, err := s.mfaService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mfaEnabled {
+		// 失败计数留到第二步通过后再清零
+		token, expiresIn, err := s.mfaService.IssuePendingToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginResult{MFARequired: true, MFAToken: token, MFAExpiresIn: expiresIn}, nil
+	}
+
+	if err := s.loginGuard.Succeed(ctx, accountKey); err != nil {
+		return nil, err
+	}
+	tokens, err := s.tokenService.IssueTokens(ctx, user, client, false)
+	if err != nil {
+		return nil, err
+	}
+	event.Type = model.AuthEventLogin
+	s.auditService.Record(ctx, event)
+	return &model.LoginResult{
+		Tokens:           tokens,
+		MFASetupRequired: s.mfaService.RequiredFor(user.Role),
+	}, nil
+}
+
+func (s *oauthService) ListIdentities(ctx context.Context, userID int) ([]model.Identity, error) {
+	return s.identityRepo.ListByUser(ctx, userID)
+}
+
+func (s *oauthService) Unlink(ctx context.Context, userID int, provider string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	// 没有密码且只绑定了这一个账号时解绑会导致无法登录
+	if user.Password == "" && len(identities) <= 1 {
+		return ErrOAuthLastLoginMethod
+	}
+
+	if err := s.identityRepo.Delete(ctx, userID, provider); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Identifier: user.Username, Type: model.AuthEventIdentityUnlinked, Detail: provider})
+	return nil
+}
+
+func (s *oauthService) Cleanup(ctx context.Context) error {
+	return s.identityRepo.DeleteExpiredStates(ctx)
+}
+
+func displayName(info *oauth.UserInfo) string {
+	if info.Name != "" {
+		return truncate(info.Name, 100)
+	}
+	if info.Username != "" {
+		return truncate(info.Username, 100)
+	}
+	return strings.SplitN(info.Email, "@", 2)[0]
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/oauth"
+	"testing"
+)
+
+func TestProvisionNormalizesEmail(t *testing.T) {
+	const email = "alice@example.edu"
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"as registered", email},
+		{"mixed case", "Alice@Example.EDU"},
+		{"surrounding spaces", " alice@example.edu\t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeEmailUsers{user: &model.User{ID: 1, Username: "alice", Email: email}}
+			svc := &oauthService{userRepo: users}
+
+			info := &oauth.UserInfo{Subject: "42", Email: tt.input, EmailVerified: true}
+			if _, err := svc.provision(context.Background(), "github", info, "INVITE"); !errors.Is(err, ErrOAuthEmailExists) {
+				t.Fatalf("provision error = %v, want %v", err, ErrOAuthEmailExists)
+			}
+			for _, got := range users.lookups {
+				if got != email {
+					t.Errorf("email used = %q, want %q", got, email)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// maxPersonalTokens 每个用户可同时持有的有效令牌数
+	maxPersonalTokens = 20
+	// defaultPersonalTokenDays 未指定有效期时的默认天数
+	defaultPersonalTokenDays = 90
+	// personalTokenPrefixLen 展示给用户的令牌前缀长度
+	personalTokenPrefixLen = 12
+)
+
+type PersonalTokenService interface {
+	// CreateToken 创建个人访问令牌，明文令牌只在创建时返回一次
+	CreateToken(ctx context.Context, userID int, req model.CreatePersonalTokenRequest) (*model.PersonalToken, string, error)
+	ListTokens(ctx context.Context, userID int) ([]model.PersonalToken, error)
+	RevokeToken(ctx context.Context, userID, tokenID int) error
+}
+
+type personalTokenService struct {
+	personalTokenRepo repository.PersonalTokenRepository
+	auditService      AuditService
+}
+
+func NewPersonalTokenService(personalTokenRepo repository.PersonalTokenRepository, auditService AuditService) PersonalTokenService {
+	return &personalTokenService{personalTokenRepo: personalTokenRepo, auditService: auditService}
+}
+
+func (s *personalTokenService) CreateToken(ctx context.Context, userID int, req model.CreatePersonalTokenRequest) (*model.PersonalToken, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", errors.New("token name is required")
+	}
+
+	count, err := s.personalTokenRepo.CountActive(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= maxPersonalTokens {
+		return nil, "", errors.New("too many personal access tokens, please revoke unused ones")
+	}
+
+	// 去重并按固定顺序保存授权范围
+	scopes := make([]string, 0, len(model.Scopes))
+	for _, scope := range model.Scopes {
+		for _, requested := range req.Scopes {
+			if requested == scope {
+				scopes = append(scopes, scope)
+				break
+			}
+		}
+	}
+
+	days := req.ExpiresInDays
+	if days <= 0 {
+		days = defaultPersonalTokenDays
+	}
+	expiresAt := time.Now().AddDate(0, 0, days)
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	plain := model.PersonalTokenPrefix + secret
+
+	token := &model.PersonalToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: utils.HashToken(plain),
+		Prefix:    plain[:personalTokenPrefixLen],
+		Scopes:    scopes,
+		ExpiresAt: &expiresAt,
+	}
+	if err := s.personalTokenRepo.Create(ctx, token); err != nil {
+		return nil, "", err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Type: model.AuthEventPersonalTokenCreated, Detail: fmt.Sprintf("token %d (%s)", token.ID, strings.Join(scopes, ","))})
+	return token, plain, nil
+}
+
+func (s *personalTokenService) ListTokens(ctx context.Context, userID int) ([]model.PersonalToken, error) {
+	return s.personalTokenRepo.ListByUser(ctx, userID)
+}
+
+func (s *personalTokenService) RevokeToken(ctx context.Context, userID, tokenID int) error {
+	if err := s.personalTokenRepo.Revoke(ctx, userID, tokenID); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Type: model.AuthEventPersonalTokenRevoked, Detail: fmt.Sprintf("token %d", tokenID)})
+	return nil
+}
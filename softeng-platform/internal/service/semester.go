@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSemesterNotFound = errors.New("semester not found")
+	// ErrSemesterExists 同一学年的同一学期或同名学期已存在
+	ErrSemesterExists = repository.ErrSemesterExists
+)
+
+type SemesterService interface {
+	// ListSemesters 获取学期目录，按时间先后排列
+	ListSemesters(ctx context.Context) ([]model.Semester, error)
+	CreateSemester(ctx context.Context, req model.CreateSemesterRequest) (*model.Semester, error)
+	// UpdateSemester 修改学期名称和起止日期
+	UpdateSemester(ctx context.Context, semesterID int, req model.UpdateSemesterRequest) (*model.Semester, error)
+	// AddOffering 为课程添加一次开课，返回课程的全部开课记录
+	AddOffering(ctx context.Context, courseID string, req model.AddOfferingRequest) ([]model.CourseOffering, error)
+}
+
+type semesterService struct {
+	semesterRepo repository.SemesterRepository
+	courseRepo   repository.CourseRepository
+}
+
+func NewSemesterService(semesterRepo repository.SemesterRepository, courseRepo repository.CourseRepository) SemesterService {
+	return &semesterService{semesterRepo: semesterRepo, courseRepo: courseRepo}
+}
+
+func (s *semesterService) ListSemesters(ctx context.Context) ([]model.Semester, error) {
+	return s.semesterRepo.List(ctx)
+}
+
+func (s *semesterService) CreateSemester(ctx context.Context, req model.CreateSemesterRequest) (*model.Semester, error) {
+	semester := &model.Semester{
+		Name:         strings.TrimSpace(req.Name),
+		AcademicYear: req.AcademicYear,
+		Term:         req.Term,
+	}
+	if semester.Name == "" {
+		semester.Name = model.SemesterName(req.AcademicYear, req.Term)
+	}
+	var err error
+	if semester.StartDate, semester.EndDate, err = normalizeSemesterDates(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.semesterRepo.Create(ctx, semester); err != nil {
+		return nil, err
+	}
+	return s.semesterRepo.GetByID(ctx, semester.SemesterID)
+}
+
+func (s *semesterService) UpdateSemester(ctx context.Context, semesterID int, req model.UpdateSemesterRequest) (*model.Semester, error) {
+	semester, err := s.semesterRepo.GetByID(ctx, semesterID)
+	if err != nil {
+		return nil, err
+	}
+	if semester == nil {
+		return nil, ErrSemesterNotFound
+	}
+
+	semester.Name = strings.TrimSpace(req.Name)
+	if semester.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if semester.StartDate, semester.EndDate, err = normalizeSemesterDates(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.semesterRepo.Update(ctx, semester); err != nil {
+		return nil, err
+	}
+	return s.semesterRepo.GetByID(ctx, semesterID)
+}
+
+func (s *semesterService) AddOffering(ctx context.Context, courseID string, req model.AddOfferingRequest) ([]model.CourseOffering, error) {
+	cid, err := strconv.Atoi(courseID)
+	if err != nil {
+		return nil, ErrCourseNotFound
+	}
+	// 审核中的课程也可以添加开课
+	_, status, err := s.courseRepo.GetCourseSubmitter(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if status == "" {
+		return nil, ErrCourseNotFound
+	}
+	semester, err := s.semesterRepo.GetByID(ctx, req.SemesterID)
+	if err != nil {
+		return nil, err
+	}
+	if semester == nil {
+		return nil, ErrSemesterNotFound
+	}
+
+	if err := s.semesterRepo.AddOffering(ctx, cid, semester.SemesterID, trimNonEmpty(req.Teachers)); err != nil {
+		return nil, err
+	}
+	return s.semesterRepo.ListOfferings(ctx, cid)
+}
+
+// normalizeSemesterDates 校验起止日期（YYYY-MM-DD，可为空），两者都有时结束日期不能早于开始日期
+func normalizeSemesterDates(start, end string) (string, string, error) {
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	var startDate, endDate time.Time
+	var err error
+	if start != "" {
+		if startDate, err = time.Parse(model.SemesterDateLayout, start); err != nil {
+			return "", "", errors.New("start_date must be in YYYY-MM-DD format")
+		}
+	}
+	if end != "" {
+		if endDate, err = time.Parse(model.SemesterDateLayout, end); err != nil {
+			return "", "", errors.New("end_date must be in YYYY-MM-DD format")
+		}
+	}
+	if start != "" && end != "" && endDate.Before(startDate) {
+		return "", "", errors.New("end_date must not be earlier than start_date")
+	}
+	return start, end, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"strings"
+)
+
+var (
+	ErrTeacherNotFound = repository.ErrTeacherNotFound
+	// ErrTeacherNameTaken 姓名或别名已属于另一位教师，需要合并而不是改名
+	ErrTeacherNameTaken = repository.ErrTeacherNameTaken
+)
+
+type TeacherService interface {
+	ListTeachers(ctx context.Context, keyword string, cursor, limit int) (map[string]interface{}, error)
+	// GetTeacher 获取教师资料、按学期分组的课程和汇总数据，没有审核通过课程的教师视为不存在
+	GetTeacher(ctx context.Context, teacherID int) (*model.TeacherDetail, error)
+	// UpdateTeacher 修改教师资料和别名
+	UpdateTeacher(ctx context.Context, teacherID int, req model.UpdateTeacherRequest) (*model.Teacher, error)
+	// MergeTeacher 将同一教师的另一条记录合并进来，返回合并后的教师
+	MergeTeacher(ctx context.Context, teacherID, sourceID int) (*model.Teacher, error)
+}
+
+type teacherService struct {
+	teacherRepo repository.TeacherRepository
+}
+
+func NewTeacherService(teacherRepo repository.TeacherRepository) TeacherService {
+	return &teacherService{teacherRepo: teacherRepo}
+}
+
+func (s *teacherService) ListTeachers(ctx context.Context, keyword string, cursor, limit int) (map[string]interface{}, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	teachers, err := s.teacherRepo.List(ctx, strings.TrimSpace(keyword), cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"message": "success",
+		"data":    teachers,
+		"cursor":  cursor + len(teachers),
+	}, nil
+}
+
+func (s *teacherService) GetTeacher(ctx context.Context, teacherID int) (*model.TeacherDetail, error) {
+	teacher, err := s.teacherRepo.GetByID(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil || teacher.CourseCount == 0 {
+		return nil, ErrTeacherNotFound
+	}
+
+	courses, err := s.teacherRepo.ListCourses(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	rating, err := s.teacherRepo.GetRatingSummary(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &model.TeacherDetail{
+		Teacher:   *teacher,
+		Semesters: make([]model.TeacherSemester, 0),
+		Stats:     model.TeacherStats{CourseCount: len(courses), Rating: rating},
+	}
+	// 查询结果已按学期排序，相同学期的课程是连续的
+	for _, course := range courses {
+		n := len(detail.Semesters)
+		if n == 0 || detail.Semesters[n-1].Semester != course.Semester {
+			detail.Semesters = append(detail.Semesters, model.TeacherSemester{Semester: course.Semester})
+			n++
+		}
+		detail.Semesters[n-1].Courses = append(detail.Semesters[n-1].Courses, course)
+
+		detail.Stats.TotalCredits += course.Credit
+		detail.Stats.TotalViews += course.Views
+		detail.Stats.TotalLikes += course.Likes
+		detail.Stats.TotalCollections += course.Collections
+	}
+	detail.Stats.SemesterCount = len(detail.Semesters)
+	return detail, nil
+}
+
+func (s *teacherService) UpdateTeacher(ctx context.Context, teacherID int, req model.UpdateTeacherRequest) (*model.Teacher, error) {
+	teacher, err := s.teacherRepo.GetByID(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if teacher == nil {
+		return nil, ErrTeacherNotFound
+	}
+
+	teacher.Name = model.NormalizeTeacherName(req.Name)
+	if teacher.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	teacher.Title = strings.TrimSpace(req.Title)
+	teacher.Department = strings.TrimSpace(req.Department)
+	teacher.Email = strings.TrimSpace(req.Email)
+	teacher.Homepage = strings.TrimSpace(req.Homepage)
+	teacher.Bio = strings.TrimSpace(req.Bio)
+
+	// 头像和课程封面一样本地化
+	avatar := strings.TrimSpace(req.Avatar)
+	if avatar != "" && avatar != teacher.Avatar {
+		if avatar, err = utils.ProcessImageURL(avatar); err != nil {
+			return nil, errors.New("invalid avatar")
+		}
+	}
+	teacher.Avatar = avatar
+
+	if err := s.teacherRepo.Update(ctx, teacher, normalizeTeacherAliases(teacher.Name, req.Aliases)); err != nil {
+		return nil, err
+	}
+	return s.teacherRepo.GetByID(ctx, teacherID)
+}
+
+func (s *teacherService) MergeTeacher(ctx context.Context, teacherID, sourceID int) (*model.Teacher, error) {
+	if teacherID == sourceID {
+		return nil, errors.New("cannot merge a teacher into itself")
+	}
+	if err := s.teacherRepo.Merge(ctx, teacherID, sourceID); err != nil {
+		return nil, err
+	}
+	return s.teacherRepo.GetByID(ctx, teacherID)
+}
+
+// normalizeTeacherAliases 规范别名写法，去掉空值、重复值和与姓名相同的写法；aliases 为 nil 时返回 nil 表示不修改
+func normalizeTeacherAliases(name string, aliases []string) []string {
+	if aliases == nil {
+		return nil
+	}
+	seen := map[string]bool{strings.ToLower(model.TeacherNameKey(name)): true}
+	result := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = model.NormalizeTeacherName(alias)
+		key := strings.ToLower(model.TeacherNameKey(alias))
+		if alias == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, alias)
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"testing"
+)
+
+// fakeTeachers 只有教师 1，合并时按仓库的写法报告找不到的教师
+type fakeTeachers struct {
+	repository.TeacherRepository
+}
+
+func (fakeTeachers) GetByID(ctx context.Context, teacherID int) (*model.Teacher, error) {
+	if teacherID != 1 {
+		return nil, nil
+	}
+	return &model.Teacher{TeacherID: 1, Name: "张三"}, nil
+}
+
+func (fakeTeachers) Merge(ctx context.Context, targetID, sourceID int) error {
+	if targetID != 1 {
+		return repository.ErrTeacherNotFound
+	}
+	if sourceID != 1 {
+		return fmt.Errorf("source %w", repository.ErrTeacherNotFound)
+	}
+	return nil
+}
+
+func TestTeacherNotFound(t *testing.T) {
+	svc := NewTeacherService(fakeTeachers{})
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"get", func() error { _, err := svc.GetTeacher(context.Background(), 2); return err }},
+		{"update", func() error {
+			_, err := svc.UpdateTeacher(context.Background(), 2, model.UpdateTeacherRequest{Name: "李四"})
+			return err
+		}},
+		{"merge into missing teacher", func() error { _, err := svc.MergeTeacher(context.Background(), 2, 1); return err }},
+		{"merge missing source", func() error { _, err := svc.MergeTeacher(context.Background(), 1, 3); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrTeacherNotFound) {
+				t.Fatalf("error = %v, want ErrTeacherNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken  = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected, please log in again")
+	ErrTokenRevoked         = errors.New("token has been revoked")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrInvalidPersonalToken = errors.New("invalid or expired personal access token")
+)
+
+// sessionTouchInterval 会话/个人令牌最后使用时间的最小更新间隔，避免每个请求都写库
+const sessionTouchInterval = time.Minute
+
+type TokenService interface {
+	// IssueTokens 为新登录创建会话（令牌家族）并签发令牌对，mfa 表示本次登录是否通过了两步验证
+	IssueTokens(ctx context.Context, user *model.User, client model.ClientInfo, mfa bool) (*model.TokenPair, error)
+	// Refresh 轮换刷新令牌；已使用过的刷新令牌再次出现时吊销整个家族
+	Refresh(ctx context.Context, refreshToken string, client model.ClientInfo) (*model.TokenPair, error)
+	// RevokeSession 登出当前会话
+	RevokeSession(ctx context.Context, claims *utils.Claims) error
+	// RevokeAllSessions 登出全部会话，exceptSessionID 非空时保留该会话
+	RevokeAllSessions(ctx context.Context, userID int, exceptSessionID string) error
+	// ListSessions 获取用户当前有效的会话，currentSessionID 用于标记当前设备
+	ListSessions(ctx context.Context, userID int, currentSessionID string) ([]model.Session, error)
+	// RevokeSessionByID 吊销用户的指定会话
+	RevokeSessionByID(ctx context.Context, userID, sessionID int) error
+	// VerifyAccessToken 供认证中间件检查访问令牌及其会话是否仍然有效
+	VerifyAccessToken(ctx context.Context, claims *utils.Claims) error
+	// AuthenticatePersonalToken 供认证中间件校验个人访问令牌，返回令牌及其所属用户
+	AuthenticatePersonalToken(ctx context.Context, token string) (*model.PersonalToken, *model.User, error)
+	// Cleanup 清理过期的令牌记录
+	Cleanup(ctx context.Context) error
+}
+
+type tokenService struct {
+	tokenRepo         repository.TokenRepository
+	personalTokenRepo repository.PersonalTokenRepository
+	userRepo          repository.UserRepository
+	auditService      AuditService
+	accessTTL         time.Duration
+	refreshTTL        time.Duration
+}
+
+func NewTokenService(tokenRepo repository.TokenRepository, personalTokenRepo repository.PersonalTokenRepository, userRepo repository.UserRepository, auditService AuditService, cfg *config.Config) TokenService {
+	return &tokenService{
+		tokenRepo:         tokenRepo,
+		personalTokenRepo: personalTokenRepo,
+		userRepo:          userRepo,
+		auditService:      auditService,
+		accessTTL:         cfg.AccessTokenTTL,
+		refreshTTL:        cfg.RefreshTokenTTL,
+	}
+}
+
+func (s *tokenService) IssueTokens(ctx context.Context, user *model.User, client model.ClientInfo, mfa bool) (*model.TokenPair, error) {
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		UserAgent: truncate(client.UserAgent, 255),
+		IP:        client.IP,
+		MFA:       mfa,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	if err := s.tokenRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, user, familyID, mfa)
+}
+
+// issue 在指定家族下签发访问令牌和新的刷新令牌
+func (s *tokenService) issue(ctx context.Context, user *model.User, familyID string, mfa bool) (*model.TokenPair, error) {
+	accessToken, claims, err := utils.GenerateToken(user.ID, user.Username, user.Role, familyID, mfa, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.RefreshToken{
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       utils.HashToken(refreshToken),
+		AccessJTI:       claims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		ExpiresAt:       time.Now().Add(s.refreshTTL),
+	}
+	if err := s.tokenRepo.CreateRefreshToken(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
+}
+
+func (s *tokenService) Refresh(ctx context.Context, refreshToken string, client model.ClientInfo) (*model.TokenPair, error) {
+	record, err := s.tokenRepo.GetRefreshTokenByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 已轮换过的令牌再次使用，说明令牌可能泄露，吊销整个家族
+	if record.UsedAt != nil {
+		if err := s.tokenRepo.RevokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		s.auditService.Record(ctx, model.AuthEvent{UserID: record.UserID, Type: model.AuthEventTokenReused, IP: client.IP, UserAgent: client.UserAgent})
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	ok, err := s.tokenRepo.MarkRefreshTokenUsed(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// 并发请求抢先使用了同一令牌，同样视为重用
+		if err := s.tokenRepo.RevokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		s.auditService.Record(ctx, model.AuthEvent{UserID: record.UserID, Type: model.AuthEventTokenReused, IP: client.IP, UserAgent: client.UserAgent})
+		return nil, ErrRefreshTokenReused
+	}
+
+	// 重新读取用户，确保角色等信息是最新的
+	user, err := s.userRepo.GetByID(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 两步验证状态随会话保留，刷新后的访问令牌沿用
+	session, err := s.tokenRepo.GetSessionByFamily(ctx, record.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	tokens, err := s.issue(ctx, user, record.FamilyID, session.MFA)
+	if err != nil {
+		return nil, err
+	}
+
+	// 刷新时顺延会话有效期并记录最新的客户端信息
+	if err := s.tokenRepo.TouchSession(ctx, record.FamilyID, client.IP, truncate(client.UserAgent, 255), time.Now().Add(s.refreshTTL)); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *tokenService) RevokeSession(ctx context.Context, claims *utils.Claims) error {
+	if claims.SessionID != "" {
+		if err := s.tokenRepo.RevokeFamily(ctx, claims.SessionID); err != nil {
+			return err
+		}
+	}
+
+	// 当前访问令牌也要立即失效
+	return s.tokenRepo.RevokeAccessToken(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time)
+}
+
+func (s *tokenService) RevokeAllSessions(ctx context.Context, userID int, exceptSessionID string) error {
+	return s.tokenRepo.RevokeUserFamilies(ctx, userID, exceptSessionID)
+}
+
+func (s *tokenService) ListSessions(ctx context.Context, userID int, currentSessionID string) ([]model.Session, error) {
+	sessions, err := s.tokenRepo.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].FamilyID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (s *tokenService) RevokeSessionByID(ctx context.Context, userID, sessionID int) error {
+	session, err := s.tokenRepo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// 只能吊销自己的会话
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	if err := s.tokenRepo.RevokeFamily(ctx, session.FamilyID); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Type: model.AuthEventSessionRevoked, Detail: fmt.Sprintf("session %d (%s)", session.ID, session.IP)})
+	return nil
+}
+
+func (s *tokenService) VerifyAccessToken(ctx context.Context, claims *utils.Claims) error {
+	// 旧版本签发的令牌没有 jti 和会话，无法吊销，直接拒绝；特殊用途令牌也不能作为访问令牌
+	if claims.ID == "" || claims.SessionID == "" || claims.Purpose != "" {
+		return ErrTokenRevoked
+	}
+
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+
+	session, err := s.tokenRepo.GetSessionByFamily(ctx, claims.SessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return ErrTokenRevoked
+	}
+
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.tokenRepo.TouchSession(ctx, session.FamilyID, "", "", time.Time{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *tokenService) AuthenticatePersonalToken(ctx context.Context, token string) (*model.PersonalToken, *model.User, error) {
+	pat, err := s.personalTokenRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if pat == nil || pat.RevokedAt != nil || (pat.ExpiresAt != nil && time.Now().After(*pat.ExpiresAt)) {
+		return nil, nil, ErrInvalidPersonalToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, pat.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, ErrInvalidPersonalToken
+	}
+
+	if pat.LastUsedAt == nil || time.Since(*pat.LastUsedAt) > sessionTouchInterval {
+		if err := s.personalTokenRepo.TouchLastUsed(ctx, pat.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pat, user, nil
+}
+
+func (s *tokenService) Cleanup(ctx context.Context) error {
+	return s.tokenRepo.DeleteExpired(ctx)
+}
+
+// truncate 按字符截断字符串，用于适配数据库字段长度
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
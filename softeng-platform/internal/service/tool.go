@@ -2,7 +2,23 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/repository"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrToolNotFound = errors.New("tool not found")
+	// ErrToolNotApproved 只有审核通过的工具可以提交修改提议
+	ErrToolNotApproved = errors.New("only approved tools can be edited")
+	// ErrToolRevisionEmpty 提议的内容与工具当前内容相同
+	ErrToolRevisionEmpty = errors.New("no changes proposed")
+	// ErrToolRevisionConflict 提议之后工具的相关字段已被修改，需要重新提议
+	ErrToolRevisionConflict = repository.ErrToolRevisionConflict
 )
 
 type ToolService interface {
@@ -19,6 +35,10 @@ type ToolService interface {
 	ReplyComment(ctx context.Context, userID int, resourceID, commentID, resourceType, content string) (map[string]interface{}, error)
 	DeleteReply(ctx context.Context, userID int, resourceID, commentID string) (map[string]interface{}, error)
 	AddView(ctx context.Context, resourceID string) (map[string]interface{}, error)
+	// UpdateTool 提议修改工具信息，保存为待审核的修改记录，审核通过后生效
+	UpdateTool(ctx context.Context, userID int, resourceID string, req model.ToolEditRequest) (*model.ToolRevision, error)
+	// GetToolRevisions 获取工具的修改记录（含待审核和已驳回的提议），最新的在前
+	GetToolRevisions(ctx context.Context, resourceID string) ([]model.ToolRevision, error)
 }
 
 // ToolSubmitRequest 工具提交请求结构体
@@ -212,3 +232,129 @@ func (s *toolService) AddView(ctx context.Context, resourceID string) (map[strin
 		},
 	}, nil
 }
+
+func (s *toolService) UpdateTool(ctx context.Context, userID int, resourceID string, req model.ToolEditRequest) (*model.ToolRevision, error) {
+	toolID, err := strconv.Atoi(resourceID)
+	if err != nil {
+		return nil, ErrToolNotFound
+	}
+	current, err := s.toolRepo.GetContent(ctx, toolID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrToolNotFound
+	}
+	if !current.Approved {
+		return nil, ErrToolNotApproved
+	}
+
+	proposed := *current
+	if req.Name != nil {
+		proposed.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Link != nil {
+		proposed.Link = strings.TrimSpace(*req.Link)
+	}
+	if req.Description != nil {
+		proposed.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.DescriptionDetail != nil {
+		proposed.DescriptionDetail = strings.TrimSpace(*req.DescriptionDetail)
+	}
+	if req.Category != nil {
+		proposed.Category = strings.TrimSpace(*req.Category)
+	}
+	if req.Tags != nil {
+		// 与 GetContent 读出的标签按同样的方式整理，只有顺序或大小写重复不同时不算修改
+		proposed.Tags = model.NormalizeToolTags(req.Tags)
+	}
+	if req.Images != nil {
+		proposed.Images = uniqueStrings(req.Images)
+	}
+	if err := validateToolContent(&proposed); err != nil {
+		return nil, err
+	}
+
+	changes := model.DiffToolContent(current, &proposed)
+	if len(changes) == 0 {
+		return nil, ErrToolRevisionEmpty
+	}
+	revision := &model.ToolRevision{
+		ToolID:   toolID,
+		EditorID: userID,
+		Summary:  strings.TrimSpace(req.Summary),
+		Changes:  changes,
+	}
+	if err := s.toolRepo.CreateRevision(ctx, revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (s *toolService) GetToolRevisions(ctx context.Context, resourceID string) ([]model.ToolRevision, error) {
+	toolID, err := strconv.Atoi(resourceID)
+	if err != nil {
+		return nil, ErrToolNotFound
+	}
+	current, err := s.toolRepo.GetContent(ctx, toolID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrToolNotFound
+	}
+	return s.toolRepo.GetRevisions(ctx, toolID)
+}
+
+// validateToolContent 校验修改后的工具内容，长度与 tools 表的列一致
+func validateToolContent(content *model.ToolContent) error {
+	if content.Name == "" {
+		return errors.New("name is required")
+	}
+	if utf8.RuneCountInString(content.Name) > 255 {
+		return errors.New("name must be at most 255 characters")
+	}
+	if !strings.HasPrefix(content.Link, "http://") && !strings.HasPrefix(content.Link, "https://") {
+		return errors.New("link must be an http(s) URL")
+	}
+	if utf8.RuneCountInString(content.Link) > 500 {
+		return errors.New("link must be at most 500 characters")
+	}
+	if utf8.RuneCountInString(content.Description) > 500 {
+		return errors.New("description must be at most 500 characters")
+	}
+	if utf8.RuneCountInString(content.Category) > 100 {
+		return errors.New("catagory must be at most 100 characters")
+	}
+	for _, tag := range content.Tags {
+		if utf8.RuneCountInString(tag) > 50 {
+			return fmt.Errorf("tag %q must be at most 50 characters", tag)
+		}
+	}
+	for _, image := range content.Images {
+		// 外部图片链接或上传接口返回的本地路径，"//host" 这样的协议相对地址不算本地路径
+		if !strings.HasPrefix(image, "http://") && !strings.HasPrefix(image, "https://") && !strings.HasPrefix(image, "/uploads/") {
+			return fmt.Errorf("image %q must be an http(s) URL or an uploaded path", image)
+		}
+		if utf8.RuneCountInString(image) > 500 {
+			return errors.New("image URL must be at most 500 characters")
+		}
+	}
+	return nil
+}
+
+// uniqueStrings 去除首尾空白、空项和重复项，保持原顺序
+func uniqueStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"testing"
+)
+
+// fakeToolContent 只有一个工具，记录保存的修改提议
+type fakeToolContent struct {
+	repository.ToolRepository
+	content   model.ToolContent
+	revisions []*model.ToolRevision
+}
+
+func (r *fakeToolContent) GetContent(ctx context.Context, toolID int) (*model.ToolContent, error) {
+	if toolID != 1 {
+		return nil, nil
+	}
+	content := r.content
+	return &content, nil
+}
+
+func (r *fakeToolContent) CreateRevision(ctx context.Context, revision *model.ToolRevision) error {
+	r.revisions = append(r.revisions, revision)
+	return nil
+}
+
+func TestUpdateTool(t *testing.T) {
+	text := func(s string) *string { return &s }
+	approved := model.ToolContent{
+		Name:     "GitHub",
+		Link:     "https://github.com",
+		Tags:     model.NormalizeToolTags([]string{"vcs", "Git"}),
+		Images:   []string{"/uploads/a.png"},
+		Approved: true,
+	}
+	pending := approved
+	pending.Approved = false
+
+	tests := []struct {
+		name    string
+		content model.ToolContent
+		req     model.ToolEditRequest
+		want    error  // nil 表示保存了修改提议
+		wantErr string // 其余校验错误，只检查是否出错
+		fields  []string
+	}{
+		{"not found", approved, model.ToolEditRequest{Name: text("x")}, ErrToolNotFound, "", nil},
+		{"pending tool", pending, model.ToolEditRequest{Name: text("GitHub Inc")}, ErrToolNotApproved, "", nil},
+		{"tags reordered", approved, model.ToolEditRequest{Tags: []string{" Git", "vcs", "git"}}, ErrToolRevisionEmpty, "", nil},
+		{"tag case changed", approved, model.ToolEditRequest{Tags: []string{"git", "vcs"}}, nil, "", []string{model.ToolFieldTags}},
+		{"uploaded image", approved, model.ToolEditRequest{Images: []string{"/uploads/b.png"}}, nil, "", []string{model.ToolFieldImages}},
+		{"protocol relative image", approved, model.ToolEditRequest{Images: []string{"//evil.example/a.png"}}, nil, "image", nil},
+		{"other local path", approved, model.ToolEditRequest{Images: []string{"/api/logout"}}, nil, "image", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeToolContent{content: tt.content}
+			svc := NewToolService(repo)
+			id := "1"
+			if tt.want == ErrToolNotFound {
+				id = "2"
+			}
+
+			revision, err := svc.UpdateTool(context.Background(), 7, id, tt.req)
+			switch {
+			case tt.wantErr != "":
+				if err == nil {
+					t.Fatalf("revision %+v saved, want %s error", revision, tt.wantErr)
+				}
+			case !errors.Is(err, tt.want):
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				if len(repo.revisions) != 0 {
+					t.Errorf("revision saved despite error %v", err)
+				}
+				return
+			}
+			if len(revision.Changes) != len(tt.fields) {
+				t.Fatalf("changes = %+v, want fields %v", revision.Changes, tt.fields)
+			}
+			for i, change := range revision.Changes {
+				if change.Field != tt.fields[i] {
+					t.Errorf("change %d field = %q, want %q", i, change.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
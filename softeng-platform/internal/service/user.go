@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"errors"
+	"softeng-platform/internal/loginguard"
 	"softeng-platform/internal/model"
 	"softeng-platform/internal/repository"
 	"softeng-platform/internal/utils"
@@ -19,17 +21,22 @@ type UserService interface {
 	GetSummit(ctx context.Context, userID int) (map[string]interface{}, error)
 	UpdateResourceStatus(ctx context.Context, userID int, resourceType, resourceID, action, state string) (map[string]interface{}, error)
 	UpdateEmail(ctx context.Context, userID int, name, password, newEmail, code string) (*model.User, error)
-	UpdatePassword(ctx context.Context, userID int, name, email, newPassword, code string) (*model.User, error)
+	UpdatePassword(ctx context.Context, userID int, sessionID, name, email, newPassword, code string) (*model.User, error)
 }
 
 type userService struct {
-	userRepo    repository.UserRepository
-	toolRepo    repository.ToolRepository
-	projectRepo repository.ProjectRepository
+	userRepo            repository.UserRepository
+	toolRepo            repository.ToolRepository
+	projectRepo         repository.ProjectRepository
+	courseRepo          repository.CourseRepository
+	verificationService VerificationService
+	tokenService        TokenService
+	loginGuard          *loginguard.Guard
+	auditService        AuditService
 }
 
-func NewUserService(userRepo repository.UserRepository, toolRepo repository.ToolRepository, projectRepo repository.ProjectRepository) UserService {
-	return &userService{userRepo: userRepo, toolRepo: toolRepo, projectRepo: projectRepo}
+func NewUserService(userRepo repository.UserRepository, toolRepo repository.ToolRepository, projectRepo repository.ProjectRepository, courseRepo repository.CourseRepository, verificationService VerificationService, tokenService TokenService, loginGuard *loginguard.Guard, auditService AuditService) UserService {
+	return &userService{userRepo: userRepo, toolRepo: toolRepo, projectRepo: projectRepo, courseRepo: courseRepo, verificationService: verificationService, tokenService: tokenService, loginGuard: loginGuard, auditService: auditService}
 }
 
 func (s *userService) GetProfile(ctx context.Context, userID int) (*model.User, error) {
@@ -232,8 +239,21 @@ func (s *userService) UpdateResourceStatus(ctx context.Context, userID int, reso
 		}
 		s.userRepo.LogStatusChange(ctx, resourceType, resourceID, oldStatus, action, userID)
 	case "course":
-		// 课程暂时不支持状态更新（没有status字段）
-		return nil, fmt.Errorf("status update not supported for %s", resourceType)
+		// 提交者只能重新提交（pending）或下线（offline），审核通过/驳回由管理员操作
+		if action != model.CourseStatusPending && action != model.CourseStatusOffline {
+			return nil, fmt.Errorf("invalid action for course: %s", action)
+		}
+		submitterID, status, err := s.courseRepo.GetCourseSubmitter(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		if status == "" || submitterID != userID {
+			return nil, fmt.Errorf("course not found or permission denied")
+		}
+		oldStatus = status
+		if err := s.courseRepo.UpdateCourseStatus(ctx, resourceID, action, ""); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("invalid resource type")
 	}
@@ -256,36 +276,63 @@ func (s *userService) UpdateEmail(ctx context.Context, userID int, name, passwor
 	if err != nil {
 		return nil, err
 	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
 	
 	// 验证用户名和密码
 	if user.Username != name {
 		return nil, fmt.Errorf("invalid username")
 	}
-	
-	// 验证密码（这里应该使用密码验证函数，暂时简化）
-	// if !utils.CheckPasswordHash(password, user.Password) {
-	//     return nil, fmt.Errorf("invalid password")
-	// }
-	
-	// TODO: 验证邮箱验证码 code
-	
-	// 检查新邮箱是否已存在
+	// 仅通过第三方账号登录的用户需要先设置密码
+	if user.Password == "" {
+		return nil, errors.New("please set a password before changing your email")
+	}
+	// 密码错误与登录失败一起计数，被盗用的访问令牌不能无限尝试密码
+	keys := guardKeys(ctx, userID)
+	if err := s.loginGuard.Check(ctx, keys...); err != nil {
+		return nil, err
+	}
+	if !utils.CheckPasswordHash(password, user.Password) {
+		if err := s.loginGuard.Fail(ctx, keys...); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("invalid password")
+	}
+	if err := s.loginGuard.Succeed(ctx, keys[0]); err != nil {
+		return nil, err
+	}
+
+	// 验证码发送到新邮箱，用于确认新邮箱归属（邮箱写法与注册时一致）
+	newEmail = normalizeEmail(newEmail)
+	if err := s.verificationService.ConsumeCode(ctx, newEmail, model.CodePurposeChangeEmail, code); err != nil {
+		return nil, err
+	}
+
+	// 已注册的邮箱收不到更换邮箱验证码，验证码通过后再检查，避免泄露邮箱是否已注册
 	existingUser, _ := s.userRepo.GetByEmail(ctx, newEmail)
 	if existingUser != nil {
 		return nil, fmt.Errorf("email already exists")
 	}
 	
 	// 更新邮箱
+	oldEmail := user.Email
 	user.Email = newEmail
 	err = s.userRepo.UpdateEmail(ctx, userID, newEmail)
 	if err != nil {
 		return nil, err
 	}
+	s.auditService.Record(ctx, model.AuthEvent{
+		UserID:     userID,
+		Identifier: user.Username,
+		Type:       model.AuthEventEmailChanged,
+		Detail:     fmt.Sprintf("%s -> %s", oldEmail, newEmail),
+	})
 	
 	return user, nil
 }
 
-func (s *userService) UpdatePassword(ctx context.Context, userID int, name, email, newPassword, code string) (*model.User, error) {
+func (s *userService) UpdatePassword(ctx context.Context, userID int, sessionID, name, email, newPassword, code string) (*model.User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -295,9 +342,17 @@ func (s *userService) UpdatePassword(ctx context.Context, userID int, name, emai
 	if user.Username != name || user.Email != email {
 		return nil, fmt.Errorf("invalid username or email")
 	}
+
+	// 检查密码策略
+	if err := utils.CheckPasswordPolicy(newPassword, user.Username, user.Email); err != nil {
+		return nil, err
+	}
 	
-	// TODO: 验证密码重置验证码 code
-	
+	// 验证发送到当前邮箱的重置密码验证码
+	if err := s.verificationService.ConsumeCode(ctx, user.Email, model.CodePurposeReset, code); err != nil {
+		return nil, err
+	}
+
 	// 加密新密码并更新
 	hashedPassword, err := s.hashPassword(newPassword)
 	if err != nil {
@@ -308,6 +363,12 @@ func (s *userService) UpdatePassword(ctx context.Context, userID int, name, emai
 	if err != nil {
 		return nil, err
 	}
+	s.auditService.Record(ctx, model.AuthEvent{UserID: userID, Identifier: user.Username, Type: model.AuthEventPasswordChanged})
+
+	// 修改密码后吊销其他设备上的会话，保留当前会话
+	if err := s.tokenService.RevokeAllSessions(ctx, userID, sessionID); err != nil {
+		return nil, err
+	}
 	
 	return user, nil
 }
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/loginguard"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/utils"
+	"testing"
+	"time"
+)
+
+// fakeEmailChangeUsers 在 fakeMFAUsers 基础上支持按邮箱查询和更新邮箱
+type fakeEmailChangeUsers struct {
+	fakeMFAUsers
+	taken string // 已被其他账号使用的邮箱
+}
+
+func (r *fakeEmailChangeUsers) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	if email == r.taken {
+		return &model.User{ID: 2, Email: email}, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeEmailChangeUsers) UpdateEmail(ctx context.Context, userID int, email string) error {
+	r.user.Email = email
+	return nil
+}
+
+func TestUpdateEmailUsesLoginGuard(t *testing.T) {
+	hashed, err := utils.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		LoginFreeAttempts:  2,
+		LoginBackoffBase:   time.Minute,
+		LoginBackoffMax:    time.Hour,
+		LoginLockThreshold: 5,
+		LoginLockDuration:  time.Hour,
+	}
+
+	tests := []struct {
+		name      string
+		password  string // 为空表示账号未设置密码
+		steps     []mfaStep
+		wantCodes int // 校验验证码的次数
+	}{
+		{
+			name:     "wrong passwords are throttled",
+			password: hashed,
+			steps: []mfaStep{
+				{password: "wrong", want: errInvalidPassword},
+				{password: "wrong", want: errInvalidPassword},
+				{password: "wrong", want: errBlocked},
+				{password: testPassword, want: errBlocked},
+			},
+		},
+		{
+			name:      "correct password",
+			password:  hashed,
+			steps:     []mfaStep{{password: testPassword}},
+			wantCodes: 1,
+		},
+		{
+			name:  "no password set",
+			steps: []mfaStep{{password: "", want: errInvalidPassword}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeEmailChangeUsers{fakeMFAUsers: fakeMFAUsers{user: &model.User{ID: 1, Username: "alice", Email: "alice@example.edu", Password: tt.password}}}
+			codes := &fakeCodes{}
+			guard := loginguard.NewGuard(loginguard.NewMemoryTracker(), cfg)
+			svc := NewUserService(users, nil, nil, nil, codes, nil, guard, &countingAudit{})
+			ctx := model.ContextWithClientInfo(context.Background(), model.ClientInfo{IP: "192.0.2.1"})
+
+			for i, step := range tt.steps {
+				_, err := svc.UpdateEmail(ctx, 1, "alice", step.password, "Alice2@Example.edu", "123456")
+
+				var blocked *loginguard.BlockedError
+				switch {
+				case step.want == errBlocked:
+					if !errors.As(err, &blocked) {
+						t.Fatalf("step %d: error = %v, want blocked", i+1, err)
+					}
+				case step.want == errInvalidPassword:
+					if err == nil || errors.As(err, &blocked) {
+						t.Fatalf("step %d: error = %v, want rejected password", i+1, err)
+					}
+				case err != nil:
+					t.Fatalf("step %d: error = %v", i+1, err)
+				}
+			}
+			if len(codes.emails) != tt.wantCodes {
+				t.Errorf("verification calls = %v, want %d", codes.emails, tt.wantCodes)
+			}
+			if tt.wantCodes > 0 && users.user.Email != "alice2@example.edu" {
+				t.Errorf("email = %q, want normalized new email", users.user.Email)
+			}
+		})
+	}
+}
+
+func TestUpdateEmailChecksCodeBeforeTakenEmail(t *testing.T) {
+	hashed, err := utils.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeEmailChangeUsers{
+		fakeMFAUsers: fakeMFAUsers{user: &model.User{ID: 1, Username: "alice", Email: "alice@example.edu", Password: hashed}},
+		taken:        "bob@example.edu",
+	}
+	codes := &fakeCodes{}
+	guard := loginguard.NewGuard(loginguard.NewMemoryTracker(), &config.Config{LoginFreeAttempts: 5, LoginLockThreshold: 10})
+	svc := NewUserService(users, nil, nil, nil, codes, nil, guard, &countingAudit{})
+
+	if _, err := svc.UpdateEmail(context.Background(), 1, "alice", testPassword, "bob@example.edu", "123456"); err == nil {
+		t.Fatal("changing to a taken email succeeded")
+	}
+	// 已注册的邮箱收不到验证码，只有验证码通过后才会提示邮箱已存在
+	if len(codes.emails) != 1 {
+		t.Errorf("verification calls = %v, want the code checked first", codes.emails)
+	}
+}
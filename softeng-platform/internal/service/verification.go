@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/mailer"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"strings"
+	"time"
+)
+
+const (
+	verificationCodeLength      = 6
+	verificationCodeTTL         = 10 * time.Minute
+	verificationCodeMaxAttempts = 5
+	verificationResendInterval  = time.Minute
+)
+
+var (
+	ErrInvalidCode     = errors.New("invalid verification code")
+	ErrCodeExpired     = errors.New("verification code expired")
+	ErrCodeAttempts    = errors.New("too many attempts, please request a new code")
+	ErrCodeTooFrequent = errors.New("verification code requested too frequently")
+)
+
+type VerificationService interface {
+	SendCode(ctx context.Context, email, purpose string) error
+	ConsumeCode(ctx context.Context, email, purpose, code string) error
+	// CheckCode 校验验证码但不使用，返回验证码ID，由调用方在自己的事务内或通过 ConsumeChecked 标记为已使用
+	CheckCode(ctx context.Context, email, purpose, code string) (int, error)
+	// ConsumeChecked 使用已通过 CheckCode 校验的验证码
+	ConsumeChecked(ctx context.Context, codeID int) error
+}
+
+type verificationService struct {
+	codeRepo repository.VerificationRepository
+	mailer   mailer.Mailer
+}
+
+func NewVerificationService(codeRepo repository.VerificationRepository, mailer mailer.Mailer) VerificationService {
+	return &verificationService{codeRepo: codeRepo, mailer: mailer}
+}
+
+func (s *verificationService) SendCode(ctx context.Context, email, purpose string) error {
+	email = normalizeEmail(email)
+
+	// 限制同一邮箱同一用途的发送频率
+	latest, err := s.codeRepo.GetLatest(ctx, email, purpose)
+	if err != nil {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < verificationResendInterval {
+		return ErrCodeTooFrequent
+	}
+
+	code, err := utils.GenerateNumericCode(verificationCodeLength)
+	if err != nil {
+		return err
+	}
+
+	// 重新发送时作废旧验证码，保证同一时刻只有一个有效验证码
+	if err := s.codeRepo.InvalidateActive(ctx, email, purpose); err != nil {
+		return err
+	}
+
+	record := &model.VerificationCode{
+		Email:       email,
+		Purpose:     purpose,
+		CodeHash:    hashVerificationCode(email, purpose, code),
+		MaxAttempts: verificationCodeMaxAttempts,
+		ExpiresAt:   time.Now().Add(verificationCodeTTL),
+	}
+	if err := s.codeRepo.Create(ctx, record); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "软件工程平台验证码",
+		Body: fmt.Sprintf("您好，\n\n您正在进行%s操作，验证码为：%s\n验证码 %d 分钟内有效，请勿泄露给他人。\n\n如非本人操作，请忽略此邮件。",
+			purposeLabel(purpose), code, int(verificationCodeTTL.Minutes())),
+	})
+}
+
+// ConsumeCode 校验并使用验证码，成功后验证码立即失效
+func (s *verificationService) ConsumeCode(ctx context.Context, email, purpose, code string) error {
+	id, err := s.CheckCode(ctx, email, purpose, code)
+	if err != nil {
+		return err
+	}
+	return s.ConsumeChecked(ctx, id)
+}
+
+func (s *verificationService) ConsumeChecked(ctx context.Context, codeID int) error {
+	consumed, err := s.codeRepo.MarkConsumed(ctx, codeID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// CheckCode 每次校验（包括正确的验证码）先占用一次校验次数，次数用完后验证码失效
+func (s *verificationService) CheckCode(ctx context.Context, email, purpose, code string) (int, error) {
+	email = normalizeEmail(email)
+
+	record, err := s.codeRepo.GetLatest(ctx, email, purpose)
+	if err != nil {
+		return 0, err
+	}
+	if record == nil {
+		return 0, ErrInvalidCode
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return 0, ErrCodeExpired
+	}
+	ok, err := s.codeRepo.UseAttempt(ctx, record.ID)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrCodeAttempts
+	}
+
+	if !utils.CompareHash(record.CodeHash, hashVerificationCode(email, purpose, strings.TrimSpace(code))) {
+		return 0, ErrInvalidCode
+	}
+	return record.ID, nil
+}
+
+func hashVerificationCode(email, purpose, code string) string {
+	return utils.HashToken(purpose + ":" + email + ":" + code)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func purposeLabel(purpose string) string {
+	switch purpose {
+	case model.CodePurposeRegister:
+		return "注册"
+	case model.CodePurposeReset:
+		return "重置密码"
+	case model.CodePurposeChangeEmail:
+		return "更换邮箱"
+	default:
+		return "身份验证"
+	}
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// resourceContentTypes 允许上传的课程资源类型（讲义、课件、文档、压缩包）
+var resourceContentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".ppt":  "application/vnd.ms-powerpoint",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".key":  "application/vnd.apple.keynote",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xls":  "application/vnd.ms-excel",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".txt":  "text/plain; charset=utf-8",
+	".md":   "text/markdown; charset=utf-8",
+	".zip":  "application/zip",
+	".rar":  "application/vnd.rar",
+	".7z":   "application/x-7z-compressed",
+	".tar":  "application/x-tar",
+	".gz":   "application/gzip",
+}
+
+// ErrResourceTypeNotAllowed 不支持的课程资源文件类型
+var ErrResourceTypeNotAllowed = errors.New("unsupported file type, allowed: pdf, ppt(x), key, doc(x), xls(x), txt, md, zip, rar, 7z, tar, gz")
+
+// resourceClient 下载外部课程资源的客户端，与图片下载一样拒绝内网和本机地址
+var resourceClient = NewHTTPClient(5*time.Minute, false)
+
+// ResourceContentType 按扩展名返回课程资源的 Content-Type，不支持的类型返回空字符串
+func ResourceContentType(filename string) string {
+	return resourceContentTypes[strings.ToLower(filepath.Ext(filename))]
+}
+
+// SaveResourceFile 将上传的课程资源保存到 dir 下（按年月组织），超过 maxSize 字节的文件不保存；
+// 返回相对 dir 的存储路径和实际写入的文件大小
+func SaveResourceFile(dir string, file *multipart.FileHeader, maxSize int64) (string, int64, error) {
+	if ResourceContentType(file.Filename) == "" {
+		return "", 0, ErrResourceTypeNotAllowed
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	return writeResourceFile(dir, file.Filename, src, maxSize)
+}
+
+// DownloadResourceFile 下载外部课程资源并保存到 dir 下，文件类型按 filename 判断，
+// 超过 maxSize 字节的文件不保存；返回存储路径和文件大小
+func DownloadResourceFile(dir, rawURL, filename string, maxSize int64) (string, int64, error) {
+	if ResourceContentType(filename) == "" {
+		return "", 0, ErrResourceTypeNotAllowed
+	}
+
+	resp, err := resourceClient.Get(rawURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to download resource: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("failed to download resource: status code %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxSize {
+		return "", 0, fmt.Errorf("resource too large: %d bytes", resp.ContentLength)
+	}
+	return writeResourceFile(dir, filename, resp.Body, maxSize)
+}
+
+// writeResourceFile 将文件内容保存到 dir 下（按年月组织），maxSize 小于 0 时不限制大小；返回存储路径和文件大小
+func writeResourceFile(dir, filename string, src io.Reader, maxSize int64) (string, int64, error) {
+	now := time.Now()
+	key := filepath.ToSlash(filepath.Join(now.Format("2006"), now.Format("01"), GenerateFileName(filename)))
+	path := filepath.Join(dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create resource dir: %w", err)
+	}
+
+	if maxSize >= 0 {
+		src = io.LimitReader(src, maxSize+1)
+	}
+	dst, err := os.Create(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create resource file: %w", err)
+	}
+	size, err := io.Copy(dst, src)
+	if err != nil {
+		dst.Close()
+		os.Remove(path)
+		return "", 0, fmt.Errorf("failed to save resource file: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path)
+		return "", 0, fmt.Errorf("failed to save resource file: %w", err)
+	}
+	if maxSize >= 0 && size > maxSize {
+		os.Remove(path)
+		return "", 0, fmt.Errorf("resource too large: more than %d bytes", maxSize)
+	}
+	return key, size, nil
+}
+
+// ResourceFilePath 将存储路径转换为 dir 下的文件路径，拒绝跳出 dir 的路径
+func ResourceFilePath(dir, key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.New("invalid resource path")
+	}
+	return filepath.Join(dir, clean), nil
+}
+
+// DeleteResourceFile 删除已保存的课程资源文件，文件不存在时忽略
+func DeleteResourceFile(dir, key string) error {
+	path, err := ResourceFilePath(dir, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete resource file: %w", err)
+	}
+	return nil
+}
+
+// AttachmentDisposition 生成下载用的 Content-Disposition，
+// filename 为 ASCII 兜底名，filename* 按 RFC 5987 携带 UTF-8 原始文件名
+func AttachmentDisposition(filename string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range filename {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			fallback.WriteByte('_')
+		} else {
+			fallback.WriteRune(r)
+		}
+	}
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback.String(), encoded.String())
+}
+
+// isAttrChar 判断字节是否为 RFC 5987 中无需编码的 attr-char
+func isAttrChar(b byte) bool {
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
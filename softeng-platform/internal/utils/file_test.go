@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteResourceFileLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		maxSize int64
+		wantErr bool
+	}{
+		{"below limit", 10, 16, false},
+		{"at limit", 16, 16, false},
+		{"over limit", 17, 16, true},
+		{"no limit", 1 << 10, -1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			key, size, err := writeResourceFile(dir, "slides.pdf", strings.NewReader(strings.Repeat("x", tt.size)), tt.maxSize)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("saved %q with %d bytes, want error", key, size)
+				}
+				// 超过上限的文件不应留在存储目录中
+				filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+					if err == nil && !info.IsDir() {
+						t.Errorf("leftover file %s", path)
+					}
+					return nil
+				})
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != int64(tt.size) {
+				t.Errorf("size = %d, want %d", size, tt.size)
+			}
+			path, err := ResourceFilePath(dir, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info, err := os.Stat(path); err != nil || info.Size() != int64(tt.size) {
+				t.Errorf("stored file = %v, %v", info, err)
+			}
+		})
+	}
+}
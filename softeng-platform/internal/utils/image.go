@@ -22,6 +22,10 @@ const (
 	AllowedImageTypes = "image/jpeg,image/jpg,image/png,image/gif,image/webp"
 )
 
+// imageClient 下载外部图片的客户端，拒绝连接内网和本机地址（包括重定向后的地址），
+// 图片地址可能来自用户提交或抓取的页面，不能用来访问内部服务
+var imageClient = NewHTTPClient(30*time.Second, false)
+
 // IsExternalURL 判断是否为外部URL
 func IsExternalURL(url string) bool {
 	if url == "" {
@@ -123,13 +127,8 @@ func SaveBase64Image(base64Data string) (string, error) {
 
 // DownloadAndSaveImage 下载外部图片并保存到本地
 func DownloadAndSaveImage(url string) (string, error) {
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-	
 	// 下载图片
-	resp, err := client.Get(url)
+	resp, err := imageClient.Get(url)
 	if err != nil {
 		return "", fmt.Errorf("failed to download image: %w", err)
 	}
@@ -230,6 +229,15 @@ func ProcessImageURL(imageURL string) (string, error) {
 	return imageURL, nil
 }
 
+// LocalImageExists 判断图片是否为已保存在本地的上传图片（以 /uploads/ 开头且文件存在）
+func LocalImageExists(imageURL string) bool {
+	if !strings.HasPrefix(imageURL, "/uploads/") {
+		return false
+	}
+	_, err := os.Stat(filepath.FromSlash(strings.TrimPrefix(imageURL, "/")))
+	return err == nil
+}
+
 // DeleteImageFile 删除图片文件
 func DeleteImageFile(imageURL string) error {
 	if imageURL == "" {
@@ -21,28 +21,78 @@ func initJWTSecret() {
 	})
 }
 
+// Claims 访问令牌声明，RegisteredClaims.ID 即令牌ID（jti），用于吊销
 type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`     // 所属会话（刷新令牌家族）
+	MFA       bool   `json:"mfa,omitempty"`     // 会话是否通过了两步验证
+	Purpose   string `json:"purpose,omitempty"` // 非空表示特殊用途令牌（如两步验证待完成），不能作为访问令牌使用
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int, username, role string) (string, error) {
+// PurposeMFAPending 密码已验证、等待两步验证的临时令牌
+const PurposeMFAPending = "mfa_pending"
+
+// GenerateToken 生成访问令牌，返回签名后的令牌及其声明（含 jti 和过期时间）
+func GenerateToken(userID int, username, role, sessionID string, mfa bool, ttl time.Duration) (string, *Claims, error) {
 	initJWTSecret()
 
-	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
+		MFA:       mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecret))
+	signed, err := token.SignedString([]byte(jwtSecret))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// GenerateMFAPendingToken 生成两步验证待完成令牌，只能用于提交第二步验证码
+func GenerateMFAPendingToken(userID int, ttl time.Duration) (string, error) {
+	initJWTSecret()
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:  userID,
+		Purpose: PurposeMFAPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtSecret))
+}
+
+// ValidateMFAPendingToken 校验两步验证待完成令牌
+func ValidateMFAPendingToken(tokenString string) (*Claims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeMFAPending {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return claims, nil
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
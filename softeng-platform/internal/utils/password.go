@@ -1,12 +1,31 @@
 package utils
 
 import (
+	"softeng-platform/internal/config"
+	"sync"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	bcryptCost     int
+	bcryptCostOnce sync.Once
+)
+
+// initBcryptCost 初始化 bcrypt 计算成本，只加载一次
+func initBcryptCost() {
+	bcryptCostOnce.Do(func() {
+		bcryptCost = config.LoadConfig().BcryptCost
+		if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+			bcryptCost = bcrypt.DefaultCost
+		}
+	})
+}
+
 // HashPassword 加密密码
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	initBcryptCost()
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
 	return string(bytes), err
 }
 
@@ -15,3 +34,13 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// NeedsRehash 判断哈希的计算成本是否低于当前配置，需要在登录成功后重新哈希
+func NeedsRehash(hash string) bool {
+	initBcryptCost()
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false
+	}
+	return cost < bcryptCost
+}
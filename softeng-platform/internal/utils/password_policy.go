@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+	"softeng-platform/internal/config"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// 字符类别
+const (
+	CharClassLower  = "lower"
+	CharClassUpper  = "upper"
+	CharClassLetter = "letter"
+	CharClassDigit  = "digit"
+	CharClassSymbol = "symbol"
+)
+
+var charClassLabels = map[string]string{
+	CharClassLower:  "a lowercase letter",
+	CharClassUpper:  "an uppercase letter",
+	CharClassLetter: "a letter",
+	CharClassDigit:  "a digit",
+	CharClassSymbol: "a symbol",
+}
+
+// PasswordViolation 单条违反密码策略的原因
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError 密码不符合策略，Violations 列出全部原因
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return "password does not meet the policy: " + strings.Join(messages, "; ")
+}
+
+// PasswordPolicy 密码策略
+type PasswordPolicy struct {
+	MinLength   int
+	MaxLength   int      // bcrypt 只使用前 72 字节
+	CharClasses []string // 必须包含的字符类别
+	blocklist   map[string]struct{}
+}
+
+var (
+	passwordPolicy     *PasswordPolicy
+	passwordPolicyOnce sync.Once
+)
+
+// initPasswordPolicy 初始化密码策略，只加载一次
+func initPasswordPolicy() {
+	passwordPolicyOnce.Do(func() {
+		passwordPolicy = NewPasswordPolicy(config.LoadConfig())
+	})
+}
+
+// NewPasswordPolicy 根据配置创建密码策略
+func NewPasswordPolicy(cfg *config.Config) *PasswordPolicy {
+	p := &PasswordPolicy{
+		MinLength: cfg.PasswordMinLength,
+		MaxLength: cfg.PasswordMaxLength,
+		blocklist: make(map[string]struct{}),
+	}
+	if p.MaxLength <= 0 || p.MaxLength > 72 {
+		p.MaxLength = 72
+	}
+
+	for _, class := range strings.Split(cfg.PasswordCharClasses, ",") {
+		class = strings.TrimSpace(class)
+		if class == "" {
+			continue
+		}
+		if _, ok := charClassLabels[class]; !ok {
+			log.Printf("Unknown password character class %q, ignored", class)
+			continue
+		}
+		p.CharClasses = append(p.CharClasses, class)
+	}
+
+	p.addBlocklist(commonPasswords)
+	if cfg.PasswordBlocklistFile != "" {
+		data, err := os.ReadFile(cfg.PasswordBlocklistFile)
+		if err != nil {
+			log.Printf("Failed to read password blocklist %s: %v", cfg.PasswordBlocklistFile, err)
+		} else {
+			p.addBlocklist(string(data))
+		}
+	}
+	return p
+}
+
+func (p *PasswordPolicy) addBlocklist(content string) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.blocklist[strings.ToLower(line)] = struct{}{}
+	}
+}
+
+// Check 按策略检查密码，username 和 email 用于检查密码是否包含账号信息
+func (p *PasswordPolicy) Check(password, username, email string) error {
+	var violations []PasswordViolation
+	add := func(code, message string) {
+		violations = append(violations, PasswordViolation{Code: code, Message: message})
+	}
+
+	length := len([]rune(password))
+	if length < p.MinLength {
+		add("too_short", fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if len(password) > p.MaxLength {
+		add("too_long", fmt.Sprintf("must be at most %d bytes", p.MaxLength))
+	}
+
+	for _, class := range p.CharClasses {
+		if !containsCharClass(password, class) {
+			add("missing_"+class, "must contain "+charClassLabels[class])
+		}
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := p.blocklist[lower]; ok {
+		add("too_common", "is too common")
+	}
+
+	username = strings.ToLower(strings.TrimSpace(username))
+	if username != "" && strings.Contains(lower, username) {
+		add("contains_username", "must not contain the username")
+	}
+	if at := strings.Index(email, "@"); at > 0 {
+		local := strings.ToLower(strings.TrimSpace(email[:at]))
+		// 邮箱前缀太短时容易误判，忽略
+		if len(local) >= 3 && local != username && strings.Contains(lower, local) {
+			add("contains_email", "must not contain the email name")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func containsCharClass(password, class string) bool {
+	for _, r := range password {
+		switch class {
+		case CharClassLower:
+			if unicode.IsLower(r) {
+				return true
+			}
+		case CharClassUpper:
+			if unicode.IsUpper(r) {
+				return true
+			}
+		case CharClassLetter:
+			if unicode.IsLetter(r) {
+				return true
+			}
+		case CharClassDigit:
+			if unicode.IsDigit(r) {
+				return true
+			}
+		case CharClassSymbol:
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckPasswordPolicy 使用全局配置的密码策略检查密码
+func CheckPasswordPolicy(password, username, email string) error {
+	initPasswordPolicy()
+	return passwordPolicy.Check(password, username, email)
+}
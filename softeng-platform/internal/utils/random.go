@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+const (
+	// DigitCharset 数字字符集
+	DigitCharset = "0123456789"
+	// CodeCharset 去掉易混淆字符（0/O、1/I/L）的大写字母数字字符集
+	CodeCharset = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+)
+
+// GenerateRandomString 从给定字符集中随机生成指定长度的字符串
+func GenerateRandomString(length int, charset string) (string, error) {
+	max := big.NewInt(int64(len(charset)))
+	out := make([]byte, length)
+	for i := range out {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate code: %w", err)
+		}
+		out[i] = charset[n.Int64()]
+	}
+	return string(out), nil
+}
+
+// GenerateNumericCode 生成指定位数的数字验证码
+func GenerateNumericCode(length int) (string, error) {
+	return GenerateRandomString(length, DigitCharset)
+}
+
+// GenerateRandomToken 生成随机令牌（十六进制字符串，长度为 2*n）
+func GenerateRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken 计算令牌的 SHA-256 摘要，用于存储验证码、刷新令牌等一次性凭证
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareHash 常量时间比较两个摘要
+func CompareHash(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"regexp"
-	"unicode"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -13,7 +12,6 @@ func init() {
 	validate = validator.New()
 
 	// 注册自定义验证规则
-	_ = validate.RegisterValidation("password", validatePassword)
 	_ = validate.RegisterValidation("username", validateUsername)
 }
 
@@ -22,27 +20,7 @@ func ValidateStruct(s interface{}) error {
 	return validate.Struct(s)
 }
 
-// validatePassword 自定义密码验证
-func validatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-
-	// 密码长度至少6位
-	if len(password) < 6 {
-		return false
-	}
-
-	// 检查是否包含数字和字母
-	var hasLetter, hasNumber bool
-	for _, char := range password {
-		if unicode.IsLetter(char) {
-			hasLetter = true
-		} else if unicode.IsNumber(char) {
-			hasNumber = true
-		}
-	}
-
-	return hasLetter && hasNumber
-}
+// 密码规则由 PasswordPolicy（password_policy.go）统一校验，需要结合用户名等上下文，不作为 tag 注册
 
 // validateUsername 自定义用户名验证
 func validateUsername(fl validator.FieldLevel) bool {
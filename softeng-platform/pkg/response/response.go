@@ -35,3 +35,11 @@ func ErrorWithData(c *gin.Context, code int, message string, errorData string) {
 		Data:    errorData,
 	})
 }
+
+// ErrorWithDetails 带结构化错误详情的错误响应（如密码策略的逐条违规原因）
+func ErrorWithDetails(c *gin.Context, code int, message string, details interface{}) {
+	c.JSON(code, ErrorResponse{
+		Message: message,
+		Data:    details,
+	})
+}